@@ -0,0 +1,142 @@
+// Command admin manages plans and issues vouchers. It reads the same
+// configuration as the API.
+//
+//	admin plan -id premium -name Premium -max-devices 10 -history-depth 500
+//	admin vouchers -plan premium -days 30 -count 100
+//
+// Voucher codes are printed once, one per line; only their hashes are
+// stored.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ObscuraNote/api-general/internal/users/dto"
+	usersRepository "github.com/ObscuraNote/api-general/internal/users/repository"
+	userService "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/logger"
+)
+
+const (
+	maxVouchers     = 10000
+	maxDurationDays = 10 * 365
+)
+
+var migrationsPath = "file://./migrations"
+
+func main() {
+	ctx := context.Background()
+	log := logger.New(ctx)
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "admin", "function": "main"}).
+			Error("Failed to load configuration")
+
+		os.Exit(1)
+	}
+
+	db, err := postgresdb.New(ctx, cfg.DB, false, migrationsPath)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "admin", "function": "main"}).
+			Error("Failed to connect to database")
+
+		os.Exit(1)
+	}
+
+	uRepo, err := usersRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "admin", "function": "main"}).
+			Error("Failed to create users repository")
+
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "plan":
+		err = upsertPlan(uRepo, os.Args[2:])
+	case "vouchers":
+		err = issueVouchers(uRepo, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "admin", "function": os.Args[1]}).
+			Error("Command failed")
+
+		os.Exit(1)
+	}
+}
+
+// upsertPlan creates a plan or updates its entitlements.
+func upsertPlan(repo usersRepository.UsersRepository, args []string) error {
+	var plan dto.Plan
+	flags := flag.NewFlagSet("plan", flag.ExitOnError)
+	flags.StringVar(&plan.ID, "id", "", "plan identifier, e.g. premium")
+	flags.StringVar(&plan.Name, "name", "", "display name")
+	flags.Int64Var(&plan.MaxEntries, "max-entries", 0, "maximum number of entries, 0 for unlimited")
+	flags.Int64Var(&plan.MaxBytes, "max-bytes", 0, "maximum bytes of entry data, 0 for unlimited")
+	flags.Int64Var(&plan.MaxAttachmentBytes, "max-attachment-bytes", 0, "maximum bytes of attachments, 0 for unlimited")
+	flags.IntVar(&plan.MaxDevices, "max-devices", 0, "maximum number of devices, 0 for unlimited")
+	flags.BoolVar(&plan.SharingAllowed, "sharing", true, "allow sharing entries with other accounts")
+	flags.IntVar(&plan.HistoryDepth, "history-depth", 0, "operations kept behind a snapshot")
+	_ = flags.Parse(args)
+
+	if plan.ID == "" || plan.Name == "" || plan.ID == dto.DefaultPlan {
+		return fmt.Errorf("a plan needs an -id other than %q and a -name", dto.DefaultPlan)
+	}
+
+	if err := repo.UpsertPlan(plan); err != nil {
+		return err
+	}
+	fmt.Printf("plan %s saved\n", plan.ID)
+
+	return nil
+}
+
+// issueVouchers stores count new vouchers for a plan and prints their codes.
+func issueVouchers(repo usersRepository.UsersRepository, args []string) error {
+	flags := flag.NewFlagSet("vouchers", flag.ExitOnError)
+	planId := flags.String("plan", "", "plan the vouchers grant")
+	days := flags.Int("days", 30, "days of the plan granted by each voucher")
+	count := flags.Int("count", 1, "number of vouchers to issue")
+	_ = flags.Parse(args)
+
+	if *planId == "" || *days <= 0 || *days > maxDurationDays || *count <= 0 || *count > maxVouchers {
+		return fmt.Errorf("vouchers need a -plan, -days between 1 and %d and -count between 1 and %d", maxDurationDays, maxVouchers)
+	}
+
+	codes := make([]string, 0, *count)
+	hashes := make([][]byte, 0, *count)
+	for i := 0; i < *count; i++ {
+		code, hash, err := userService.NewVoucherCode()
+		if err != nil {
+			return err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hash)
+	}
+
+	if err := repo.AddVouchers(*planId, *days, hashes); err != nil {
+		return err
+	}
+	for _, code := range codes {
+		fmt.Println(code)
+	}
+
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: admin plan|vouchers [flags]")
+	os.Exit(2)
+}
@@ -4,13 +4,52 @@ import (
 	"context"
 	"os"
 
+	aHTTP "github.com/ObscuraNote/api-general/internal/attachments/http"
+	attachmentsRepository "github.com/ObscuraNote/api-general/internal/attachments/repository"
+	attachmentsService "github.com/ObscuraNote/api-general/internal/attachments/service"
+	cHTTP "github.com/ObscuraNote/api-general/internal/comments/http"
+	commentsRepository "github.com/ObscuraNote/api-general/internal/comments/repository"
+	commentsService "github.com/ObscuraNote/api-general/internal/comments/service"
+	dHTTP "github.com/ObscuraNote/api-general/internal/dropbox/http"
+	dropboxRepository "github.com/ObscuraNote/api-general/internal/dropbox/repository"
+	dropboxService "github.com/ObscuraNote/api-general/internal/dropbox/service"
+	eHTTP "github.com/ObscuraNote/api-general/internal/events/http"
+	eventsRepository "github.com/ObscuraNote/api-general/internal/events/repository"
+	eventsService "github.com/ObscuraNote/api-general/internal/events/service"
 	kHTTP "github.com/ObscuraNote/api-general/internal/keys/http"
 	keysRepository "github.com/ObscuraNote/api-general/internal/keys/repository"
 	keysService "github.com/ObscuraNote/api-general/internal/keys/service"
+	msgHTTP "github.com/ObscuraNote/api-general/internal/messages/http"
+	messagesRepository "github.com/ObscuraNote/api-general/internal/messages/repository"
+	messagesService "github.com/ObscuraNote/api-general/internal/messages/service"
+	mHTTP "github.com/ObscuraNote/api-general/internal/mls/http"
+	mlsRepository "github.com/ObscuraNote/api-general/internal/mls/repository"
+	mlsService "github.com/ObscuraNote/api-general/internal/mls/service"
+	oHTTP "github.com/ObscuraNote/api-general/internal/oplog/http"
+	oplogRepository "github.com/ObscuraNote/api-general/internal/oplog/repository"
+	oplogService "github.com/ObscuraNote/api-general/internal/oplog/service"
+	outboxRepository "github.com/ObscuraNote/api-general/internal/outbox/repository"
+	outboxService "github.com/ObscuraNote/api-general/internal/outbox/service"
+	outboxSink "github.com/ObscuraNote/api-general/internal/outbox/sink"
+	sHTTP "github.com/ObscuraNote/api-general/internal/secrets/http"
+	secretsRepository "github.com/ObscuraNote/api-general/internal/secrets/repository"
+	secretsService "github.com/ObscuraNote/api-general/internal/secrets/service"
+	tHTTP "github.com/ObscuraNote/api-general/internal/transparency/http"
+	transparencyRepository "github.com/ObscuraNote/api-general/internal/transparency/repository"
+	transparencyService "github.com/ObscuraNote/api-general/internal/transparency/service"
+	upHTTP "github.com/ObscuraNote/api-general/internal/uploads/http"
+	uploadsRepository "github.com/ObscuraNote/api-general/internal/uploads/repository"
+	uploadsService "github.com/ObscuraNote/api-general/internal/uploads/service"
 	uHTTP "github.com/ObscuraNote/api-general/internal/users/http"
 	usersRepository "github.com/ObscuraNote/api-general/internal/users/repository"
 	userService "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils/blobstore"
 	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/ObscuraNote/api-general/internal/utils/eventbus"
+	"github.com/ObscuraNote/api-general/internal/utils/scheduler"
+	vHTTP "github.com/ObscuraNote/api-general/internal/vaults/http"
+	vaultsRepository "github.com/ObscuraNote/api-general/internal/vaults/repository"
+	vaultsService "github.com/ObscuraNote/api-general/internal/vaults/service"
 	"github.com/philippe-berto/database/postgresdb"
 	httpkit "github.com/philippe-berto/httpkit"
 	metrics "github.com/philippe-berto/httpkit/metrics"
@@ -47,6 +86,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	tRepo, err := transparencyRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create transparency repository")
+		os.Exit(1)
+	}
+
+	tServ, err := transparencyService.New(ctx, *log, tRepo, cfg.Transparency)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create transparency service")
+		os.Exit(1)
+	}
+	log.Info("Transparency service initialized")
+
 	uRepo, err := usersRepository.New(ctx, db)
 	if err != nil {
 		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
@@ -55,22 +109,205 @@ func main() {
 	}
 	log.Info("Users repository initialized")
 
-	uServ := userService.New(ctx, uRepo)
+	bus, err := eventbus.New(cfg.EventBus, cfg.DB, db, *log)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create event bus")
+		os.Exit(1)
+	}
+	log.Info("Event bus initialized")
+
+	uServ := userService.New(ctx, uRepo, tServ, bus, cfg.Quota, cfg.Entitlements)
 	log.Info("User service initialized")
 
-	kRepo := keysRepository.New(ctx, db)
+	vRepo, err := vaultsRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create vaults repository")
+		os.Exit(1)
+	}
+
+	vServ := vaultsService.New(ctx, *log, vRepo, uServ)
+	log.Info("Vaults service initialized")
+
+	mRepo, err := mlsRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create mls repository")
+		os.Exit(1)
+	}
+
+	mServ := mlsService.New(ctx, *log, mRepo, uServ, vServ, cfg.MLS)
+	log.Info("MLS delivery service initialized")
+
+	store, err := blobstore.New(cfg.Blob)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create blob store")
+		os.Exit(1)
+	}
+
+	upRepo, err := uploadsRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create uploads repository")
+		os.Exit(1)
+	}
+
+	upServ := uploadsService.New(ctx, *log, upRepo, uServ, store, cfg.Uploads)
+	log.Info("Uploads service initialized")
+
+	kRepo := keysRepository.New(ctx, db, store, cfg.Tiering, cfg.Sync)
 	if kRepo == nil {
 		log.WithFields(logger.Fields{"error": "Failed to create keys repository", "component": "main", "function": "main"}).
 			Error("Failed to create keys repository")
 		os.Exit(1)
 	}
 
-	kServ := keysService.New(ctx, *log, kRepo, uServ)
+	kServ, err := keysService.New(ctx, *log, kRepo, uServ, vServ, upServ, bus, cfg.Export)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create keys service")
+		os.Exit(1)
+	}
 	log.Info("Keys service initialized")
 
+	cRepo, err := commentsRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create comments repository")
+		os.Exit(1)
+	}
+
+	cServ := commentsService.New(ctx, *log, cRepo, uServ, &kServ)
+	log.Info("Comments service initialized")
+
+	oRepo, err := oplogRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create oplog repository")
+		os.Exit(1)
+	}
+
+	oServ := oplogService.New(ctx, *log, oRepo, uServ, &kServ)
+	log.Info("Oplog service initialized")
+
+	sRepo, err := secretsRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create secrets repository")
+		os.Exit(1)
+	}
+
+	sServ := secretsService.New(ctx, *log, sRepo, cfg.Secrets)
+	log.Info("Secrets service initialized")
+
+	dRepo, err := dropboxRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create dropbox repository")
+		os.Exit(1)
+	}
+
+	dServ, err := dropboxService.New(ctx, *log, dRepo, uServ, cfg.Dropbox)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create dropbox service")
+		os.Exit(1)
+	}
+	log.Info("Dropbox service initialized")
+
+	msgRepo, err := messagesRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create messages repository")
+		os.Exit(1)
+	}
+
+	msgServ := messagesService.New(ctx, *log, msgRepo, uServ, cfg.Messages)
+	log.Info("Messages service initialized")
+
+	aRepo, err := attachmentsRepository.New(ctx, db)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create attachments repository")
+		os.Exit(1)
+	}
+
+	aServ := attachmentsService.New(ctx, *log, aRepo, uServ, &kServ, store, cfg.Attachments)
+	log.Info("Attachments service initialized")
+
+	eRepo, err := eventsRepository.New(ctx, db, cfg.Events)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create events repository")
+		os.Exit(1)
+	}
+
+	eServ := eventsService.New(ctx, *log, eRepo, uServ, bus, cfg.Events)
+	go eServ.Start(ctx)
+	log.Info("Events service initialized")
+
+	obRepo, err := outboxRepository.New(ctx, db, cfg.Outbox)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create outbox repository")
+		os.Exit(1)
+	}
+
+	obSink, err := outboxSink.New(cfg.Outbox, *log)
+	if err != nil {
+		log.WithFields(logger.Fields{"error": err.Error(), "component": "main", "function": "main"}).
+			Error("Failed to create outbox sink")
+		os.Exit(1)
+	}
+
+	if cfg.Outbox.Enable {
+		obServ := outboxService.New(ctx, *log, obRepo, obSink, cfg.Outbox)
+		go scheduler.Start(ctx, *log, scheduler.Job{Name: "outbox_dispatcher", Interval: cfg.Outbox.Interval, Run: obServ.Dispatch})
+		log.Info("Outbox dispatcher started")
+	}
+
+	// Releases, emergency access and payload tiering are how those features
+	// work, so they always run. Each cleanup job has its own switch.
+	go scheduler.Start(ctx, *log, scheduler.Job{Name: "keys_releaser", Interval: cfg.Reaper.ReleaseInterval, Run: kServ.ReleaseDueKeys})
+	go scheduler.Start(ctx, *log, scheduler.Job{Name: "emergency_access", Interval: cfg.Emergency.Interval, Run: uRepo.ProcessEmergencyAccess})
+	go scheduler.Start(ctx, *log, scheduler.Job{Name: "keys_tiering", Interval: cfg.Tiering.Interval, Run: kRepo.MigratePayloads})
+
+	reapers := []struct {
+		enable bool
+		job    scheduler.Job
+	}{
+		{cfg.Reaper.Enable, scheduler.Job{Name: "keys_reaper", Interval: cfg.Reaper.Interval, Run: kRepo.DeleteExpiredKeys}},
+		{cfg.Secrets.ReaperEnable, scheduler.Job{Name: "secrets_reaper", Interval: cfg.Secrets.ReaperInterval, Run: sRepo.DeleteExpiredSecrets}},
+		{cfg.Dropbox.ReaperEnable, scheduler.Job{Name: "dropbox_reaper", Interval: cfg.Dropbox.ReaperInterval, Run: dRepo.DeleteExpiredChallenges}},
+		{cfg.Attachments.ReaperEnable, scheduler.Job{Name: "attachments_reaper", Interval: cfg.Attachments.ReaperInterval, Run: aServ.CollectGarbage}},
+		{cfg.Uploads.ReaperEnable, scheduler.Job{Name: "uploads_reaper", Interval: cfg.Uploads.ReaperInterval, Run: upRepo.DeleteExpiredUploads}},
+		{cfg.Sync.ReaperEnable, scheduler.Job{Name: "keys_tombstones", Interval: cfg.Sync.ReaperInterval, Run: kRepo.DeleteOldTombstones}},
+		{cfg.Events.ReaperEnable, scheduler.Job{Name: "events_reaper", Interval: cfg.Events.ReaperInterval, Run: eRepo.DeleteOldEvents}},
+		{cfg.Outbox.ReaperEnable, scheduler.Job{Name: "outbox_reaper", Interval: cfg.Outbox.ReaperInterval, Run: obRepo.DeleteOldDelivered}},
+	}
+	for _, reaper := range reapers {
+		if reaper.enable {
+			go scheduler.Start(ctx, *log, reaper.job)
+		}
+	}
+	log.Info("Background jobs started")
+
 	server := httpkit.New(cfg.Port, false, false, cfg.EnableCORS, cfg.CorsAllowOrigins)
 	uHTTP.Register(server.Router, uServ, *log)
-	kHTTP.Register(server.Router, &kServ, uServ, *log)
+	kHTTP.Register(server.Router, &kServ, uServ, cfg.Export.MaxImportSize, *log)
+	sHTTP.Register(server.Router, sServ, cfg.Secrets.MaxSize, *log)
+	tHTTP.Register(server.Router, tServ, *log)
+	vHTTP.Register(server.Router, vServ, *log)
+	mHTTP.Register(server.Router, mServ, cfg.MLS.MaxMessageSize, *log)
+	cHTTP.Register(server.Router, cServ, *log)
+	oHTTP.Register(server.Router, oServ, *log)
+	dHTTP.Register(server.Router, dServ, cfg.Dropbox.MaxSize, *log)
+	msgHTTP.Register(server.Router, msgServ, cfg.Messages.MaxSize, *log)
+	aHTTP.Register(server.Router, aServ, cfg.Attachments.MaxChunkSize, *log)
+	upHTTP.Register(server.Router, upServ, cfg.Uploads.MaxSize, *log)
+	eHTTP.Register(server.Router, eServ, cfg.Events.Heartbeat, *log)
 
 	go metrics.StartMetrics(cfg.Metrics.Port, cfg.Metrics.Enable, log)
 
@@ -90,6 +327,14 @@ func main() {
 	}
 
 	log.Info("Closing HTTP Server")
+	if err := bus.Close(); err != nil {
+		log.WithFields(logger.Fields{"error": err.Error()}).
+			Error("Event bus: Error closing down")
+	}
+	if err := obSink.Close(); err != nil {
+		log.WithFields(logger.Fields{"error": err.Error()}).
+			Error("Outbox sink: Error closing down")
+	}
 	if err := closeTracer(ctx); err != nil {
 		log.WithFields(logger.Fields{"error": err.Error()}).
 			Error("Tracer: Error closing down")
@@ -0,0 +1,50 @@
+package dto
+
+const (
+	StatusUploading = "uploading"
+	StatusComplete  = "complete"
+)
+
+type (
+	AuthInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+	}
+	// AttachmentInput starts an upload. Chunks are encrypted separately by the
+	// client; ChunkSize is the plaintext size of every chunk but the last.
+	AttachmentInput struct {
+		UserAddress   string `json:"user_address" db:"user_address"`
+		Password      string `json:"password" db:"password"`
+		EncryptedName []byte `json:"encrypted_name,omitempty" db:"encrypted_name"`
+		NameIV        []byte `json:"name_iv,omitempty" db:"name_iv"`
+		ChunkSize     int    `json:"chunk_size" db:"chunk_size"`
+	}
+	CompleteInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+		ChunkCount  int    `json:"chunk_count" db:"chunk_count"`
+	}
+	Attachment struct {
+		ID              string  `json:"id" db:"id"`
+		KeyID           string  `json:"key_id" db:"key_id"`
+		UploaderID      *int64  `json:"-" db:"uploader_id"`
+		UploaderAddress *string `json:"uploader_address,omitempty" db:"uploader_address"`
+		EncryptedName   []byte  `json:"encrypted_name,omitempty" db:"encrypted_name"`
+		NameIV          []byte  `json:"name_iv,omitempty" db:"name_iv"`
+		ChunkSize       int     `json:"chunk_size" db:"chunk_size"`
+		Status          string  `json:"status" db:"status"`
+		Size            int64   `json:"size" db:"size"`
+		ChunkCount      int     `json:"chunk_count" db:"chunk_count"`
+		CreatedAt       string  `json:"created_at" db:"created_at"`
+		CompletedAt     *string `json:"completed_at,omitempty" db:"completed_at"`
+		Chunks          []Chunk `json:"chunks,omitempty"`
+	}
+	// Chunk is one encrypted chunk. Offset is its position in the attachment
+	// content, so clients can fetch single chunks with a Range request.
+	Chunk struct {
+		Index   int    `json:"index" db:"chunk_index"`
+		Offset  int64  `json:"offset" db:"offset"`
+		Size    int64  `json:"size" db:"size"`
+		BlobKey string `json:"-" db:"blob_key"`
+	}
+)
@@ -0,0 +1,382 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ObscuraNote/api-general/internal/attachments/dto"
+	aService "github.com/ObscuraNote/api-general/internal/attachments/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+type handler struct {
+	log          *logger.Logger
+	as           aService.AttachmentsService
+	maxChunkSize int64
+}
+
+func Register(router chi.Router, as aService.AttachmentsService, maxChunkSize int64, log logger.Logger) {
+	h := &handler{
+		log:          &log,
+		as:           as,
+		maxChunkSize: maxChunkSize,
+	}
+
+	router.Post("/keys/{id}/attachments", h.CreateAttachment)
+	router.Get("/keys/{id}/attachments", h.GetAttachments)
+	router.Get("/keys/{id}/attachments/{attachmentId}", h.GetAttachment)
+	router.Delete("/keys/{id}/attachments/{attachmentId}", h.DeleteAttachment)
+	router.Put("/keys/{id}/attachments/{attachmentId}/chunks/{index}", h.UploadChunk)
+	router.Post("/keys/{id}/attachments/{attachmentId}/complete", h.CompleteAttachment)
+	router.Get("/keys/{id}/attachments/{attachmentId}/content", h.GetContent)
+}
+
+func (h *handler) CreateAttachment(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var input dto.AttachmentInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	attachment, err := h.as.CreateAttachment(keyID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "CreateAttachment"}).
+			Error("Failed to create attachment")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, attachment); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "CreateAttachment"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.as.GetAttachments(keyID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "GetAttachments"}).
+			Error("Failed to get attachments")
+
+		writeError(w, err)
+		return
+	}
+
+	if attachments == nil {
+		attachments = []dto.Attachment{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, attachments); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "GetAttachments"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	attachmentID, err := utils.ParseParamUUID(w, r, "attachmentId")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	attachment, err := h.as.GetAttachment(keyID.String(), attachmentID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "GetAttachment"}).
+			Error("Failed to get attachment")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, attachment); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "GetAttachment"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	attachmentID, err := utils.ParseParamUUID(w, r, "attachmentId")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.as.DeleteAttachment(keyID.String(), attachmentID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "DeleteAttachment"}).
+			Error("Failed to delete attachment")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UploadChunk takes the raw encrypted chunk as the request body, so it is
+// streamed to the blob store instead of being decoded from JSON.
+func (h *handler) UploadChunk(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	attachmentID, err := utils.ParseParamUUID(w, r, "attachmentId")
+	if err != nil {
+		return
+	}
+
+	index, err := utils.ParseIDParam(w, r, "index")
+	if err != nil {
+		return
+	}
+
+	if r.ContentLength <= 0 {
+		_ = utils.Fault(w, http.StatusLengthRequired, utils.BadRequest)
+		return
+	}
+	if r.ContentLength > h.maxChunkSize {
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, utils.PayloadTooLarge)
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	err = h.as.UploadChunk(keyID.String(), attachmentID.String(), int(index), r.ContentLength, r.Body, auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "UploadChunk"}).
+			Error("Failed to upload chunk")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) CompleteAttachment(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	attachmentID, err := utils.ParseParamUUID(w, r, "attachmentId")
+	if err != nil {
+		return
+	}
+
+	var input dto.CompleteInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	attachment, err := h.as.CompleteAttachment(keyID.String(), attachmentID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "CompleteAttachment"}).
+			Error("Failed to complete attachment")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, attachment); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "CompleteAttachment"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+// GetContent streams the encrypted content of a completed attachment. A
+// single byte range may be requested with the Range header.
+func (h *handler) GetContent(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	attachmentID, err := utils.ParseParamUUID(w, r, "attachmentId")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	attachment, err := h.as.OpenContent(keyID.String(), attachmentID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "GetContent"}).
+			Error("Failed to open attachment")
+
+		writeError(w, err)
+		return
+	}
+
+	// Completed attachments never change, so their ID is a strong validator.
+	etag := `"` + attachment.ID + `"`
+	rangeHeader := r.Header.Get("Range")
+	if ifRange := r.Header.Get("If-Range"); ifRange != "" && ifRange != etag {
+		rangeHeader = ""
+	}
+
+	start, length, partial, ok := parseRange(rangeHeader, attachment.Size)
+	if !ok {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", attachment.Size))
+		_ = utils.Fault(w, http.StatusRequestedRangeNotSatisfiable, utils.InvalidRange)
+		return
+	}
+
+	body := h.as.ReadContent(attachment, start, length)
+	defer body.Close()
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("ETag", etag)
+	w.Header().Set(utils.ContentType, "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+
+	status := http.StatusOK
+	if partial {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, attachment.Size))
+		status = http.StatusPartialContent
+	}
+	w.WriteHeader(status)
+
+	if _, err := io.Copy(w, body); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "attachments", "function": "GetContent"}).
+			Error("Failed to stream attachment")
+	}
+}
+
+// parseRange resolves a Range header against a resource of size bytes. Only
+// single ranges are honoured; a missing, malformed or multi-range header
+// selects the whole resource, as RFC 9110 allows. ok is false when the range
+// cannot be satisfied.
+func parseRange(header string, size int64) (start, length int64, partial, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, size, false, true
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, size, false, true
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, size, false, true
+		}
+		if suffix == 0 {
+			return 0, 0, false, false
+		}
+		start = max(size-suffix, 0)
+		return start, size - start, true, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, size, false, true
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, size, false, true
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, false, false
+	}
+
+	return start, end - start + 1, true, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.BadRequest:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.Forbidden:
+		_ = utils.Fault(w, http.StatusForbidden, utils.Forbidden)
+	case utils.PayloadTooLarge:
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, err.Error())
+	case utils.KeyNotFound, utils.NoAttachment:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	case utils.UploadPending, utils.QuotaExceeded:
+		_ = utils.Fault(w, http.StatusConflict, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
+}
@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/attachments/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/lock"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
+)
+
+var _ AttachmentsRepository = (*Repository)(nil)
+
+var (
+	// ErrIncomplete is returned when an attachment is completed with a chunk
+	// count that does not match the uploaded chunks.
+	ErrIncomplete = errors.New("attachment upload is incomplete")
+	// ErrQuotaExceeded is returned when a chunk would take the owner of the
+	// entry past their attachment storage limit.
+	ErrQuotaExceeded = errors.New("attachment quota exceeded")
+)
+
+type (
+	AttachmentsRepository interface {
+		CreateAttachment(uploaderId int64, keyId string, input dto.AttachmentInput) (*dto.Attachment, error)
+		GetAttachments(keyId string) ([]dto.Attachment, error)
+		GetAttachment(keyId, id string) (*dto.Attachment, error)
+		GetChunks(id string) ([]dto.Chunk, error)
+		PutChunk(id string, index int, size int64, blobKey string, quota QuotaFunc) (bool, error)
+		CompleteAttachment(id string, chunkCount int) (*dto.Attachment, error)
+		DeleteAttachment(keyId, id string) (bool, error)
+		CollectGarbage(uploadTTL time.Duration, limit int, remove func(blobKey string) error) (int64, error)
+	}
+	// QuotaFunc returns the attachment byte limit of ownerId. Zero is
+	// unlimited.
+	QuotaFunc func(ownerId int64) (int64, error)
+
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+func (r *Repository) CreateAttachment(uploaderId int64, keyId string, input dto.AttachmentInput) (*dto.Attachment, error) {
+	attachment, err := scanAttachment(r.statements.createAttachment.statement.
+		QueryRowContext(r.ctx, keyId, uploaderId, input.EncryptedName, input.NameIV, input.ChunkSize))
+	if err != nil {
+		log.Println("Error creating attachment")
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (r *Repository) GetAttachments(keyId string) ([]dto.Attachment, error) {
+	rows, err := r.statements.getAttachments.statement.QueryContext(r.ctx, keyId)
+	if err != nil {
+		log.Println("Error getting attachments")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []dto.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			log.Println("Error scanning attachment")
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	return attachments, nil
+}
+
+func (r *Repository) GetAttachment(keyId, id string) (*dto.Attachment, error) {
+	attachment, err := scanAttachment(r.statements.getAttachment.statement.QueryRowContext(r.ctx, id, keyId))
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// GetChunks returns the uploaded chunks of an attachment in order, with their
+// offsets in the attachment content.
+func (r *Repository) GetChunks(id string) ([]dto.Chunk, error) {
+	rows, err := r.statements.getChunks.statement.QueryContext(r.ctx, id)
+	if err != nil {
+		log.Println("Error getting chunks")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []dto.Chunk
+	for rows.Next() {
+		var chunk dto.Chunk
+		if err := rows.Scan(&chunk.Index, &chunk.Offset, &chunk.Size, &chunk.BlobKey); err != nil {
+			log.Println("Error scanning chunk")
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// PutChunk records an uploaded chunk, replacing an earlier upload of the same
+// index. It reports false when the attachment is missing or already complete.
+// Attachments count against the owner of the entry, whose row is locked while
+// their usage is checked; ErrQuotaExceeded is returned when the chunk would
+// take them past the limit quota returns for them.
+func (r *Repository) PutChunk(id string, index int, size int64, blobKey string, quota QuotaFunc) (bool, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var ownerId int64
+		if err := tx.StmtxContext(ctx, r.statements.getOwner.statement).
+			QueryRowContext(ctx, id).Scan(&ownerId); err != nil {
+			return false, err
+		}
+
+		var locked int64
+		if err := tx.StmtxContext(ctx, r.statements.lockUser.statement).
+			QueryRowContext(ctx, ownerId).Scan(&locked); err != nil {
+			return false, err
+		}
+
+		var used, current int64
+		if err := tx.StmtxContext(ctx, r.statements.getUsage.statement).
+			QueryRowContext(ctx, ownerId).Scan(&used); err != nil {
+			return false, err
+		}
+		if err := tx.StmtxContext(ctx, r.statements.getChunkSize.statement).
+			QueryRowContext(ctx, id, index).Scan(&current); err != nil {
+			return false, err
+		}
+		if size > current {
+			limit, err := quota(ownerId)
+			if err != nil {
+				return false, err
+			}
+			if limit > 0 && used+size-current > limit {
+				return false, ErrQuotaExceeded
+			}
+		}
+
+		result, err := tx.StmtxContext(ctx, r.statements.putChunk.statement).
+			ExecContext(ctx, id, index, size, blobKey)
+		if err != nil {
+			return false, err
+		}
+
+		return rowsAffected(result)
+	}))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		if !errors.Is(err, ErrQuotaExceeded) {
+			log.Println("Error putting chunk")
+		}
+		return false, err
+	}
+
+	return result.(bool), nil
+}
+
+// CompleteAttachment seals an upload once chunks 0 to chunkCount-1 are all
+// present. It returns sql.ErrNoRows when the attachment is missing or already
+// complete and ErrIncomplete when chunks are missing.
+func (r *Repository) CompleteAttachment(id string, chunkCount int) (*dto.Attachment, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var locked string
+		if err := tx.StmtxContext(ctx, r.statements.lockAttachment.statement).
+			QueryRowContext(ctx, id).Scan(&locked); err != nil {
+			return nil, err
+		}
+
+		attachment, err := scanAttachment(tx.StmtxContext(ctx, r.statements.completeAttachment.statement).
+			QueryRowContext(ctx, id, chunkCount))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrIncomplete
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return attachment, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, ErrIncomplete) {
+			log.Println("Error completing attachment")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.Attachment), nil
+}
+
+// DeleteAttachment removes an attachment and its chunks. Their blobs are
+// queued for deletion and removed by CollectGarbage.
+func (r *Repository) DeleteAttachment(keyId, id string) (bool, error) {
+	result, err := r.statements.deleteAttachment.statement.ExecContext(r.ctx, id, keyId)
+	if err != nil {
+		log.Println("Error deleting attachment")
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+// CollectGarbage drops uploads left incomplete for longer than uploadTTL and
+// passes up to limit queued blob keys to remove, forgetting each one that was
+// removed. It returns how many blobs were removed.
+func (r *Repository) CollectGarbage(uploadTTL time.Duration, limit int, remove func(blobKey string) error) (int64, error) {
+	removed, err := lock.TryExclusive(r.ctx, r.db, lock.BlobGarbage, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		if _, err := tx.StmtxContext(ctx, r.statements.deleteStaleUploads.statement).
+			ExecContext(ctx, uploadTTL.Seconds()); err != nil {
+			return 0, err
+		}
+
+		var blobKeys []string
+		if err := tx.StmtxContext(ctx, r.statements.getBlobGarbage.statement).
+			SelectContext(ctx, &blobKeys, limit); err != nil {
+			return 0, err
+		}
+
+		var removed int64
+		for _, blobKey := range blobKeys {
+			if err := remove(blobKey); err != nil {
+				return 0, err
+			}
+			if _, err := tx.StmtxContext(ctx, r.statements.deleteBlobGarbage.statement).
+				ExecContext(ctx, blobKey); err != nil {
+				return 0, err
+			}
+			removed++
+		}
+
+		return removed, nil
+	})
+	if err != nil {
+		log.Println("Error collecting blob garbage")
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAttachment(row scanner) (*dto.Attachment, error) {
+	var attachment dto.Attachment
+	if err := row.Scan(&attachment.ID, &attachment.KeyID, &attachment.UploaderID, &attachment.UploaderAddress,
+		&attachment.EncryptedName, &attachment.NameIV, &attachment.ChunkSize, &attachment.Status, &attachment.Size,
+		&attachment.ChunkCount, &attachment.CreatedAt, &attachment.CompletedAt); err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.createAttachment.statement, err = r.db.PrepareStatement(statementsList.createAttachment.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getAttachments.statement, err = r.db.PrepareStatement(statementsList.getAttachments.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getAttachment.statement, err = r.db.PrepareStatement(statementsList.getAttachment.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getChunks.statement, err = r.db.PrepareStatement(statementsList.getChunks.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.putChunk.statement, err = r.db.PrepareStatement(statementsList.putChunk.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockAttachment.statement, err = r.db.PrepareStatement(statementsList.lockAttachment.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getOwner.statement, err = r.db.PrepareStatement(statementsList.getOwner.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockUser.statement, err = r.db.PrepareStatement(statementsList.lockUser.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getUsage.statement, err = r.db.PrepareStatement(statementsList.getUsage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getChunkSize.statement, err = r.db.PrepareStatement(statementsList.getChunkSize.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.completeAttachment.statement, err = r.db.PrepareStatement(statementsList.completeAttachment.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteAttachment.statement, err = r.db.PrepareStatement(statementsList.deleteAttachment.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteStaleUploads.statement, err = r.db.PrepareStatement(statementsList.deleteStaleUploads.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getBlobGarbage.statement, err = r.db.PrepareStatement(statementsList.getBlobGarbage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteBlobGarbage.statement, err = r.db.PrepareStatement(statementsList.deleteBlobGarbage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
+
+func rowsAffected(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
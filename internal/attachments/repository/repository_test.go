@@ -0,0 +1,170 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/attachments/dto"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var unlimited = quota(0)
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE keys CASCADE;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE keys CASCADE;")
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	db.GetClient().Exec("TRUNCATE TABLE blob_garbage;")
+	defer db.GetClient().Exec("TRUNCATE TABLE blob_garbage;")
+
+	db.GetClient().Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa');
+	`)
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var userId int64
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '1111111111111111111111111111111111111111111111111111111111111111';
+	`).Scan(&userId)
+	if err != nil {
+		t.Fatalf("failed to get user id: %v", err)
+	}
+
+	var keyId string
+	err = db.GetClient().QueryRow(`
+		INSERT INTO keys (user_id, user_address, encrypted_key, key_iv, encrypted_data, data_iv)
+		VALUES ($1, '1111111111111111111111111111111111111111111111111111111111111111', 'key', 'iv', 'data', 'iv')
+		RETURNING id;
+	`, userId).Scan(&keyId)
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+
+	var attachment *dto.Attachment
+
+	t.Run("CreateAttachment", func(t *testing.T) {
+		attachment, err = repo.CreateAttachment(userId, keyId, dto.AttachmentInput{
+			EncryptedName: []byte("name"),
+			NameIV:        []byte("iv"),
+			ChunkSize:     4,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, dto.StatusUploading, attachment.Status)
+		assert.Equal(t, userId, *attachment.UploaderID)
+
+		attachments, err := repo.GetAttachments(keyId)
+		assert.NoError(t, err)
+		assert.Len(t, attachments, 1)
+	})
+
+	t.Run("PutChunk", func(t *testing.T) {
+		stored, err := repo.PutChunk(attachment.ID, 1, 3, "attachments/a/1-old", unlimited)
+		assert.NoError(t, err)
+		assert.True(t, stored)
+
+		_, err = repo.CompleteAttachment(attachment.ID, 2)
+		assert.ErrorIs(t, err, ErrIncomplete)
+
+		stored, err = repo.PutChunk(attachment.ID, 0, 4, "attachments/a/0", unlimited)
+		assert.NoError(t, err)
+		assert.True(t, stored)
+
+		stored, err = repo.PutChunk(attachment.ID, 1, 2, "attachments/a/1-new", unlimited)
+		assert.NoError(t, err)
+		assert.True(t, stored)
+
+		_, err = repo.PutChunk(attachment.ID, 2, 5, "attachments/a/2-large", quota(10))
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+		chunks, err := repo.GetChunks(attachment.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.Chunk{
+			{Index: 0, Offset: 0, Size: 4, BlobKey: "attachments/a/0"},
+			{Index: 1, Offset: 4, Size: 2, BlobKey: "attachments/a/1-new"},
+		}, chunks)
+	})
+
+	t.Run("CompleteAttachment", func(t *testing.T) {
+		_, err := repo.CompleteAttachment(attachment.ID, 3)
+		assert.ErrorIs(t, err, ErrIncomplete)
+
+		completed, err := repo.CompleteAttachment(attachment.ID, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, dto.StatusComplete, completed.Status)
+		assert.Equal(t, int64(6), completed.Size)
+		assert.Equal(t, 2, completed.ChunkCount)
+
+		_, err = repo.CompleteAttachment(attachment.ID, 2)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		stored, err := repo.PutChunk(attachment.ID, 2, 1, "attachments/a/2", unlimited)
+		assert.NoError(t, err)
+		assert.False(t, stored)
+	})
+
+	t.Run("CollectGarbage", func(t *testing.T) {
+		stale, err := repo.CreateAttachment(userId, keyId, dto.AttachmentInput{ChunkSize: 4})
+		assert.NoError(t, err)
+		_, err = repo.PutChunk(stale.ID, 0, 4, "attachments/b/0", unlimited)
+		assert.NoError(t, err)
+		_, err = db.GetClient().Exec(`UPDATE attachments SET created_at = CURRENT_TIMESTAMP - INTERVAL '2 days' WHERE id = $1;`, stale.ID)
+		assert.NoError(t, err)
+
+		deleted, err := repo.DeleteAttachment(keyId, attachment.ID)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+
+		var removedKeys []string
+		removed, err := repo.CollectGarbage(24*time.Hour, 100, func(blobKey string) error {
+			removedKeys = append(removedKeys, blobKey)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), removed)
+		assert.ElementsMatch(t, []string{"attachments/a/1-old", "attachments/a/0", "attachments/a/1-new", "attachments/b/0"}, removedKeys)
+
+		attachments, err := repo.GetAttachments(keyId)
+		assert.NoError(t, err)
+		assert.Len(t, attachments, 0)
+
+		removed, err = repo.CollectGarbage(24*time.Hour, 100, func(blobKey string) error { return nil })
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), removed)
+	})
+}
+
+func quota(limit int64) QuotaFunc {
+	return func(int64) (int64, error) {
+		return limit, nil
+	}
+}
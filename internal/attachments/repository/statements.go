@@ -0,0 +1,184 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	createAttachment   statementsItem
+	getAttachments     statementsItem
+	getAttachment      statementsItem
+	getChunks          statementsItem
+	putChunk           statementsItem
+	lockAttachment     statementsItem
+	completeAttachment statementsItem
+	deleteAttachment   statementsItem
+	deleteStaleUploads statementsItem
+	getBlobGarbage     statementsItem
+	deleteBlobGarbage  statementsItem
+	getOwner           statementsItem
+	lockUser           statementsItem
+	getUsage           statementsItem
+	getChunkSize       statementsItem
+}
+
+var statementsList = statements{
+	createAttachment: statementsItem{
+		name: "createAttachment",
+		query: `
+			WITH created AS (
+				INSERT INTO attachments (key_id, uploader_id, encrypted_name, name_iv, chunk_size)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING id, key_id, uploader_id, encrypted_name, name_iv, chunk_size, status, size, chunk_count,
+					created_at, completed_at
+			)
+			SELECT c.id, c.key_id, c.uploader_id, u.user_address, c.encrypted_name, c.name_iv, c.chunk_size, c.status,
+				c.size, c.chunk_count, c.created_at, c.completed_at
+			FROM created c
+			LEFT JOIN users u ON u.id = c.uploader_id;`,
+	},
+	getAttachments: statementsItem{
+		name: "getAttachments",
+		query: `
+			SELECT a.id, a.key_id, a.uploader_id, u.user_address, a.encrypted_name, a.name_iv, a.chunk_size, a.status,
+				a.size, a.chunk_count, a.created_at, a.completed_at
+			FROM attachments a
+			LEFT JOIN users u ON u.id = a.uploader_id
+			WHERE a.key_id = $1
+			ORDER BY a.created_at ASC;`,
+	},
+	getAttachment: statementsItem{
+		name: "getAttachment",
+		query: `
+			SELECT a.id, a.key_id, a.uploader_id, u.user_address, a.encrypted_name, a.name_iv, a.chunk_size, a.status,
+				a.size, a.chunk_count, a.created_at, a.completed_at
+			FROM attachments a
+			LEFT JOIN users u ON u.id = a.uploader_id
+			WHERE a.id = $1
+			AND a.key_id = $2;`,
+	},
+	getChunks: statementsItem{
+		name: "getChunks",
+		query: `
+			SELECT chunk_index, SUM(size) OVER (ORDER BY chunk_index) - size, size, blob_key
+			FROM attachment_chunks
+			WHERE attachment_id = $1
+			ORDER BY chunk_index ASC;`,
+	},
+	// putChunk share-locks the attachment so that a chunk cannot land after a
+	// concurrent completion.
+	putChunk: statementsItem{
+		name: "putChunk",
+		query: `
+			INSERT INTO attachment_chunks (attachment_id, chunk_index, size, blob_key)
+			SELECT id, $2, $3, $4
+			FROM attachments
+			WHERE id = $1
+			AND status = 'uploading'
+			FOR SHARE
+			ON CONFLICT (attachment_id, chunk_index) DO UPDATE
+			SET size = EXCLUDED.size,
+				blob_key = EXCLUDED.blob_key,
+				created_at = CURRENT_TIMESTAMP;`,
+	},
+	lockAttachment: statementsItem{
+		name: "lockAttachment",
+		query: `
+			SELECT id
+			FROM attachments
+			WHERE id = $1
+			AND status = 'uploading'
+			FOR UPDATE;`,
+	},
+	completeAttachment: statementsItem{
+		name: "completeAttachment",
+		query: `
+			WITH chunks AS (
+				SELECT COUNT(*) AS chunk_count, COALESCE(SUM(size), 0) AS size, MAX(chunk_index) AS last_index
+				FROM attachment_chunks
+				WHERE attachment_id = $1
+			), completed AS (
+				UPDATE attachments a
+				SET status = 'complete',
+					size = c.size,
+					chunk_count = c.chunk_count,
+					completed_at = CURRENT_TIMESTAMP
+				FROM chunks c
+				WHERE a.id = $1
+				AND c.chunk_count = $2
+				AND c.last_index = $2 - 1
+				RETURNING a.id, a.key_id, a.uploader_id, a.encrypted_name, a.name_iv, a.chunk_size, a.status, a.size,
+					a.chunk_count, a.created_at, a.completed_at
+			)
+			SELECT c.id, c.key_id, c.uploader_id, u.user_address, c.encrypted_name, c.name_iv, c.chunk_size, c.status,
+				c.size, c.chunk_count, c.created_at, c.completed_at
+			FROM completed c
+			LEFT JOIN users u ON u.id = c.uploader_id;`,
+	},
+	deleteAttachment: statementsItem{
+		name: "deleteAttachment",
+		query: `
+			DELETE FROM attachments
+			WHERE id = $1
+			AND key_id = $2;`,
+	},
+	deleteStaleUploads: statementsItem{
+		name: "deleteStaleUploads",
+		query: `
+			DELETE FROM attachments
+			WHERE status = 'uploading'
+			AND created_at < CURRENT_TIMESTAMP - make_interval(secs => $1);`,
+	},
+	getBlobGarbage: statementsItem{
+		name: "getBlobGarbage",
+		query: `
+			SELECT blob_key
+			FROM blob_garbage
+			ORDER BY created_at ASC
+			LIMIT $1;`,
+	},
+	deleteBlobGarbage: statementsItem{
+		name: "deleteBlobGarbage",
+		query: `
+			DELETE FROM blob_garbage
+			WHERE blob_key = $1;`,
+	},
+	getOwner: statementsItem{
+		name: "getOwner",
+		query: `
+			SELECT k.user_id
+			FROM attachments a
+			JOIN keys k ON k.id = a.key_id
+			WHERE a.id = $1
+			AND a.status = 'uploading';`,
+	},
+	lockUser: statementsItem{
+		name: "lockUser",
+		query: `
+			SELECT id
+			FROM users
+			WHERE id = $1
+			FOR NO KEY UPDATE;`,
+	},
+	getUsage: statementsItem{
+		name: "getUsage",
+		query: `
+			SELECT COALESCE(SUM(c.size), 0)
+			FROM attachment_chunks c
+			JOIN attachments a ON a.id = c.attachment_id
+			JOIN keys k ON k.id = a.key_id
+			WHERE k.user_id = $1;`,
+	},
+	getChunkSize: statementsItem{
+		name: "getChunkSize",
+		query: `
+			SELECT COALESCE(MAX(size), 0)
+			FROM attachment_chunks
+			WHERE attachment_id = $1
+			AND chunk_index = $2;`,
+	},
+}
@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ObscuraNote/api-general/internal/attachments/dto"
+	r "github.com/ObscuraNote/api-general/internal/attachments/repository"
+	k "github.com/ObscuraNote/api-general/internal/keys/service"
+	u "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/blobstore"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/logger"
+)
+
+var _ AttachmentsService = (*Service)(nil)
+
+const (
+	maxNameSize  = 1024
+	blobIdSize   = 8
+	garbageBatch = 100
+)
+
+type (
+	// AttachmentsService keeps files attached to entries. Files are split and
+	// encrypted in chunks by the client; chunks are streamed to a BlobStore and
+	// never held in memory as a whole.
+	AttachmentsService interface {
+		CreateAttachment(keyId string, input dto.AttachmentInput) (*dto.Attachment, error)
+		GetAttachments(keyId string, auth dto.AuthInput) ([]dto.Attachment, error)
+		GetAttachment(keyId, id string, auth dto.AuthInput) (*dto.Attachment, error)
+		UploadChunk(keyId, id string, index int, size int64, body io.Reader, auth dto.AuthInput) error
+		CompleteAttachment(keyId, id string, input dto.CompleteInput) (*dto.Attachment, error)
+		DeleteAttachment(keyId, id string, auth dto.AuthInput) error
+		OpenContent(keyId, id string, auth dto.AuthInput) (*dto.Attachment, error)
+		ReadContent(attachment *dto.Attachment, offset, length int64) io.ReadCloser
+		CollectGarbage() (int64, error)
+	}
+
+	Service struct {
+		ctx   context.Context
+		r     r.AttachmentsRepository
+		us    u.UserService
+		ks    k.KeysService
+		store blobstore.BlobStore
+		log   *logger.Logger
+		cfg   config.AttachmentsConfig
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.AttachmentsRepository, us u.UserService, ks k.KeysService,
+	store blobstore.BlobStore, cfg config.AttachmentsConfig) *Service {
+	return &Service{
+		ctx:   ctx,
+		log:   &log,
+		r:     repo,
+		us:    us,
+		ks:    ks,
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// CreateAttachment starts an upload on an entry the caller may edit.
+func (s *Service) CreateAttachment(keyId string, input dto.AttachmentInput) (*dto.Attachment, error) {
+	if input.ChunkSize <= 0 || int64(input.ChunkSize) > s.cfg.MaxChunkSize {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if len(input.EncryptedName) > maxNameSize {
+		return nil, fmt.Errorf(utils.PayloadTooLarge)
+	}
+
+	userId, err := s.getUserId(input.UserAddress, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ks.AuthorizeKeyWrite(keyId, userId); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.r.CreateAttachment(userId, keyId, input)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "CreateAttachment"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return attachment, nil
+}
+
+func (s *Service) GetAttachments(keyId string, auth dto.AuthInput) ([]dto.Attachment, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.ks.AuthorizeKey(keyId, userId); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.r.GetAttachments(keyId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "GetAttachments"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return attachments, nil
+}
+
+// GetAttachment returns an attachment with its chunk list.
+func (s *Service) GetAttachment(keyId, id string, auth dto.AuthInput) (*dto.Attachment, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.ks.AuthorizeKey(keyId, userId); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.getAttachment(keyId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := s.r.GetChunks(id)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "GetAttachment"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+	attachment.Chunks = chunks
+
+	return attachment, nil
+}
+
+// UploadChunk streams one encrypted chunk of size bytes to the blob store.
+// Uploading an index again replaces the chunk until the attachment is
+// completed. Only the uploader may add chunks.
+func (s *Service) UploadChunk(keyId, id string, index int, size int64, body io.Reader, auth dto.AuthInput) error {
+	if index < 0 || index >= s.cfg.MaxChunks || size <= 0 {
+		return fmt.Errorf(utils.BadRequest)
+	}
+	if size > s.cfg.MaxChunkSize {
+		return fmt.Errorf(utils.PayloadTooLarge)
+	}
+
+	if err := s.authorizeUpload(keyId, id, auth); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, blobIdSize)
+	if _, err := rand.Read(suffix); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "UploadChunk"}).Error(utils.InternalCode)
+		return fmt.Errorf(utils.InternalCode)
+	}
+	// Every upload gets a fresh blob key, so a replaced chunk is never
+	// overwritten in place and its old blob is collected as garbage.
+	blobKey := fmt.Sprintf("attachments/%s/%d-%s", id, index, hex.EncodeToString(suffix))
+
+	source := &sourceReader{r: io.LimitReader(body, size), remaining: size}
+	if err := s.store.Put(s.ctx, blobKey, source, size); err != nil {
+		if source.failed() {
+			return fmt.Errorf(utils.BadRequest)
+		}
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "UploadChunk"}).Error(utils.InternalCode)
+		return fmt.Errorf(utils.InternalCode)
+	}
+
+	stored, err := s.r.PutChunk(id, index, size, blobKey, s.attachmentQuota)
+	if err != nil || !stored {
+		if deleteErr := s.store.Delete(s.ctx, blobKey); deleteErr != nil {
+			s.log.WithFields(logger.Fields{"error": deleteErr.Error(), "component": "attachments_service", "function": "UploadChunk"}).
+				Warn("Failed to delete unreferenced chunk")
+		}
+	}
+	if errors.Is(err, r.ErrQuotaExceeded) {
+		return fmt.Errorf(utils.QuotaExceeded)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "UploadChunk"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !stored {
+		return fmt.Errorf(utils.NoAttachment)
+	}
+
+	return nil
+}
+
+// CompleteAttachment makes an upload available for download once chunks 0 to
+// ChunkCount-1 are present. Completed attachments are immutable.
+func (s *Service) CompleteAttachment(keyId, id string, input dto.CompleteInput) (*dto.Attachment, error) {
+	if input.ChunkCount <= 0 || input.ChunkCount > s.cfg.MaxChunks {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	if err := s.authorizeUpload(keyId, id, dto.AuthInput{UserAddress: input.UserAddress, Password: input.Password}); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.r.CompleteAttachment(id, input.ChunkCount)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf(utils.NoAttachment)
+	case errors.Is(err, r.ErrIncomplete):
+		return nil, fmt.Errorf(utils.UploadPending)
+	case err != nil:
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "CompleteAttachment"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return attachment, nil
+}
+
+// DeleteAttachment removes an attachment. Uploaders can delete their own
+// attachments while they may edit the entry; the entry owner and vault admins
+// can delete any attachment.
+func (s *Service) DeleteAttachment(keyId, id string, auth dto.AuthInput) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	moderator, err := s.ks.AuthorizeKey(keyId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !moderator {
+		attachment, err := s.getAttachment(keyId, id)
+		if err != nil {
+			return err
+		}
+		if attachment.UploaderID == nil || *attachment.UploaderID != userId {
+			return fmt.Errorf(utils.Forbidden)
+		}
+		if err := s.ks.AuthorizeKeyWrite(keyId, userId); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := s.r.DeleteAttachment(keyId, id)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "DeleteAttachment"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !deleted {
+		return fmt.Errorf(utils.NoAttachment)
+	}
+
+	return nil
+}
+
+// OpenContent returns a completed attachment with its chunk list, ready to be
+// passed to ReadContent.
+func (s *Service) OpenContent(keyId, id string, auth dto.AuthInput) (*dto.Attachment, error) {
+	attachment, err := s.GetAttachment(keyId, id, auth)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.Status != dto.StatusComplete {
+		return nil, fmt.Errorf(utils.UploadPending)
+	}
+
+	return attachment, nil
+}
+
+// ReadContent streams length bytes of the attachment content starting at
+// offset. Chunk blobs are opened one at a time as the reader advances.
+func (s *Service) ReadContent(attachment *dto.Attachment, offset, length int64) io.ReadCloser {
+	return &contentReader{
+		ctx:    s.ctx,
+		store:  s.store,
+		chunks: attachment.Chunks,
+		offset: offset,
+		end:    offset + length,
+	}
+}
+
+// CollectGarbage drops stale attachment uploads and deletes queued blobs that
+// no attachment chunk, upload segment or entry payload references anymore.
+func (s *Service) CollectGarbage() (int64, error) {
+	return s.r.CollectGarbage(s.cfg.UploadTTL, garbageBatch, func(blobKey string) error {
+		return s.store.Delete(s.ctx, blobKey)
+	})
+}
+
+// authorizeUpload checks that the caller started the upload, may still edit
+// the entry and that the upload is not completed yet.
+func (s *Service) authorizeUpload(keyId, id string, auth dto.AuthInput) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ks.AuthorizeKeyWrite(keyId, userId); err != nil {
+		return err
+	}
+
+	attachment, err := s.getAttachment(keyId, id)
+	if err != nil {
+		return err
+	}
+	if attachment.UploaderID == nil || *attachment.UploaderID != userId {
+		return fmt.Errorf(utils.Forbidden)
+	}
+	if attachment.Status != dto.StatusUploading {
+		return fmt.Errorf(utils.NoAttachment)
+	}
+
+	return nil
+}
+
+func (s *Service) getAttachment(keyId, id string) (*dto.Attachment, error) {
+	attachment, err := s.r.GetAttachment(keyId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.NoAttachment)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "getAttachment"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return attachment, nil
+}
+
+// attachmentQuota returns the attachment byte limit of ownerId's plan.
+func (s *Service) attachmentQuota(ownerId int64) (int64, error) {
+	entitlements, err := s.us.GetEntitlements(ownerId)
+	if err != nil {
+		return 0, err
+	}
+
+	return entitlements.MaxAttachmentBytes, nil
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "attachments_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
+
+// sourceReader remembers whether an upload failed on the client side, either
+// with a read error or a body shorter than announced.
+type sourceReader struct {
+	r         io.Reader
+	remaining int64
+	err       error
+}
+
+func (s *sourceReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.remaining -= int64(n)
+	if err != nil && (err != io.EOF || s.remaining > 0) {
+		s.err = err
+	}
+	return n, err
+}
+
+func (s *sourceReader) failed() bool {
+	return s.err != nil
+}
+
+// contentReader reads a byte range across the chunk blobs of an attachment.
+type contentReader struct {
+	ctx        context.Context
+	store      blobstore.BlobStore
+	chunks     []dto.Chunk
+	index      int
+	offset     int64
+	end        int64
+	current    io.ReadCloser
+	currentEnd int64
+}
+
+func (c *contentReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if c.offset >= c.end {
+				return 0, io.EOF
+			}
+			for c.index < len(c.chunks) && c.chunks[c.index].Offset+c.chunks[c.index].Size <= c.offset {
+				c.index++
+			}
+			if c.index == len(c.chunks) {
+				return 0, io.ErrUnexpectedEOF
+			}
+
+			chunk := c.chunks[c.index]
+			c.currentEnd = min(chunk.Offset+chunk.Size, c.end)
+			body, err := c.store.Get(c.ctx, chunk.BlobKey, c.offset-chunk.Offset, c.currentEnd-c.offset)
+			if err != nil {
+				return 0, err
+			}
+			c.current = body
+		}
+
+		n, err := c.current.Read(p)
+		c.offset += int64(n)
+		if err == io.EOF {
+			c.current.Close()
+			c.current = nil
+			if c.offset < c.currentEnd {
+				return n, io.ErrUnexpectedEOF
+			}
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+
+		return n, err
+	}
+}
+
+func (c *contentReader) Close() error {
+	if c.current == nil {
+		return nil
+	}
+	err := c.current.Close()
+	c.current = nil
+	return err
+}
@@ -0,0 +1,32 @@
+package dto
+
+type (
+	AuthInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+	}
+	// CommentInput carries a comment body encrypted with the entry key, so
+	// anyone who can read the entry can read its comments.
+	CommentInput struct {
+		UserAddress   string `json:"user_address" db:"user_address"`
+		Password      string `json:"password" db:"password"`
+		EncryptedBody []byte `json:"encrypted_body" db:"encrypted_body"`
+		BodyIV        []byte `json:"body_iv" db:"body_iv"`
+	}
+	Comment struct {
+		ID            string `json:"id" db:"id"`
+		Seq           int64  `json:"-" db:"seq"`
+		KeyID         string `json:"key_id" db:"key_id"`
+		AuthorAddress string `json:"author_address" db:"author_address"`
+		EncryptedBody []byte `json:"encrypted_body" db:"encrypted_body"`
+		BodyIV        []byte `json:"body_iv" db:"body_iv"`
+		CreatedAt     string `json:"created_at" db:"created_at"`
+		UpdatedAt     string `json:"updated_at" db:"updated_at"`
+	}
+	// CommentPage is one page of a thread, oldest first. NextCursor is set
+	// when more comments follow and is passed back as the cursor parameter.
+	CommentPage struct {
+		Comments   []Comment `json:"comments"`
+		NextCursor *int64    `json:"next_cursor,omitempty"`
+	}
+)
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ObscuraNote/api-general/internal/comments/dto"
+	cService "github.com/ObscuraNote/api-general/internal/comments/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+type handler struct {
+	log *logger.Logger
+	cs  cService.CommentsService
+}
+
+func Register(router chi.Router, cs cService.CommentsService, log logger.Logger) {
+	h := &handler{
+		log: &log,
+		cs:  cs,
+	}
+
+	router.Get("/keys/{id}/comments", h.GetComments)
+	router.Post("/keys/{id}/comments", h.AddComment)
+	router.Put("/keys/{id}/comments/{commentId}", h.UpdateComment)
+	router.Delete("/keys/{id}/comments/{commentId}", h.DeleteComment)
+}
+
+func (h *handler) GetComments(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	cursor, err := utils.ParseInt64Query(w, r, "cursor", 0)
+	if err != nil {
+		return
+	}
+
+	limit, err := utils.ParseInt64Query(w, r, "limit", 0)
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.cs.GetComments(keyID.String(), cursor, limit, auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "comments", "function": "GetComments"}).
+			Error("Failed to get comments")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, page); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "comments", "function": "GetComments"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) AddComment(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var input dto.CommentInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	comment, err := h.cs.AddComment(keyID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "comments", "function": "AddComment"}).
+			Error("Failed to add comment")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, comment); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "comments", "function": "AddComment"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	commentID, err := utils.ParseParamUUID(w, r, "commentId")
+	if err != nil {
+		return
+	}
+
+	var input dto.CommentInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	comment, err := h.cs.UpdateComment(keyID.String(), commentID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "comments", "function": "UpdateComment"}).
+			Error("Failed to update comment")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, comment); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "comments", "function": "UpdateComment"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	commentID, err := utils.ParseParamUUID(w, r, "commentId")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.cs.DeleteComment(keyID.String(), commentID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "comments", "function": "DeleteComment"}).
+			Error("Failed to delete comment")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.BadRequest:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.PayloadTooLarge:
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, err.Error())
+	case utils.KeyNotFound, utils.CommentNotFound:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/ObscuraNote/api-general/internal/comments/dto"
+	"github.com/philippe-berto/database/postgresdb"
+)
+
+var _ CommentsRepository = (*Repository)(nil)
+
+type (
+	CommentsRepository interface {
+		AddComment(authorId int64, keyId string, comment dto.CommentInput) (*dto.Comment, error)
+		GetComments(keyId string, afterSeq, limit int64) ([]dto.Comment, error)
+		UpdateComment(authorId int64, keyId, id string, comment dto.CommentInput) (*dto.Comment, error)
+		DeleteComment(authorId int64, keyId, id string) (bool, error)
+		DeleteAnyComment(keyId, id string) (bool, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+func (r *Repository) AddComment(authorId int64, keyId string, comment dto.CommentInput) (*dto.Comment, error) {
+	var result dto.Comment
+	err := r.statements.addComment.statement.
+		QueryRowContext(r.ctx, keyId, authorId, comment.EncryptedBody, comment.BodyIV).
+		Scan(&result.ID, &result.Seq, &result.KeyID, &result.AuthorAddress, &result.EncryptedBody, &result.BodyIV,
+			&result.CreatedAt, &result.UpdatedAt)
+	if err != nil {
+		log.Println("Error adding comment")
+
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetComments returns up to limit comments of an entry that come after the
+// afterSeq cursor, oldest first.
+func (r *Repository) GetComments(keyId string, afterSeq, limit int64) ([]dto.Comment, error) {
+	rows, err := r.statements.getComments.statement.
+		QueryContext(r.ctx, keyId, afterSeq, limit)
+	if err != nil {
+		log.Println("Error getting comments")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []dto.Comment
+	for rows.Next() {
+		var comment dto.Comment
+		if err := rows.Scan(&comment.ID, &comment.Seq, &comment.KeyID, &comment.AuthorAddress, &comment.EncryptedBody,
+			&comment.BodyIV, &comment.CreatedAt, &comment.UpdatedAt); err != nil {
+			log.Println("Error scanning comment")
+
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// UpdateComment replaces the body of one of the author's comments. It returns
+// sql.ErrNoRows when the comment does not exist or has another author.
+func (r *Repository) UpdateComment(authorId int64, keyId, id string, comment dto.CommentInput) (*dto.Comment, error) {
+	var result dto.Comment
+	err := r.statements.updateComment.statement.
+		QueryRowContext(r.ctx, id, keyId, authorId, comment.EncryptedBody, comment.BodyIV).
+		Scan(&result.ID, &result.Seq, &result.KeyID, &result.AuthorAddress, &result.EncryptedBody, &result.BodyIV,
+			&result.CreatedAt, &result.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *Repository) DeleteComment(authorId int64, keyId, id string) (bool, error) {
+	result, err := r.statements.deleteComment.statement.
+		ExecContext(r.ctx, id, keyId, authorId)
+	if err != nil {
+		log.Println("Error deleting comment")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+// DeleteAnyComment removes a comment regardless of its author. It is meant for
+// entry owners and vault admins.
+func (r *Repository) DeleteAnyComment(keyId, id string) (bool, error) {
+	result, err := r.statements.moderateDelete.statement.
+		ExecContext(r.ctx, id, keyId)
+	if err != nil {
+		log.Println("Error deleting comment")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.addComment.statement, err = r.db.PrepareStatement(statementsList.addComment.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getComments.statement, err = r.db.PrepareStatement(statementsList.getComments.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.updateComment.statement, err = r.db.PrepareStatement(statementsList.updateComment.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteComment.statement, err = r.db.PrepareStatement(statementsList.deleteComment.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.moderateDelete.statement, err = r.db.PrepareStatement(statementsList.moderateDelete.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
+
+func rowsAffected(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
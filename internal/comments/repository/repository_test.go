@@ -0,0 +1,129 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ObscuraNote/api-general/internal/comments/dto"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE keys CASCADE;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE keys CASCADE;")
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	db.GetClient().Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'),
+		       ('2222222222222222222222222222222222222222222222222222222222222222', 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb');
+	`)
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var authorId, otherId int64
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '1111111111111111111111111111111111111111111111111111111111111111';
+	`).Scan(&authorId)
+	if err != nil {
+		t.Fatalf("failed to get author id: %v", err)
+	}
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+	`).Scan(&otherId)
+	if err != nil {
+		t.Fatalf("failed to get other user id: %v", err)
+	}
+
+	var keyId string
+	err = db.GetClient().QueryRow(`
+		INSERT INTO keys (user_id, user_address, encrypted_key, key_iv, encrypted_data, data_iv)
+		VALUES ($1, '1111111111111111111111111111111111111111111111111111111111111111', 'key', 'iv', 'data', 'iv')
+		RETURNING id;
+	`, authorId).Scan(&keyId)
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+
+	var commentIds []string
+
+	t.Run("AddComment", func(t *testing.T) {
+		for _, body := range []string{"first", "second", "third"} {
+			comment, err := repo.AddComment(authorId, keyId, dto.CommentInput{EncryptedBody: []byte(body), BodyIV: []byte("iv")})
+			assert.NoError(t, err)
+			assert.Equal(t, "1111111111111111111111111111111111111111111111111111111111111111", comment.AuthorAddress)
+			commentIds = append(commentIds, comment.ID)
+		}
+	})
+
+	t.Run("GetComments", func(t *testing.T) {
+		comments, err := repo.GetComments(keyId, 0, 2)
+		assert.NoError(t, err)
+		assert.Len(t, comments, 2)
+		assert.Equal(t, []byte("first"), comments[0].EncryptedBody)
+
+		comments, err = repo.GetComments(keyId, comments[1].Seq, 2)
+		assert.NoError(t, err)
+		assert.Len(t, comments, 1)
+		assert.Equal(t, []byte("third"), comments[0].EncryptedBody)
+	})
+
+	t.Run("UpdateComment", func(t *testing.T) {
+		_, err := repo.UpdateComment(otherId, keyId, commentIds[0], dto.CommentInput{EncryptedBody: []byte("hijack")})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		comment, err := repo.UpdateComment(authorId, keyId, commentIds[0], dto.CommentInput{EncryptedBody: []byte("edited")})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("edited"), comment.EncryptedBody)
+	})
+
+	t.Run("DeleteComment", func(t *testing.T) {
+		deleted, err := repo.DeleteComment(otherId, keyId, commentIds[0])
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+
+		deleted, err = repo.DeleteComment(authorId, keyId, commentIds[0])
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+
+		deleted, err = repo.DeleteAnyComment(keyId, commentIds[1])
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+	})
+
+	t.Run("CascadeOnKeyDelete", func(t *testing.T) {
+		_, err := db.GetClient().Exec("DELETE FROM keys WHERE id = $1;", keyId)
+		assert.NoError(t, err)
+
+		comments, err := repo.GetComments(keyId, 0, 10)
+		assert.NoError(t, err)
+		assert.Len(t, comments, 0)
+	})
+}
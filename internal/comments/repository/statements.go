@@ -0,0 +1,73 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	addComment     statementsItem
+	getComments    statementsItem
+	updateComment  statementsItem
+	deleteComment  statementsItem
+	moderateDelete statementsItem
+}
+
+var statementsList = statements{
+	addComment: statementsItem{
+		name: "addComment",
+		query: `
+			WITH c AS (
+				INSERT INTO comments (key_id, author_id, encrypted_body, body_iv)
+				VALUES ($1, $2, $3, $4)
+				RETURNING id, seq, key_id, author_id, encrypted_body, body_iv, created_at, updated_at
+			)
+			SELECT c.id, c.seq, c.key_id, u.user_address, c.encrypted_body, c.body_iv, c.created_at, c.updated_at
+			FROM c
+			JOIN users u ON u.id = c.author_id;`,
+	},
+	getComments: statementsItem{
+		name: "getComments",
+		query: `
+			SELECT c.id, c.seq, c.key_id, u.user_address, c.encrypted_body, c.body_iv, c.created_at, c.updated_at
+			FROM comments c
+			JOIN users u ON u.id = c.author_id
+			WHERE c.key_id = $1
+			AND c.seq > $2
+			ORDER BY c.seq
+			LIMIT $3;`,
+	},
+	updateComment: statementsItem{
+		name: "updateComment",
+		query: `
+			WITH c AS (
+				UPDATE comments
+				SET encrypted_body = $4, body_iv = $5, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1
+				AND key_id = $2
+				AND author_id = $3
+				RETURNING id, seq, key_id, author_id, encrypted_body, body_iv, created_at, updated_at
+			)
+			SELECT c.id, c.seq, c.key_id, u.user_address, c.encrypted_body, c.body_iv, c.created_at, c.updated_at
+			FROM c
+			JOIN users u ON u.id = c.author_id;`,
+	},
+	deleteComment: statementsItem{
+		name: "deleteComment",
+		query: `
+			DELETE FROM comments
+			WHERE id = $1
+			AND key_id = $2
+			AND author_id = $3;`,
+	},
+	moderateDelete: statementsItem{
+		name: "moderateDelete",
+		query: `
+			DELETE FROM comments
+			WHERE id = $1
+			AND key_id = $2;`,
+	},
+}
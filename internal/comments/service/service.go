@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ObscuraNote/api-general/internal/comments/dto"
+	r "github.com/ObscuraNote/api-general/internal/comments/repository"
+	k "github.com/ObscuraNote/api-general/internal/keys/service"
+	u "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/philippe-berto/logger"
+)
+
+var _ CommentsService = (*Service)(nil)
+
+const (
+	maxCommentSize  = 16 * 1024
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type (
+	CommentsService interface {
+		AddComment(keyId string, comment dto.CommentInput) (*dto.Comment, error)
+		GetComments(keyId string, cursor, limit int64, auth dto.AuthInput) (*dto.CommentPage, error)
+		UpdateComment(keyId, commentId string, comment dto.CommentInput) (*dto.Comment, error)
+		DeleteComment(keyId, commentId string, auth dto.AuthInput) error
+	}
+
+	Service struct {
+		ctx context.Context
+		r   r.CommentsRepository
+		us  u.UserService
+		ks  k.KeysService
+		log *logger.Logger
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.CommentsRepository, us u.UserService, ks k.KeysService) *Service {
+	return &Service{
+		ctx: ctx,
+		log: &log,
+		r:   repo,
+		us:  us,
+		ks:  ks,
+	}
+}
+
+func (s *Service) AddComment(keyId string, comment dto.CommentInput) (*dto.Comment, error) {
+	if err := validateBody(comment.EncryptedBody); err != nil {
+		return nil, err
+	}
+
+	userId, _, err := s.authorize(keyId, comment.UserAddress, comment.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.r.AddComment(userId, keyId, comment)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "comments_service", "function": "AddComment"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return created, nil
+}
+
+func (s *Service) GetComments(keyId string, cursor, limit int64, auth dto.AuthInput) (*dto.CommentPage, error) {
+	if cursor < 0 || limit < 0 {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	if _, _, err := s.authorize(keyId, auth.UserAddress, auth.Password); err != nil {
+		return nil, err
+	}
+
+	// One extra row tells whether another page follows.
+	comments, err := s.r.GetComments(keyId, cursor, limit+1)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "comments_service", "function": "GetComments"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	page := &dto.CommentPage{Comments: comments}
+	if int64(len(comments)) > limit {
+		page.Comments = comments[:limit]
+		next := page.Comments[limit-1].Seq
+		page.NextCursor = &next
+	}
+	if page.Comments == nil {
+		page.Comments = []dto.Comment{}
+	}
+
+	return page, nil
+}
+
+// UpdateComment lets an author edit their own comment while they still have
+// access to the entry.
+func (s *Service) UpdateComment(keyId, commentId string, comment dto.CommentInput) (*dto.Comment, error) {
+	if err := validateBody(comment.EncryptedBody); err != nil {
+		return nil, err
+	}
+
+	userId, _, err := s.authorize(keyId, comment.UserAddress, comment.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.r.UpdateComment(userId, keyId, commentId, comment)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.CommentNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "comments_service", "function": "UpdateComment"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return updated, nil
+}
+
+// DeleteComment removes a comment. Authors can delete their own comments;
+// the entry owner and vault admins can delete any comment on the entry.
+func (s *Service) DeleteComment(keyId, commentId string, auth dto.AuthInput) error {
+	userId, moderator, err := s.authorize(keyId, auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	var deleted bool
+	if moderator {
+		deleted, err = s.r.DeleteAnyComment(keyId, commentId)
+	} else {
+		deleted, err = s.r.DeleteComment(userId, keyId, commentId)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "comments_service", "function": "DeleteComment"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if !deleted {
+		return fmt.Errorf(utils.CommentNotFound)
+	}
+
+	return nil
+}
+
+// authorize resolves the caller and checks their access to the entry through
+// the same rules that guard the entry itself.
+func (s *Service) authorize(keyId, userAddress, password string) (int64, bool, error) {
+	userId, err := s.getUserId(userAddress, password)
+	if err != nil {
+		return 0, false, err
+	}
+
+	moderator, err := s.ks.AuthorizeKey(keyId, userId)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return userId, moderator, nil
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "comments_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
+
+func validateBody(body []byte) error {
+	if len(body) == 0 {
+		return fmt.Errorf(utils.BadRequest)
+	}
+	if len(body) > maxCommentSize {
+		return fmt.Errorf(utils.PayloadTooLarge)
+	}
+	return nil
+}
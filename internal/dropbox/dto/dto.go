@@ -0,0 +1,46 @@
+package dto
+
+type (
+	AuthInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+	}
+	DropboxInput struct {
+		UserAddress    string `json:"user_address" db:"user_address"`
+		Password       string `json:"password" db:"password"`
+		EncryptedLabel []byte `json:"encrypted_label,omitempty" db:"encrypted_label"`
+	}
+	Dropbox struct {
+		ID             string `json:"id" db:"id"`
+		EncryptedLabel []byte `json:"encrypted_label,omitempty" db:"encrypted_label"`
+		Submissions    int    `json:"submissions" db:"submissions"`
+		CreatedAt      string `json:"created_at" db:"created_at"`
+	}
+	// DropboxInfo is what an anonymous sender needs to seal a submission: the
+	// owner's current encryption key and the proof-of-work parameters.
+	DropboxInfo struct {
+		ID            string `json:"id" db:"id"`
+		KeyVersion    int    `json:"key_version" db:"key_version"`
+		EncryptionKey []byte `json:"encryption_key" db:"encryption_key"`
+		Difficulty    int    `json:"difficulty"`
+		MaxSize       int    `json:"max_size"`
+	}
+	Challenge struct {
+		Challenge  string `json:"challenge"`
+		Difficulty int    `json:"difficulty"`
+		ExpiresAt  string `json:"expires_at"`
+	}
+	// SubmissionInput is an anonymous submission. SealedBlob is encrypted to
+	// the owner's key; Solution solves Challenge.
+	SubmissionInput struct {
+		Challenge  string `json:"challenge"`
+		Solution   []byte `json:"solution"`
+		SealedBlob []byte `json:"sealed_blob"`
+	}
+	Submission struct {
+		ID         string `json:"id" db:"id"`
+		Size       int    `json:"size" db:"size"`
+		SealedBlob []byte `json:"sealed_blob,omitempty" db:"sealed_blob"`
+		CreatedAt  string `json:"created_at" db:"created_at"`
+	}
+)
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ObscuraNote/api-general/internal/dropbox/dto"
+	dService "github.com/ObscuraNote/api-general/internal/dropbox/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+type handler struct {
+	log         *logger.Logger
+	ds          dService.DropboxService
+	maxBodySize int64
+}
+
+func Register(router chi.Router, ds dService.DropboxService, maxSize int, log logger.Logger) {
+	h := &handler{
+		log: &log,
+		ds:  ds,
+		// Sealed blobs arrive base64 encoded inside a JSON document.
+		maxBodySize: int64(maxSize)*4/3 + 1024,
+	}
+
+	router.Post("/dropboxes", h.CreateDropbox)
+	router.Get("/dropboxes", h.GetDropboxes)
+	router.Delete("/dropboxes/{id}", h.DeleteDropbox)
+	router.Get("/dropboxes/{id}/submissions", h.GetSubmissions)
+	router.Get("/dropboxes/{id}/submissions/{submissionId}", h.GetSubmission)
+	router.Delete("/dropboxes/{id}/submissions/{submissionId}", h.DeleteSubmission)
+
+	// Public endpoints for senders without an account. They must not log
+	// anything that could identify a sender or the drop box they write to.
+	router.Get("/drop/{id}", h.GetDropboxInfo)
+	router.Get("/drop/{id}/challenge", h.GetChallenge)
+	router.Post("/drop/{id}", h.Submit)
+}
+
+func (h *handler) CreateDropbox(w http.ResponseWriter, r *http.Request) {
+	var input dto.DropboxInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	dropbox, err := h.ds.CreateDropbox(input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "CreateDropbox"}).
+			Error("Failed to create drop box")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, dropbox); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "CreateDropbox"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetDropboxes(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	dropboxes, err := h.ds.GetDropboxes(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "GetDropboxes"}).
+			Error("Failed to get drop boxes")
+
+		writeError(w, err)
+		return
+	}
+
+	if dropboxes == nil {
+		dropboxes = []dto.Dropbox{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, dropboxes); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "GetDropboxes"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) DeleteDropbox(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ds.DeleteDropbox(chi.URLParam(r, "id"), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "DeleteDropbox"}).
+			Error("Failed to delete drop box")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetSubmissions(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	submissions, err := h.ds.GetSubmissions(chi.URLParam(r, "id"), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "GetSubmissions"}).
+			Error("Failed to get submissions")
+
+		writeError(w, err)
+		return
+	}
+
+	if submissions == nil {
+		submissions = []dto.Submission{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, submissions); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "GetSubmissions"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetSubmission(w http.ResponseWriter, r *http.Request) {
+	submissionID, err := utils.ParseParamUUID(w, r, "submissionId")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	submission, err := h.ds.GetSubmission(chi.URLParam(r, "id"), submissionID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "GetSubmission"}).
+			Error("Failed to get submission")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, submission); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "GetSubmission"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) DeleteSubmission(w http.ResponseWriter, r *http.Request) {
+	submissionID, err := utils.ParseParamUUID(w, r, "submissionId")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ds.DeleteSubmission(chi.URLParam(r, "id"), submissionID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "dropbox", "function": "DeleteSubmission"}).
+			Error("Failed to delete submission")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetDropboxInfo(w http.ResponseWriter, r *http.Request) {
+	info, err := h.ds.GetDropboxInfo(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	_ = utils.WriteBody(w, http.StatusOK, info)
+}
+
+func (h *handler) GetChallenge(w http.ResponseWriter, r *http.Request) {
+	challenge, err := h.ds.GetChallenge(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	_ = utils.WriteBody(w, http.StatusOK, challenge)
+}
+
+func (h *handler) Submit(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+
+	var input dto.SubmissionInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if err := h.ds.Submit(chi.URLParam(r, "id"), input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.BadRequest, utils.InvalidProof:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.PayloadTooLarge:
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, err.Error())
+	case utils.DropboxNotFound, utils.NoSubmission, utils.KeysNotFound:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	case utils.DropboxFull:
+		_ = utils.Fault(w, http.StatusConflict, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
+}
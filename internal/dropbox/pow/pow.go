@@ -0,0 +1,120 @@
+// Package pow implements stateless hashcash-style proof-of-work challenges.
+// A challenge is a random nonce bound to a scope and an expiry by an HMAC, so
+// the server stores nothing until a solution comes back. A solution is any
+// byte string whose SHA-256 hash together with the challenge starts with the
+// required number of zero bits:
+//
+//	SHA-256(challenge || solution) < 2^(256 - difficulty)
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"time"
+)
+
+const (
+	nonceSize = 16
+	macSize   = 16
+	// MaxSolutionSize bounds the solution a client may send.
+	MaxSolutionSize = 64
+)
+
+var (
+	ErrInvalidChallenge = errors.New("pow: invalid challenge")
+	ErrExpired          = errors.New("pow: challenge expired")
+	ErrInsufficientWork = errors.New("pow: insufficient work")
+)
+
+type Issuer struct {
+	key        []byte
+	difficulty int
+	ttl        time.Duration
+}
+
+func New(key []byte, difficulty int, ttl time.Duration) *Issuer {
+	return &Issuer{
+		key:        key,
+		difficulty: difficulty,
+		ttl:        ttl,
+	}
+}
+
+func (i *Issuer) Difficulty() int {
+	return i.difficulty
+}
+
+// Challenge issues a challenge valid for scope until the returned time.
+func (i *Issuer) Challenge(scope string, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(i.ttl).Truncate(time.Second)
+
+	raw := make([]byte, nonceSize+8, nonceSize+8+macSize)
+	if _, err := rand.Read(raw[:nonceSize]); err != nil {
+		return "", time.Time{}, err
+	}
+	binary.BigEndian.PutUint64(raw[nonceSize:], uint64(expiresAt.Unix()))
+	raw = append(raw, i.mac(scope, raw)...)
+
+	return base64.RawURLEncoding.EncodeToString(raw), expiresAt, nil
+}
+
+// Verify checks a solution to a challenge issued for scope. On success it
+// returns a digest that identifies the challenge, so callers can refuse to
+// accept the same challenge twice, and the time the challenge expires.
+func (i *Issuer) Verify(scope, challenge string, solution []byte, now time.Time) ([]byte, time.Time, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(challenge)
+	if err != nil || len(raw) != nonceSize+8+macSize || len(solution) > MaxSolutionSize {
+		return nil, time.Time{}, ErrInvalidChallenge
+	}
+
+	body, mac := raw[:nonceSize+8], raw[nonceSize+8:]
+	if subtle.ConstantTimeCompare(mac, i.mac(scope, body)) != 1 {
+		return nil, time.Time{}, ErrInvalidChallenge
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(body[nonceSize:])), 0)
+	if !now.Before(expiresAt) {
+		return nil, time.Time{}, ErrExpired
+	}
+
+	if LeadingZeroBits(Hash(raw, solution)) < i.difficulty {
+		return nil, time.Time{}, ErrInsufficientWork
+	}
+
+	digest := sha256.Sum256(raw)
+
+	return digest[:], expiresAt, nil
+}
+
+// Hash is the value a solution must make small enough.
+func Hash(challenge, solution []byte) []byte {
+	h := sha256.New()
+	h.Write(challenge)
+	h.Write(solution)
+	return h.Sum(nil)
+}
+
+func LeadingZeroBits(hash []byte) int {
+	zeros := 0
+	for _, b := range hash {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+	return zeros
+}
+
+func (i *Issuer) mac(scope string, body []byte) []byte {
+	h := hmac.New(sha256.New, i.key)
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write(body)
+	return h.Sum(nil)[:macSize]
+}
@@ -0,0 +1,60 @@
+package pow
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func solve(t *testing.T, challenge string, difficulty int) []byte {
+	raw, err := base64.RawURLEncoding.DecodeString(challenge)
+	assert.NoError(t, err)
+
+	solution := make([]byte, 8)
+	for counter := uint64(0); ; counter++ {
+		binary.BigEndian.PutUint64(solution, counter)
+		if LeadingZeroBits(Hash(raw, solution)) >= difficulty {
+			return solution
+		}
+	}
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	assert.Equal(t, 0, LeadingZeroBits([]byte{0x80}))
+	assert.Equal(t, 7, LeadingZeroBits([]byte{0x01}))
+	assert.Equal(t, 12, LeadingZeroBits([]byte{0x00, 0x0f}))
+	assert.Equal(t, 16, LeadingZeroBits([]byte{0x00, 0x00}))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	issuer := New([]byte("key"), 8, time.Minute)
+
+	challenge, expiresAt, err := issuer.Challenge("box", now)
+	assert.NoError(t, err)
+	assert.True(t, expiresAt.After(now))
+
+	solution := solve(t, challenge, 8)
+
+	digest, _, err := issuer.Verify("box", challenge, solution, now)
+	assert.NoError(t, err)
+	assert.Len(t, digest, 32)
+
+	_, _, err = issuer.Verify("other-box", challenge, solution, now)
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+
+	_, _, err = New([]byte("other-key"), 8, time.Minute).Verify("box", challenge, solution, now)
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+
+	_, _, err = issuer.Verify("box", challenge, solution, now.Add(2*time.Minute))
+	assert.ErrorIs(t, err, ErrExpired)
+
+	_, _, err = New([]byte("key"), 40, time.Minute).Verify("box", challenge, solution, now)
+	assert.ErrorIs(t, err, ErrInsufficientWork)
+
+	_, _, err = issuer.Verify("box", "not-a-challenge", solution, now)
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+}
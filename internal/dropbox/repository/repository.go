@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/dropbox/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/lock"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
+)
+
+var _ DropboxRepository = (*Repository)(nil)
+
+var (
+	// ErrChallengeUsed is returned when a proof-of-work challenge was already
+	// spent on another submission.
+	ErrChallengeUsed = errors.New("challenge already used")
+	// ErrDropboxFull is returned when a drop box holds the maximum number of
+	// submissions.
+	ErrDropboxFull = errors.New("drop box is full")
+)
+
+type (
+	DropboxRepository interface {
+		CreateDropbox(userId int64, id string, encryptedLabel []byte) (*dto.Dropbox, error)
+		GetDropboxesByUser(userId int64) ([]dto.Dropbox, error)
+		GetDropboxInfo(id string) (*dto.DropboxInfo, error)
+		DeleteDropbox(userId int64, id string) (bool, error)
+		AddSubmission(dropboxId string, digest []byte, expiresAt time.Time, sealedBlob []byte, maxSubmissions int) error
+		GetSubmissions(userId int64, dropboxId string) ([]dto.Submission, error)
+		GetSubmission(userId int64, dropboxId, id string) (*dto.Submission, error)
+		DeleteSubmission(userId int64, dropboxId, id string) (bool, error)
+		DeleteExpiredChallenges() (int64, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+func (r *Repository) CreateDropbox(userId int64, id string, encryptedLabel []byte) (*dto.Dropbox, error) {
+	var dropbox dto.Dropbox
+	if err := r.statements.createDropbox.statement.
+		QueryRowContext(r.ctx, id, userId, encryptedLabel).
+		Scan(&dropbox.ID, &dropbox.EncryptedLabel, &dropbox.CreatedAt); err != nil {
+		log.Println("Error creating drop box")
+		return nil, err
+	}
+
+	return &dropbox, nil
+}
+
+func (r *Repository) GetDropboxesByUser(userId int64) ([]dto.Dropbox, error) {
+	rows, err := r.statements.getDropboxesByUser.statement.QueryContext(r.ctx, userId)
+	if err != nil {
+		log.Println("Error getting drop boxes")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dropboxes []dto.Dropbox
+	for rows.Next() {
+		var dropbox dto.Dropbox
+		if err := rows.Scan(&dropbox.ID, &dropbox.EncryptedLabel, &dropbox.Submissions, &dropbox.CreatedAt); err != nil {
+			log.Println("Error scanning drop box")
+			return nil, err
+		}
+		dropboxes = append(dropboxes, dropbox)
+	}
+
+	return dropboxes, rows.Err()
+}
+
+// GetDropboxInfo returns the drop box with its owner's latest encryption key.
+// It returns sql.ErrNoRows when the drop box does not exist or its owner has
+// no published keys.
+func (r *Repository) GetDropboxInfo(id string) (*dto.DropboxInfo, error) {
+	var info dto.DropboxInfo
+	if err := r.statements.getDropboxInfo.statement.
+		QueryRowContext(r.ctx, id).
+		Scan(&info.ID, &info.KeyVersion, &info.EncryptionKey); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("Error getting drop box")
+		}
+
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+func (r *Repository) DeleteDropbox(userId int64, id string) (bool, error) {
+	result, err := r.statements.deleteDropbox.statement.ExecContext(r.ctx, id, userId)
+	if err != nil {
+		log.Println("Error deleting drop box")
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+// AddSubmission spends the challenge digest and stores the sealed blob. It
+// returns sql.ErrNoRows when the drop box does not exist, ErrChallengeUsed
+// when the digest was already spent and ErrDropboxFull when the drop box
+// already holds maxSubmissions blobs. Nothing is stored on error.
+func (r *Repository) AddSubmission(dropboxId string, digest []byte, expiresAt time.Time, sealedBlob []byte, maxSubmissions int) error {
+	_, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var id string
+		if err := tx.StmtxContext(ctx, r.statements.lockDropbox.statement).
+			QueryRowContext(ctx, dropboxId).Scan(&id); err != nil {
+			return nil, err
+		}
+
+		result, err := tx.StmtxContext(ctx, r.statements.consumeChallenge.statement).
+			ExecContext(ctx, hex.EncodeToString(digest), expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		if consumed, err := rowsAffected(result); err != nil {
+			return nil, err
+		} else if !consumed {
+			return nil, ErrChallengeUsed
+		}
+
+		var count int
+		if err := tx.StmtxContext(ctx, r.statements.countSubmissions.statement).
+			QueryRowContext(ctx, dropboxId).Scan(&count); err != nil {
+			return nil, err
+		}
+		if count >= maxSubmissions {
+			return nil, ErrDropboxFull
+		}
+
+		if _, err := tx.StmtxContext(ctx, r.statements.addSubmission.statement).
+			ExecContext(ctx, dropboxId, sealedBlob); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, ErrChallengeUsed) && !errors.Is(err, ErrDropboxFull) {
+			log.Println("Error adding submission")
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetSubmissions(userId int64, dropboxId string) ([]dto.Submission, error) {
+	rows, err := r.statements.getSubmissions.statement.QueryContext(r.ctx, dropboxId, userId)
+	if err != nil {
+		log.Println("Error getting submissions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submissions []dto.Submission
+	for rows.Next() {
+		var submission dto.Submission
+		if err := rows.Scan(&submission.ID, &submission.Size, &submission.CreatedAt); err != nil {
+			log.Println("Error scanning submission")
+			return nil, err
+		}
+		submissions = append(submissions, submission)
+	}
+
+	return submissions, rows.Err()
+}
+
+func (r *Repository) GetSubmission(userId int64, dropboxId, id string) (*dto.Submission, error) {
+	var submission dto.Submission
+	if err := r.statements.getSubmission.statement.
+		QueryRowContext(r.ctx, id, dropboxId, userId).
+		Scan(&submission.ID, &submission.Size, &submission.SealedBlob, &submission.CreatedAt); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("Error getting submission")
+		}
+
+		return nil, err
+	}
+
+	return &submission, nil
+}
+
+func (r *Repository) DeleteSubmission(userId int64, dropboxId, id string) (bool, error) {
+	result, err := r.statements.deleteSubmission.statement.ExecContext(r.ctx, id, dropboxId, userId)
+	if err != nil {
+		log.Println("Error deleting submission")
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+// DeleteExpiredChallenges forgets spent challenges that can no longer be
+// replayed. When another replica is already reaping, it does nothing and
+// returns zero.
+func (r *Repository) DeleteExpiredChallenges() (int64, error) {
+	deleted, err := lock.TryExclusive(r.ctx, r.db, lock.DropboxReaper, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		res, err := tx.StmtxContext(ctx, r.statements.deleteExpiredChallenges.statement).ExecContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		return res.RowsAffected()
+	})
+	if err != nil {
+		log.Println("Error deleting expired challenges")
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.createDropbox.statement, err = r.db.PrepareStatement(statementsList.createDropbox.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getDropboxesByUser.statement, err = r.db.PrepareStatement(statementsList.getDropboxesByUser.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getDropboxInfo.statement, err = r.db.PrepareStatement(statementsList.getDropboxInfo.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteDropbox.statement, err = r.db.PrepareStatement(statementsList.deleteDropbox.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockDropbox.statement, err = r.db.PrepareStatement(statementsList.lockDropbox.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.consumeChallenge.statement, err = r.db.PrepareStatement(statementsList.consumeChallenge.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.countSubmissions.statement, err = r.db.PrepareStatement(statementsList.countSubmissions.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.addSubmission.statement, err = r.db.PrepareStatement(statementsList.addSubmission.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getSubmissions.statement, err = r.db.PrepareStatement(statementsList.getSubmissions.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getSubmission.statement, err = r.db.PrepareStatement(statementsList.getSubmission.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteSubmission.statement, err = r.db.PrepareStatement(statementsList.deleteSubmission.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteExpiredChallenges.statement, err = r.db.PrepareStatement(statementsList.deleteExpiredChallenges.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
+
+func rowsAffected(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
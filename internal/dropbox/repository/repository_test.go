@@ -0,0 +1,162 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE drop_challenges;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE drop_challenges;")
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	db.GetClient().Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'),
+		       ('2222222222222222222222222222222222222222222222222222222222222222', 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb');
+	`)
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var ownerId, otherId int64
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '1111111111111111111111111111111111111111111111111111111111111111';
+	`).Scan(&ownerId)
+	if err != nil {
+		t.Fatalf("failed to get user id: %v", err)
+	}
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+	`).Scan(&otherId)
+	if err != nil {
+		t.Fatalf("failed to get user id: %v", err)
+	}
+
+	encryptionKey := make([]byte, 32)
+	_, err = db.GetClient().Exec(`
+		INSERT INTO user_public_keys (user_id, version, encryption_key, signing_key, signature)
+		VALUES ($1, 1, $2, $2, 'sig');
+	`, ownerId, encryptionKey)
+	if err != nil {
+		t.Fatalf("failed to publish keys: %v", err)
+	}
+
+	dropboxId := "c2VjcmV0LWRyb3AtYm94"
+	expiresAt := time.Now().Add(time.Minute)
+
+	t.Run("CreateDropbox", func(t *testing.T) {
+		dropbox, err := repo.CreateDropbox(ownerId, dropboxId, []byte("label"))
+		assert.NoError(t, err)
+		assert.Equal(t, dropboxId, dropbox.ID)
+
+		info, err := repo.GetDropboxInfo(dropboxId)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, info.KeyVersion)
+		assert.Equal(t, encryptionKey, info.EncryptionKey)
+
+		_, err = repo.GetDropboxInfo("missing")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("AddSubmission", func(t *testing.T) {
+		err := repo.AddSubmission(dropboxId, []byte("digest-1"), expiresAt, []byte("sealed"), 2)
+		assert.NoError(t, err)
+
+		err = repo.AddSubmission(dropboxId, []byte("digest-1"), expiresAt, []byte("sealed"), 2)
+		assert.ErrorIs(t, err, ErrChallengeUsed)
+
+		err = repo.AddSubmission(dropboxId, []byte("digest-2"), expiresAt, []byte("sealed"), 2)
+		assert.NoError(t, err)
+
+		err = repo.AddSubmission(dropboxId, []byte("digest-3"), expiresAt, []byte("sealed"), 2)
+		assert.ErrorIs(t, err, ErrDropboxFull)
+
+		err = repo.AddSubmission("missing", []byte("digest-4"), expiresAt, []byte("sealed"), 2)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		dropboxes, err := repo.GetDropboxesByUser(ownerId)
+		assert.NoError(t, err)
+		assert.Len(t, dropboxes, 1)
+		assert.Equal(t, 2, dropboxes[0].Submissions)
+	})
+
+	t.Run("Submissions", func(t *testing.T) {
+		submissions, err := repo.GetSubmissions(otherId, dropboxId)
+		assert.NoError(t, err)
+		assert.Len(t, submissions, 0)
+
+		submissions, err = repo.GetSubmissions(ownerId, dropboxId)
+		assert.NoError(t, err)
+		assert.Len(t, submissions, 2)
+		assert.Equal(t, len("sealed"), submissions[0].Size)
+		assert.Nil(t, submissions[0].SealedBlob)
+
+		submission, err := repo.GetSubmission(ownerId, dropboxId, submissions[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("sealed"), submission.SealedBlob)
+
+		_, err = repo.GetSubmission(otherId, dropboxId, submissions[0].ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		deleted, err := repo.DeleteSubmission(otherId, dropboxId, submissions[0].ID)
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+
+		deleted, err = repo.DeleteSubmission(ownerId, dropboxId, submissions[0].ID)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+	})
+
+	t.Run("DeleteExpiredChallenges", func(t *testing.T) {
+		err := repo.AddSubmission(dropboxId, []byte("digest-5"), time.Now().Add(-time.Minute), []byte("sealed"), 10)
+		assert.NoError(t, err)
+
+		deleted, err := repo.DeleteExpiredChallenges()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("DeleteDropbox", func(t *testing.T) {
+		deleted, err := repo.DeleteDropbox(otherId, dropboxId)
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+
+		deleted, err = repo.DeleteDropbox(ownerId, dropboxId)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+
+		dropboxes, err := repo.GetDropboxesByUser(ownerId)
+		assert.NoError(t, err)
+		assert.Len(t, dropboxes, 0)
+	})
+}
@@ -0,0 +1,125 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	createDropbox           statementsItem
+	getDropboxesByUser      statementsItem
+	getDropboxInfo          statementsItem
+	deleteDropbox           statementsItem
+	consumeChallenge        statementsItem
+	lockDropbox             statementsItem
+	countSubmissions        statementsItem
+	addSubmission           statementsItem
+	getSubmissions          statementsItem
+	getSubmission           statementsItem
+	deleteSubmission        statementsItem
+	deleteExpiredChallenges statementsItem
+}
+
+var statementsList = statements{
+	createDropbox: statementsItem{
+		name: "createDropbox",
+		query: `
+			INSERT INTO dropboxes (id, user_id, encrypted_label)
+			VALUES ($1, $2, $3)
+			RETURNING id, encrypted_label, created_at;`,
+	},
+	getDropboxesByUser: statementsItem{
+		name: "getDropboxesByUser",
+		query: `
+			SELECT d.id, d.encrypted_label, COUNT(s.id), d.created_at
+			FROM dropboxes d
+			LEFT JOIN drop_submissions s ON s.dropbox_id = d.id
+			WHERE d.user_id = $1
+			GROUP BY d.id
+			ORDER BY d.created_at DESC;`,
+	},
+	getDropboxInfo: statementsItem{
+		name: "getDropboxInfo",
+		query: `
+			SELECT d.id, k.version, k.encryption_key
+			FROM dropboxes d
+			JOIN user_public_keys k ON k.user_id = d.user_id
+			WHERE d.id = $1
+			ORDER BY k.version DESC
+			LIMIT 1;`,
+	},
+	deleteDropbox: statementsItem{
+		name: "deleteDropbox",
+		query: `
+			DELETE FROM dropboxes
+			WHERE id = $1
+			AND user_id = $2;`,
+	},
+	consumeChallenge: statementsItem{
+		name: "consumeChallenge",
+		query: `
+			INSERT INTO drop_challenges (digest, expires_at)
+			VALUES ($1, $2)
+			ON CONFLICT (digest) DO NOTHING;`,
+	},
+	lockDropbox: statementsItem{
+		name: "lockDropbox",
+		query: `
+			SELECT id
+			FROM dropboxes
+			WHERE id = $1
+			FOR UPDATE;`,
+	},
+	countSubmissions: statementsItem{
+		name: "countSubmissions",
+		query: `
+			SELECT COUNT(*)
+			FROM drop_submissions
+			WHERE dropbox_id = $1;`,
+	},
+	addSubmission: statementsItem{
+		name: "addSubmission",
+		query: `
+			INSERT INTO drop_submissions (dropbox_id, sealed_blob)
+			VALUES ($1, $2);`,
+	},
+	getSubmissions: statementsItem{
+		name: "getSubmissions",
+		query: `
+			SELECT s.id, octet_length(s.sealed_blob), s.created_at
+			FROM drop_submissions s
+			JOIN dropboxes d ON d.id = s.dropbox_id
+			WHERE s.dropbox_id = $1
+			AND d.user_id = $2
+			ORDER BY s.created_at DESC, s.id;`,
+	},
+	getSubmission: statementsItem{
+		name: "getSubmission",
+		query: `
+			SELECT s.id, octet_length(s.sealed_blob), s.sealed_blob, s.created_at
+			FROM drop_submissions s
+			JOIN dropboxes d ON d.id = s.dropbox_id
+			WHERE s.id = $1
+			AND s.dropbox_id = $2
+			AND d.user_id = $3;`,
+	},
+	deleteSubmission: statementsItem{
+		name: "deleteSubmission",
+		query: `
+			DELETE FROM drop_submissions s
+			USING dropboxes d
+			WHERE d.id = s.dropbox_id
+			AND s.id = $1
+			AND s.dropbox_id = $2
+			AND d.user_id = $3;`,
+	},
+	deleteExpiredChallenges: statementsItem{
+		name: "deleteExpiredChallenges",
+		query: `
+			DELETE FROM drop_challenges
+			WHERE expires_at <= CURRENT_TIMESTAMP;`,
+	},
+}
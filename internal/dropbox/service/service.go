@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/dropbox/dto"
+	"github.com/ObscuraNote/api-general/internal/dropbox/pow"
+	r "github.com/ObscuraNote/api-general/internal/dropbox/repository"
+	u "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/logger"
+)
+
+var _ DropboxService = (*Service)(nil)
+
+const (
+	dropboxIdSize     = 16
+	maxDropboxIdLen   = 32
+	maxLabelSize      = 1024
+	challengeScope    = "dropbox:"
+	powKeySize        = 32
+	minPowKeySize     = 16
+	maxPowDifficulty  = 32
+	minPowDifficulty  = 1
+	dropboxIdAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+)
+
+type (
+	// DropboxService lets a user publish drop boxes that anyone can submit
+	// blobs to without an account. Submissions are sealed to the owner's
+	// public key by the sender and gated by a proof-of-work challenge.
+	DropboxService interface {
+		CreateDropbox(input dto.DropboxInput) (*dto.Dropbox, error)
+		GetDropboxes(auth dto.AuthInput) ([]dto.Dropbox, error)
+		DeleteDropbox(id string, auth dto.AuthInput) error
+		GetSubmissions(id string, auth dto.AuthInput) ([]dto.Submission, error)
+		GetSubmission(id, submissionId string, auth dto.AuthInput) (*dto.Submission, error)
+		DeleteSubmission(id, submissionId string, auth dto.AuthInput) error
+		GetDropboxInfo(id string) (*dto.DropboxInfo, error)
+		GetChallenge(id string) (*dto.Challenge, error)
+		Submit(id string, input dto.SubmissionInput) error
+	}
+
+	Service struct {
+		ctx    context.Context
+		r      r.DropboxRepository
+		us     u.UserService
+		log    *logger.Logger
+		issuer *pow.Issuer
+		cfg    config.DropboxConfig
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.DropboxRepository, us u.UserService, cfg config.DropboxConfig) (*Service, error) {
+	if cfg.Difficulty < minPowDifficulty || cfg.Difficulty > maxPowDifficulty {
+		return nil, errors.New("dropbox: proof-of-work difficulty must be between 1 and 32")
+	}
+
+	s := &Service{
+		ctx: ctx,
+		log: &log,
+		r:   repo,
+		us:  us,
+		cfg: cfg,
+	}
+
+	var key []byte
+	if cfg.PowKey == "" {
+		key = make([]byte, powKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		s.log.Warn("DROPBOX_POW_KEY is not set, challenges are signed with an ephemeral key")
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(cfg.PowKey)
+		if err != nil || len(decoded) < minPowKeySize {
+			return nil, errors.New("dropbox: proof-of-work key must be base64 encoded and at least 16 bytes long")
+		}
+		key = decoded
+	}
+	s.issuer = pow.New(key, cfg.Difficulty, cfg.ChallengeTTL)
+
+	return s, nil
+}
+
+// CreateDropbox publishes a new drop box. The owner must have published
+// public keys, since senders seal submissions to them.
+func (s *Service) CreateDropbox(input dto.DropboxInput) (*dto.Dropbox, error) {
+	if len(input.EncryptedLabel) > maxLabelSize {
+		return nil, fmt.Errorf(utils.PayloadTooLarge)
+	}
+
+	userId, err := s.getUserId(input.UserAddress, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.us.GetPublicKeys(input.UserAddress); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, dropboxIdSize)
+	if _, err := rand.Read(raw); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "CreateDropbox"}).Error(utils.InternalCode)
+		return nil, fmt.Errorf(utils.InternalCode)
+	}
+
+	dropbox, err := s.r.CreateDropbox(userId, base64.RawURLEncoding.EncodeToString(raw), input.EncryptedLabel)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "CreateDropbox"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return dropbox, nil
+}
+
+func (s *Service) GetDropboxes(auth dto.AuthInput) ([]dto.Dropbox, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	dropboxes, err := s.r.GetDropboxesByUser(userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "GetDropboxes"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return dropboxes, nil
+}
+
+func (s *Service) DeleteDropbox(id string, auth dto.AuthInput) error {
+	if !validDropboxId(id) {
+		return fmt.Errorf(utils.DropboxNotFound)
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.r.DeleteDropbox(userId, id)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "DeleteDropbox"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !deleted {
+		return fmt.Errorf(utils.DropboxNotFound)
+	}
+
+	return nil
+}
+
+func (s *Service) GetSubmissions(id string, auth dto.AuthInput) ([]dto.Submission, error) {
+	if !validDropboxId(id) {
+		return nil, fmt.Errorf(utils.DropboxNotFound)
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	submissions, err := s.r.GetSubmissions(userId, id)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "GetSubmissions"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return submissions, nil
+}
+
+func (s *Service) GetSubmission(id, submissionId string, auth dto.AuthInput) (*dto.Submission, error) {
+	if !validDropboxId(id) {
+		return nil, fmt.Errorf(utils.DropboxNotFound)
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	submission, err := s.r.GetSubmission(userId, id, submissionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.NoSubmission)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "GetSubmission"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return submission, nil
+}
+
+func (s *Service) DeleteSubmission(id, submissionId string, auth dto.AuthInput) error {
+	if !validDropboxId(id) {
+		return fmt.Errorf(utils.DropboxNotFound)
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.r.DeleteSubmission(userId, id, submissionId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "DeleteSubmission"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !deleted {
+		return fmt.Errorf(utils.NoSubmission)
+	}
+
+	return nil
+}
+
+// GetDropboxInfo returns what an anonymous sender needs to submit to a drop
+// box. Drop boxes whose owner has no published keys are reported as missing.
+func (s *Service) GetDropboxInfo(id string) (*dto.DropboxInfo, error) {
+	if !validDropboxId(id) {
+		return nil, fmt.Errorf(utils.DropboxNotFound)
+	}
+
+	info, err := s.r.GetDropboxInfo(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.DropboxNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "GetDropboxInfo"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	info.Difficulty = s.issuer.Difficulty()
+	info.MaxSize = s.cfg.MaxSize
+
+	return info, nil
+}
+
+// GetChallenge issues a proof-of-work challenge bound to one drop box.
+// Challenges are stateless; nothing is stored until one is spent.
+func (s *Service) GetChallenge(id string) (*dto.Challenge, error) {
+	if _, err := s.GetDropboxInfo(id); err != nil {
+		return nil, err
+	}
+
+	challenge, expiresAt, err := s.issuer.Challenge(challengeScope+id, time.Now())
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "GetChallenge"}).Error(utils.InternalCode)
+		return nil, fmt.Errorf(utils.InternalCode)
+	}
+
+	return &dto.Challenge{
+		Challenge:  challenge,
+		Difficulty: s.issuer.Difficulty(),
+		ExpiresAt:  expiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// Submit stores an anonymous submission after checking its proof of work.
+// Each challenge can be spent once.
+func (s *Service) Submit(id string, input dto.SubmissionInput) error {
+	if len(input.SealedBlob) == 0 || input.Challenge == "" {
+		return fmt.Errorf(utils.BadRequest)
+	}
+	if len(input.SealedBlob) > s.cfg.MaxSize {
+		return fmt.Errorf(utils.PayloadTooLarge)
+	}
+	if !validDropboxId(id) {
+		return fmt.Errorf(utils.DropboxNotFound)
+	}
+
+	digest, expiresAt, err := s.issuer.Verify(challengeScope+id, input.Challenge, input.Solution, time.Now())
+	if err != nil {
+		return fmt.Errorf(utils.InvalidProof)
+	}
+
+	err = s.r.AddSubmission(id, digest, expiresAt, input.SealedBlob, s.cfg.MaxSubmissions)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf(utils.DropboxNotFound)
+	case errors.Is(err, r.ErrChallengeUsed):
+		return fmt.Errorf(utils.InvalidProof)
+	case errors.Is(err, r.ErrDropboxFull):
+		return fmt.Errorf(utils.DropboxFull)
+	case err != nil:
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "Submit"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return nil
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "dropbox_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
+
+func validDropboxId(id string) bool {
+	if id == "" || len(id) > maxDropboxIdLen {
+		return false
+	}
+	for _, c := range id {
+		if !strings.ContainsRune(dropboxIdAlphabet, c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+package dto
+
+const (
+	EventKeyCreated     = "key.created"
+	EventKeyUpdated     = "key.updated"
+	EventKeyDeleted     = "key.deleted"
+	EventShareReceived  = "share.received"
+	EventShareUpdated   = "share.updated"
+	EventShareRevoked   = "share.revoked"
+	EventSessionRevoked = "session.revoked"
+	// EventReset tells a client that the events after its Last-Event-ID are
+	// no longer kept. It syncs again before using the stream.
+	EventReset = "reset"
+	// EventHeartbeat keeps idle WebSocket connections open.
+	EventHeartbeat = "heartbeat"
+)
+
+type (
+	AuthInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+	}
+	// Event is a change in an account. ID numbers the events of the account
+	// and is what a client resumes from. Subject is the entry or share the
+	// event is about.
+	Event struct {
+		UserID    int64   `json:"-" db:"user_id"`
+		ID        int64   `json:"id,omitempty" db:"seq"`
+		Type      string  `json:"type" db:"type"`
+		Subject   *string `json:"subject,omitempty" db:"subject"`
+		CreatedAt string  `json:"created_at,omitempty" db:"created_at"`
+	}
+)
@@ -0,0 +1,281 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/events/dto"
+	eService "github.com/ObscuraNote/api-general/internal/events/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	lastEventIdHeader      = "Last-Event-ID"
+	lastEventIdParam       = "last_event_id"
+	// writeTimeout bounds a single write to a WebSocket client.
+	writeTimeout = 10 * time.Second
+	// authTimeout bounds the wait for the credentials of a WebSocket client
+	// that did not send an Authorization header.
+	authTimeout = 10 * time.Second
+)
+
+type handler struct {
+	log       *logger.Logger
+	es        eService.EventsService
+	heartbeat time.Duration
+}
+
+func Register(router chi.Router, es eService.EventsService, heartbeat time.Duration, log logger.Logger) {
+	h := &handler{
+		log:       &log,
+		es:        es,
+		heartbeat: heartbeat,
+	}
+
+	router.Get("/events", h.Stream)
+	router.Get("/events/ws", h.WebSocket)
+}
+
+// Stream sends the caller's events as Server-Sent Events. Heartbeats are
+// comments, which clients ignore.
+func (h *handler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+		return
+	}
+
+	since, ok := getSince(w, r)
+	if !ok {
+		return
+	}
+
+	sub, ok := h.subscribe(w, r, since, "Stream")
+	if !ok {
+		return
+	}
+	defer h.es.Unsubscribe(sub)
+
+	w.Header().Set(utils.ContentType, eventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "events", "function": "Stream"}).
+					Error("Failed to write event")
+				return
+			}
+			flusher.Flush()
+
+			if event.Type == dto.EventSessionRevoked {
+				return
+			}
+		}
+	}
+}
+
+// WebSocket sends the caller's events as JSON text messages, with heartbeat
+// messages in between. Browsers cannot set the Authorization header on a
+// WebSocket, so without it the first client message must carry the
+// credentials as an AuthInput. Later client messages are ignored.
+func (h *handler) WebSocket(w http.ResponseWriter, r *http.Request) {
+	since, ok := getSince(w, r)
+	if !ok {
+		return
+	}
+
+	var sub *eService.Subscription
+	defer func() {
+		if sub != nil {
+			h.es.Unsubscribe(sub)
+		}
+	}()
+
+	if r.Header.Get("Authorization") != "" {
+		if sub, ok = h.subscribe(w, r, since, "WebSocket"); !ok {
+			return
+		}
+	}
+
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		if sub == nil {
+			var err error
+			if sub, err = h.authenticate(ws, since); err != nil {
+				return
+			}
+		}
+
+		closed := make(chan struct{})
+		go func() {
+			_, _ = io.Copy(io.Discard, ws)
+			close(closed)
+		}()
+
+		heartbeat := time.NewTicker(h.heartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			var event dto.Event
+			select {
+			case <-closed:
+				return
+			case <-heartbeat.C:
+				event = dto.Event{Type: dto.EventHeartbeat}
+			case next, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+				event = next
+			}
+
+			_ = ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				return
+			}
+
+			if event.Type == dto.EventSessionRevoked {
+				return
+			}
+		}
+	}}
+	server.ServeHTTP(w, r)
+}
+
+// subscribe authenticates the caller from the Authorization header and opens
+// their stream after event since.
+func (h *handler) subscribe(w http.ResponseWriter, r *http.Request, since int64, function string) (*eService.Subscription, bool) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	sub, err := h.es.Subscribe(auth, since)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "events", "function": function}).
+			Error("Failed to subscribe to events")
+
+		writeError(w, err)
+		return nil, false
+	}
+
+	return sub, true
+}
+
+// authenticate opens the stream of a WebSocket client from the credentials
+// in its first message. A failure is sent back as an error message with the
+// code an HTTP response would have had.
+func (h *handler) authenticate(ws *websocket.Conn, since int64) (*eService.Subscription, error) {
+	var auth dto.AuthInput
+	_ = ws.SetReadDeadline(time.Now().Add(authTimeout))
+	err := websocket.JSON.Receive(ws, &auth)
+	_ = ws.SetReadDeadline(time.Time{})
+	if err != nil || auth.UserAddress == "" || auth.Password == "" {
+		err = fmt.Errorf(utils.BadRequest)
+	}
+
+	var sub *eService.Subscription
+	if err == nil {
+		if sub, err = h.es.Subscribe(auth, since); err != nil {
+			h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "events", "function": "WebSocket"}).
+				Error("Failed to subscribe to events")
+		}
+	}
+	if err != nil {
+		_, code := fault(err)
+		_ = ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+		_ = websocket.JSON.Send(ws, map[string]string{utils.ErrorCode: code})
+
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// getSince reads the last event a client has seen, from the Last-Event-ID
+// header or, for clients that cannot set it, the last_event_id parameter.
+func getSince(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	lastEventId := r.Header.Get(lastEventIdHeader)
+	if lastEventId == "" {
+		lastEventId = r.URL.Query().Get(lastEventIdParam)
+	}
+	if lastEventId == "" {
+		return 0, true
+	}
+
+	since, err := strconv.ParseInt(lastEventId, 10, 64)
+	if err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidParam)
+		return 0, false
+	}
+
+	return since, true
+}
+
+func writeEvent(w io.Writer, event dto.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+
+	return err
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, code := fault(err)
+	_ = utils.Fault(w, status, code)
+}
+
+// fault maps a service error to its HTTP status and error code.
+func fault(err error) (int, string) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		return http.StatusUnauthorized, utils.InvalidCredentials
+	case utils.BadRequest:
+		return http.StatusBadRequest, err.Error()
+	case utils.TooManyConnections:
+		return http.StatusTooManyRequests, err.Error()
+	default:
+		return http.StatusInternalServerError, utils.InternalCode
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"github.com/ObscuraNote/api-general/internal/events/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/ObscuraNote/api-general/internal/utils/lock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/philippe-berto/database/postgresdb"
+)
+
+var _ EventsRepository = (*Repository)(nil)
+
+type (
+	// EventsRepository reads the events database triggers record for every
+	// account.
+	EventsRepository interface {
+		GetEventState(userId int64) (int64, int64, error)
+		GetEvents(cursors map[int64]int64, limit int) ([]dto.Event, error)
+		DeleteOldEvents() (int64, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+		cfg        config.EventsConfig
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client, cfg config.EventsConfig) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+		cfg:        cfg,
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+// GetEventState returns the ID of the last event of userId and the floor
+// below which events were purged.
+func (r *Repository) GetEventState(userId int64) (int64, int64, error) {
+	var seq, floor int64
+	err := r.statements.getEventState.statement.
+		QueryRowContext(r.ctx, userId).
+		Scan(&seq, &floor)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return seq, floor, nil
+}
+
+// GetEvents returns up to limit events of the accounts in cursors that come
+// after the cursor of their account, in order for each account.
+func (r *Repository) GetEvents(cursors map[int64]int64, limit int) ([]dto.Event, error) {
+	userIds := make([]int64, 0, len(cursors))
+	seqs := make([]int64, 0, len(cursors))
+	for userId, seq := range cursors {
+		userIds = append(userIds, userId)
+		seqs = append(seqs, seq)
+	}
+
+	rows, err := r.statements.getEvents.statement.
+		QueryContext(r.ctx, pq.Array(userIds), pq.Array(seqs), limit)
+	if err != nil {
+		log.Println("Error getting events")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []dto.Event
+	for rows.Next() {
+		var event dto.Event
+		if err := rows.Scan(&event.UserID, &event.ID, &event.Type, &event.Subject, &event.CreatedAt); err != nil {
+			log.Println("Error scanning event")
+
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// DeleteOldEvents purges the events older than the retention period and
+// raises the event floor of their accounts past them. When another replica
+// is already purging, it does nothing and returns zero.
+func (r *Repository) DeleteOldEvents() (int64, error) {
+	deleted, err := lock.TryExclusive(r.ctx, r.db, lock.EventsReaper, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		var deleted int64
+		err := tx.StmtxContext(ctx, r.statements.deleteOldEvents.statement).
+			QueryRowContext(ctx, r.cfg.Retention.Seconds()).Scan(&deleted)
+
+		return deleted, err
+	})
+	if err != nil {
+		log.Println("Error deleting old events")
+
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.getEventState.statement, err = r.db.PrepareStatement(statementsList.getEventState.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getEvents.statement, err = r.db.PrepareStatement(statementsList.getEvents.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteOldEvents.statement, err = r.db.PrepareStatement(statementsList.deleteOldEvents.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
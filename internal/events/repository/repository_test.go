@@ -0,0 +1,128 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/events/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	db.GetClient().Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'),
+		       ('2222222222222222222222222222222222222222222222222222222222222222', 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb');
+	`)
+
+	repo, err := New(ctx, db, config.EventsConfig{Retention: time.Hour})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var userId, otherId int64
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '1111111111111111111111111111111111111111111111111111111111111111';
+	`).Scan(&userId)
+	if err != nil {
+		t.Fatalf("failed to get user id: %v", err)
+	}
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+	`).Scan(&otherId)
+	if err != nil {
+		t.Fatalf("failed to get other user id: %v", err)
+	}
+
+	seq, floor, err := repo.GetEventState(userId)
+	assert.NoError(t, err)
+	assert.Zero(t, seq)
+	assert.Zero(t, floor)
+
+	var keyId string
+	err = db.GetClient().QueryRow(`
+		INSERT INTO keys (user_id, user_address, encrypted_key, key_iv, encrypted_data, data_iv)
+		VALUES ($1, '1111111111111111111111111111111111111111111111111111111111111111', 'key', 'iv', 'data', 'iv')
+		RETURNING id;
+	`, userId).Scan(&keyId)
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+
+	_, err = db.GetClient().Exec(`UPDATE keys SET data_iv = 'iv2' WHERE id = $1;`, keyId)
+	assert.NoError(t, err)
+	_, err = db.GetClient().Exec(`
+		INSERT INTO key_shares (key_id, owner_id, recipient_id, encrypted_key)
+		VALUES ($1, $2, $3, 'key');
+	`, keyId, userId, otherId)
+	assert.NoError(t, err)
+	_, err = db.GetClient().Exec(`UPDATE key_shares SET status = 'accepted' WHERE key_id = $1;`, keyId)
+	assert.NoError(t, err)
+	_, err = db.GetClient().Exec(`DELETE FROM keys WHERE id = $1;`, keyId)
+	assert.NoError(t, err)
+	_, err = db.GetClient().Exec(`
+		UPDATE users SET password = 'cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc' WHERE id = $1;
+	`, userId)
+	assert.NoError(t, err)
+
+	events, err := repo.GetEvents(map[int64]int64{userId: 0, otherId: 0}, 100)
+	assert.NoError(t, err)
+
+	var types, otherTypes []string
+	for _, event := range events {
+		if event.UserID == userId {
+			types = append(types, event.Type)
+		} else {
+			otherTypes = append(otherTypes, event.Type)
+		}
+	}
+	assert.Equal(t, []string{dto.EventKeyCreated, dto.EventKeyUpdated, dto.EventShareUpdated, dto.EventKeyDeleted,
+		dto.EventSessionRevoked}, types)
+	assert.Equal(t, []string{dto.EventShareReceived, dto.EventShareRevoked}, otherTypes)
+
+	events, err = repo.GetEvents(map[int64]int64{userId: 4}, 100)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, int64(5), events[0].ID)
+		assert.Nil(t, events[0].Subject)
+	}
+
+	purge, err := New(ctx, db, config.EventsConfig{Retention: -time.Hour})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	purged, err := purge.DeleteOldEvents()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), purged)
+
+	seq, floor, err = repo.GetEventState(userId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), seq)
+	assert.Equal(t, int64(5), floor)
+}
@@ -0,0 +1,49 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	getEventState   statementsItem
+	getEvents       statementsItem
+	deleteOldEvents statementsItem
+}
+
+var statementsList = statements{
+	getEventState: statementsItem{
+		name: "getEventState",
+		query: `
+			SELECT event_seq, event_floor
+			FROM users
+			WHERE id = $1;`,
+	},
+	getEvents: statementsItem{
+		name: "getEvents",
+		query: `
+			SELECT e.user_id, e.seq, e.type, e.subject, e.created_at
+			FROM user_events e
+			JOIN unnest($1::bigint[], $2::bigint[]) AS c (user_id, seq) ON e.user_id = c.user_id AND e.seq > c.seq
+			ORDER BY e.user_id, e.seq
+			LIMIT $3;`,
+	},
+	deleteOldEvents: statementsItem{
+		name: "deleteOldEvents",
+		query: `
+			WITH purged AS (
+				DELETE FROM user_events
+				WHERE created_at < CURRENT_TIMESTAMP - make_interval(secs => $1)
+				RETURNING user_id, seq
+			), floors AS (
+				UPDATE users u
+				SET event_floor = GREATEST(u.event_floor, p.seq)
+				FROM (SELECT user_id, MAX(seq) AS seq FROM purged GROUP BY user_id) p
+				WHERE u.id = p.user_id
+			)
+			SELECT COUNT(*) FROM purged;`,
+	},
+}
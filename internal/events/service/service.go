@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/events/dto"
+	r "github.com/ObscuraNote/api-general/internal/events/repository"
+	u "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/ObscuraNote/api-general/internal/utils/eventbus"
+	"github.com/philippe-berto/logger"
+)
+
+var _ EventsService = (*Service)(nil)
+
+const (
+	// bufferSize is the number of events a subscription holds for a slow
+	// client. A client that falls further behind is disconnected and
+	// resumes from its last event.
+	bufferSize = 64
+	// pollBatch bounds the events read on each poll.
+	pollBatch = 1000
+)
+
+type (
+	// EventsService delivers the events of an account to its open streams.
+	// Events are read from the database, so a stream sees the changes made
+	// through every replica, and resumes from the ID of the last event it
+	// received. The event bus tells it when to read; the poll interval only
+	// bounds the delay of the notifications the bus loses.
+	EventsService interface {
+		Subscribe(auth dto.AuthInput, lastEventId int64) (*Subscription, error)
+		Unsubscribe(sub *Subscription)
+		Start(ctx context.Context)
+	}
+
+	Service struct {
+		ctx  context.Context
+		r    r.EventsRepository
+		us   u.UserService
+		log  *logger.Logger
+		cfg  config.EventsConfig
+		bus  eventbus.Bus
+		wake chan struct{}
+		mu   sync.Mutex
+		subs map[int64]map[*Subscription]struct{}
+	}
+
+	// Subscription is an open stream. Events is closed when the stream is
+	// dropped for falling behind or when the service stops.
+	Subscription struct {
+		userId int64
+		cursor int64
+		events chan dto.Event
+		closed bool
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.EventsRepository, us u.UserService, bus eventbus.Bus,
+	cfg config.EventsConfig) *Service {
+	return &Service{
+		ctx:  ctx,
+		log:  &log,
+		r:    repo,
+		us:   us,
+		cfg:  cfg,
+		bus:  bus,
+		wake: make(chan struct{}, 1),
+		subs: map[int64]map[*Subscription]struct{}{},
+	}
+}
+
+func (s *Subscription) Events() <-chan dto.Event {
+	return s.events
+}
+
+// Subscribe opens a stream of the caller's events after lastEventId; zero
+// starts from the next event. When the events after lastEventId are no
+// longer kept, the stream starts with a reset event. Streams are only counted
+// against MaxConnections on this replica.
+func (s *Service) Subscribe(auth dto.AuthInput, lastEventId int64) (*Subscription, error) {
+	if lastEventId < 0 {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	seq, floor, err := s.r.GetEventState(userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "events_service", "function": "Subscribe"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	sub := &Subscription{
+		userId: userId,
+		cursor: lastEventId,
+		events: make(chan dto.Event, bufferSize),
+	}
+	if lastEventId == 0 || lastEventId < floor || lastEventId > seq {
+		sub.cursor = seq
+	}
+	if lastEventId != 0 && sub.cursor != lastEventId {
+		sub.events <- dto.Event{ID: seq, Type: dto.EventReset}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.MaxConnections > 0 && len(s.subs[userId]) >= s.cfg.MaxConnections {
+		return nil, fmt.Errorf(utils.TooManyConnections)
+	}
+	if s.subs[userId] == nil {
+		s.subs[userId] = map[*Subscription]struct{}{}
+	}
+	s.subs[userId][sub] = struct{}{}
+
+	return sub, nil
+}
+
+// Unsubscribe closes a stream. It is safe to call on a stream the service
+// already dropped.
+func (s *Service) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.drop(sub)
+}
+
+// Start polls for new events and delivers them until ctx is cancelled, then
+// closes every stream. It polls when the bus announces a change of an account
+// with open streams, and on every tick in case an announcement was lost.
+func (s *Service) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	unsubscribe := s.bus.Subscribe(s.notify)
+	defer unsubscribe()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			for _, subs := range s.subs {
+				for sub := range subs {
+					s.drop(sub)
+				}
+			}
+			s.mu.Unlock()
+
+			return
+		case <-ticker.C:
+			err = s.poll()
+		case <-s.wake:
+			err = s.poll()
+		}
+		if err != nil {
+			s.log.WithFields(logger.Fields{"error": err.Error(), "component": "events_service", "function": "Start"}).
+				Error("Failed to poll events")
+		}
+	}
+}
+
+// notify wakes Start for the events of accounts with open streams. Wakes
+// coalesce: one poll reads every event committed before it.
+func (s *Service) notify(event eventbus.Event) {
+	if event.Type != eventbus.Resync {
+		s.mu.Lock()
+		_, ok := s.subs[event.UserID]
+		s.mu.Unlock()
+		if !ok {
+			return
+		}
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// poll reads the events after the oldest cursor of every account with open
+// streams and hands each stream the ones it has not seen.
+func (s *Service) poll() error {
+	s.mu.Lock()
+	cursors := make(map[int64]int64, len(s.subs))
+	for userId, subs := range s.subs {
+		for sub := range subs {
+			if cursor, ok := cursors[userId]; !ok || sub.cursor < cursor {
+				cursors[userId] = sub.cursor
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if len(cursors) == 0 {
+		return nil
+	}
+
+	events, err := s.r.GetEvents(cursors, pollBatch)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+		for sub := range s.subs[event.UserID] {
+			if event.ID <= sub.cursor {
+				continue
+			}
+
+			select {
+			case sub.events <- event:
+				sub.cursor = event.ID
+			default:
+				s.drop(sub)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "events_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
+
+// drop removes sub and closes its events. The caller holds s.mu.
+func (s *Service) drop(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.events)
+
+	delete(s.subs[sub.userId], sub)
+	if len(s.subs[sub.userId]) == 0 {
+		delete(s.subs, sub.userId)
+	}
+}
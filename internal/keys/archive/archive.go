@@ -0,0 +1,246 @@
+// Package archive defines the export format of an account's entries.
+//
+// An archive is a stream of JSON records, one per line: a Header, one Entry
+// record per entry and a closing Manifest. Every payload stays encrypted by
+// the client; the server only vouches for the integrity of the stream. The
+// manifest holds the SHA-256 digest of all the bytes before it and an
+// Ed25519 signature by the exporting server over:
+//
+//	"obscuranote-export-v1" || uint64be(entry_count) || digest
+//
+// Folders and other organisation live inside the encrypted entries and
+// travel with them.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"time"
+)
+
+const (
+	Format  = "obscuranote-export"
+	Version = 1
+
+	RecordHeader   = "header"
+	RecordEntry    = "entry"
+	RecordManifest = "manifest"
+
+	manifestContext = "obscuranote-export-v1"
+)
+
+// ErrInvalidArchive is returned when an archive is malformed, truncated or
+// does not match its manifest.
+var ErrInvalidArchive = errors.New("invalid archive")
+
+type (
+	// Record is one line of an archive. Exactly one of its fields matches
+	// Type.
+	Record struct {
+		Type     string    `json:"type"`
+		Header   *Header   `json:"header,omitempty"`
+		Entry    *Entry    `json:"entry,omitempty"`
+		Manifest *Manifest `json:"manifest,omitempty"`
+	}
+
+	Header struct {
+		Format      string    `json:"format"`
+		Version     int       `json:"version"`
+		UserAddress string    `json:"user_address"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+
+	// Entry is a stored entry with its metadata. ID is kept across servers
+	// so that importing the same archive twice is harmless.
+	Entry struct {
+		ID            string   `json:"id"`
+		EncryptedKey  []byte   `json:"encrypted_key"`
+		KeyIV         []byte   `json:"key_iv"`
+		EncryptedData []byte   `json:"encrypted_data"`
+		DataIV        []byte   `json:"data_iv"`
+		CreatedAt     string   `json:"created_at"`
+		ExpiresAt     *string  `json:"expires_at,omitempty"`
+		History       *History `json:"history,omitempty"`
+	}
+
+	// History holds the versions of an entry: its latest snapshot and the
+	// operations that were not compacted into it.
+	History struct {
+		Snapshot   *Snapshot   `json:"snapshot,omitempty"`
+		Operations []Operation `json:"operations"`
+	}
+
+	Snapshot struct {
+		Seq               int64  `json:"seq"`
+		EncryptedSnapshot []byte `json:"encrypted_snapshot"`
+		SnapshotIV        []byte `json:"snapshot_iv,omitempty"`
+		CreatedAt         string `json:"created_at"`
+	}
+
+	Operation struct {
+		Seq           int64  `json:"seq"`
+		AuthorAddress string `json:"author_address,omitempty"`
+		EncryptedOp   []byte `json:"encrypted_op"`
+		OpIV          []byte `json:"op_iv,omitempty"`
+		CreatedAt     string `json:"created_at"`
+	}
+
+	Manifest struct {
+		Entries   int64  `json:"entries"`
+		Digest    []byte `json:"digest"`
+		PublicKey []byte `json:"public_key"`
+		Signature []byte `json:"signature"`
+	}
+
+	// Archive is a read and verified archive.
+	Archive struct {
+		Header   Header
+		Entries  []Entry
+		Manifest Manifest
+	}
+
+	// Writer streams an archive. Entries are written as they come; Close
+	// writes the signed manifest.
+	Writer struct {
+		w       io.Writer
+		hash    hash.Hash
+		key     ed25519.PrivateKey
+		entries int64
+	}
+)
+
+func NewWriter(w io.Writer, key ed25519.PrivateKey, userAddress string) (*Writer, error) {
+	aw := &Writer{
+		w:    w,
+		hash: sha256.New(),
+		key:  key,
+	}
+
+	header := Header{
+		Format:      Format,
+		Version:     Version,
+		UserAddress: userAddress,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := aw.write(Record{Type: RecordHeader, Header: &header}); err != nil {
+		return nil, err
+	}
+
+	return aw, nil
+}
+
+func (w *Writer) WriteEntry(entry Entry) error {
+	if err := w.write(Record{Type: RecordEntry, Entry: &entry}); err != nil {
+		return err
+	}
+	w.entries++
+
+	return nil
+}
+
+// Close signs what was written and ends the archive. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	digest := w.hash.Sum(nil)
+	manifest := Manifest{
+		Entries:   w.entries,
+		Digest:    digest,
+		PublicKey: w.key.Public().(ed25519.PublicKey),
+		Signature: ed25519.Sign(w.key, manifestMessage(w.entries, digest)),
+	}
+
+	return w.write(Record{Type: RecordManifest, Manifest: &manifest})
+}
+
+func (w *Writer) write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if _, err := w.w.Write(line); err != nil {
+		return err
+	}
+	w.hash.Write(line)
+
+	return nil
+}
+
+// Read parses an archive and checks it against its manifest. The signature
+// is checked with the key the manifest names; callers that know the
+// exporting server compare Manifest.PublicKey with its key.
+func Read(r io.Reader) (*Archive, error) {
+	reader := bufio.NewReader(r)
+	digest := sha256.New()
+
+	var archive Archive
+	var header, manifest bool
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) != 0 {
+				return nil, ErrInvalidArchive
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if manifest {
+			return nil, ErrInvalidArchive
+		}
+
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, ErrInvalidArchive
+		}
+
+		switch {
+		case record.Type == RecordHeader && !header && record.Header != nil:
+			if record.Header.Format != Format || record.Header.Version != Version {
+				return nil, ErrInvalidArchive
+			}
+			archive.Header = *record.Header
+			header = true
+		case record.Type == RecordEntry && header && record.Entry != nil:
+			archive.Entries = append(archive.Entries, *record.Entry)
+		case record.Type == RecordManifest && header && record.Manifest != nil:
+			archive.Manifest = *record.Manifest
+			manifest = true
+			continue
+		default:
+			return nil, ErrInvalidArchive
+		}
+		digest.Write(line)
+	}
+
+	if !manifest || !verify(archive.Manifest, int64(len(archive.Entries)), digest.Sum(nil)) {
+		return nil, ErrInvalidArchive
+	}
+
+	return &archive, nil
+}
+
+func verify(manifest Manifest, entries int64, digest []byte) bool {
+	if manifest.Entries != entries || !bytes.Equal(manifest.Digest, digest) ||
+		len(manifest.PublicKey) != ed25519.PublicKeySize {
+		return false
+	}
+
+	return ed25519.Verify(manifest.PublicKey, manifestMessage(entries, digest), manifest.Signature)
+}
+
+func manifestMessage(entries int64, digest []byte) []byte {
+	message := []byte(manifestContext)
+	message = binary.BigEndian.AppendUint64(message, uint64(entries))
+
+	return append(message, digest...)
+}
@@ -0,0 +1,71 @@
+package archive
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func export(t *testing.T, key ed25519.PrivateKey, entries ...Entry) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key, "owner")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.NoError(t, w.WriteEntry(entry))
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	entry := Entry{
+		ID:            "9f1c2b3a-4e5f-4a6b-8c7d-9e0f1a2b3c4d",
+		EncryptedKey:  []byte("key"),
+		EncryptedData: []byte("data"),
+		History: &History{
+			Snapshot:   &Snapshot{Seq: 2, EncryptedSnapshot: []byte("state")},
+			Operations: []Operation{{Seq: 3, EncryptedOp: []byte("op")}},
+		},
+	}
+
+	archive, err := Read(bytes.NewReader(export(t, key, entry, Entry{ID: "second"})))
+	require.NoError(t, err)
+	assert.Equal(t, "owner", archive.Header.UserAddress)
+	assert.Equal(t, []Entry{entry, {ID: "second"}}, archive.Entries)
+	assert.Equal(t, int64(2), archive.Manifest.Entries)
+	assert.Equal(t, []byte(key.Public().(ed25519.PublicKey)), archive.Manifest.PublicKey)
+
+	archive, err = Read(bytes.NewReader(export(t, key)))
+	require.NoError(t, err)
+	assert.Empty(t, archive.Entries)
+}
+
+func TestReadRejectsTampering(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	data := export(t, key, Entry{ID: "a", EncryptedData: []byte("data")}, Entry{ID: "b"})
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	require.Len(t, lines, 5)
+
+	cases := map[string][]byte{
+		"empty":     nil,
+		"truncated": bytes.Join(lines[:3], nil),
+		"dropped":   bytes.Join([][]byte{lines[0], lines[2], lines[3]}, nil),
+		"edited":    bytes.Replace(data, []byte(`"id":"a"`), []byte(`"id":"c"`), 1),
+		"trailing":  append(append([]byte{}, data...), lines[1]...),
+		"garbage":   []byte("not json\n"),
+	}
+	for name, archive := range cases {
+		_, err := Read(bytes.NewReader(archive))
+		assert.ErrorIs(t, err, ErrInvalidArchive, name)
+	}
+}
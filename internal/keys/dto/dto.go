@@ -1,29 +1,213 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/keys/vclock"
+)
+
+const (
+	PermissionRead = "read"
+	PermissionEdit = "edit"
+
+	ShareStatusPending  = "pending"
+	ShareStatusAccepted = "accepted"
+	ShareStatusDeclined = "declined"
+
+	// Conflict strategies of an import, for entries that already exist.
+	ImportSkip      = "skip"
+	ImportOverwrite = "overwrite"
+	ImportDuplicate = "duplicate"
+
+	ImportOutcomeImported    = "imported"
+	ImportOutcomeOverwritten = "overwritten"
+	ImportOutcomeDuplicated  = "duplicated"
+	ImportOutcomeSkipped     = "skipped"
+)
+
 type (
 	AuthInput struct {
 		UserAddress string `json:"user_address" db:"user_address"`
 		Password    string `json:"password" db:"password"`
 	}
 	KeyImput struct {
-		UserAddress   string `json:"user_address" db:"user_address"`
-		Password      string `json:"password" db:"password"`
-		EncryptedKey  []byte `json:"encrypted_key" db:"encrypted_key"`
-		EncryptedData []byte `json:"encrypted_data" db:"encrypted_data"`
-		KeyIV         []byte `json:"key_iv" db:"key_iv"`
-		DataIV        []byte `json:"data_iv" db:"data_iv"`
+		UserAddress   string     `json:"user_address" db:"user_address"`
+		Password      string     `json:"password" db:"password"`
+		EncryptedKey  []byte     `json:"encrypted_key" db:"encrypted_key"`
+		EncryptedData []byte     `json:"encrypted_data" db:"encrypted_data"`
+		KeyIV         []byte     `json:"key_iv" db:"key_iv"`
+		DataIV        []byte     `json:"data_iv" db:"data_iv"`
+		ExpiresAt     *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+		// VaultID places a new entry in a shared vault. VaultKeyVersion is the
+		// vault key version the entry key was wrapped with.
+		VaultID         string `json:"vault_id,omitempty" db:"vault_id"`
+		VaultKeyVersion int    `json:"vault_key_version,omitempty" db:"vault_key_version"`
+		// UploadID takes EncryptedData from a completed resumable upload of
+		// the caller, which is discarded once the entry is stored.
+		UploadID string `json:"upload_id,omitempty" db:"-"`
+		// DeviceID names the writing device of a client that tracks versions.
+		// Version is the version the write is based on: a write whose base
+		// does not cover the stored version is kept as a conflict.
+		DeviceID string        `json:"device_id,omitempty" db:"-"`
+		Version  vclock.Vector `json:"version,omitempty" db:"-"`
 	}
 	KeyOutput struct {
-		ID            string `json:"id" db:"id"`
-		EncryptedKey  []byte `json:"encrypted_key" db:"encrypted_key"`
-		EncryptedData []byte `json:"encrypted_data" db:"encrypted_data"`
-		KeyIV         []byte `json:"key_iv" db:"key_iv"`
-		DataIV        []byte `json:"data_iv" db:"data_iv"`
-		CreatedAt     string `json:"created_at" db:"created_at"`
+		ID              string  `json:"id" db:"id"`
+		EncryptedKey    []byte  `json:"encrypted_key" db:"encrypted_key"`
+		EncryptedData   []byte  `json:"encrypted_data" db:"encrypted_data"`
+		KeyIV           []byte  `json:"key_iv" db:"key_iv"`
+		DataIV          []byte  `json:"data_iv" db:"data_iv"`
+		CreatedAt       string  `json:"created_at" db:"created_at"`
+		ExpiresAt       *string `json:"expires_at,omitempty" db:"expires_at"`
+		VaultID         *string `json:"vault_id,omitempty" db:"vault_id"`
+		VaultKeyVersion *int    `json:"vault_key_version,omitempty" db:"vault_key_version"`
+		// Version is the version vector of the stored entry and Conflicts
+		// the number of concurrent versions kept beside it. Conflict is set
+		// on the answer to a write that was kept as a conflict.
+		Version   vclock.Vector `json:"version,omitempty" db:"version_vector"`
+		Conflicts int           `json:"conflicts,omitempty" db:"conflicts"`
+		Conflict  bool          `json:"conflict,omitempty" db:"-"`
 	}
 	DeleteKeyInput struct {
 		ID          string `json:"id" db:"id"`
 		UserAddress string `json:"user_address" db:"user_address"`
 		Password    string `json:"password" db:"password"`
 	}
+	// ShareInput grants a recipient access to one entry. EncryptedKey is the
+	// entry key re-wrapped by the owner to the recipient's public key.
+	ShareInput struct {
+		UserAddress      string `json:"user_address" db:"user_address"`
+		Password         string `json:"password" db:"password"`
+		RecipientAddress string `json:"recipient_address" db:"recipient_address"`
+		EncryptedKey     []byte `json:"encrypted_key" db:"encrypted_key"`
+		KeyIV            []byte `json:"key_iv" db:"key_iv"`
+		Permission       string `json:"permission" db:"permission"`
+	}
+	ShareOutput struct {
+		ID               string `json:"id" db:"id"`
+		KeyID            string `json:"key_id" db:"key_id"`
+		RecipientAddress string `json:"recipient_address" db:"recipient_address"`
+		Permission       string `json:"permission" db:"permission"`
+		Status           string `json:"status" db:"status"`
+		CreatedAt        string `json:"created_at" db:"created_at"`
+	}
+	SharedKeyOutput struct {
+		ShareID       string  `json:"share_id" db:"share_id"`
+		KeyID         string  `json:"key_id" db:"key_id"`
+		OwnerAddress  string  `json:"owner_address" db:"owner_address"`
+		EncryptedKey  []byte  `json:"encrypted_key" db:"encrypted_key"`
+		KeyIV         []byte  `json:"key_iv" db:"key_iv"`
+		EncryptedData []byte  `json:"encrypted_data" db:"encrypted_data"`
+		DataIV        []byte  `json:"data_iv" db:"data_iv"`
+		Permission    string  `json:"permission" db:"permission"`
+		Status        string  `json:"status" db:"status"`
+		CreatedAt     string  `json:"created_at" db:"created_at"`
+		ExpiresAt     *string `json:"expires_at,omitempty" db:"expires_at"`
+	}
+	// ReleaseInput schedules a share that only reaches the recipient at
+	// ReleaseAt. EncryptedKey is wrapped to the recipient's public key, as
+	// for ShareInput.
+	ReleaseInput struct {
+		UserAddress      string    `json:"user_address" db:"user_address"`
+		Password         string    `json:"password" db:"password"`
+		RecipientAddress string    `json:"recipient_address" db:"recipient_address"`
+		EncryptedKey     []byte    `json:"encrypted_key" db:"encrypted_key"`
+		KeyIV            []byte    `json:"key_iv" db:"key_iv"`
+		Permission       string    `json:"permission" db:"permission"`
+		ReleaseAt        time.Time `json:"release_at" db:"release_at"`
+	}
+	RescheduleInput struct {
+		UserAddress string    `json:"user_address" db:"user_address"`
+		Password    string    `json:"password" db:"password"`
+		ReleaseAt   time.Time `json:"release_at" db:"release_at"`
+	}
+	ReleaseOutput struct {
+		ID               string `json:"id" db:"id"`
+		KeyID            string `json:"key_id" db:"key_id"`
+		RecipientAddress string `json:"recipient_address" db:"recipient_address"`
+		Permission       string `json:"permission" db:"permission"`
+		ReleaseAt        string `json:"release_at" db:"release_at"`
+		CreatedAt        string `json:"created_at" db:"created_at"`
+	}
+	// KeyAccess describes how a user reaches an entry: as its owner, through
+	// an accepted share, or through the vault it belongs to.
+	KeyAccess struct {
+		IsOwner    bool    `db:"is_owner"`
+		VaultID    *string `db:"vault_id"`
+		Permission *string `db:"permission"`
+	}
+
+	// ImportResult counts what an import did with the entries of an archive
+	// and names the server that signed it.
+	ImportResult struct {
+		Imported    int    `json:"imported"`
+		Overwritten int    `json:"overwritten"`
+		Duplicated  int    `json:"duplicated"`
+		Skipped     int    `json:"skipped"`
+		SignerKey   []byte `json:"signer_key"`
+	}
+
+	// BatchWriteInput creates and updates entries in one go. Items with an
+	// ID update that entry, the others create one; the credentials of the
+	// batch apply to every item. With Atomic, one failed item leaves every
+	// entry as it was.
+	BatchWriteInput struct {
+		UserAddress string           `json:"user_address"`
+		Password    string           `json:"password"`
+		Atomic      bool             `json:"atomic"`
+		Items       []BatchWriteItem `json:"items"`
+	}
+	BatchWriteItem struct {
+		ID string `json:"id,omitempty"`
+		KeyImput
+	}
+	// BatchIDsInput names the entries of a batch fetch or delete.
+	BatchIDsInput struct {
+		IDs    []string `json:"ids"`
+		Atomic bool     `json:"atomic"`
+	}
+	// BatchItemResult is the outcome of the item at Index: the entry it
+	// returned, or the code of the error that failed it.
+	BatchItemResult struct {
+		Index int        `json:"index"`
+		ID    string     `json:"id,omitempty"`
+		Key   *KeyOutput `json:"key,omitempty"`
+		Error string     `json:"error,omitempty"`
+	}
+	// BatchOutput holds a result per item, in order. Complete reports that
+	// every item succeeded; an atomic batch that is not complete changed
+	// nothing and returns no entries.
+	BatchOutput struct {
+		Complete bool              `json:"complete"`
+		Results  []BatchItemResult `json:"results"`
+	}
+
+	// SyncOutput is a page of the changes to the caller's personal entries
+	// after a cursor, oldest first. Changed holds the current state of the
+	// entries created or updated, Deleted the IDs of the ones deleted or
+	// expired. Cursor is passed as since to get the next page, or the next
+	// changes once HasMore is false.
+	SyncOutput struct {
+		Changed []KeyOutput `json:"changed"`
+		Deleted []string    `json:"deleted"`
+		Cursor  int64       `json:"cursor"`
+		HasMore bool        `json:"has_more"`
+	}
+
+	// ConflictOutput is a version of an entry written concurrently with the
+	// stored one. It is resolved by a write whose version covers it.
+	ConflictOutput struct {
+		ID            string        `json:"id" db:"id"`
+		DeviceID      string        `json:"device_id" db:"device_id"`
+		Version       vclock.Vector `json:"version" db:"version_vector"`
+		EncryptedKey  []byte        `json:"encrypted_key" db:"encrypted_key"`
+		KeyIV         []byte        `json:"key_iv" db:"key_iv"`
+		EncryptedData []byte        `json:"encrypted_data" db:"encrypted_data"`
+		DataIV        []byte        `json:"data_iv" db:"data_iv"`
+		CreatedAt     string        `json:"created_at" db:"created_at"`
+	}
+
+	ExportPublicKey struct {
+		PublicKey []byte `json:"public_key"`
+	}
 )
@@ -2,7 +2,7 @@ package http
 
 import (
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/ObscuraNote/api-general/internal/keys/dto"
 	kService "github.com/ObscuraNote/api-general/internal/keys/service"
@@ -13,21 +13,48 @@ import (
 )
 
 type handler struct {
-	log *logger.Logger
-	us  uService.UserService
-	ks  kService.KeysService
+	log           *logger.Logger
+	us            uService.UserService
+	ks            kService.KeysService
+	maxImportSize int64
 }
 
-func Register(router chi.Router, ks kService.KeysService, us uService.UserService, log logger.Logger) {
+func Register(router chi.Router, ks kService.KeysService, us uService.UserService, maxImportSize int64, log logger.Logger) {
 	h := &handler{
-		log: &log,
-		us:  us,
-		ks:  ks,
+		log:           &log,
+		us:            us,
+		ks:            ks,
+		maxImportSize: maxImportSize,
 	}
 
 	router.Post("/keys", h.AddKey)
 	router.Get("/keys", h.GetKeysByUser)
+	router.Delete("/keys", h.DeleteKeys)
+	router.Post("/keys/batch", h.WriteKeys)
+	router.Post("/keys/batch-get", h.GetKeysByIds)
+	router.Put("/keys/{id}", h.UpdateKey)
 	router.Delete("/keys/{id}", h.DeleteKey)
+	router.Get("/keys/{id}/conflicts", h.GetKeyConflicts)
+
+	router.Post("/keys/{id}/shares", h.ShareKey)
+	router.Get("/keys/{id}/shares", h.GetKeyShares)
+	router.Delete("/keys/{id}/shares/{shareId}", h.RevokeShare)
+	router.Post("/keys/{id}/releases", h.ScheduleRelease)
+	router.Get("/keys/{id}/releases", h.GetKeyReleases)
+	router.Put("/keys/{id}/releases/{releaseId}", h.RescheduleRelease)
+	router.Delete("/keys/{id}/releases/{releaseId}", h.CancelRelease)
+	router.Get("/keys/shared", h.GetSharedKeys)
+	router.Put("/keys/shared/{shareId}", h.UpdateSharedKey)
+	router.Post("/keys/shared/{shareId}/accept", h.AcceptShare)
+	router.Post("/keys/shared/{shareId}/decline", h.DeclineShare)
+
+	router.Get("/vaults/{id}/keys", h.GetVaultKeys)
+
+	router.Get("/sync", h.Sync)
+
+	router.Get("/export", h.Export)
+	router.Get("/export/key", h.GetExportKey)
+	router.Post("/import", h.Import)
 }
 
 func (h *handler) AddKey(w http.ResponseWriter, r *http.Request) {
@@ -47,11 +74,7 @@ func (h *handler) AddKey(w http.ResponseWriter, r *http.Request) {
 		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "AddKey"}).
 			Error("Failed to add key")
 
-		if err.Error() == utils.ErrUnauthorized {
-			_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
-		} else {
-			_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
-		}
+		writeError(w, err)
 		return
 	}
 
@@ -63,7 +86,7 @@ func (h *handler) AddKey(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) GetKeysByUser(w http.ResponseWriter, r *http.Request) {
-	userAddress, password := getCredentials(r)
+	userAddress, password := utils.GetCredentials(r)
 	if userAddress == "" || password == "" {
 		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
 		return
@@ -79,11 +102,7 @@ func (h *handler) GetKeysByUser(w http.ResponseWriter, r *http.Request) {
 		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetKeysByUser"}).
 			Error("Failed to get keys")
 
-		if err.Error() == utils.ErrUnauthorized {
-			_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
-		} else {
-			_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
-		}
+		writeError(w, err)
 		return
 	}
 
@@ -100,8 +119,42 @@ func (h *handler) GetKeysByUser(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *handler) UpdateKey(w http.ResponseWriter, r *http.Request) {
+	keyID := chi.URLParam(r, "id")
+	if keyID == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	var input dto.KeyImput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	updatedKey, err := h.ks.UpdateKey(keyID, input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "UpdateKey"}).
+			Error("Failed to update key")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, updatedKey); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "UpdateKey"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
 func (h *handler) DeleteKey(w http.ResponseWriter, r *http.Request) {
-	userAddress, password := getCredentials(r)
+	userAddress, password := utils.GetCredentials(r)
 	keyID := chi.URLParam(r, "id")
 	if keyID == "" {
 		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
@@ -122,27 +175,597 @@ func (h *handler) DeleteKey(w http.ResponseWriter, r *http.Request) {
 		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "DeleteKey"}).
 			Error("Failed to delete key")
 
-		if err.Error() == utils.ErrUnauthorized {
-			_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
-		} else {
-			_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
-		}
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) ShareKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var input dto.ShareInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	share, err := h.ks.ShareKey(keyID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "ShareKey"}).
+			Error("Failed to share key")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, share); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "ShareKey"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetKeyShares(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	shares, err := h.ks.GetKeyShares(keyID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetKeyShares"}).
+			Error("Failed to get key shares")
+
+		writeError(w, err)
+		return
+	}
+
+	if shares == nil {
+		shares = []dto.ShareOutput{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, shares); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetKeyShares"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetKeyConflicts(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	conflicts, err := h.ks.GetConflicts(keyID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetKeyConflicts"}).
+			Error("Failed to get key conflicts")
+
+		writeError(w, err)
+		return
+	}
+
+	if conflicts == nil {
+		conflicts = []dto.ConflictOutput{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, conflicts); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetKeyConflicts"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) RevokeShare(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	shareID, err := utils.ParseParamUUID(w, r, "shareId")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ks.RevokeShare(keyID.String(), shareID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "RevokeShare"}).
+			Error("Failed to revoke share")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) ScheduleRelease(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var input dto.ReleaseInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	release, err := h.ks.ScheduleRelease(keyID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "ScheduleRelease"}).
+			Error("Failed to schedule release")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, release); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "ScheduleRelease"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetKeyReleases(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	releases, err := h.ks.GetKeyReleases(keyID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetKeyReleases"}).
+			Error("Failed to get key releases")
+
+		writeError(w, err)
+		return
+	}
+
+	if releases == nil {
+		releases = []dto.ReleaseOutput{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, releases); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetKeyReleases"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) RescheduleRelease(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	releaseID, err := utils.ParseParamUUID(w, r, "releaseId")
+	if err != nil {
+		return
+	}
+
+	var input dto.RescheduleInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	release, err := h.ks.RescheduleRelease(keyID.String(), releaseID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "RescheduleRelease"}).
+			Error("Failed to reschedule release")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, release); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "RescheduleRelease"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) CancelRelease(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	releaseID, err := utils.ParseParamUUID(w, r, "releaseId")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ks.CancelRelease(keyID.String(), releaseID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "CancelRelease"}).
+			Error("Failed to cancel release")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetSharedKeys(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.ks.GetSharedKeys(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetSharedKeys"}).
+			Error("Failed to get shared keys")
+
+		writeError(w, err)
+		return
+	}
+
+	if keys == nil {
+		keys = []dto.SharedKeyOutput{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, keys); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetSharedKeys"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) UpdateSharedKey(w http.ResponseWriter, r *http.Request) {
+	shareID, err := utils.ParseParamUUID(w, r, "shareId")
+	if err != nil {
+		return
+	}
+
+	var input dto.KeyImput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	updatedKey, err := h.ks.UpdateSharedKey(shareID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "UpdateSharedKey"}).
+			Error("Failed to update shared key")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, updatedKey); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "UpdateSharedKey"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) AcceptShare(w http.ResponseWriter, r *http.Request) {
+	h.respondToShare(w, r, true)
+}
+
+func (h *handler) DeclineShare(w http.ResponseWriter, r *http.Request) {
+	h.respondToShare(w, r, false)
+}
+
+func (h *handler) respondToShare(w http.ResponseWriter, r *http.Request, accept bool) {
+	shareID, err := utils.ParseParamUUID(w, r, "shareId")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ks.RespondToShare(shareID.String(), accept, auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "RespondToShare"}).
+			Error("Failed to respond to share")
+
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func getCredentials(r *http.Request) (string, string) {
-	authHeader := r.Header.Get("Authorization")
-	if strings.HasPrefix(authHeader, "Bearer ") {
-		credentials := strings.TrimPrefix(authHeader, "Bearer ")
-		parts := strings.Split(credentials, ":")
-		userAddress := parts[0]
-		password := parts[1]
+// writeError maps a service error code to its HTTP response.
+func (h *handler) GetVaultKeys(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
 
-		return userAddress, password
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
 	}
 
-	return "", ""
+	keys, err := h.ks.GetVaultKeys(vaultID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetVaultKeys"}).
+			Error("Failed to get vault keys")
+
+		writeError(w, err)
+		return
+	}
+
+	if keys == nil {
+		keys = []dto.KeyOutput{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, keys); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetVaultKeys"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.Forbidden:
+		_ = utils.Fault(w, http.StatusForbidden, utils.Forbidden)
+	case utils.NotEntitled, utils.UntrustedSigner:
+		_ = utils.Fault(w, http.StatusForbidden, err.Error())
+	case utils.BadRequest, utils.InvalidExpiration, utils.InvalidReleaseAt, utils.InvalidArchive:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.KeyNotFound, utils.ShareNotFound, utils.UserNotFound, utils.VaultNotFound, utils.ReleaseNotFound,
+		utils.UploadNotFound:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	case utils.UploadExpired, utils.CursorExpired:
+		_ = utils.Fault(w, http.StatusGone, err.Error())
+	case utils.UploadPending, utils.QuotaExceeded, utils.VersionConflict:
+		_ = utils.Fault(w, http.StatusConflict, err.Error())
+	case utils.PayloadTooLarge:
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+// WriteKeys answers 200 OK with a result per item, whether or not every
+// item succeeded.
+func (h *handler) WriteKeys(w http.ResponseWriter, r *http.Request) {
+	var input dto.BatchWriteInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	output, err := h.ks.WriteKeys(input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "WriteKeys"}).
+			Error("Failed to write keys")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, output); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "WriteKeys"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetKeysByIds(w http.ResponseWriter, r *http.Request) {
+	h.batchIDs(w, r, "GetKeysByIds", h.ks.GetKeysByIds)
+}
+
+func (h *handler) DeleteKeys(w http.ResponseWriter, r *http.Request) {
+	h.batchIDs(w, r, "DeleteKeys", h.ks.DeleteKeys)
+}
+
+// batchIDs serves the batch endpoints that take a list of entry IDs as the
+// body and the credentials in the Authorization header.
+func (h *handler) batchIDs(w http.ResponseWriter, r *http.Request, function string,
+	run func(auth dto.AuthInput, input dto.BatchIDsInput) (*dto.BatchOutput, error)) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.BatchIDsInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	output, err := run(auth, input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": function}).
+			Error("Failed to run batch")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, output); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": function}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) Sync(w http.ResponseWriter, r *http.Request) {
+	since, err := utils.ParseInt64Query(w, r, "since", 0)
+	if err != nil {
+		return
+	}
+
+	limit, err := utils.ParseInt64Query(w, r, "limit", 0)
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	output, err := h.ks.Sync(auth, since, limit)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "Sync"}).
+			Error("Failed to sync keys")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, output); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "Sync"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+// Export streams the caller's archive. Errors found before the first byte
+// get the usual error response; later ones can only cut the archive short,
+// which its reader detects by the missing manifest.
+func (h *handler) Export(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	body := &exportWriter{w: w}
+	if err := h.ks.ExportKeys(auth, body); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "Export"}).
+			Error("Failed to export keys")
+
+		if !body.started {
+			writeError(w, err)
+		}
+		return
+	}
+}
+
+func (h *handler) GetExportKey(w http.ResponseWriter, r *http.Request) {
+	if err := utils.WriteBody(w, http.StatusOK, h.ks.ExportPublicKey()); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "GetExportKey"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+// Import takes an archive as the request body and the conflict strategy
+// as the strategy query parameter.
+func (h *handler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.ContentLength > h.maxImportSize {
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, utils.PayloadTooLarge)
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, h.maxImportSize)
+	result, err := h.ks.ImportKeys(auth, r.URL.Query().Get("strategy"), body)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "Import"}).
+			Error("Failed to import keys")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, result); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "keys", "function": "Import"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+// exportWriter sends the response headers of an archive with its first
+// bytes.
+type exportWriter struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.w.Header().Set("Content-Type", "application/x-ndjson")
+		e.w.Header().Set("Content-Disposition",
+			`attachment; filename="obscuranote-`+time.Now().UTC().Format("20060102")+`.ndjson"`)
+		e.w.WriteHeader(http.StatusOK)
+	}
+
+	return e.w.Write(p)
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
 }
@@ -1,33 +1,151 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
 	"log"
+	"sort"
+	"time"
 
+	"github.com/ObscuraNote/api-general/internal/keys/archive"
 	"github.com/ObscuraNote/api-general/internal/keys/dto"
+	"github.com/ObscuraNote/api-general/internal/keys/vclock"
+	"github.com/ObscuraNote/api-general/internal/utils/blobstore"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/ObscuraNote/api-general/internal/utils/lock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
 )
 
 var _ KeysRepository = (*Repository)(nil)
 
+var (
+	// ErrChecksumMismatch is returned when an offloaded payload read back
+	// from the blob store does not match the size and checksum recorded for
+	// it.
+	ErrChecksumMismatch = errors.New("payload checksum mismatch")
+	// ErrQuotaExceeded is returned when a write would take the owner of the
+	// entry past one of their storage limits.
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+	// ErrCursorExpired is returned when the changes after a sync cursor can
+	// no longer be told apart, because the tombstones it needs were purged,
+	// or when the cursor was never handed out.
+	ErrCursorExpired = errors.New("sync cursor expired")
+	// ErrTooManyConflicts is returned when a concurrent write would keep more
+	// conflict siblings beside an entry than allowed; the client has to
+	// resolve the existing ones first.
+	ErrTooManyConflicts = errors.New("too many conflicting versions")
+
+	// errBatchFailed rolls back an atomic batch once one of its items failed.
+	errBatchFailed = errors.New("batch item failed")
+)
+
+const (
+	blobIdSize = 16
+	// maxConflicts bounds the conflict siblings kept beside an entry.
+	maxConflicts = 8
+)
+
 type (
 	KeysRepository interface {
-		AddKey(userId int64, note dto.KeyImput) (*dto.KeyOutput, error)
+		AddKey(userId int64, note dto.KeyImput, limits QuotaFunc) (*dto.KeyOutput, error)
 		GetKeysByUser(userId int64) ([]dto.KeyOutput, error)
-		DeleteKey(id string) error
+		UpdateKey(userId int64, id string, note dto.KeyImput, limits QuotaFunc) (*dto.KeyOutput, error)
+		DeleteKey(userId int64, id string) (bool, error)
+		DeleteExpiredKeys() (int64, error)
+		UpdateKeyData(id, shareId string, recipientId int64, encryptedData, dataIV []byte, limits QuotaFunc) (bool, error)
+		CreateShare(ownerId, recipientId int64, keyId string, share dto.ShareInput) (*dto.ShareOutput, error)
+		GetSharesByKey(ownerId int64, keyId string) ([]dto.ShareOutput, error)
+		DeleteShare(ownerId int64, keyId, shareId string) (int64, error)
+		GetSharedKeys(recipientId int64) ([]dto.SharedKeyOutput, error)
+		GetSharedKey(recipientId int64, shareId string) (*dto.SharedKeyOutput, error)
+		UpdateShareStatus(recipientId int64, shareId, status string) (int64, error)
+		GetKeyVault(id string) (string, error)
+		GetKeysByVault(vaultId string) ([]dto.KeyOutput, error)
+		UpdateVaultKey(vaultId, id string, note dto.KeyImput, limits QuotaFunc) (*dto.KeyOutput, error)
+		DeleteVaultKey(vaultId, id string) (bool, error)
+		GetKeyAccess(userId int64, id string) (*dto.KeyAccess, error)
+		CreateRelease(ownerId, recipientId int64, keyId string, release dto.ReleaseInput) (*dto.ReleaseOutput, error)
+		GetReleasesByKey(ownerId int64, keyId string) ([]dto.ReleaseOutput, error)
+		UpdateRelease(ownerId int64, keyId, releaseId string, releaseAt time.Time) (*dto.ReleaseOutput, error)
+		DeleteRelease(ownerId int64, keyId, releaseId string) (bool, error)
+		ReleaseDueKeys() ([]ReleasedShare, error)
+		MigratePayloads() (int64, error)
+		ExportKeys(userId int64, emit func(entry archive.Entry) error) error
+		ImportKey(userId int64, userAddress string, entry archive.Entry, duplicateId, strategy string, limits QuotaFunc) (string, error)
+		WriteKeys(userId int64, writes []KeyWrite, atomic bool, limits QuotaFunc) ([]*dto.KeyOutput, []error, error)
+		GetKeysByIds(userId int64, ids []string) ([]dto.KeyOutput, error)
+		DeleteKeys(userId int64, refs []KeyRef, atomic bool) ([]error, error)
+		GetChanges(userId, since int64, limit int) (*dto.SyncOutput, error)
+		DeleteOldTombstones() (int64, error)
+		GetConflicts(keyId string) ([]dto.ConflictOutput, error)
+	}
+	// KeyWrite is one write of a batch. An empty ID creates an entry; any
+	// other updates that entry, as a vault entry when VaultID is set.
+	KeyWrite struct {
+		ID      string
+		VaultID string
+		Note    dto.KeyImput
+	}
+	// KeyRef names an entry of a batch delete and the vault it belongs to,
+	// if any.
+	KeyRef struct {
+		ID      string
+		VaultID string
+	}
+	// ReleasedShare is a pending share created from a due release.
+	ReleasedShare struct {
+		ShareID     string
+		RecipientID int64
 	}
+	// QuotaFunc returns the storage limits of ownerId. Writes call it once
+	// the owner of the entry is known and their row is locked.
+	QuotaFunc func(ownerId int64) (config.QuotaConfig, error)
+	// Repository keeps encrypted payloads larger than the tiering threshold
+	// in the blob store, with only a reference and checksum in Postgres.
+	// Reads hydrate them transparently.
 	Repository struct {
 		ctx        context.Context
 		db         *postgresdb.Client
 		statements statements
+		store      blobstore.BlobStore
+		cfg        config.TieringConfig
+		sync       config.SyncConfig
+	}
+	// update is how a write to an existing entry lands. Version is the
+	// vector of the write and Conflicts the number of siblings the entry
+	// keeps after it. A Concurrent write is kept as a sibling.
+	update struct {
+		ownerId    int64
+		version    vclock.Vector
+		conflicts  int
+		concurrent bool
+	}
+	// payload locates an offloaded payload. A nil ref means the payload is
+	// stored inline.
+	payload struct {
+		ref      *string
+		size     *int64
+		checksum []byte
 	}
 )
 
-func New(ctx context.Context, db *postgresdb.Client) *Repository {
+func New(ctx context.Context, db *postgresdb.Client, store blobstore.BlobStore, cfg config.TieringConfig, sync config.SyncConfig) *Repository {
 	r := &Repository{
 		ctx:        ctx,
 		db:         db,
 		statements: statements{},
+		store:      store,
+		cfg:        cfg,
+		sync:       sync,
 	}
 	statements, err := r.prepareStatements()
 	if err != nil {
@@ -39,18 +157,30 @@ func New(ctx context.Context, db *postgresdb.Client) *Repository {
 	return r
 }
 
-func (r *Repository) AddKey(userId int64, note dto.KeyImput) (*dto.KeyOutput, error) {
-	var result dto.KeyOutput
-	err := r.statements.addKey.statement.
-		QueryRowContext(r.ctx, userId, note.UserAddress, note.EncryptedKey, note.KeyIV, note.EncryptedData, note.DataIV).
-		Scan(&result.ID, &result.EncryptedKey, &result.KeyIV, &result.EncryptedData, &result.DataIV, &result.CreatedAt)
+// AddKey stores a new entry. The user's row is locked while their usage is
+// checked, so concurrent writes cannot take them over quota together. It
+// returns ErrQuotaExceeded when the entry does not fit.
+func (r *Repository) AddKey(userId int64, note dto.KeyImput, limits QuotaFunc) (*dto.KeyOutput, error) {
+	data, stored, err := r.storeData(note.EncryptedData)
 	if err != nil {
-		log.Println("Error adding note")
+		log.Println("Error offloading note data")
 
 		return nil, err
 	}
 
-	return &result, nil
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		return r.addKey(ctx, tx, userId, note, data, stored, limits)
+	}))
+	if err != nil {
+		r.discardData(stored)
+		if !errors.Is(err, ErrQuotaExceeded) {
+			log.Println("Error adding note")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.KeyOutput), nil
 }
 
 func (r *Repository) GetKeysByUser(userId int64) ([]dto.KeyOutput, error) {
@@ -64,48 +194,1799 @@ func (r *Repository) GetKeysByUser(userId int64) ([]dto.KeyOutput, error) {
 	defer rows.Close()
 
 	var notes []dto.KeyOutput
+	var payloads []payload
 	for rows.Next() {
 		var note dto.KeyOutput
-		if err := rows.Scan(&note.ID, &note.EncryptedKey, &note.KeyIV, &note.EncryptedData, &note.DataIV, &note.CreatedAt); err != nil {
+		var p payload
+		if err := rows.Scan(&note.ID, &note.EncryptedKey, &note.KeyIV, &note.EncryptedData, &note.DataIV, &note.CreatedAt, &note.ExpiresAt,
+			&note.VaultID, &note.VaultKeyVersion, &note.Version, &note.Conflicts, &p.ref, &p.size, &p.checksum); err != nil {
 			log.Println("Error scanning note")
 
 			return nil, err
 		}
 		notes = append(notes, note)
+		payloads = append(payloads, p)
+	}
+
+	for i := range notes {
+		if err := r.hydrate(&notes[i].EncryptedData, payloads[i]); err != nil {
+			log.Println("Error loading note data")
+
+			return nil, err
+		}
 	}
 
 	return notes, nil
 }
 
-func (r *Repository) DeleteKey(id string) error {
-	_, err := r.statements.deleteKey.statement.
-		ExecContext(r.ctx, id)
+func (r *Repository) UpdateKey(userId int64, id string, note dto.KeyImput, limits QuotaFunc) (*dto.KeyOutput, error) {
+	data, stored, err := r.storeData(note.EncryptedData)
+	if err != nil {
+		log.Println("Error offloading note data")
+
+		return nil, err
+	}
+
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		return r.updateKey(ctx, tx, userId, id, note, data, stored, limits)
+	}))
+	if err != nil {
+		r.discardData(stored)
+		if !errors.Is(err, ErrQuotaExceeded) && !errors.Is(err, ErrTooManyConflicts) {
+			log.Println("Error updating note")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.KeyOutput), nil
+}
+
+func (r *Repository) DeleteKey(userId int64, id string) (bool, error) {
+	result, err := r.statements.deleteKey.statement.
+		ExecContext(r.ctx, id, userId)
 	if err != nil {
 		log.Println("Error deleting note")
 
-		return err
+		return false, err
 	}
 
-	return nil
+	return rowsAffected(result)
 }
 
-func (r *Repository) prepareStatements() (statements, error) {
-	var err error
+// DeleteExpiredKeys removes every key past its expiration date. When another
+// replica is already reaping, it does nothing and returns zero.
+func (r *Repository) DeleteExpiredKeys() (int64, error) {
+	deleted, err := lock.TryExclusive(r.ctx, r.db, lock.KeysReaper, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		res, err := tx.StmtxContext(ctx, r.statements.deleteExpiredKeys.statement).ExecContext(ctx)
+		if err != nil {
+			return 0, err
+		}
 
-	statementsList.addKey.statement, err = r.db.PrepareStatement(statementsList.addKey.query)
+		return res.RowsAffected()
+	})
 	if err != nil {
-		return statements{}, err
+		log.Println("Error deleting expired notes")
+
+		return 0, err
 	}
 
-	statementsList.getKeysByUser.statement, err = r.db.PrepareStatement(statementsList.getKeysByUser.query)
+	return deleted, nil
+}
+
+// UpdateKeyData replaces the payload of an entry on behalf of the recipient
+// of shareId. The share is checked by the update itself, so an edit racing a
+// revocation or a downgrade of the share changes nothing and reports false.
+func (r *Repository) UpdateKeyData(id, shareId string, recipientId int64, encryptedData, dataIV []byte, limits QuotaFunc) (bool, error) {
+	data, stored, err := r.storeData(encryptedData)
 	if err != nil {
-		return statements{}, err
+		log.Println("Error offloading note data")
+
+		return false, err
 	}
 
-	statementsList.deleteKey.statement, err = r.db.PrepareStatement(statementsList.deleteKey.query)
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		if _, err := r.checkUpdateQuota(ctx, tx, id, len(encryptedData), limits); err != nil {
+			return false, err
+		}
+
+		result, err := tx.StmtxContext(ctx, r.statements.updateKeyData.statement).
+			ExecContext(ctx, id, data, dataIV, stored.ref, stored.size, stored.checksum, shareId, recipientId)
+		if err != nil {
+			return false, err
+		}
+
+		return rowsAffected(result)
+	}))
+	if errors.Is(err, sql.ErrNoRows) {
+		r.discardData(stored)
+
+		return false, nil
+	}
 	if err != nil {
-		return statements{}, err
+		r.discardData(stored)
+		if !errors.Is(err, ErrQuotaExceeded) {
+			log.Println("Error updating note data")
+		}
+
+		return false, err
 	}
 
-	return statementsList, nil
+	updated := result.(bool)
+	if !updated {
+		r.discardData(stored)
+	}
+
+	return updated, nil
+}
+
+func (r *Repository) CreateShare(ownerId, recipientId int64, keyId string, share dto.ShareInput) (*dto.ShareOutput, error) {
+	result := dto.ShareOutput{RecipientAddress: share.RecipientAddress}
+	err := r.statements.createShare.statement.
+		QueryRowContext(r.ctx, keyId, ownerId, recipientId, share.EncryptedKey, share.KeyIV, share.Permission).
+		Scan(&result.ID, &result.KeyID, &result.Permission, &result.Status, &result.CreatedAt)
+	if err != nil {
+		log.Println("Error creating share")
+
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *Repository) GetSharesByKey(ownerId int64, keyId string) ([]dto.ShareOutput, error) {
+	rows, err := r.statements.getSharesByKey.statement.
+		QueryContext(r.ctx, keyId, ownerId)
+	if err != nil {
+		log.Println("Error getting shares by note")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shares []dto.ShareOutput
+	for rows.Next() {
+		var share dto.ShareOutput
+		if err := rows.Scan(&share.ID, &share.KeyID, &share.RecipientAddress, &share.Permission, &share.Status, &share.CreatedAt); err != nil {
+			log.Println("Error scanning share")
+
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, nil
+}
+
+// DeleteShare revokes a share of ownerId and returns its recipient, or
+// sql.ErrNoRows when there is no such share.
+func (r *Repository) DeleteShare(ownerId int64, keyId, shareId string) (int64, error) {
+	var recipientId int64
+	err := r.statements.deleteShare.statement.
+		QueryRowContext(r.ctx, shareId, keyId, ownerId).
+		Scan(&recipientId)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("Error deleting share")
+		}
+
+		return 0, err
+	}
+
+	return recipientId, nil
+}
+
+func (r *Repository) GetSharedKeys(recipientId int64) ([]dto.SharedKeyOutput, error) {
+	rows, err := r.statements.getSharedKeys.statement.
+		QueryContext(r.ctx, recipientId)
+	if err != nil {
+		log.Println("Error getting shared notes")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []dto.SharedKeyOutput
+	var payloads []payload
+	for rows.Next() {
+		var key dto.SharedKeyOutput
+		var p payload
+		if err := rows.Scan(&key.ShareID, &key.KeyID, &key.OwnerAddress, &key.EncryptedKey, &key.KeyIV, &key.EncryptedData,
+			&key.DataIV, &key.Permission, &key.Status, &key.CreatedAt, &key.ExpiresAt, &p.ref, &p.size, &p.checksum); err != nil {
+			log.Println("Error scanning shared note")
+
+			return nil, err
+		}
+		keys = append(keys, key)
+		payloads = append(payloads, p)
+	}
+
+	for i := range keys {
+		if err := r.hydrate(&keys[i].EncryptedData, payloads[i]); err != nil {
+			log.Println("Error loading shared note data")
+
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+func (r *Repository) GetSharedKey(recipientId int64, shareId string) (*dto.SharedKeyOutput, error) {
+	var key dto.SharedKeyOutput
+	var p payload
+	err := r.statements.getSharedKey.statement.
+		QueryRowContext(r.ctx, shareId, recipientId).
+		Scan(&key.ShareID, &key.KeyID, &key.OwnerAddress, &key.EncryptedKey, &key.KeyIV, &key.EncryptedData,
+			&key.DataIV, &key.Permission, &key.Status, &key.CreatedAt, &key.ExpiresAt, &p.ref, &p.size, &p.checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.hydrate(&key.EncryptedData, p); err != nil {
+		log.Println("Error loading shared note data")
+
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// UpdateShareStatus records the answer of recipientId to a share and returns
+// the owner of the entry, or sql.ErrNoRows when there is no such share.
+func (r *Repository) UpdateShareStatus(recipientId int64, shareId, status string) (int64, error) {
+	var ownerId int64
+	err := r.statements.updateShareStatus.statement.
+		QueryRowContext(r.ctx, shareId, recipientId, status).
+		Scan(&ownerId)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("Error updating share status")
+		}
+
+		return 0, err
+	}
+
+	return ownerId, nil
+}
+
+// GetKeyVault returns the vault an entry belongs to, or sql.ErrNoRows for
+// personal entries.
+func (r *Repository) GetKeyVault(id string) (string, error) {
+	var vaultId string
+	err := r.statements.getKeyVault.statement.
+		QueryRowContext(r.ctx, id).
+		Scan(&vaultId)
+	if err != nil {
+		return "", err
+	}
+
+	return vaultId, nil
+}
+
+func (r *Repository) GetKeysByVault(vaultId string) ([]dto.KeyOutput, error) {
+	rows, err := r.statements.getKeysByVault.statement.
+		QueryContext(r.ctx, vaultId)
+	if err != nil {
+		log.Println("Error getting notes by vault")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []dto.KeyOutput
+	var payloads []payload
+	for rows.Next() {
+		var note dto.KeyOutput
+		var p payload
+		if err := rows.Scan(&note.ID, &note.EncryptedKey, &note.KeyIV, &note.EncryptedData, &note.DataIV, &note.CreatedAt, &note.ExpiresAt,
+			&note.VaultID, &note.VaultKeyVersion, &note.Version, &note.Conflicts, &p.ref, &p.size, &p.checksum); err != nil {
+			log.Println("Error scanning note")
+
+			return nil, err
+		}
+		notes = append(notes, note)
+		payloads = append(payloads, p)
+	}
+
+	for i := range notes {
+		if err := r.hydrate(&notes[i].EncryptedData, payloads[i]); err != nil {
+			log.Println("Error loading note data")
+
+			return nil, err
+		}
+	}
+
+	return notes, nil
+}
+
+// UpdateVaultKey replaces a vault entry. Its size counts against the member
+// who created it.
+func (r *Repository) UpdateVaultKey(vaultId, id string, note dto.KeyImput, limits QuotaFunc) (*dto.KeyOutput, error) {
+	data, stored, err := r.storeData(note.EncryptedData)
+	if err != nil {
+		log.Println("Error offloading vault note data")
+
+		return nil, err
+	}
+
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		return r.updateVaultKey(ctx, tx, vaultId, id, note, data, stored, limits)
+	}))
+	if err != nil {
+		r.discardData(stored)
+		if !errors.Is(err, ErrQuotaExceeded) && !errors.Is(err, ErrTooManyConflicts) {
+			log.Println("Error updating vault note")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.KeyOutput), nil
+}
+
+func (r *Repository) DeleteVaultKey(vaultId, id string) (bool, error) {
+	result, err := r.statements.deleteVaultKey.statement.
+		ExecContext(r.ctx, id, vaultId)
+	if err != nil {
+		log.Println("Error deleting vault note")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+func (r *Repository) GetKeyAccess(userId int64, id string) (*dto.KeyAccess, error) {
+	var access dto.KeyAccess
+	err := r.statements.getKeyAccess.statement.
+		QueryRowContext(r.ctx, id, userId).
+		Scan(&access.IsOwner, &access.VaultID, &access.Permission)
+	if err != nil {
+		return nil, err
+	}
+
+	return &access, nil
+}
+
+// CreateRelease schedules a share of one of the owner's entries for
+// releaseAt. Scheduling the same entry for the same recipient again replaces
+// the pending release. It returns sql.ErrNoRows when the owner has no such
+// entry.
+func (r *Repository) CreateRelease(ownerId, recipientId int64, keyId string, release dto.ReleaseInput) (*dto.ReleaseOutput, error) {
+	result := dto.ReleaseOutput{RecipientAddress: release.RecipientAddress}
+	err := r.statements.createRelease.statement.
+		QueryRowContext(r.ctx, keyId, ownerId, recipientId, release.EncryptedKey, release.KeyIV, release.Permission, release.ReleaseAt).
+		Scan(&result.ID, &result.KeyID, &result.Permission, &result.ReleaseAt, &result.CreatedAt)
+	if err != nil {
+		log.Println("Error creating release")
+
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *Repository) GetReleasesByKey(ownerId int64, keyId string) ([]dto.ReleaseOutput, error) {
+	rows, err := r.statements.getReleasesByKey.statement.
+		QueryContext(r.ctx, keyId, ownerId)
+	if err != nil {
+		log.Println("Error getting releases by note")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var releases []dto.ReleaseOutput
+	for rows.Next() {
+		var release dto.ReleaseOutput
+		if err := rows.Scan(&release.ID, &release.KeyID, &release.RecipientAddress, &release.Permission,
+			&release.ReleaseAt, &release.CreatedAt); err != nil {
+			log.Println("Error scanning release")
+
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+
+	return releases, nil
+}
+
+// UpdateRelease moves a pending release to releaseAt. It returns sql.ErrNoRows
+// when the release does not exist or is already due.
+func (r *Repository) UpdateRelease(ownerId int64, keyId, releaseId string, releaseAt time.Time) (*dto.ReleaseOutput, error) {
+	var result dto.ReleaseOutput
+	err := r.statements.updateRelease.statement.
+		QueryRowContext(r.ctx, releaseId, keyId, ownerId, releaseAt).
+		Scan(&result.ID, &result.KeyID, &result.RecipientAddress, &result.Permission, &result.ReleaseAt, &result.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// DeleteRelease cancels a release that is not due yet.
+func (r *Repository) DeleteRelease(ownerId int64, keyId, releaseId string) (bool, error) {
+	result, err := r.statements.deleteRelease.statement.
+		ExecContext(r.ctx, releaseId, keyId, ownerId)
+	if err != nil {
+		log.Println("Error deleting release")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+// ReleaseDueKeys turns every due release into a pending share for its
+// recipient and returns the shares it created. When another replica is
+// already releasing, it does nothing.
+func (r *Repository) ReleaseDueKeys() ([]ReleasedShare, error) {
+	var released []ReleasedShare
+	_, err := lock.TryExclusive(r.ctx, r.db, lock.KeyReleases, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		rows, err := tx.StmtxContext(ctx, r.statements.releaseDueKeys.statement).QueryContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var share ReleasedShare
+			if err := rows.Scan(&share.ShareID, &share.RecipientID); err != nil {
+				return 0, err
+			}
+			released = append(released, share)
+		}
+
+		return int64(len(released)), rows.Err()
+	})
+	if err != nil {
+		log.Println("Error releasing due notes")
+		return nil, err
+	}
+
+	return released, nil
+}
+
+// MigratePayloads moves existing payloads across the tiering threshold:
+// larger inline payloads go to the blob store and smaller offloaded ones come
+// back to Postgres. Each run moves at most one batch in each direction. When
+// another replica is already migrating, it does nothing and returns zero.
+func (r *Repository) MigratePayloads() (int64, error) {
+	var stored []payload
+	moved, err := lock.TryExclusive(r.ctx, r.db, lock.PayloadTiering, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		offloaded, err := r.offloadPayloads(ctx, tx, &stored)
+		if err != nil {
+			return 0, err
+		}
+
+		inlined, err := r.inlinePayloads(ctx, tx)
+		if err != nil {
+			return 0, err
+		}
+
+		return offloaded + inlined, nil
+	})
+	if err != nil {
+		for _, p := range stored {
+			r.discardData(p)
+		}
+		log.Println("Error migrating note payloads")
+
+		return 0, err
+	}
+
+	return moved, nil
+}
+
+// ExportKeys hands every personal entry of userId to emit, oldest first,
+// with its history. Entries of shared vaults belong to the vault and are
+// left out. Each entry is read in its own transaction holding it against
+// compaction, and emitted once that transaction is over.
+func (r *Repository) ExportKeys(userId int64, emit func(entry archive.Entry) error) error {
+	rows, err := r.statements.getExportIds.statement.QueryContext(r.ctx, userId)
+	if err != nil {
+		log.Println("Error listing notes to export")
+
+		return err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+			return r.exportKey(ctx, tx, userId, id)
+		}))
+		if errors.Is(err, sql.ErrNoRows) {
+			// Deleted or expired since it was listed.
+			continue
+		}
+		if err != nil {
+			log.Println("Error exporting note")
+
+			return err
+		}
+
+		if err := emit(*result.(*archive.Entry)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) exportKey(ctx context.Context, tx *sqlx.Tx, userId int64, id string) (*archive.Entry, error) {
+	var entry archive.Entry
+	var p payload
+	if err := tx.StmtxContext(ctx, r.statements.getExportKey.statement).
+		QueryRowContext(ctx, id, userId).
+		Scan(&entry.ID, &entry.EncryptedKey, &entry.KeyIV, &entry.EncryptedData, &entry.DataIV, &entry.CreatedAt, &entry.ExpiresAt,
+			&p.ref, &p.size, &p.checksum); err != nil {
+		return nil, err
+	}
+	if err := r.hydrate(&entry.EncryptedData, p); err != nil {
+		return nil, err
+	}
+
+	var history archive.History
+	var snapshot archive.Snapshot
+	err := tx.StmtxContext(ctx, r.statements.getKeySnapshot.statement).
+		QueryRowContext(ctx, id).
+		Scan(&snapshot.Seq, &snapshot.EncryptedSnapshot, &snapshot.SnapshotIV, &snapshot.CreatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil {
+		history.Snapshot = &snapshot
+	}
+
+	rows, err := tx.StmtxContext(ctx, r.statements.getKeyOperations.statement).QueryContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var operation archive.Operation
+		if err := rows.Scan(&operation.Seq, &operation.AuthorAddress, &operation.EncryptedOp, &operation.OpIV,
+			&operation.CreatedAt); err != nil {
+			return nil, err
+		}
+		history.Operations = append(history.Operations, operation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if history.Snapshot != nil || len(history.Operations) > 0 {
+		entry.History = &history
+	}
+
+	return &entry, nil
+}
+
+// ImportKey restores an archived entry for userId and reports what it did,
+// as one of the dto.ImportOutcome values. An entry is matched by its ID:
+// a missing one is created with that ID, a personal one of userId is
+// skipped, overwritten or stored again under duplicateId as strategy
+// says. Vault entries and entries of other accounts are never touched; the
+// entry is stored under duplicateId instead. Nothing is stored when
+// duplicateId already exists, so replaying an archive changes nothing. The
+// new entries and bytes count against the quota of userId.
+func (r *Repository) ImportKey(userId int64, userAddress string, entry archive.Entry, duplicateId, strategy string,
+	limits QuotaFunc) (string, error) {
+	data, stored, err := r.storeData(entry.EncryptedData)
+	if err != nil {
+		log.Println("Error offloading note data")
+
+		return "", err
+	}
+
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		if err := r.lockUser(ctx, tx, userId); err != nil {
+			return "", err
+		}
+
+		target, err := r.getImportTarget(ctx, tx, entry.ID)
+		if err != nil {
+			return "", err
+		}
+
+		id, outcome := entry.ID, dto.ImportOutcomeImported
+		switch {
+		case target.mine(userId) && strategy == dto.ImportSkip:
+			return dto.ImportOutcomeSkipped, nil
+		case target.mine(userId) && strategy == dto.ImportOverwrite:
+			if err := r.checkUsage(ctx, tx, userId, 0, int64(len(entry.EncryptedData))-target.size, limits); err != nil {
+				return "", err
+			}
+			if _, err := tx.StmtxContext(ctx, r.statements.overwriteKey.statement).
+				ExecContext(ctx, entry.ID, entry.EncryptedKey, entry.KeyIV, data, entry.DataIV, entry.ExpiresAt,
+					stored.ref, stored.size, stored.checksum, lastSeq(entry.History)); err != nil {
+				return "", err
+			}
+
+			return dto.ImportOutcomeOverwritten, r.importHistory(ctx, tx, entry.ID, entry.History)
+		case target.ownerId != 0:
+			duplicate, err := r.getImportTarget(ctx, tx, duplicateId)
+			if err != nil {
+				return "", err
+			}
+			if duplicate.ownerId != 0 {
+				return dto.ImportOutcomeSkipped, nil
+			}
+			id, outcome = duplicateId, dto.ImportOutcomeDuplicated
+		}
+
+		if err := r.checkUsage(ctx, tx, userId, 1, int64(len(entry.EncryptedData)), limits); err != nil {
+			return "", err
+		}
+		if _, err := tx.StmtxContext(ctx, r.statements.importKey.statement).
+			ExecContext(ctx, id, userId, userAddress, entry.EncryptedKey, entry.KeyIV, data, entry.DataIV,
+				nullString(entry.CreatedAt), entry.ExpiresAt, stored.ref, stored.size, stored.checksum,
+				lastSeq(entry.History)); err != nil {
+			return "", err
+		}
+
+		return outcome, r.importHistory(ctx, tx, id, entry.History)
+	}))
+	if err != nil {
+		r.discardData(stored)
+		if !errors.Is(err, ErrQuotaExceeded) {
+			log.Println("Error importing note")
+		}
+
+		return "", err
+	}
+	if result.(string) == dto.ImportOutcomeSkipped {
+		r.discardData(stored)
+	}
+
+	return result.(string), nil
+}
+
+// importTarget is the entry an archived entry is restored over.
+type importTarget struct {
+	ownerId  int64
+	personal bool
+	size     int64
+}
+
+// mine reports whether the target is a personal entry of userId, the only
+// kind an import may skip or overwrite.
+func (t importTarget) mine(userId int64) bool {
+	return t.ownerId == userId && t.personal
+}
+
+// getImportTarget locks entry id for the rest of tx. It returns a zero
+// target when the entry does not exist.
+func (r *Repository) getImportTarget(ctx context.Context, tx *sqlx.Tx, id string) (importTarget, error) {
+	var target importTarget
+	err := tx.StmtxContext(ctx, r.statements.getImportTarget.statement).
+		QueryRowContext(ctx, id).Scan(&target.ownerId, &target.personal, &target.size)
+	if errors.Is(err, sql.ErrNoRows) {
+		return importTarget{}, nil
+	}
+
+	return target, err
+}
+
+// importHistory replaces the snapshot and operations of entry id with
+// history.
+func (r *Repository) importHistory(ctx context.Context, tx *sqlx.Tx, id string, history *archive.History) error {
+	if _, err := tx.StmtxContext(ctx, r.statements.deleteSnapshot.statement).ExecContext(ctx, id); err != nil {
+		return err
+	}
+	if _, err := tx.StmtxContext(ctx, r.statements.deleteOperations.statement).ExecContext(ctx, id); err != nil {
+		return err
+	}
+	if history == nil {
+		return nil
+	}
+
+	if snapshot := history.Snapshot; snapshot != nil {
+		if _, err := tx.StmtxContext(ctx, r.statements.importSnapshot.statement).
+			ExecContext(ctx, id, snapshot.Seq, snapshot.EncryptedSnapshot, snapshot.SnapshotIV,
+				nullString(snapshot.CreatedAt)); err != nil {
+			return err
+		}
+	}
+
+	for _, operation := range history.Operations {
+		if _, err := tx.StmtxContext(ctx, r.statements.importOperation.statement).
+			ExecContext(ctx, id, operation.Seq, operation.AuthorAddress, operation.EncryptedOp, operation.OpIV,
+				nullString(operation.CreatedAt)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteKeys applies writes in one transaction and returns, for each, the
+// stored entry or the error that stopped it: sql.ErrNoRows or
+// ErrQuotaExceeded. A failed write only undoes its own changes, unless the
+// batch is atomic: then it rolls back the whole batch and no entries are
+// returned. Any other error fails the batch.
+func (r *Repository) WriteKeys(userId int64, writes []KeyWrite, atomic bool, limits QuotaFunc) ([]*dto.KeyOutput, []error, error) {
+	data := make([][]byte, len(writes))
+	stored := make([]payload, len(writes))
+	for i, write := range writes {
+		var err error
+		if data[i], stored[i], err = r.storeData(write.Note.EncryptedData); err != nil {
+			log.Println("Error offloading note data")
+			for _, p := range stored[:i] {
+				r.discardData(p)
+			}
+
+			return nil, nil, err
+		}
+	}
+
+	keys := make([]*dto.KeyOutput, len(writes))
+	errs := make([]error, len(writes))
+	_, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		for i, write := range writes {
+			var err error
+			errs[i], err = batchItem(ctx, tx, atomic, func() error {
+				var err error
+				switch {
+				case write.ID == "":
+					keys[i], err = r.addKey(ctx, tx, userId, write.Note, data[i], stored[i], limits)
+				case write.VaultID != "":
+					keys[i], err = r.updateVaultKey(ctx, tx, write.VaultID, write.ID, write.Note, data[i], stored[i], limits)
+				default:
+					keys[i], err = r.updateKey(ctx, tx, userId, write.ID, write.Note, data[i], stored[i], limits)
+				}
+
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	}))
+	if err != nil {
+		for _, p := range stored {
+			r.discardData(p)
+		}
+		if errors.Is(err, errBatchFailed) {
+			return nil, errs, nil
+		}
+		log.Println("Error writing notes")
+
+		return nil, nil, err
+	}
+
+	for i := range writes {
+		if errs[i] != nil {
+			keys[i] = nil
+			r.discardData(stored[i])
+		}
+	}
+
+	return keys, errs, nil
+}
+
+// GetKeysByIds returns the entries among ids that are personal entries of
+// userId or belong to a vault, in no particular order. Callers check vault
+// membership.
+func (r *Repository) GetKeysByIds(userId int64, ids []string) ([]dto.KeyOutput, error) {
+	rows, err := r.statements.getKeysByIds.statement.
+		QueryContext(r.ctx, pq.Array(ids), userId)
+	if err != nil {
+		log.Println("Error getting notes by id")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []dto.KeyOutput
+	var payloads []payload
+	for rows.Next() {
+		var note dto.KeyOutput
+		var p payload
+		if err := rows.Scan(&note.ID, &note.EncryptedKey, &note.KeyIV, &note.EncryptedData, &note.DataIV, &note.CreatedAt, &note.ExpiresAt,
+			&note.VaultID, &note.VaultKeyVersion, &note.Version, &note.Conflicts, &p.ref, &p.size, &p.checksum); err != nil {
+			log.Println("Error scanning note")
+
+			return nil, err
+		}
+		notes = append(notes, note)
+		payloads = append(payloads, p)
+	}
+
+	for i := range notes {
+		if err := r.hydrate(&notes[i].EncryptedData, payloads[i]); err != nil {
+			log.Println("Error loading note data")
+
+			return nil, err
+		}
+	}
+
+	return notes, nil
+}
+
+// DeleteKeys deletes entries in one transaction and returns, for each,
+// sql.ErrNoRows when it did not exist. When atomic, a missing entry rolls
+// back the whole batch.
+func (r *Repository) DeleteKeys(userId int64, refs []KeyRef, atomic bool) ([]error, error) {
+	errs := make([]error, len(refs))
+	_, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		for i, ref := range refs {
+			var err error
+			errs[i], err = batchItem(ctx, tx, atomic, func() error {
+				var result sql.Result
+				var err error
+				if ref.VaultID != "" {
+					result, err = tx.StmtxContext(ctx, r.statements.deleteVaultKey.statement).ExecContext(ctx, ref.ID, ref.VaultID)
+				} else {
+					result, err = tx.StmtxContext(ctx, r.statements.deleteKey.statement).ExecContext(ctx, ref.ID, userId)
+				}
+				if err != nil {
+					return err
+				}
+				deleted, err := rowsAffected(result)
+				if err == nil && !deleted {
+					return sql.ErrNoRows
+				}
+
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	}))
+	if errors.Is(err, errBatchFailed) {
+		return errs, nil
+	}
+	if err != nil {
+		log.Println("Error deleting notes")
+
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// GetChanges returns up to limit changes to the personal entries of userId
+// after the change sequence number since. Entries past their expiration are
+// reported as deleted. The owner's row is share locked while the changes are
+// read, so tombstones cannot be purged in between.
+func (r *Repository) GetChanges(userId, since int64, limit int) (*dto.SyncOutput, error) {
+	type change struct {
+		seq       int64
+		key       *dto.KeyOutput
+		p         payload
+		deletedId string
+	}
+
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var current, floor int64
+		if err := tx.StmtxContext(ctx, r.statements.getSyncState.statement).
+			QueryRowContext(ctx, userId).Scan(&current, &floor); err != nil {
+			return nil, err
+		}
+		if since > current || (since > 0 && since < floor) {
+			return nil, ErrCursorExpired
+		}
+
+		var changes []change
+		rows, err := tx.StmtxContext(ctx, r.statements.getChangedKeys.statement).QueryContext(ctx, userId, since, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c change
+			var expired bool
+			var key dto.KeyOutput
+			if err := rows.Scan(&key.ID, &c.seq, &expired, &key.EncryptedKey, &key.KeyIV, &key.EncryptedData, &key.DataIV,
+				&key.CreatedAt, &key.ExpiresAt, &key.VaultID, &key.VaultKeyVersion, &key.Version, &key.Conflicts, &c.p.ref, &c.p.size, &c.p.checksum); err != nil {
+				return nil, err
+			}
+			if expired {
+				c.deletedId = key.ID
+			} else {
+				c.key = &key
+			}
+			changes = append(changes, c)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		tombstones, err := tx.StmtxContext(ctx, r.statements.getTombstones.statement).QueryContext(ctx, userId, since, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		defer tombstones.Close()
+
+		for tombstones.Next() {
+			var c change
+			if err := tombstones.Scan(&c.deletedId, &c.seq); err != nil {
+				return nil, err
+			}
+			changes = append(changes, c)
+		}
+		if err := tombstones.Err(); err != nil {
+			return nil, err
+		}
+
+		sort.Slice(changes, func(i, j int) bool { return changes[i].seq < changes[j].seq })
+
+		return changes, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, ErrCursorExpired) {
+			log.Println("Error getting note changes")
+		}
+
+		return nil, err
+	}
+
+	changes := result.([]change)
+	output := dto.SyncOutput{
+		Changed: []dto.KeyOutput{},
+		Deleted: []string{},
+		Cursor:  since,
+		HasMore: len(changes) > limit,
+	}
+	if output.HasMore {
+		changes = changes[:limit]
+	}
+
+	for _, c := range changes {
+		output.Cursor = c.seq
+		if c.key == nil {
+			output.Deleted = append(output.Deleted, c.deletedId)
+			continue
+		}
+		if err := r.hydrate(&c.key.EncryptedData, c.p); err != nil {
+			log.Println("Error loading note data")
+
+			return nil, err
+		}
+		output.Changed = append(output.Changed, *c.key)
+	}
+
+	return &output, nil
+}
+
+// DeleteOldTombstones purges the tombstones older than the retention period
+// and raises the sync floor of their owners past them. When another replica
+// is already purging, it does nothing and returns zero.
+func (r *Repository) DeleteOldTombstones() (int64, error) {
+	deleted, err := lock.TryExclusive(r.ctx, r.db, lock.TombstonesReaper, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		var deleted int64
+		err := tx.StmtxContext(ctx, r.statements.deleteTombstones.statement).
+			QueryRowContext(ctx, r.sync.TombstoneRetention.Seconds()).Scan(&deleted)
+
+		return deleted, err
+	})
+	if err != nil {
+		log.Println("Error deleting old tombstones")
+
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// GetConflicts returns the siblings kept beside entry keyId, oldest first.
+// Callers check access to the entry.
+func (r *Repository) GetConflicts(keyId string) ([]dto.ConflictOutput, error) {
+	rows, err := r.statements.getConflicts.statement.
+		QueryContext(r.ctx, keyId)
+	if err != nil {
+		log.Println("Error getting note conflicts")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conflicts []dto.ConflictOutput
+	var payloads []payload
+	for rows.Next() {
+		var conflict dto.ConflictOutput
+		var p payload
+		if err := rows.Scan(&conflict.ID, &conflict.DeviceID, &conflict.Version, &conflict.EncryptedKey, &conflict.KeyIV,
+			&conflict.EncryptedData, &conflict.DataIV, &conflict.CreatedAt, &p.ref, &p.size, &p.checksum); err != nil {
+			log.Println("Error scanning note conflict")
+
+			return nil, err
+		}
+		conflicts = append(conflicts, conflict)
+		payloads = append(payloads, p)
+	}
+
+	for i := range conflicts {
+		if err := r.hydrate(&conflicts[i].EncryptedData, payloads[i]); err != nil {
+			log.Println("Error loading note conflict data")
+
+			return nil, err
+		}
+	}
+
+	return conflicts, nil
+}
+
+// batchItem runs one item of a batch inside tx and returns the error that
+// failed the item, if it is one an item may fail with, and apart from it any
+// error that fails the batch. Outside atomic batches the item runs under a
+// savepoint, so that its failure only undoes its own changes.
+func batchItem(ctx context.Context, tx *sqlx.Tx, atomic bool, item func() error) (error, error) {
+	if atomic {
+		err := item()
+		if failsItem(err) {
+			return err, errBatchFailed
+		}
+
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, savepointItem); err != nil {
+		return nil, err
+	}
+	err := item()
+	if failsItem(err) {
+		_, rollbackErr := tx.ExecContext(ctx, rollbackItem)
+
+		return err, rollbackErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, releaseItem)
+
+	return nil, err
+}
+
+// failsItem reports whether err fails a single item of a batch rather than
+// the whole batch.
+func failsItem(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrTooManyConflicts)
+}
+
+func (r *Repository) offloadPayloads(ctx context.Context, tx *sqlx.Tx, stored *[]payload) (int64, error) {
+	rows, err := tx.StmtxContext(ctx, r.statements.getOffloadable.statement).
+		QueryContext(ctx, r.cfg.Threshold, r.cfg.Batch)
+	if err != nil {
+		return 0, err
+	}
+
+	type candidate struct {
+		id   string
+		data []byte
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.id, &c.data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+
+	var moved int64
+	for _, c := range candidates {
+		_, p, err := r.storeData(c.data)
+		if err != nil {
+			return moved, err
+		}
+		if p.ref == nil {
+			continue
+		}
+		*stored = append(*stored, p)
+
+		if _, err := tx.StmtxContext(ctx, r.statements.offloadKeyData.statement).
+			ExecContext(ctx, c.id, p.ref, p.size, p.checksum); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+
+	return moved, nil
+}
+
+// inlinePayloads brings offloaded payloads at or below the threshold back to
+// Postgres. Payloads that cannot be read are skipped and retried on the next
+// run.
+func (r *Repository) inlinePayloads(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+	rows, err := tx.StmtxContext(ctx, r.statements.getInlinable.statement).
+		QueryContext(ctx, r.cfg.Threshold, r.cfg.Batch)
+	if err != nil {
+		return 0, err
+	}
+
+	type candidate struct {
+		id string
+		p  payload
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.id, &c.p.ref, &c.p.size, &c.p.checksum); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+
+	var moved int64
+	for _, c := range candidates {
+		data, err := r.loadData(c.p)
+		if err != nil {
+			log.Println("Error loading offloaded note data")
+			continue
+		}
+
+		if _, err := tx.StmtxContext(ctx, r.statements.inlineKeyData.statement).
+			ExecContext(ctx, c.id, data); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+
+	return moved, nil
+}
+
+func (r *Repository) addKey(ctx context.Context, tx *sqlx.Tx, userId int64, note dto.KeyImput, data []byte, stored payload,
+	limits QuotaFunc) (*dto.KeyOutput, error) {
+	if err := r.checkQuota(ctx, tx, userId, 1, int64(len(note.EncryptedData)), limits); err != nil {
+		return nil, err
+	}
+
+	var result dto.KeyOutput
+	var p payload
+	if err := tx.StmtxContext(ctx, r.statements.addKey.statement).
+		QueryRowContext(ctx, userId, note.UserAddress, note.EncryptedKey, note.KeyIV, data, note.DataIV, note.ExpiresAt,
+			nullString(note.VaultID), nullInt(note.VaultKeyVersion), stored.ref, stored.size, stored.checksum, initialVersion(note)).
+		Scan(&result.ID, &result.EncryptedKey, &result.KeyIV, &result.EncryptedData, &result.DataIV, &result.CreatedAt, &result.ExpiresAt,
+			&result.VaultID, &result.VaultKeyVersion, &result.Version, &result.Conflicts, &p.ref, &p.size, &p.checksum); err != nil {
+		return nil, err
+	}
+	result.EncryptedData = note.EncryptedData
+
+	return &result, nil
+}
+
+func (r *Repository) updateKey(ctx context.Context, tx *sqlx.Tx, userId int64, id string, note dto.KeyImput, data []byte,
+	stored payload, limits QuotaFunc) (*dto.KeyOutput, error) {
+	u, err := r.prepareUpdate(ctx, tx, id, "", note, limits)
+	if err != nil {
+		return nil, err
+	}
+	if u.ownerId != userId {
+		return nil, sql.ErrNoRows
+	}
+	if u.concurrent {
+		return r.addConflict(ctx, tx, id, note, u, data, stored)
+	}
+
+	var result dto.KeyOutput
+	var p payload
+	if err := tx.StmtxContext(ctx, r.statements.updateKey.statement).
+		QueryRowContext(ctx, id, userId, note.EncryptedKey, note.KeyIV, data, note.DataIV, note.ExpiresAt,
+			stored.ref, stored.size, stored.checksum, u.version, u.conflicts).
+		Scan(&result.ID, &result.EncryptedKey, &result.KeyIV, &result.EncryptedData, &result.DataIV, &result.CreatedAt, &result.ExpiresAt,
+			&result.VaultID, &result.VaultKeyVersion, &result.Version, &result.Conflicts, &p.ref, &p.size, &p.checksum); err != nil {
+		return nil, err
+	}
+	result.EncryptedData = note.EncryptedData
+
+	return &result, nil
+}
+
+func (r *Repository) updateVaultKey(ctx context.Context, tx *sqlx.Tx, vaultId, id string, note dto.KeyImput, data []byte,
+	stored payload, limits QuotaFunc) (*dto.KeyOutput, error) {
+	u, err := r.prepareUpdate(ctx, tx, id, vaultId, note, limits)
+	if err != nil {
+		return nil, err
+	}
+	if u.concurrent {
+		return r.addConflict(ctx, tx, id, note, u, data, stored)
+	}
+
+	var result dto.KeyOutput
+	var p payload
+	if err := tx.StmtxContext(ctx, r.statements.updateVaultKey.statement).
+		QueryRowContext(ctx, id, vaultId, note.EncryptedKey, note.KeyIV, data, note.DataIV, note.ExpiresAt,
+			nullInt(note.VaultKeyVersion), stored.ref, stored.size, stored.checksum, u.version, u.conflicts).
+		Scan(&result.ID, &result.EncryptedKey, &result.KeyIV, &result.EncryptedData, &result.DataIV, &result.CreatedAt, &result.ExpiresAt,
+			&result.VaultID, &result.VaultKeyVersion, &result.Version, &result.Conflicts, &p.ref, &p.size, &p.checksum); err != nil {
+		return nil, err
+	}
+	result.EncryptedData = note.EncryptedData
+
+	return &result, nil
+}
+
+// checkQuota locks ownerId's row for the rest of tx and checks that adding
+// entries and bytes to their usage stays within limits.
+func (r *Repository) checkQuota(ctx context.Context, tx *sqlx.Tx, ownerId, entries, bytes int64, limits QuotaFunc) error {
+	if err := r.lockUser(ctx, tx, ownerId); err != nil {
+		return err
+	}
+
+	return r.checkUsage(ctx, tx, ownerId, entries, bytes, limits)
+}
+
+// checkUpdateQuota checks that replacing the payload of entry id with size
+// bytes fits in its owner's quota and returns the owner. It returns
+// sql.ErrNoRows when the entry does not exist.
+func (r *Repository) checkUpdateQuota(ctx context.Context, tx *sqlx.Tx, id string, size int, limits QuotaFunc) (int64, error) {
+	ownerId, current, err := r.lockKeyOwner(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return ownerId, r.checkUsage(ctx, tx, ownerId, 0, int64(size)-current, limits)
+}
+
+// lockKeyOwner locks the row of the owner of entry id for the rest of tx and
+// returns the owner and the size of the entry's payload.
+func (r *Repository) lockKeyOwner(ctx context.Context, tx *sqlx.Tx, id string) (int64, int64, error) {
+	var ownerId, current int64
+	if err := tx.StmtxContext(ctx, r.statements.getKeySize.statement).
+		QueryRowContext(ctx, id).Scan(&ownerId, &current); err != nil {
+		return 0, 0, err
+	}
+
+	if err := r.lockUser(ctx, tx, ownerId); err != nil {
+		return 0, 0, err
+	}
+
+	// The size is read again now that writes of the owner are serialized.
+	if err := tx.StmtxContext(ctx, r.statements.getKeySize.statement).
+		QueryRowContext(ctx, id).Scan(&ownerId, &current); err != nil {
+		return 0, 0, err
+	}
+
+	return ownerId, current, nil
+}
+
+// prepareUpdate locks entry id of vaultId, or a personal entry when vaultId
+// is empty, and works out how note lands on it. A write without a device
+// overwrites the entry. A versioned write whose base covers the stored
+// version overwrites it too and drops the siblings it covers; any other is
+// concurrent and becomes a sibling. Its quota is checked either way.
+func (r *Repository) prepareUpdate(ctx context.Context, tx *sqlx.Tx, id, vaultId string, note dto.KeyImput,
+	limits QuotaFunc) (update, error) {
+	ownerId, current, err := r.lockKeyOwner(ctx, tx, id)
+	if err != nil {
+		return update{}, err
+	}
+
+	u := update{ownerId: ownerId}
+	var stored vclock.Vector
+	if err := tx.StmtxContext(ctx, r.statements.lockKeyVersion.statement).
+		QueryRowContext(ctx, id, nullString(vaultId)).Scan(&stored, &u.conflicts); err != nil {
+		return update{}, err
+	}
+
+	size := int64(len(note.EncryptedData))
+	switch {
+	case note.DeviceID == "":
+		u.version = stored.Next(vclock.Unversioned)
+	case !note.Version.Covers(stored):
+		if u.conflicts >= maxConflicts {
+			return update{}, ErrTooManyConflicts
+		}
+		u.version = note.Version.Next(note.DeviceID)
+		u.conflicts++
+		u.concurrent = true
+
+		return u, r.checkUsage(ctx, tx, ownerId, 0, size, limits)
+	default:
+		u.version = note.Version.Next(note.DeviceID)
+		if u.conflicts, err = r.dropCoveredConflicts(ctx, tx, id, note.Version); err != nil {
+			return update{}, err
+		}
+	}
+
+	return u, r.checkUsage(ctx, tx, ownerId, 0, size-current, limits)
+}
+
+// dropCoveredConflicts deletes the siblings of entry id that version covers
+// and returns the number left.
+func (r *Repository) dropCoveredConflicts(ctx context.Context, tx *sqlx.Tx, id string, version vclock.Vector) (int, error) {
+	rows, err := tx.StmtxContext(ctx, r.statements.getConflictVersions.statement).QueryContext(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var covered []string
+	left := 0
+	for rows.Next() {
+		var conflictId string
+		var sibling vclock.Vector
+		if err := rows.Scan(&conflictId, &sibling); err != nil {
+			return 0, err
+		}
+		if version.Covers(sibling) {
+			covered = append(covered, conflictId)
+		} else {
+			left++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, conflictId := range covered {
+		if _, err := tx.StmtxContext(ctx, r.statements.deleteConflict.statement).ExecContext(ctx, conflictId); err != nil {
+			return 0, err
+		}
+	}
+
+	return left, nil
+}
+
+// addConflict keeps a concurrent write to entry id as a sibling and returns
+// the stored entry, flagged as conflicting.
+func (r *Repository) addConflict(ctx context.Context, tx *sqlx.Tx, id string, note dto.KeyImput, u update, data []byte,
+	stored payload) (*dto.KeyOutput, error) {
+	if _, err := tx.StmtxContext(ctx, r.statements.addConflict.statement).
+		ExecContext(ctx, id, note.DeviceID, u.version, note.EncryptedKey, note.KeyIV, data, note.DataIV,
+			stored.ref, stored.size, stored.checksum); err != nil {
+		return nil, err
+	}
+
+	var result dto.KeyOutput
+	var p payload
+	if err := tx.StmtxContext(ctx, r.statements.setConflicts.statement).
+		QueryRowContext(ctx, id, u.conflicts).
+		Scan(&result.ID, &result.EncryptedKey, &result.KeyIV, &result.EncryptedData, &result.DataIV, &result.CreatedAt, &result.ExpiresAt,
+			&result.VaultID, &result.VaultKeyVersion, &result.Version, &result.Conflicts, &p.ref, &p.size, &p.checksum); err != nil {
+		return nil, err
+	}
+	if err := r.hydrate(&result.EncryptedData, p); err != nil {
+		return nil, err
+	}
+	result.Conflict = true
+
+	return &result, nil
+}
+
+func (r *Repository) lockUser(ctx context.Context, tx *sqlx.Tx, userId int64) error {
+	var id int64
+	return tx.StmtxContext(ctx, r.statements.lockUser.statement).
+		QueryRowContext(ctx, userId).Scan(&id)
+}
+
+// checkUsage returns ErrQuotaExceeded when a write would grow userId's usage
+// past a limit. Writes that do not grow usage are always allowed, even above
+// the limits.
+func (r *Repository) checkUsage(ctx context.Context, tx *sqlx.Tx, userId, entries, bytes int64, limits QuotaFunc) error {
+	if entries <= 0 && bytes <= 0 {
+		return nil
+	}
+
+	quota, err := limits(userId)
+	if err != nil {
+		return err
+	}
+
+	var usedEntries, usedBytes int64
+	if err := tx.StmtxContext(ctx, r.statements.getUsage.statement).
+		QueryRowContext(ctx, userId).Scan(&usedEntries, &usedBytes); err != nil {
+		return err
+	}
+
+	if exceeds(usedEntries, entries, quota.MaxEntries) || exceeds(usedBytes, bytes, quota.MaxBytes) {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+// storeData returns the value to store in encrypted_data. Payloads above the
+// threshold are written to the blob store first and described by the
+// returned payload instead.
+func (r *Repository) storeData(data []byte) ([]byte, payload, error) {
+	if r.cfg.Threshold <= 0 || len(data) <= r.cfg.Threshold {
+		return data, payload{}, nil
+	}
+
+	suffix := make([]byte, blobIdSize)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, payload{}, err
+	}
+	ref := "keys/" + hex.EncodeToString(suffix)
+	size := int64(len(data))
+	checksum := sha256.Sum256(data)
+
+	if err := r.store.Put(r.ctx, ref, bytes.NewReader(data), size); err != nil {
+		return nil, payload{}, err
+	}
+
+	return nil, payload{ref: &ref, size: &size, checksum: checksum[:]}, nil
+}
+
+// discardData deletes a blob written by storeData that no row references.
+func (r *Repository) discardData(p payload) {
+	if p.ref == nil {
+		return
+	}
+
+	if err := r.store.Delete(r.ctx, *p.ref); err != nil {
+		log.Println("Error deleting unreferenced note data")
+	}
+}
+
+// loadData reads an offloaded payload back and checks it against its
+// recorded size and checksum.
+func (r *Repository) loadData(p payload) ([]byte, error) {
+	body, err := r.store.Get(r.ctx, *p.ref, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, *p.size+1))
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(data)
+	if int64(len(data)) != *p.size || !bytes.Equal(checksum[:], p.checksum) {
+		return nil, ErrChecksumMismatch
+	}
+
+	return data, nil
+}
+
+func (r *Repository) hydrate(data *[]byte, p payload) error {
+	if p.ref == nil {
+		return nil
+	}
+
+	loaded, err := r.loadData(p)
+	if err != nil {
+		return err
+	}
+	*data = loaded
+
+	return nil
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.addKey.statement, err = r.db.PrepareStatement(statementsList.addKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getKeysByUser.statement, err = r.db.PrepareStatement(statementsList.getKeysByUser.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.updateKey.statement, err = r.db.PrepareStatement(statementsList.updateKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteKey.statement, err = r.db.PrepareStatement(statementsList.deleteKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteExpiredKeys.statement, err = r.db.PrepareStatement(statementsList.deleteExpiredKeys.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.updateKeyData.statement, err = r.db.PrepareStatement(statementsList.updateKeyData.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.createShare.statement, err = r.db.PrepareStatement(statementsList.createShare.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getSharesByKey.statement, err = r.db.PrepareStatement(statementsList.getSharesByKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteShare.statement, err = r.db.PrepareStatement(statementsList.deleteShare.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getSharedKeys.statement, err = r.db.PrepareStatement(statementsList.getSharedKeys.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getSharedKey.statement, err = r.db.PrepareStatement(statementsList.getSharedKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.updateShareStatus.statement, err = r.db.PrepareStatement(statementsList.updateShareStatus.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getKeyVault.statement, err = r.db.PrepareStatement(statementsList.getKeyVault.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getKeysByVault.statement, err = r.db.PrepareStatement(statementsList.getKeysByVault.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.updateVaultKey.statement, err = r.db.PrepareStatement(statementsList.updateVaultKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteVaultKey.statement, err = r.db.PrepareStatement(statementsList.deleteVaultKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getKeyAccess.statement, err = r.db.PrepareStatement(statementsList.getKeyAccess.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.createRelease.statement, err = r.db.PrepareStatement(statementsList.createRelease.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getReleasesByKey.statement, err = r.db.PrepareStatement(statementsList.getReleasesByKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.updateRelease.statement, err = r.db.PrepareStatement(statementsList.updateRelease.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteRelease.statement, err = r.db.PrepareStatement(statementsList.deleteRelease.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.releaseDueKeys.statement, err = r.db.PrepareStatement(statementsList.releaseDueKeys.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getOffloadable.statement, err = r.db.PrepareStatement(statementsList.getOffloadable.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.offloadKeyData.statement, err = r.db.PrepareStatement(statementsList.offloadKeyData.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getInlinable.statement, err = r.db.PrepareStatement(statementsList.getInlinable.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.inlineKeyData.statement, err = r.db.PrepareStatement(statementsList.inlineKeyData.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockUser.statement, err = r.db.PrepareStatement(statementsList.lockUser.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getUsage.statement, err = r.db.PrepareStatement(statementsList.getUsage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getKeySize.statement, err = r.db.PrepareStatement(statementsList.getKeySize.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getExportIds.statement, err = r.db.PrepareStatement(statementsList.getExportIds.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getExportKey.statement, err = r.db.PrepareStatement(statementsList.getExportKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getKeySnapshot.statement, err = r.db.PrepareStatement(statementsList.getKeySnapshot.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getKeyOperations.statement, err = r.db.PrepareStatement(statementsList.getKeyOperations.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getImportTarget.statement, err = r.db.PrepareStatement(statementsList.getImportTarget.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.importKey.statement, err = r.db.PrepareStatement(statementsList.importKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.overwriteKey.statement, err = r.db.PrepareStatement(statementsList.overwriteKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteSnapshot.statement, err = r.db.PrepareStatement(statementsList.deleteSnapshot.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteOperations.statement, err = r.db.PrepareStatement(statementsList.deleteOperations.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.importSnapshot.statement, err = r.db.PrepareStatement(statementsList.importSnapshot.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.importOperation.statement, err = r.db.PrepareStatement(statementsList.importOperation.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getKeysByIds.statement, err = r.db.PrepareStatement(statementsList.getKeysByIds.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getSyncState.statement, err = r.db.PrepareStatement(statementsList.getSyncState.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getChangedKeys.statement, err = r.db.PrepareStatement(statementsList.getChangedKeys.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getTombstones.statement, err = r.db.PrepareStatement(statementsList.getTombstones.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteTombstones.statement, err = r.db.PrepareStatement(statementsList.deleteTombstones.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockKeyVersion.statement, err = r.db.PrepareStatement(statementsList.lockKeyVersion.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getConflicts.statement, err = r.db.PrepareStatement(statementsList.getConflicts.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getConflictVersions.statement, err = r.db.PrepareStatement(statementsList.getConflictVersions.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.addConflict.statement, err = r.db.PrepareStatement(statementsList.addConflict.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteConflict.statement, err = r.db.PrepareStatement(statementsList.deleteConflict.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.setConflicts.statement, err = r.db.PrepareStatement(statementsList.setConflicts.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
+
+func rowsAffected(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// exceeds reports whether adding delta to used grows it past limit. A
+// zero limit is unlimited.
+func exceeds(used, delta, limit int64) bool {
+	return limit > 0 && delta > 0 && used+delta > limit
+}
+
+// lastSeq is the operation counter an entry resumes from after an import.
+func lastSeq(history *archive.History) int64 {
+	if history == nil {
+		return 0
+	}
+
+	var seq int64
+	if history.Snapshot != nil {
+		seq = history.Snapshot.Seq
+	}
+	if n := len(history.Operations); n > 0 && history.Operations[n-1].Seq > seq {
+		seq = history.Operations[n-1].Seq
+	}
+
+	return seq
+}
+
+// initialVersion is the version vector of a new entry.
+func initialVersion(note dto.KeyImput) vclock.Vector {
+	if note.DeviceID == "" {
+		return vclock.Vector{}
+	}
+
+	return vclock.Vector{}.Next(note.DeviceID)
+}
+
+func nullString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+func nullInt(value int) *int {
+	if value == 0 {
+		return nil
+	}
+
+	return &value
 }
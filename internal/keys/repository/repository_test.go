@@ -4,15 +4,24 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
+	"github.com/ObscuraNote/api-general/internal/keys/archive"
 	"github.com/ObscuraNote/api-general/internal/keys/dto"
+	"github.com/ObscuraNote/api-general/internal/keys/vclock"
+	"github.com/ObscuraNote/api-general/internal/utils/blobstore"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/google/uuid"
 	"github.com/philippe-berto/database/postgresdb"
 	"github.com/stretchr/testify/assert"
 )
 
 var ctx = context.Background()
+var unlimited = quota(config.QuotaConfig{})
 var cfg = postgresdb.Config{
 	Host:         "localhost",
 	Name:         "crypter",
@@ -42,7 +51,12 @@ func TestRepository(t *testing.T) {
 		       ('2222222222222222222222222222222222222222222222222222222222222222', 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb');
 	`)
 
-	repo := New(ctx, db)
+	store, err := blobstore.NewFS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %v", err)
+	}
+
+	repo := New(ctx, db, store, config.TieringConfig{Threshold: 16, Batch: 10}, config.SyncConfig{})
 
 	// Test user ID (should match an existing user in the database)
 	var userId int64
@@ -61,7 +75,7 @@ func TestRepository(t *testing.T) {
 			EncryptedData: []byte("enc"),
 			DataIV:        []byte("iv"),
 		}
-		createdKey, err := repo.AddKey(userId, note)
+		createdKey, err := repo.AddKey(userId, note, unlimited)
 		assert.NoError(t, err)
 		assert.NotNil(t, createdKey)
 		assert.NotEmpty(t, createdKey.ID)
@@ -86,7 +100,7 @@ func TestRepository(t *testing.T) {
 			EncryptedData: []byte("enc"),
 			DataIV:        []byte("iv"),
 		}
-		createdKey, err := repo.AddKey(userId, note)
+		createdKey, err := repo.AddKey(userId, note, unlimited)
 		assert.NoError(t, err)
 		assert.NotNil(t, createdKey)
 
@@ -94,8 +108,9 @@ func TestRepository(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, keys, 2)
 
-		err = repo.DeleteKey(keys[0].ID)
+		deleted, err := repo.DeleteKey(userId, keys[0].ID)
 		assert.NoError(t, err)
+		assert.True(t, deleted)
 
 		keys, err = repo.GetKeysByUser(userId)
 		assert.NoError(t, err)
@@ -110,4 +125,518 @@ func TestRepository(t *testing.T) {
 		assert.Len(t, keys, 0)
 	})
 
+	t.Run("UpdateKey", func(t *testing.T) {
+		keys, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		expiresAt := time.Now().Add(time.Hour)
+		note := dto.KeyImput{
+			EncryptedKey:  []byte("key2"),
+			KeyIV:         []byte("key2"),
+			EncryptedData: []byte("enc2"),
+			DataIV:        []byte("iv2"),
+			ExpiresAt:     &expiresAt,
+		}
+		updatedKey, err := repo.UpdateKey(userId, keys[0].ID, note, unlimited)
+		assert.NoError(t, err)
+		assert.Equal(t, note.EncryptedData, updatedKey.EncryptedData)
+		assert.NotNil(t, updatedKey.ExpiresAt)
+
+		_, err = repo.UpdateKey(int64(99999), keys[0].ID, note, unlimited)
+		assert.Error(t, err)
+	})
+
+	t.Run("Shares", func(t *testing.T) {
+		var recipientId int64
+		err := db.GetClient().QueryRow(`
+			SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+		`).Scan(&recipientId)
+		assert.NoError(t, err)
+
+		keys, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		share := dto.ShareInput{
+			RecipientAddress: "2222222222222222222222222222222222222222222222222222222222222222",
+			EncryptedKey:     []byte("wrapped"),
+			Permission:       dto.PermissionEdit,
+		}
+		createdShare, err := repo.CreateShare(userId, recipientId, keys[0].ID, share)
+		assert.NoError(t, err)
+		assert.Equal(t, dto.ShareStatusPending, createdShare.Status)
+
+		_, err = repo.CreateShare(recipientId, userId, keys[0].ID, share)
+		assert.Error(t, err)
+
+		shares, err := repo.GetSharesByKey(userId, keys[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, shares, 1)
+
+		ownerId, err := repo.UpdateShareStatus(recipientId, createdShare.ID, dto.ShareStatusAccepted)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, ownerId)
+
+		_, err = repo.UpdateShareStatus(userId, createdShare.ID, dto.ShareStatusAccepted)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		shared, err := repo.GetSharedKeys(recipientId)
+		assert.NoError(t, err)
+		assert.Len(t, shared, 1)
+		assert.Equal(t, []byte("wrapped"), shared[0].EncryptedKey)
+		assert.Equal(t, keys[0].EncryptedData, shared[0].EncryptedData)
+
+		updated, err := repo.UpdateKeyData(keys[0].ID, createdShare.ID, recipientId, []byte("edited"), []byte("iv3"), unlimited)
+		assert.NoError(t, err)
+		assert.True(t, updated)
+
+		sharedKey, err := repo.GetSharedKey(recipientId, createdShare.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("edited"), sharedKey.EncryptedData)
+
+		access, err := repo.GetKeyAccess(recipientId, keys[0].ID)
+		assert.NoError(t, err)
+		assert.False(t, access.IsOwner)
+		assert.Equal(t, dto.PermissionEdit, *access.Permission)
+
+		access, err = repo.GetKeyAccess(userId, keys[0].ID)
+		assert.NoError(t, err)
+		assert.True(t, access.IsOwner)
+		assert.Nil(t, access.Permission)
+
+		revokedId, err := repo.DeleteShare(userId, keys[0].ID, createdShare.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, recipientId, revokedId)
+
+		shared, err = repo.GetSharedKeys(recipientId)
+		assert.NoError(t, err)
+		assert.Len(t, shared, 0)
+
+		updated, err = repo.UpdateKeyData(keys[0].ID, createdShare.ID, recipientId, []byte("revoked"), []byte("iv4"), unlimited)
+		assert.NoError(t, err)
+		assert.False(t, updated)
+	})
+
+	t.Run("Releases", func(t *testing.T) {
+		var recipientId int64
+		err := db.GetClient().QueryRow(`
+			SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+		`).Scan(&recipientId)
+		assert.NoError(t, err)
+
+		keys, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		release := dto.ReleaseInput{
+			RecipientAddress: "2222222222222222222222222222222222222222222222222222222222222222",
+			EncryptedKey:     []byte("wrapped"),
+			Permission:       dto.PermissionRead,
+			ReleaseAt:        time.Now().Add(time.Hour),
+		}
+		created, err := repo.CreateRelease(userId, recipientId, keys[0].ID, release)
+		assert.NoError(t, err)
+
+		_, err = repo.CreateRelease(recipientId, userId, keys[0].ID, release)
+		assert.Error(t, err)
+
+		released, err := repo.ReleaseDueKeys()
+		assert.NoError(t, err)
+		assert.Empty(t, released)
+
+		shared, err := repo.GetSharedKeys(recipientId)
+		assert.NoError(t, err)
+		assert.Len(t, shared, 0)
+
+		updated, err := repo.UpdateRelease(userId, keys[0].ID, created.ID, time.Now().Add(2*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, created.ID, updated.ID)
+
+		releases, err := repo.GetReleasesByKey(userId, keys[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, releases, 1)
+
+		_, err = db.GetClient().Exec(`UPDATE key_releases SET release_at = CURRENT_TIMESTAMP - INTERVAL '1 minute';`)
+		assert.NoError(t, err)
+
+		deleted, err := repo.DeleteRelease(userId, keys[0].ID, created.ID)
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+
+		released, err = repo.ReleaseDueKeys()
+		assert.NoError(t, err)
+
+		shared, err = repo.GetSharedKeys(recipientId)
+		assert.NoError(t, err)
+		assert.Len(t, shared, 1)
+		assert.Equal(t, []ReleasedShare{{ShareID: shared[0].ShareID, RecipientID: recipientId}}, released)
+		assert.Equal(t, dto.ShareStatusPending, shared[0].Status)
+
+		releases, err = repo.GetReleasesByKey(userId, keys[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, releases, 0)
+	})
+
+	t.Run("ExpiredKeys", func(t *testing.T) {
+		expiredAt := time.Now().Add(-time.Minute)
+		note := dto.KeyImput{
+			UserAddress:   "1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			EncryptedKey:  []byte("key"),
+			KeyIV:         []byte("key"),
+			EncryptedData: []byte("enc"),
+			DataIV:        []byte("iv"),
+			ExpiresAt:     &expiredAt,
+		}
+		_, err := repo.AddKey(userId, note, unlimited)
+		assert.NoError(t, err)
+
+		keys, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		deleted, err := repo.DeleteExpiredKeys()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("PayloadTiering", func(t *testing.T) {
+		db.GetClient().Exec("TRUNCATE TABLE blob_garbage;")
+		defer db.GetClient().Exec("TRUNCATE TABLE blob_garbage;")
+
+		data := bytes.Repeat([]byte("x"), 40)
+		created, err := repo.AddKey(userId, dto.KeyImput{
+			UserAddress:   "1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			EncryptedKey:  []byte("key"),
+			KeyIV:         []byte("key"),
+			EncryptedData: data,
+			DataIV:        []byte("iv"),
+		}, unlimited)
+		assert.NoError(t, err)
+		assert.Equal(t, data, created.EncryptedData)
+
+		var offloaded bool
+		err = db.GetClient().QueryRow(`SELECT encrypted_data IS NULL AND data_ref IS NOT NULL FROM keys WHERE id = $1;`, created.ID).
+			Scan(&offloaded)
+		assert.NoError(t, err)
+		assert.True(t, offloaded)
+
+		keys, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		for _, key := range keys {
+			if key.ID == created.ID {
+				assert.Equal(t, data, key.EncryptedData)
+			}
+		}
+
+		inline := New(ctx, db, store, config.TieringConfig{Threshold: 0, Batch: 10}, config.SyncConfig{})
+		moved, err := inline.MigratePayloads()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), moved)
+
+		err = db.GetClient().QueryRow(`SELECT encrypted_data IS NULL AND data_ref IS NOT NULL FROM keys WHERE id = $1;`, created.ID).
+			Scan(&offloaded)
+		assert.NoError(t, err)
+		assert.False(t, offloaded)
+
+		var queued int
+		err = db.GetClient().QueryRow(`SELECT COUNT(*) FROM blob_garbage;`).Scan(&queued)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, queued)
+
+		moved, err = repo.MigratePayloads()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), moved)
+
+		keys, err = repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		for _, key := range keys {
+			if key.ID == created.ID {
+				assert.Equal(t, data, key.EncryptedData)
+			}
+		}
+	})
+	t.Run("Quota", func(t *testing.T) {
+		keys, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+
+		var used int64
+		for _, key := range keys {
+			used += int64(len(key.EncryptedData))
+		}
+
+		note := dto.KeyImput{
+			UserAddress:   "1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			EncryptedKey:  []byte("key"),
+			KeyIV:         []byte("key"),
+			EncryptedData: []byte("0123456789"),
+			DataIV:        []byte("iv"),
+		}
+		limits := config.QuotaConfig{MaxEntries: int64(len(keys)) + 1, MaxBytes: used + 10}
+
+		created, err := repo.AddKey(userId, note, quota(limits))
+		assert.NoError(t, err)
+
+		_, err = repo.AddKey(userId, note, quota(config.QuotaConfig{MaxEntries: int64(len(keys)) + 1}))
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+		note.EncryptedData = []byte("01234567890")
+		_, err = repo.UpdateKey(userId, created.ID, note, quota(limits))
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+		note.EncryptedData = []byte("01234")
+		_, err = repo.UpdateKey(userId, created.ID, note, quota(config.QuotaConfig{MaxBytes: 1}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("ExportImport", func(t *testing.T) {
+		var entries []archive.Entry
+		err := repo.ExportKeys(userId, func(entry archive.Entry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+		assert.NoError(t, err)
+		keys, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		assert.Len(t, entries, len(keys))
+		if len(entries) == 0 {
+			t.Fatal("nothing exported")
+		}
+		entry := entries[0]
+
+		var otherId int64
+		err = db.GetClient().QueryRow(`
+			SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+		`).Scan(&otherId)
+		assert.NoError(t, err)
+		otherAddress := "2222222222222222222222222222222222222222222222222222222222222222"
+		duplicateId := uuid.NewString()
+
+		outcome, err := repo.ImportKey(userId, "", entry, duplicateId, dto.ImportSkip, unlimited)
+		assert.NoError(t, err)
+		assert.Equal(t, dto.ImportOutcomeSkipped, outcome)
+
+		entry.EncryptedData = []byte("restored")
+		outcome, err = repo.ImportKey(userId, "", entry, duplicateId, dto.ImportOverwrite, unlimited)
+		assert.NoError(t, err)
+		assert.Equal(t, dto.ImportOutcomeOverwritten, outcome)
+
+		outcome, err = repo.ImportKey(otherId, otherAddress, entry, duplicateId, dto.ImportOverwrite, unlimited)
+		assert.NoError(t, err)
+		assert.Equal(t, dto.ImportOutcomeDuplicated, outcome)
+
+		outcome, err = repo.ImportKey(otherId, otherAddress, entry, duplicateId, dto.ImportDuplicate, unlimited)
+		assert.NoError(t, err)
+		assert.Equal(t, dto.ImportOutcomeSkipped, outcome)
+
+		entry.ID = uuid.NewString()
+		entry.History = &archive.History{
+			Snapshot:   &archive.Snapshot{Seq: 2, EncryptedSnapshot: []byte("state")},
+			Operations: []archive.Operation{{Seq: 3, AuthorAddress: otherAddress, EncryptedOp: []byte("op")}},
+		}
+		_, err = repo.ImportKey(otherId, otherAddress, entry, uuid.NewString(), dto.ImportSkip,
+			quota(config.QuotaConfig{MaxEntries: 1}))
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+		outcome, err = repo.ImportKey(otherId, otherAddress, entry, uuid.NewString(), dto.ImportSkip, unlimited)
+		assert.NoError(t, err)
+		assert.Equal(t, dto.ImportOutcomeImported, outcome)
+
+		var restored []archive.Entry
+		err = repo.ExportKeys(otherId, func(entry archive.Entry) error {
+			restored = append(restored, entry)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, restored, 2)
+		assert.Equal(t, duplicateId, restored[0].ID)
+		assert.Equal(t, []byte("restored"), restored[0].EncryptedData)
+		assert.Equal(t, entry.ID, restored[1].ID)
+		if assert.NotNil(t, restored[1].History) {
+			assert.Equal(t, int64(2), restored[1].History.Snapshot.Seq)
+			assert.Len(t, restored[1].History.Operations, 1)
+			assert.Equal(t, otherAddress, restored[1].History.Operations[0].AuthorAddress)
+		}
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		note := dto.KeyImput{
+			UserAddress:   "1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			EncryptedKey:  []byte("key"),
+			KeyIV:         []byte("key"),
+			EncryptedData: []byte("batch"),
+			DataIV:        []byte("iv"),
+		}
+		missing := uuid.NewString()
+
+		keys, errs, err := repo.WriteKeys(userId, []KeyWrite{{Note: note}, {ID: missing, Note: note}, {Note: note}}, false, unlimited)
+		assert.NoError(t, err)
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], sql.ErrNoRows)
+		assert.NoError(t, errs[2])
+		assert.Nil(t, keys[1])
+		if keys[0] == nil || keys[2] == nil {
+			t.Fatal("batch did not create the entries")
+		}
+
+		found, err := repo.GetKeysByIds(userId, []string{keys[0].ID, keys[2].ID, missing})
+		assert.NoError(t, err)
+		assert.Len(t, found, 2)
+
+		before, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		note.EncryptedData = []byte("updated")
+		keys, errs, err = repo.WriteKeys(userId, []KeyWrite{{ID: keys[0].ID, Note: note}, {Note: note}, {ID: missing, Note: note}}, true, unlimited)
+		assert.NoError(t, err)
+		assert.Nil(t, keys)
+		assert.ErrorIs(t, errs[2], sql.ErrNoRows)
+		after, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		assert.Equal(t, before, after)
+
+		errs, err = repo.DeleteKeys(userId, []KeyRef{{ID: found[0].ID}, {ID: missing}}, true)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, errs[1], sql.ErrNoRows)
+
+		errs, err = repo.DeleteKeys(userId, []KeyRef{{ID: found[0].ID}, {ID: found[1].ID}, {ID: missing}}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []error{nil, nil, sql.ErrNoRows}, errs)
+
+		found, err = repo.GetKeysByIds(userId, []string{found[0].ID, found[1].ID})
+		assert.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		full, err := repo.GetChanges(userId, 0, 1000)
+		assert.NoError(t, err)
+		keys, err := repo.GetKeysByUser(userId)
+		assert.NoError(t, err)
+		assert.Len(t, full.Changed, len(keys))
+		assert.False(t, full.HasMore)
+
+		created, err := repo.AddKey(userId, dto.KeyImput{
+			UserAddress:   "1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			EncryptedKey:  []byte("key"),
+			KeyIV:         []byte("key"),
+			EncryptedData: []byte("sync"),
+			DataIV:        []byte("iv"),
+		}, unlimited)
+		assert.NoError(t, err)
+
+		changes, err := repo.GetChanges(userId, full.Cursor, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, changes.Changed, 1) {
+			assert.Equal(t, created.ID, changes.Changed[0].ID)
+		}
+		assert.Empty(t, changes.Deleted)
+		assert.Greater(t, changes.Cursor, full.Cursor)
+
+		deleted, err := repo.DeleteKey(userId, created.ID)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+
+		changes, err = repo.GetChanges(userId, changes.Cursor, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, changes.Changed)
+		assert.Equal(t, []string{created.ID}, changes.Deleted)
+
+		page, err := repo.GetChanges(userId, full.Cursor, 1)
+		assert.NoError(t, err)
+		assert.True(t, page.HasMore)
+		assert.Len(t, page.Changed, 1)
+
+		_, err = repo.GetChanges(userId, changes.Cursor+1, 10)
+		assert.ErrorIs(t, err, ErrCursorExpired)
+
+		purge := New(ctx, db, store, config.TieringConfig{}, config.SyncConfig{TombstoneRetention: -time.Hour})
+		purged, err := purge.DeleteOldTombstones()
+		assert.NoError(t, err)
+		assert.Positive(t, purged)
+
+		_, err = repo.GetChanges(userId, full.Cursor, 10)
+		assert.ErrorIs(t, err, ErrCursorExpired)
+
+		changes, err = repo.GetChanges(userId, 0, 1000)
+		assert.NoError(t, err)
+		assert.NotContains(t, changes.Deleted, created.ID)
+		assert.Len(t, changes.Changed, len(keys))
+	})
+
+	t.Run("Conflicts", func(t *testing.T) {
+		note := dto.KeyImput{
+			UserAddress:   "1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			EncryptedKey:  []byte("key"),
+			KeyIV:         []byte("key"),
+			EncryptedData: []byte("base"),
+			DataIV:        []byte("iv"),
+			DeviceID:      "phone",
+		}
+		created, err := repo.AddKey(userId, note, unlimited)
+		assert.NoError(t, err)
+		assert.Equal(t, vclock.Vector{"phone": 1}, created.Version)
+
+		phone := note
+		phone.EncryptedData = []byte("phone")
+		phone.Version = created.Version
+		updated, err := repo.UpdateKey(userId, created.ID, phone, unlimited)
+		assert.NoError(t, err)
+		assert.False(t, updated.Conflict)
+		assert.Equal(t, vclock.Vector{"phone": 2}, updated.Version)
+
+		laptop := note
+		laptop.DeviceID = "laptop"
+		laptop.EncryptedData = []byte("laptop")
+		laptop.Version = created.Version
+		stale, err := repo.UpdateKey(userId, created.ID, laptop, unlimited)
+		assert.NoError(t, err)
+		assert.True(t, stale.Conflict)
+		assert.Equal(t, 1, stale.Conflicts)
+		assert.Equal(t, []byte("phone"), stale.EncryptedData)
+
+		conflicts, err := repo.GetConflicts(created.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, conflicts, 1) {
+			assert.Equal(t, "laptop", conflicts[0].DeviceID)
+			assert.Equal(t, []byte("laptop"), conflicts[0].EncryptedData)
+		}
+
+		merged := note
+		merged.EncryptedData = []byte("merged")
+		merged.Version = vclock.Vector{"phone": 2, "laptop": 1}
+		resolved, err := repo.UpdateKey(userId, created.ID, merged, unlimited)
+		assert.NoError(t, err)
+		assert.False(t, resolved.Conflict)
+		assert.Zero(t, resolved.Conflicts)
+		assert.Equal(t, vclock.Vector{"phone": 3, "laptop": 1}, resolved.Version)
+
+		conflicts, err = repo.GetConflicts(created.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, conflicts)
+
+		for i := 0; i < maxConflicts; i++ {
+			_, err = repo.UpdateKey(userId, created.ID, laptop, unlimited)
+			assert.NoError(t, err)
+		}
+		_, err = repo.UpdateKey(userId, created.ID, laptop, unlimited)
+		assert.ErrorIs(t, err, ErrTooManyConflicts)
+
+		unversioned := note
+		unversioned.DeviceID = ""
+		unversioned.EncryptedData = []byte("plain")
+		plain, err := repo.UpdateKey(userId, created.ID, unversioned, unlimited)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), plain.Version[vclock.Unversioned])
+		assert.Equal(t, maxConflicts, plain.Conflicts)
+
+		deleted, err := repo.DeleteKey(userId, created.ID)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+	})
+}
+
+func quota(limits config.QuotaConfig) QuotaFunc {
+	return func(int64) (config.QuotaConfig, error) {
+		return limits, nil
+	}
 }
@@ -9,31 +9,564 @@ type statementsItem struct {
 }
 
 type statements struct {
-	addKey        statementsItem
-	getKeysByUser statementsItem
-	deleteKey     statementsItem
+	addKey              statementsItem
+	getKeysByUser       statementsItem
+	updateKey           statementsItem
+	deleteKey           statementsItem
+	deleteExpiredKeys   statementsItem
+	updateKeyData       statementsItem
+	createShare         statementsItem
+	getSharesByKey      statementsItem
+	deleteShare         statementsItem
+	getSharedKeys       statementsItem
+	getSharedKey        statementsItem
+	updateShareStatus   statementsItem
+	getKeyVault         statementsItem
+	getKeysByVault      statementsItem
+	updateVaultKey      statementsItem
+	deleteVaultKey      statementsItem
+	getKeyAccess        statementsItem
+	createRelease       statementsItem
+	getReleasesByKey    statementsItem
+	updateRelease       statementsItem
+	deleteRelease       statementsItem
+	releaseDueKeys      statementsItem
+	getOffloadable      statementsItem
+	offloadKeyData      statementsItem
+	getInlinable        statementsItem
+	inlineKeyData       statementsItem
+	lockUser            statementsItem
+	getUsage            statementsItem
+	getKeySize          statementsItem
+	getExportIds        statementsItem
+	getExportKey        statementsItem
+	getKeySnapshot      statementsItem
+	getKeyOperations    statementsItem
+	getImportTarget     statementsItem
+	importKey           statementsItem
+	overwriteKey        statementsItem
+	deleteSnapshot      statementsItem
+	deleteOperations    statementsItem
+	importSnapshot      statementsItem
+	importOperation     statementsItem
+	getKeysByIds        statementsItem
+	getSyncState        statementsItem
+	getChangedKeys      statementsItem
+	getTombstones       statementsItem
+	deleteTombstones    statementsItem
+	lockKeyVersion      statementsItem
+	getConflicts        statementsItem
+	getConflictVersions statementsItem
+	addConflict         statementsItem
+	deleteConflict      statementsItem
+	setConflicts        statementsItem
 }
 
+// Savepoints around the items of a batch that is not all-or-nothing.
+const (
+	savepointItem = `SAVEPOINT batch_item;`
+	rollbackItem  = `ROLLBACK TO SAVEPOINT batch_item;`
+	releaseItem   = `RELEASE SAVEPOINT batch_item;`
+)
+
 var statementsList = statements{
 	addKey: statementsItem{
 		name: "addKey",
 		query: `
-			INSERT INTO keys (user_id, user_address, encrypted_key, key_iv, encrypted_data, data_iv)
-        VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id, encrypted_key, key_iv, encrypted_data, data_iv, created_at;`,
+			INSERT INTO keys (user_id, user_address, encrypted_key, key_iv, encrypted_data, data_iv, expires_at,
+				vault_id, vault_key_version, data_ref, data_size, data_checksum, version_vector)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+        RETURNING id, encrypted_key, key_iv, encrypted_data, data_iv, created_at, expires_at, vault_id, vault_key_version,
+				version_vector, conflicts, data_ref, data_size, data_checksum;`,
 	},
 	getKeysByUser: statementsItem{
 		name: "getKeysByUser",
 		query: `
-      SELECT id, encrypted_key, key_iv, encrypted_data, data_iv, created_at
+      SELECT id, encrypted_key, key_iv, encrypted_data, data_iv, created_at, expires_at, vault_id, vault_key_version,
+        version_vector, conflicts, data_ref, data_size, data_checksum
       FROM keys
       WHERE user_id = $1
+      AND vault_id IS NULL
+      AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
 			ORDER BY created_at DESC;`,
 	},
+	updateKey: statementsItem{
+		name: "updateKey",
+		query: `
+			UPDATE keys
+			SET encrypted_key = $3, key_iv = $4, encrypted_data = $5, data_iv = $6, expires_at = $7,
+				data_ref = $8, data_size = $9, data_checksum = $10, version_vector = $11, conflicts = $12
+			WHERE id = $1
+			AND user_id = $2
+			AND vault_id IS NULL
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			RETURNING id, encrypted_key, key_iv, encrypted_data, data_iv, created_at, expires_at, vault_id, vault_key_version,
+				version_vector, conflicts, data_ref, data_size, data_checksum;`,
+	},
 	deleteKey: statementsItem{
 		name: "deleteKey",
 		query: `
 			DELETE FROM keys
+			WHERE id = $1
+			AND user_id = $2
+			AND vault_id IS NULL;`,
+	},
+	deleteExpiredKeys: statementsItem{
+		name: "deleteExpiredKeys",
+		query: `
+			DELETE FROM keys
+			WHERE expires_at IS NOT NULL
+			AND expires_at <= CURRENT_TIMESTAMP;`,
+	},
+	updateKeyData: statementsItem{
+		name: "updateKeyData",
+		query: `
+			UPDATE keys
+			SET encrypted_data = $2, data_iv = $3, data_ref = $4, data_size = $5, data_checksum = $6,
+				version_vector = version_vector || jsonb_build_object('unversioned', COALESCE((version_vector->>'unversioned')::bigint, 0) + 1)
+			WHERE id = $1
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			AND EXISTS (
+				SELECT 1
+				FROM key_shares
+				WHERE id = $7
+				AND key_id = keys.id
+				AND recipient_id = $8
+				AND permission = 'edit'
+				AND status = 'accepted'
+			);`,
+	},
+	createShare: statementsItem{
+		name: "createShare",
+		query: `
+			INSERT INTO key_shares (key_id, owner_id, recipient_id, encrypted_key, key_iv, permission)
+			SELECT id, user_id, $3, $4, $5, $6
+			FROM keys
+			WHERE id = $1
+			AND user_id = $2
+			AND vault_id IS NULL
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			ON CONFLICT (key_id, recipient_id) DO UPDATE
+			SET encrypted_key = EXCLUDED.encrypted_key, key_iv = EXCLUDED.key_iv,
+				permission = EXCLUDED.permission, status = 'pending', updated_at = CURRENT_TIMESTAMP
+			RETURNING id, key_id, permission, status, created_at;`,
+	},
+	getSharesByKey: statementsItem{
+		name: "getSharesByKey",
+		query: `
+			SELECT s.id, s.key_id, u.user_address, s.permission, s.status, s.created_at
+			FROM key_shares s
+			JOIN users u ON u.id = s.recipient_id
+			WHERE s.key_id = $1
+			AND s.owner_id = $2
+			ORDER BY s.created_at DESC;`,
+	},
+	deleteShare: statementsItem{
+		name: "deleteShare",
+		query: `
+			DELETE FROM key_shares
+			WHERE id = $1
+			AND key_id = $2
+			AND owner_id = $3
+			RETURNING recipient_id;`,
+	},
+	getSharedKeys: statementsItem{
+		name: "getSharedKeys",
+		query: `
+			SELECT s.id, k.id, k.user_address, s.encrypted_key, s.key_iv, k.encrypted_data, k.data_iv,
+				s.permission, s.status, k.created_at, k.expires_at, k.data_ref, k.data_size, k.data_checksum
+			FROM key_shares s
+			JOIN keys k ON k.id = s.key_id
+			WHERE s.recipient_id = $1
+			AND s.status <> 'declined'
+			AND (k.expires_at IS NULL OR k.expires_at > CURRENT_TIMESTAMP)
+			ORDER BY s.created_at DESC;`,
+	},
+	getSharedKey: statementsItem{
+		name: "getSharedKey",
+		query: `
+			SELECT s.id, k.id, k.user_address, s.encrypted_key, s.key_iv, k.encrypted_data, k.data_iv,
+				s.permission, s.status, k.created_at, k.expires_at, k.data_ref, k.data_size, k.data_checksum
+			FROM key_shares s
+			JOIN keys k ON k.id = s.key_id
+			WHERE s.id = $1
+			AND s.recipient_id = $2
+			AND (k.expires_at IS NULL OR k.expires_at > CURRENT_TIMESTAMP);`,
+	},
+	updateShareStatus: statementsItem{
+		name: "updateShareStatus",
+		query: `
+			UPDATE key_shares
+			SET status = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+			AND recipient_id = $2
+			RETURNING owner_id;`,
+	},
+	getKeyVault: statementsItem{
+		name: "getKeyVault",
+		query: `
+			SELECT vault_id
+			FROM keys
+			WHERE id = $1
+			AND vault_id IS NOT NULL
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP);`,
+	},
+	getKeysByVault: statementsItem{
+		name: "getKeysByVault",
+		query: `
+			SELECT id, encrypted_key, key_iv, encrypted_data, data_iv, created_at, expires_at, vault_id, vault_key_version,
+				version_vector, conflicts, data_ref, data_size, data_checksum
+			FROM keys
+			WHERE vault_id = $1
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			ORDER BY created_at DESC;`,
+	},
+	updateVaultKey: statementsItem{
+		name: "updateVaultKey",
+		query: `
+			UPDATE keys
+			SET encrypted_key = $3, key_iv = $4, encrypted_data = $5, data_iv = $6, expires_at = $7,
+				vault_key_version = COALESCE($8, vault_key_version), data_ref = $9, data_size = $10, data_checksum = $11,
+				version_vector = $12, conflicts = $13
+			WHERE id = $1
+			AND vault_id = $2
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			RETURNING id, encrypted_key, key_iv, encrypted_data, data_iv, created_at, expires_at, vault_id, vault_key_version,
+				version_vector, conflicts, data_ref, data_size, data_checksum;`,
+	},
+	deleteVaultKey: statementsItem{
+		name: "deleteVaultKey",
+		query: `
+			DELETE FROM keys
+			WHERE id = $1
+			AND vault_id = $2;`,
+	},
+	getKeyAccess: statementsItem{
+		name: "getKeyAccess",
+		query: `
+			SELECT k.user_id = $2 AND k.vault_id IS NULL, k.vault_id, s.permission
+			FROM keys k
+			LEFT JOIN key_shares s ON s.key_id = k.id AND s.recipient_id = $2 AND s.status = 'accepted'
+			WHERE k.id = $1
+			AND (k.expires_at IS NULL OR k.expires_at > CURRENT_TIMESTAMP);`,
+	},
+	createRelease: statementsItem{
+		name: "createRelease",
+		query: `
+			INSERT INTO key_releases (key_id, owner_id, recipient_id, encrypted_key, key_iv, permission, release_at)
+			SELECT id, user_id, $3, $4, $5, $6, $7
+			FROM keys
+			WHERE id = $1
+			AND user_id = $2
+			AND vault_id IS NULL
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			ON CONFLICT (key_id, recipient_id) DO UPDATE
+			SET encrypted_key = EXCLUDED.encrypted_key, key_iv = EXCLUDED.key_iv,
+				permission = EXCLUDED.permission, release_at = EXCLUDED.release_at, updated_at = CURRENT_TIMESTAMP
+			RETURNING id, key_id, permission, release_at, created_at;`,
+	},
+	getReleasesByKey: statementsItem{
+		name: "getReleasesByKey",
+		query: `
+			SELECT r.id, r.key_id, u.user_address, r.permission, r.release_at, r.created_at
+			FROM key_releases r
+			JOIN users u ON u.id = r.recipient_id
+			WHERE r.key_id = $1
+			AND r.owner_id = $2
+			ORDER BY r.release_at;`,
+	},
+	updateRelease: statementsItem{
+		name: "updateRelease",
+		query: `
+			WITH r AS (
+				UPDATE key_releases
+				SET release_at = $4, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1
+				AND key_id = $2
+				AND owner_id = $3
+				AND release_at > CURRENT_TIMESTAMP
+				RETURNING id, key_id, recipient_id, permission, release_at, created_at
+			)
+			SELECT r.id, r.key_id, u.user_address, r.permission, r.release_at, r.created_at
+			FROM r
+			JOIN users u ON u.id = r.recipient_id;`,
+	},
+	deleteRelease: statementsItem{
+		name: "deleteRelease",
+		query: `
+			DELETE FROM key_releases
+			WHERE id = $1
+			AND key_id = $2
+			AND owner_id = $3
+			AND release_at > CURRENT_TIMESTAMP;`,
+	},
+	releaseDueKeys: statementsItem{
+		name: "releaseDueKeys",
+		query: `
+			WITH due AS (
+				DELETE FROM key_releases
+				WHERE release_at <= CURRENT_TIMESTAMP
+				RETURNING key_id, owner_id, recipient_id, encrypted_key, key_iv, permission
+			)
+			INSERT INTO key_shares (key_id, owner_id, recipient_id, encrypted_key, key_iv, permission)
+			SELECT key_id, owner_id, recipient_id, encrypted_key, key_iv, permission
+			FROM due
+			ON CONFLICT (key_id, recipient_id) DO UPDATE
+			SET encrypted_key = EXCLUDED.encrypted_key, key_iv = EXCLUDED.key_iv,
+				permission = EXCLUDED.permission, status = 'pending', updated_at = CURRENT_TIMESTAMP
+			RETURNING id, recipient_id;`,
+	},
+	getOffloadable: statementsItem{
+		name: "getOffloadable",
+		query: `
+			SELECT id, encrypted_data
+			FROM keys
+			WHERE encrypted_data IS NOT NULL
+			AND $1 > 0
+			AND octet_length(encrypted_data) > $1
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED;`,
+	},
+	offloadKeyData: statementsItem{
+		name: "offloadKeyData",
+		query: `
+			UPDATE keys
+			SET encrypted_data = NULL, data_ref = $2, data_size = $3, data_checksum = $4
+			WHERE id = $1;`,
+	},
+	getInlinable: statementsItem{
+		name: "getInlinable",
+		query: `
+			SELECT id, data_ref, data_size, data_checksum
+			FROM keys
+			WHERE data_ref IS NOT NULL
+			AND ($1 <= 0 OR data_size <= $1)
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED;`,
+	},
+	inlineKeyData: statementsItem{
+		name: "inlineKeyData",
+		query: `
+			UPDATE keys
+			SET encrypted_data = $2, data_ref = NULL, data_size = NULL, data_checksum = NULL
+			WHERE id = $1;`,
+	},
+	lockUser: statementsItem{
+		name: "lockUser",
+		query: `
+			SELECT id
+			FROM users
+			WHERE id = $1
+			FOR NO KEY UPDATE;`,
+	},
+	getUsage: statementsItem{
+		name: "getUsage",
+		query: `
+			SELECT COUNT(*), COALESCE(SUM(COALESCE(data_size, octet_length(encrypted_data))), 0)
+				+ COALESCE((
+					SELECT SUM(COALESCE(c.data_size, octet_length(c.encrypted_data)))
+					FROM key_conflicts c
+					JOIN keys ck ON ck.id = c.key_id
+					WHERE ck.user_id = $1
+				), 0)
+			FROM keys
+			WHERE user_id = $1;`,
+	},
+	getKeySize: statementsItem{
+		name: "getKeySize",
+		query: `
+			SELECT user_id, COALESCE(data_size, octet_length(encrypted_data))
+			FROM keys
+			WHERE id = $1;`,
+	},
+	getExportIds: statementsItem{
+		name: "getExportIds",
+		query: `
+			SELECT id
+			FROM keys
+			WHERE user_id = $1
+			AND vault_id IS NULL
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			ORDER BY created_at, id;`,
+	},
+	getExportKey: statementsItem{
+		name: "getExportKey",
+		query: `
+			SELECT id, encrypted_key, key_iv, encrypted_data, data_iv, created_at, expires_at,
+				data_ref, data_size, data_checksum
+			FROM keys
+			WHERE id = $1
+			AND user_id = $2
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			FOR SHARE;`,
+	},
+	getKeySnapshot: statementsItem{
+		name: "getKeySnapshot",
+		query: `
+			SELECT seq, encrypted_snapshot, snapshot_iv, created_at
+			FROM key_snapshots
+			WHERE key_id = $1;`,
+	},
+	getKeyOperations: statementsItem{
+		name: "getKeyOperations",
+		query: `
+			SELECT o.seq, COALESCE(u.user_address, ''), o.encrypted_op, o.op_iv, o.created_at
+			FROM key_operations o
+			LEFT JOIN users u ON u.id = o.author_id
+			WHERE o.key_id = $1
+			ORDER BY o.seq;`,
+	},
+	getImportTarget: statementsItem{
+		name: "getImportTarget",
+		query: `
+			SELECT user_id, vault_id IS NULL, COALESCE(data_size, octet_length(encrypted_data))
+			FROM keys
+			WHERE id = $1
+			FOR UPDATE;`,
+	},
+	importKey: statementsItem{
+		name: "importKey",
+		query: `
+			INSERT INTO keys (id, user_id, user_address, encrypted_key, key_iv, encrypted_data, data_iv, created_at,
+				expires_at, data_ref, data_size, data_checksum, op_seq)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8::timestamp, CURRENT_TIMESTAMP), $9, $10, $11, $12, $13);`,
+	},
+	overwriteKey: statementsItem{
+		name: "overwriteKey",
+		query: `
+			UPDATE keys
+			SET encrypted_key = $2, key_iv = $3, encrypted_data = $4, data_iv = $5, expires_at = $6,
+				data_ref = $7, data_size = $8, data_checksum = $9, op_seq = $10,
+				version_vector = version_vector || jsonb_build_object('unversioned', COALESCE((version_vector->>'unversioned')::bigint, 0) + 1)
+			WHERE id = $1;`,
+	},
+	deleteSnapshot: statementsItem{
+		name: "deleteSnapshot",
+		query: `
+			DELETE FROM key_snapshots
+			WHERE key_id = $1;`,
+	},
+	deleteOperations: statementsItem{
+		name: "deleteOperations",
+		query: `
+			DELETE FROM key_operations
+			WHERE key_id = $1;`,
+	},
+	importSnapshot: statementsItem{
+		name: "importSnapshot",
+		query: `
+			INSERT INTO key_snapshots (key_id, seq, encrypted_snapshot, snapshot_iv, created_at)
+			VALUES ($1, $2, $3, $4, COALESCE($5::timestamp, CURRENT_TIMESTAMP));`,
+	},
+	importOperation: statementsItem{
+		name: "importOperation",
+		query: `
+			INSERT INTO key_operations (key_id, seq, author_id, encrypted_op, op_iv, created_at)
+			VALUES ($1, $2, (SELECT id FROM users WHERE user_address = $3), $4, $5,
+				COALESCE($6::timestamp, CURRENT_TIMESTAMP));`,
+	},
+	getKeysByIds: statementsItem{
+		name: "getKeysByIds",
+		query: `
+			SELECT id, encrypted_key, key_iv, encrypted_data, data_iv, created_at, expires_at, vault_id, vault_key_version,
+				version_vector, conflicts, data_ref, data_size, data_checksum
+			FROM keys
+			WHERE id = ANY($1::uuid[])
+			AND ((user_id = $2 AND vault_id IS NULL) OR vault_id IS NOT NULL)
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP);`,
+	},
+	getSyncState: statementsItem{
+		name: "getSyncState",
+		query: `
+			SELECT change_seq, sync_floor
+			FROM users
+			WHERE id = $1
+			FOR SHARE;`,
+	},
+	getChangedKeys: statementsItem{
+		name: "getChangedKeys",
+		query: `
+			SELECT id, change_seq, expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP,
+				encrypted_key, key_iv, encrypted_data, data_iv, created_at, expires_at, vault_id, vault_key_version,
+				version_vector, conflicts, data_ref, data_size, data_checksum
+			FROM keys
+			WHERE user_id = $1
+			AND vault_id IS NULL
+			AND change_seq > $2
+			ORDER BY change_seq
+			LIMIT $3;`,
+	},
+	getTombstones: statementsItem{
+		name: "getTombstones",
+		query: `
+			SELECT key_id, change_seq
+			FROM key_tombstones
+			WHERE user_id = $1
+			AND change_seq > $2
+			ORDER BY change_seq
+			LIMIT $3;`,
+	},
+	deleteTombstones: statementsItem{
+		name: "deleteTombstones",
+		query: `
+			WITH purged AS (
+				DELETE FROM key_tombstones
+				WHERE deleted_at < CURRENT_TIMESTAMP - make_interval(secs => $1)
+				RETURNING user_id, change_seq
+			), floors AS (
+				UPDATE users u
+				SET sync_floor = GREATEST(u.sync_floor, p.change_seq)
+				FROM (SELECT user_id, MAX(change_seq) AS change_seq FROM purged GROUP BY user_id) p
+				WHERE u.id = p.user_id
+			)
+			SELECT COUNT(*) FROM purged;`,
+	},
+	lockKeyVersion: statementsItem{
+		name: "lockKeyVersion",
+		query: `
+			SELECT version_vector, conflicts
+			FROM keys
+			WHERE id = $1
+			AND vault_id IS NOT DISTINCT FROM $2
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			FOR UPDATE;`,
+	},
+	getConflicts: statementsItem{
+		name: "getConflicts",
+		query: `
+			SELECT id, device_id, version_vector, encrypted_key, key_iv, encrypted_data, data_iv, created_at,
+				data_ref, data_size, data_checksum
+			FROM key_conflicts
+			WHERE key_id = $1
+			ORDER BY created_at, id;`,
+	},
+	getConflictVersions: statementsItem{
+		name: "getConflictVersions",
+		query: `
+			SELECT id, version_vector
+			FROM key_conflicts
+			WHERE key_id = $1;`,
+	},
+	addConflict: statementsItem{
+		name: "addConflict",
+		query: `
+			INSERT INTO key_conflicts (key_id, device_id, version_vector, encrypted_key, key_iv, encrypted_data, data_iv,
+				data_ref, data_size, data_checksum)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`,
+	},
+	deleteConflict: statementsItem{
+		name: "deleteConflict",
+		query: `
+			DELETE FROM key_conflicts
 			WHERE id = $1;`,
 	},
+	setConflicts: statementsItem{
+		name: "setConflicts",
+		query: `
+			UPDATE keys
+			SET conflicts = $2
+			WHERE id = $1
+			RETURNING id, encrypted_key, key_iv, encrypted_data, data_iv, created_at, expires_at, vault_id, vault_key_version,
+				version_vector, conflicts, data_ref, data_size, data_checksum;`,
+	},
 }
@@ -2,52 +2,157 @@ package service
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/ObscuraNote/api-general/internal/keys/archive"
 	"github.com/ObscuraNote/api-general/internal/keys/dto"
 	r "github.com/ObscuraNote/api-general/internal/keys/repository"
+	"github.com/ObscuraNote/api-general/internal/keys/vclock"
+	up "github.com/ObscuraNote/api-general/internal/uploads/service"
 	u "github.com/ObscuraNote/api-general/internal/users/service"
 	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/ObscuraNote/api-general/internal/utils/eventbus"
+	vDto "github.com/ObscuraNote/api-general/internal/vaults/dto"
+	v "github.com/ObscuraNote/api-general/internal/vaults/service"
+	"github.com/google/uuid"
 	"github.com/philippe-berto/logger"
 )
 
 var _ KeysService = (*Service)(nil)
 
+const (
+	maxBatchSize        = 500
+	defaultSyncPageSize = 500
+	maxSyncPageSize     = 1000
+)
+
 type (
 	KeysService interface {
 		AddKey(note dto.KeyImput) (*dto.KeyOutput, error)
 		GetKeysByUser(ctx context.Context, auth dto.AuthInput) ([]dto.KeyOutput, error)
+		UpdateKey(keyId string, note dto.KeyImput) (*dto.KeyOutput, error)
 		DeleteKey(keyId string, auth dto.AuthInput) error
+		ShareKey(keyId string, share dto.ShareInput) (*dto.ShareOutput, error)
+		GetKeyShares(keyId string, auth dto.AuthInput) ([]dto.ShareOutput, error)
+		RevokeShare(keyId, shareId string, auth dto.AuthInput) error
+		ScheduleRelease(keyId string, release dto.ReleaseInput) (*dto.ReleaseOutput, error)
+		GetKeyReleases(keyId string, auth dto.AuthInput) ([]dto.ReleaseOutput, error)
+		RescheduleRelease(keyId, releaseId string, input dto.RescheduleInput) (*dto.ReleaseOutput, error)
+		CancelRelease(keyId, releaseId string, auth dto.AuthInput) error
+		ReleaseDueKeys() (int64, error)
+		GetSharedKeys(auth dto.AuthInput) ([]dto.SharedKeyOutput, error)
+		RespondToShare(shareId string, accept bool, auth dto.AuthInput) error
+		UpdateSharedKey(shareId string, note dto.KeyImput) (*dto.SharedKeyOutput, error)
+		GetVaultKeys(vaultId string, auth dto.AuthInput) ([]dto.KeyOutput, error)
+		AuthorizeKey(keyId string, userId int64) (bool, error)
+		AuthorizeKeyWrite(keyId string, userId int64) error
+		ExportKeys(auth dto.AuthInput, w io.Writer) error
+		ImportKeys(auth dto.AuthInput, strategy string, body io.Reader) (*dto.ImportResult, error)
+		ExportPublicKey() dto.ExportPublicKey
+		WriteKeys(input dto.BatchWriteInput) (*dto.BatchOutput, error)
+		GetKeysByIds(auth dto.AuthInput, input dto.BatchIDsInput) (*dto.BatchOutput, error)
+		DeleteKeys(auth dto.AuthInput, input dto.BatchIDsInput) (*dto.BatchOutput, error)
+		Sync(auth dto.AuthInput, since, limit int64) (*dto.SyncOutput, error)
+		GetConflicts(keyId string, auth dto.AuthInput) ([]dto.ConflictOutput, error)
 	}
 
 	Service struct {
 		ctx context.Context
 		r   r.KeysRepository
 		us  u.UserService
-		log *logger.Logger
+		vs  v.VaultsService
+		ups up.UploadsService
+		bus eventbus.Bus
+		key ed25519.PrivateKey
+		// trusted are the signers, besides key, whose archives are imported.
+		trusted []ed25519.PublicKey
+		log     *logger.Logger
 	}
 )
 
-func New(ctx context.Context, log logger.Logger, repo r.KeysRepository, us u.UserService) Service {
-	return Service{
+func New(ctx context.Context, log logger.Logger, repo r.KeysRepository, us u.UserService, vs v.VaultsService, ups up.UploadsService,
+	bus eventbus.Bus, cfg config.ExportConfig) (Service, error) {
+	s := Service{
 		ctx: ctx,
 		log: &log,
 		r:   repo,
 		us:  us,
+		vs:  vs,
+		ups: ups,
+		bus: bus,
+	}
+
+	for _, encoded := range cfg.TrustedKeys {
+		trusted, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(trusted) != ed25519.PublicKeySize {
+			return Service{}, errors.New("keys: trusted export keys must be base64 encoded Ed25519 public keys")
+		}
+		s.trusted = append(s.trusted, trusted)
+	}
+
+	if cfg.SigningKey == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return Service{}, err
+		}
+		s.key = key
+		s.log.Warn("EXPORT_SIGNING_KEY is not set, export manifests are signed with an ephemeral key")
+
+		return s, nil
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(cfg.SigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return Service{}, errors.New("keys: export signing key must be a base64 encoded 32 byte seed")
 	}
+	s.key = ed25519.NewKeyFromSeed(seed)
+
+	return s, nil
 }
 
 func (s *Service) AddKey(note dto.KeyImput) (*dto.KeyOutput, error) {
+	if err := validateExpiration(note.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if err := validateVersion(note); err != nil {
+		return nil, err
+	}
+
 	userId, err := s.getUserId(note.UserAddress, note.Password)
 	if err != nil {
 		return nil, err
 	}
 
-	createdKey, err := s.r.AddKey(userId, note)
+	if note.VaultID != "" {
+		if err := s.vs.RequireRole(note.VaultID, userId, vDto.RoleEditor); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.loadUpload(&note, userId); err != nil {
+		return nil, err
+	}
+
+	createdKey, err := s.r.AddKey(userId, note, s.quotaOf)
+	if errors.Is(err, r.ErrQuotaExceeded) {
+		return nil, fmt.Errorf(utils.QuotaExceeded)
+	}
 	if err != nil {
 		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "AddKey"}).Error(utils.ErrDatabase)
 		return nil, fmt.Errorf(utils.ErrDatabase)
 	}
+	s.releaseUpload(note, userId)
+	s.publishKey(userId, note.VaultID, eventbus.KeyCreated, createdKey.ID)
 
 	return createdKey, nil
 }
@@ -66,31 +171,1119 @@ func (s *Service) GetKeysByUser(ctx context.Context, auth dto.AuthInput) ([]dto.
 	return keys, nil
 }
 
+// UpdateKey replaces an entry. A write that names its device and the
+// version it is based on is kept as a conflict when that version does not
+// cover the stored one; the stored entry is then returned with Conflict set.
+func (s *Service) UpdateKey(keyId string, note dto.KeyImput) (*dto.KeyOutput, error) {
+	if err := validateExpiration(note.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if err := validateVersion(note); err != nil {
+		return nil, err
+	}
+
+	userId, err := s.getUserId(note.UserAddress, note.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	vaultId, err := s.getKeyVault(keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadUpload(&note, userId); err != nil {
+		return nil, err
+	}
+
+	var updatedKey *dto.KeyOutput
+	if vaultId != "" {
+		if err := s.vs.RequireRole(vaultId, userId, vDto.RoleEditor); err != nil {
+			return nil, err
+		}
+		updatedKey, err = s.r.UpdateVaultKey(vaultId, keyId, note, s.quotaOf)
+	} else {
+		updatedKey, err = s.r.UpdateKey(userId, keyId, note, s.quotaOf)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.KeyNotFound)
+	}
+	if errors.Is(err, r.ErrQuotaExceeded) {
+		return nil, fmt.Errorf(utils.QuotaExceeded)
+	}
+	if errors.Is(err, r.ErrTooManyConflicts) {
+		return nil, fmt.Errorf(utils.VersionConflict)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "UpdateKey"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+	s.releaseUpload(note, userId)
+	s.publishKey(userId, vaultId, eventbus.KeyUpdated, keyId)
+
+	return updatedKey, nil
+}
+
 func (s *Service) DeleteKey(keyId string, auth dto.AuthInput) error {
-	exists, err := s.us.CheckUserExists(auth.UserAddress, auth.Password)
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
 	if err != nil {
-		return fmt.Errorf(utils.ErrUnauthorized)
+		return err
 	}
 
-	if exists {
-		err = s.r.DeleteKey(keyId)
-		if err != nil {
-			s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "DeleteKey"}).Error(utils.ErrDatabase)
-			return fmt.Errorf(utils.ErrDatabase)
+	vaultId, err := s.getKeyVault(keyId)
+	if err != nil {
+		return err
+	}
+
+	var deleted bool
+	if vaultId != "" {
+		if err := s.vs.RequireRole(vaultId, userId, vDto.RoleEditor); err != nil {
+			return err
 		}
+		deleted, err = s.r.DeleteVaultKey(vaultId, keyId)
+	} else {
+		deleted, err = s.r.DeleteKey(userId, keyId)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "DeleteKey"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
 	}
 
+	if !deleted {
+		return fmt.Errorf(utils.KeyNotFound)
+	}
+	s.publishKey(userId, vaultId, eventbus.KeyDeleted, keyId)
+
 	return nil
 }
 
-func (s *Service) getUserId(userAddress, password string) (int64, error) {
-	userId, err := s.us.GetUserId(userAddress, password)
+// ShareKey grants another user access to one of the caller's entries. Sharing
+// the same entry again with the same recipient replaces the previous grant and
+// asks the recipient to accept it again.
+func (s *Service) ShareKey(keyId string, share dto.ShareInput) (*dto.ShareOutput, error) {
+	if share.Permission == "" {
+		share.Permission = dto.PermissionRead
+	}
+	if share.RecipientAddress == "" || len(share.EncryptedKey) == 0 ||
+		(share.Permission != dto.PermissionRead && share.Permission != dto.PermissionEdit) {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	ownerId, err := s.getUserId(share.UserAddress, share.Password)
 	if err != nil {
-		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "getUserId"}).Error(utils.ErrDatabase)
-		return 0, fmt.Errorf(utils.ErrDatabase)
+		return nil, err
 	}
-	if userId <= 0 {
-		return 0, fmt.Errorf(utils.ErrUnauthorized)
+
+	if err := s.requireSharing(ownerId); err != nil {
+		return nil, err
 	}
-	return userId, nil
+
+	recipientId, err := s.us.GetUserIdByAddress(share.RecipientAddress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.UserNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if recipientId == ownerId {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	created, err := s.r.CreateShare(ownerId, recipientId, keyId, share)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.KeyNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "ShareKey"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+	s.publish(recipientId, eventbus.ShareReceived, created.ID)
+
+	return created, nil
+}
+
+func (s *Service) GetKeyShares(keyId string, auth dto.AuthInput) ([]dto.ShareOutput, error) {
+	ownerId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := s.r.GetSharesByKey(ownerId, keyId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "GetKeyShares"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return shares, nil
+}
+
+func (s *Service) RevokeShare(keyId, shareId string, auth dto.AuthInput) error {
+	ownerId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	recipientId, err := s.r.DeleteShare(ownerId, keyId, shareId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf(utils.ShareNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "RevokeShare"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	s.publish(recipientId, eventbus.ShareRevoked, shareId)
+
+	return nil
+}
+
+// ScheduleRelease shares one of the caller's entries with a recipient at a
+// later date. Until then the recipient cannot see it; a background job turns
+// due releases into pending shares.
+func (s *Service) ScheduleRelease(keyId string, release dto.ReleaseInput) (*dto.ReleaseOutput, error) {
+	if release.Permission == "" {
+		release.Permission = dto.PermissionRead
+	}
+	if release.RecipientAddress == "" || len(release.EncryptedKey) == 0 ||
+		(release.Permission != dto.PermissionRead && release.Permission != dto.PermissionEdit) {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if err := validateReleaseAt(release.ReleaseAt); err != nil {
+		return nil, err
+	}
+
+	ownerId, err := s.getUserId(release.UserAddress, release.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.requireSharing(ownerId); err != nil {
+		return nil, err
+	}
+
+	recipientId, err := s.us.GetUserIdByAddress(release.RecipientAddress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.UserNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if recipientId == ownerId {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	created, err := s.r.CreateRelease(ownerId, recipientId, keyId, release)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.KeyNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "ScheduleRelease"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return created, nil
+}
+
+func (s *Service) GetKeyReleases(keyId string, auth dto.AuthInput) ([]dto.ReleaseOutput, error) {
+	ownerId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := s.r.GetReleasesByKey(ownerId, keyId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "GetKeyReleases"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return releases, nil
+}
+
+// RescheduleRelease moves a release that is not due yet. Releases already due
+// are reported as missing, even when the job has not picked them up.
+func (s *Service) RescheduleRelease(keyId, releaseId string, input dto.RescheduleInput) (*dto.ReleaseOutput, error) {
+	if err := validateReleaseAt(input.ReleaseAt); err != nil {
+		return nil, err
+	}
+
+	ownerId, err := s.getUserId(input.UserAddress, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.r.UpdateRelease(ownerId, keyId, releaseId, input.ReleaseAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.ReleaseNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "RescheduleRelease"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return updated, nil
+}
+
+func (s *Service) CancelRelease(keyId, releaseId string, auth dto.AuthInput) error {
+	ownerId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.r.DeleteRelease(ownerId, keyId, releaseId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "CancelRelease"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if !deleted {
+		return fmt.Errorf(utils.ReleaseNotFound)
+	}
+
+	return nil
+}
+
+func (s *Service) GetSharedKeys(auth dto.AuthInput) ([]dto.SharedKeyOutput, error) {
+	recipientId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.r.GetSharedKeys(recipientId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "GetSharedKeys"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return keys, nil
+}
+
+func (s *Service) RespondToShare(shareId string, accept bool, auth dto.AuthInput) error {
+	recipientId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	status := dto.ShareStatusDeclined
+	if accept {
+		status = dto.ShareStatusAccepted
+	}
+
+	ownerId, err := s.r.UpdateShareStatus(recipientId, shareId, status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf(utils.ShareNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "RespondToShare"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	s.publish(ownerId, eventbus.ShareUpdated, shareId)
+
+	return nil
+}
+
+// UpdateSharedKey lets a recipient replace the data of an entry shared with
+// them. Only accepted shares with edit permission may write; the entry key and
+// expiration stay under the owner's control.
+func (s *Service) UpdateSharedKey(shareId string, note dto.KeyImput) (*dto.SharedKeyOutput, error) {
+	recipientId, err := s.getUserId(note.UserAddress, note.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := s.getSharedKey(recipientId, shareId)
+	if err != nil {
+		return nil, err
+	}
+
+	if shared.Status != dto.ShareStatusAccepted || shared.Permission != dto.PermissionEdit {
+		return nil, fmt.Errorf(utils.Forbidden)
+	}
+
+	if err := s.loadUpload(&note, recipientId); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.r.UpdateKeyData(shared.KeyID, shareId, recipientId, note.EncryptedData, note.DataIV, s.quotaOf)
+	if errors.Is(err, r.ErrQuotaExceeded) {
+		return nil, fmt.Errorf(utils.QuotaExceeded)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "UpdateSharedKey"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if !updated {
+		return nil, fmt.Errorf(utils.ShareNotFound)
+	}
+	s.releaseUpload(note, recipientId)
+
+	ownerId, err := s.us.GetUserIdByAddress(shared.OwnerAddress)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "UpdateSharedKey"}).
+			Error("Failed to find entry owner")
+	} else {
+		s.publish(ownerId, eventbus.KeyUpdated, shared.KeyID)
+	}
+
+	return s.getSharedKey(recipientId, shareId)
+}
+
+// GetVaultKeys lists the entries of a vault the caller is an accepted member
+// of. Entries are returned as stored; clients unwrap them with the vault key.
+func (s *Service) GetVaultKeys(vaultId string, auth dto.AuthInput) ([]dto.KeyOutput, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.vs.RequireRole(vaultId, userId, vDto.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.r.GetKeysByVault(vaultId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "GetVaultKeys"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return keys, nil
+}
+
+// AuthorizeKey checks that userId can read an entry, as its owner, through an
+// accepted share or as a vault member. It reports whether the user also
+// moderates the entry, which holds for the owner and for vault admins.
+// Users without access get KEY_NOT_FOUND.
+func (s *Service) AuthorizeKey(keyId string, userId int64) (bool, error) {
+	access, err := s.r.GetKeyAccess(userId, keyId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf(utils.KeyNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "AuthorizeKey"}).Error(utils.ErrDatabase)
+		return false, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if access.IsOwner {
+		return true, nil
+	}
+
+	if access.VaultID != nil {
+		err := s.vs.RequireRole(*access.VaultID, userId, vDto.RoleAdmin)
+		if err == nil {
+			return true, nil
+		}
+
+		switch err.Error() {
+		case utils.Forbidden:
+			return false, nil
+		case utils.VaultNotFound:
+			return false, fmt.Errorf(utils.KeyNotFound)
+		default:
+			return false, err
+		}
+	}
+
+	if access.Permission != nil {
+		return false, nil
+	}
+
+	return false, fmt.Errorf(utils.KeyNotFound)
+}
+
+// AuthorizeKeyWrite checks that userId may change an entry: its owner, a
+// recipient of an accepted edit share, or a vault editor. Readers get
+// FORBIDDEN and users without access get KEY_NOT_FOUND.
+func (s *Service) AuthorizeKeyWrite(keyId string, userId int64) error {
+	access, err := s.r.GetKeyAccess(userId, keyId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf(utils.KeyNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "AuthorizeKeyWrite"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if access.IsOwner {
+		return nil
+	}
+
+	if access.VaultID != nil {
+		err := s.vs.RequireRole(*access.VaultID, userId, vDto.RoleEditor)
+		if err != nil && err.Error() == utils.VaultNotFound {
+			return fmt.Errorf(utils.KeyNotFound)
+		}
+
+		return err
+	}
+
+	if access.Permission == nil {
+		return fmt.Errorf(utils.KeyNotFound)
+	}
+
+	if *access.Permission != dto.PermissionEdit {
+		return fmt.Errorf(utils.Forbidden)
+	}
+
+	return nil
+}
+
+// ExportKeys writes an archive of the caller's personal entries and their
+// history to w. Nothing is written when the caller cannot be authenticated;
+// after that, a failure leaves a truncated archive without a manifest.
+func (s *Service) ExportKeys(auth dto.AuthInput, w io.Writer) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	aw, err := archive.NewWriter(w, s.key, auth.UserAddress)
+	if err != nil {
+		return err
+	}
+
+	var writeErr error
+	err = s.r.ExportKeys(userId, func(entry archive.Entry) error {
+		writeErr = aw.WriteEntry(entry)
+		return writeErr
+	})
+	if writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "ExportKeys"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return aw.Close()
+}
+
+// ImportKeys restores the entries of an archive for the caller. Only intact
+// archives signed by this server or a trusted one are accepted. Entries are
+// restored one by one, so a failure keeps the ones before it; importing the
+// same archive again is harmless with every strategy. Expired entries are
+// skipped.
+func (s *Service) ImportKeys(auth dto.AuthInput, strategy string, body io.Reader) (*dto.ImportResult, error) {
+	if strategy == "" {
+		strategy = dto.ImportSkip
+	}
+	if strategy != dto.ImportSkip && strategy != dto.ImportOverwrite && strategy != dto.ImportDuplicate {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := archive.Read(body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, fmt.Errorf(utils.PayloadTooLarge)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(utils.InvalidArchive)
+	}
+	if !s.trustedSigner(imported.Manifest.PublicKey) {
+		return nil, fmt.Errorf(utils.UntrustedSigner)
+	}
+	for _, entry := range imported.Entries {
+		if !validEntry(entry) {
+			return nil, fmt.Errorf(utils.InvalidArchive)
+		}
+	}
+
+	result := dto.ImportResult{SignerKey: imported.Manifest.PublicKey}
+	for _, entry := range imported.Entries {
+		if expired(entry) {
+			result.Skipped++
+			continue
+		}
+
+		// The duplicate of an entry gets the same ID every time this archive
+		// is imported.
+		duplicateId := uuid.NewSHA1(uuid.MustParse(entry.ID), imported.Manifest.Digest).String()
+		outcome, err := s.r.ImportKey(userId, auth.UserAddress, entry, duplicateId, strategy, s.quotaOf)
+		if errors.Is(err, r.ErrQuotaExceeded) {
+			return nil, fmt.Errorf(utils.QuotaExceeded)
+		}
+		if err != nil {
+			s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "ImportKeys"}).Error(utils.ErrDatabase)
+			return nil, fmt.Errorf(utils.ErrDatabase)
+		}
+
+		switch outcome {
+		case dto.ImportOutcomeImported:
+			result.Imported++
+			s.publish(userId, eventbus.KeyCreated, entry.ID)
+		case dto.ImportOutcomeOverwritten:
+			result.Overwritten++
+			s.publish(userId, eventbus.KeyUpdated, entry.ID)
+		case dto.ImportOutcomeDuplicated:
+			result.Duplicated++
+			s.publish(userId, eventbus.KeyCreated, duplicateId)
+		default:
+			result.Skipped++
+		}
+	}
+
+	return &result, nil
+}
+
+// trustedSigner reports whether archives signed with publicKey may be
+// imported.
+func (s *Service) trustedSigner(publicKey []byte) bool {
+	if s.key.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(publicKey)) {
+		return true
+	}
+
+	for _, trusted := range s.trusted {
+		if trusted.Equal(ed25519.PublicKey(publicKey)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReleaseDueKeys turns due releases into pending shares and tells each
+// recipient about theirs.
+func (s *Service) ReleaseDueKeys() (int64, error) {
+	released, err := s.r.ReleaseDueKeys()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, share := range released {
+		s.publish(share.RecipientID, eventbus.ShareReceived, share.ShareID)
+	}
+
+	return int64(len(released)), nil
+}
+
+// ExportPublicKey returns the key export manifests of this server are
+// signed with.
+func (s *Service) ExportPublicKey() dto.ExportPublicKey {
+	return dto.ExportPublicKey{PublicKey: s.key.Public().(ed25519.PublicKey)}
+}
+
+// WriteKeys creates and updates entries in one transaction. Every item goes
+// through the checks of AddKey or UpdateKey; the ones that fail them are
+// reported without being written.
+func (s *Service) WriteKeys(input dto.BatchWriteInput) (*dto.BatchOutput, error) {
+	if len(input.Items) == 0 || len(input.Items) > maxBatchSize {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	userId, err := s.getUserId(input.UserAddress, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]dto.BatchItemResult, len(input.Items))
+	var writes []r.KeyWrite
+	var indexes []int
+	for i, item := range input.Items {
+		results[i] = dto.BatchItemResult{Index: i, ID: item.ID}
+		item.UserAddress = input.UserAddress
+
+		write, err := s.prepareWrite(userId, item)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		writes = append(writes, write)
+		indexes = append(indexes, i)
+	}
+	if len(writes) == 0 || (input.Atomic && len(writes) < len(input.Items)) {
+		return batchOutput(results, input.Atomic), nil
+	}
+
+	keys, errs, err := s.r.WriteKeys(userId, writes, input.Atomic, s.quotaOf)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "WriteKeys"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	for j, i := range indexes {
+		switch {
+		case errors.Is(errs[j], sql.ErrNoRows):
+			results[i].Error = utils.KeyNotFound
+		case errors.Is(errs[j], r.ErrQuotaExceeded):
+			results[i].Error = utils.QuotaExceeded
+		case errors.Is(errs[j], r.ErrTooManyConflicts):
+			results[i].Error = utils.VersionConflict
+		case keys != nil:
+			results[i].ID = keys[j].ID
+			results[i].Key = keys[j]
+			s.releaseUpload(writes[j].Note, userId)
+		}
+	}
+
+	output := batchOutput(results, input.Atomic)
+	for j, i := range indexes {
+		if output.Results[i].Error != "" {
+			continue
+		}
+		if writes[j].ID == "" {
+			s.publishKey(userId, writes[j].VaultID, eventbus.KeyCreated, output.Results[i].ID)
+		} else {
+			s.publishKey(userId, writes[j].VaultID, eventbus.KeyUpdated, output.Results[i].ID)
+		}
+	}
+
+	return output, nil
+}
+
+// GetKeysByIds returns the entries the caller can read among input.IDs:
+// their personal entries and the entries of their vaults. The others are
+// reported as not found.
+func (s *Service) GetKeysByIds(auth dto.AuthInput, input dto.BatchIDsInput) (*dto.BatchOutput, error) {
+	if len(input.IDs) == 0 || len(input.IDs) > maxBatchSize {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(input.IDs))
+	for _, id := range input.IDs {
+		if parsed, err := uuid.Parse(id); err == nil {
+			ids = append(ids, parsed.String())
+		}
+	}
+
+	var keys []dto.KeyOutput
+	if len(ids) > 0 {
+		if keys, err = s.r.GetKeysByIds(userId, ids); err != nil {
+			s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "GetKeysByIds"}).Error(utils.ErrDatabase)
+			return nil, fmt.Errorf(utils.ErrDatabase)
+		}
+	}
+
+	found := make(map[string]*dto.KeyOutput, len(keys))
+	readable := make(map[string]bool)
+	for i := range keys {
+		if vaultId := keys[i].VaultID; vaultId != nil {
+			ok, checked := readable[*vaultId]
+			if !checked {
+				err := s.vs.RequireRole(*vaultId, userId, vDto.RoleViewer)
+				if err != nil && err.Error() == utils.ErrDatabase {
+					return nil, err
+				}
+				ok = err == nil
+				readable[*vaultId] = ok
+			}
+			if !ok {
+				continue
+			}
+		}
+		found[keys[i].ID] = &keys[i]
+	}
+
+	results := make([]dto.BatchItemResult, len(input.IDs))
+	for i, id := range input.IDs {
+		results[i] = dto.BatchItemResult{Index: i, ID: id, Error: utils.KeyNotFound}
+		if parsed, err := uuid.Parse(id); err == nil && found[parsed.String()] != nil {
+			results[i].Key = found[parsed.String()]
+			results[i].Error = ""
+		}
+	}
+
+	return batchOutput(results, input.Atomic), nil
+}
+
+// DeleteKeys deletes entries in one transaction, with the checks of
+// DeleteKey for each of them.
+func (s *Service) DeleteKeys(auth dto.AuthInput, input dto.BatchIDsInput) (*dto.BatchOutput, error) {
+	if len(input.IDs) == 0 || len(input.IDs) > maxBatchSize {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]dto.BatchItemResult, len(input.IDs))
+	var refs []r.KeyRef
+	var indexes []int
+	for i, id := range input.IDs {
+		results[i] = dto.BatchItemResult{Index: i, ID: id}
+
+		ref, err := s.prepareDelete(userId, id)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		refs = append(refs, ref)
+		indexes = append(indexes, i)
+	}
+	if len(refs) == 0 || (input.Atomic && len(refs) < len(input.IDs)) {
+		return batchOutput(results, input.Atomic), nil
+	}
+
+	errs, err := s.r.DeleteKeys(userId, refs, input.Atomic)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "DeleteKeys"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	for j, i := range indexes {
+		if errs[j] != nil {
+			results[i].Error = utils.KeyNotFound
+		}
+	}
+
+	output := batchOutput(results, input.Atomic)
+	for j, i := range indexes {
+		if output.Results[i].Error == "" {
+			s.publishKey(userId, refs[j].VaultID, eventbus.KeyDeleted, refs[j].ID)
+		}
+	}
+
+	return output, nil
+}
+
+// Sync returns what changed in the caller's personal entries after the
+// cursor since; zero starts a full sync. Entries that expire are reported
+// as deleted at their next change, or once the reaper removes them. A cursor
+// older than the tombstone retention gets CURSOR_EXPIRED, and the client
+// starts over from zero.
+func (s *Service) Sync(auth dto.AuthInput, since, limit int64) (*dto.SyncOutput, error) {
+	if since < 0 || limit < 0 {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if limit == 0 {
+		limit = defaultSyncPageSize
+	}
+	if limit > maxSyncPageSize {
+		limit = maxSyncPageSize
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s.r.GetChanges(userId, since, int(limit))
+	if errors.Is(err, r.ErrCursorExpired) {
+		return nil, fmt.Errorf(utils.CursorExpired)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "Sync"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return output, nil
+}
+
+// GetConflicts returns the versions of an entry written concurrently with
+// the stored one, to anyone who can read the entry.
+func (s *Service) GetConflicts(keyId string, auth dto.AuthInput) ([]dto.ConflictOutput, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.AuthorizeKey(keyId, userId); err != nil {
+		return nil, err
+	}
+
+	conflicts, err := s.r.GetConflicts(keyId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "GetConflicts"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return conflicts, nil
+}
+
+// prepareWrite makes the checks AddKey and UpdateKey make before writing.
+func (s *Service) prepareWrite(userId int64, item dto.BatchWriteItem) (r.KeyWrite, error) {
+	if err := validateExpiration(item.ExpiresAt); err != nil {
+		return r.KeyWrite{}, err
+	}
+	if err := validateVersion(item.KeyImput); err != nil {
+		return r.KeyWrite{}, err
+	}
+
+	write := r.KeyWrite{ID: item.ID, Note: item.KeyImput}
+	switch {
+	case item.ID != "":
+		if _, err := uuid.Parse(item.ID); err != nil {
+			return r.KeyWrite{}, fmt.Errorf(utils.BadRequest)
+		}
+		vaultId, err := s.getKeyVault(item.ID)
+		if err != nil {
+			return r.KeyWrite{}, err
+		}
+		if vaultId != "" {
+			if err := s.vs.RequireRole(vaultId, userId, vDto.RoleEditor); err != nil {
+				return r.KeyWrite{}, err
+			}
+		}
+		write.VaultID = vaultId
+	case item.VaultID != "":
+		if err := s.vs.RequireRole(item.VaultID, userId, vDto.RoleEditor); err != nil {
+			return r.KeyWrite{}, err
+		}
+	}
+
+	if err := s.loadUpload(&write.Note, userId); err != nil {
+		return r.KeyWrite{}, err
+	}
+
+	return write, nil
+}
+
+// prepareDelete makes the checks DeleteKey makes before deleting.
+func (s *Service) prepareDelete(userId int64, id string) (r.KeyRef, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return r.KeyRef{}, fmt.Errorf(utils.BadRequest)
+	}
+
+	vaultId, err := s.getKeyVault(id)
+	if err != nil {
+		return r.KeyRef{}, err
+	}
+	if vaultId != "" {
+		if err := s.vs.RequireRole(vaultId, userId, vDto.RoleEditor); err != nil {
+			return r.KeyRef{}, err
+		}
+	}
+
+	return r.KeyRef{ID: id, VaultID: vaultId}, nil
+}
+
+// batchOutput completes the results of a batch. When an atomic batch is not
+// complete, the items that did not fail themselves are reported as aborted.
+func batchOutput(results []dto.BatchItemResult, atomic bool) *dto.BatchOutput {
+	complete := true
+	for _, result := range results {
+		if result.Error != "" {
+			complete = false
+		}
+	}
+
+	if atomic && !complete {
+		for i := range results {
+			results[i].Key = nil
+			if results[i].Error == "" {
+				results[i].Error = utils.BatchAborted
+			}
+		}
+	}
+
+	return &dto.BatchOutput{Complete: complete, Results: results}
+}
+
+// publish announces a committed change of userId's entries or shares. The
+// change is already stored, so a failure is only logged: subscribers catch up
+// on their next poll.
+func (s *Service) publish(userId int64, eventType, subject string) {
+	event := eventbus.Event{UserID: userId, Type: eventType, Subject: subject}
+	if err := s.bus.Publish(s.ctx, event); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "publish"}).
+			Error("Failed to publish event")
+	}
+}
+
+// publishKey announces a committed change of an entry to whoever can read it:
+// the owner of a personal entry, or every accepted member of the vault of a
+// vault entry.
+func (s *Service) publishKey(userId int64, vaultId, eventType, keyId string) {
+	if vaultId == "" {
+		s.publish(userId, eventType, keyId)
+		return
+	}
+
+	memberIds, err := s.vs.GetMemberIds(vaultId)
+	if err != nil {
+		return
+	}
+	for _, memberId := range memberIds {
+		s.publish(memberId, eventType, keyId)
+	}
+}
+
+// getKeyVault returns the vault of a vault entry, or an empty string for
+// personal entries and entries that do not exist.
+func (s *Service) getKeyVault(keyId string) (string, error) {
+	vaultId, err := s.r.GetKeyVault(keyId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "getKeyVault"}).Error(utils.ErrDatabase)
+		return "", fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return vaultId, nil
+}
+
+func (s *Service) getSharedKey(recipientId int64, shareId string) (*dto.SharedKeyOutput, error) {
+	shared, err := s.r.GetSharedKey(recipientId, shareId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.ShareNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "getSharedKey"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return shared, nil
+}
+
+// loadUpload fills the entry payload from a completed resumable upload of
+// the caller when the note names one instead of carrying the data inline.
+func (s *Service) loadUpload(note *dto.KeyImput, userId int64) error {
+	if note.UploadID == "" {
+		return nil
+	}
+	if len(note.EncryptedData) > 0 {
+		return fmt.Errorf(utils.BadRequest)
+	}
+
+	data, err := s.ups.ReadUpload(note.UploadID, userId)
+	if err != nil {
+		return err
+	}
+	note.EncryptedData = data
+
+	return nil
+}
+
+// releaseUpload discards an upload once the entry stores its content. A
+// failure only leaves the upload to expire.
+func (s *Service) releaseUpload(note dto.KeyImput, userId int64) {
+	if note.UploadID == "" {
+		return
+	}
+
+	_ = s.ups.ReleaseUpload(note.UploadID, userId)
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "keys_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
+
+// quotaOf returns the storage limits of ownerId's plan. The repository calls
+// it for the owner of the entry, who is not always the caller.
+func (s *Service) quotaOf(ownerId int64) (config.QuotaConfig, error) {
+	entitlements, err := s.us.GetEntitlements(ownerId)
+	if err != nil {
+		return config.QuotaConfig{}, err
+	}
+
+	return config.QuotaConfig{
+		MaxEntries:         entitlements.MaxEntries,
+		MaxBytes:           entitlements.MaxBytes,
+		MaxAttachmentBytes: entitlements.MaxAttachmentBytes,
+	}, nil
+}
+
+// requireSharing returns NOT_ENTITLED when the plan of userId does not
+// allow handing entries to other accounts.
+func (s *Service) requireSharing(userId int64) error {
+	entitlements, err := s.us.GetEntitlements(userId)
+	if err != nil {
+		return err
+	}
+	if !entitlements.SharingAllowed {
+		return fmt.Errorf(utils.NotEntitled)
+	}
+
+	return nil
+}
+
+// validEntry checks what the signature of an archive cannot: that an entry
+// was well formed when it was signed.
+func validEntry(entry archive.Entry) bool {
+	if _, err := uuid.Parse(entry.ID); err != nil {
+		return false
+	}
+	if !validTime(entry.CreatedAt) || (entry.ExpiresAt != nil && !validTime(*entry.ExpiresAt)) {
+		return false
+	}
+	if entry.History == nil {
+		return true
+	}
+
+	var seq int64
+	if snapshot := entry.History.Snapshot; snapshot != nil {
+		if snapshot.Seq <= 0 || len(snapshot.EncryptedSnapshot) == 0 || !validTime(snapshot.CreatedAt) {
+			return false
+		}
+		seq = snapshot.Seq
+	}
+	for _, operation := range entry.History.Operations {
+		if operation.Seq <= seq || len(operation.EncryptedOp) == 0 || !validTime(operation.CreatedAt) {
+			return false
+		}
+		seq = operation.Seq
+	}
+
+	return true
+}
+
+// validTime accepts the timestamps of an archive, where empty means now.
+func validTime(value string) bool {
+	if value == "" {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339Nano, value)
+
+	return err == nil
+}
+
+func expired(entry archive.Entry) bool {
+	if entry.ExpiresAt == nil {
+		return false
+	}
+	expiresAt, _ := time.Parse(time.RFC3339Nano, *entry.ExpiresAt)
+
+	return !expiresAt.After(time.Now())
+}
+
+func validateExpiration(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return fmt.Errorf(utils.InvalidExpiration)
+	}
+	return nil
+}
+
+// validateVersion checks the device and base version of a versioned write.
+// Writes without a device are not versioned and must not send a version.
+func validateVersion(note dto.KeyImput) error {
+	if note.DeviceID == "" {
+		if note.Version != nil {
+			return fmt.Errorf(utils.BadRequest)
+		}
+		return nil
+	}
+	if !vclock.ValidDevice(note.DeviceID) || !note.Version.Valid() {
+		return fmt.Errorf(utils.BadRequest)
+	}
+	return nil
+}
+
+func validateReleaseAt(releaseAt time.Time) error {
+	if !releaseAt.After(time.Now()) {
+		return fmt.Errorf(utils.InvalidReleaseAt)
+	}
+	return nil
 }
@@ -0,0 +1,98 @@
+// Package vclock implements the version vectors of entries. A vector counts,
+// for every device, the writes it made to an entry; a write that was based
+// on a vector not covering the stored one was made without knowing about
+// some of the stored writes, and the two are concurrent.
+package vclock
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	// Unversioned counts the writes of clients that do not send a vector,
+	// so that versioned clients still notice them.
+	Unversioned = "unversioned"
+
+	maxDevices  = 256
+	maxDeviceID = 64
+)
+
+// Vector maps a device ID to the number of writes it made.
+type Vector map[string]int64
+
+// Covers reports whether v has seen every write counted in other.
+func (v Vector) Covers(other Vector) bool {
+	for device, count := range other {
+		if v[device] < count {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Next returns a copy of v that counts one more write by device.
+func (v Vector) Next(device string) Vector {
+	next := make(Vector, len(v)+1)
+	for d, count := range v {
+		next[d] = count
+	}
+	next[device]++
+
+	return next
+}
+
+// Valid reports whether v can be stored: a bounded number of devices with
+// short IDs and positive counts.
+func (v Vector) Valid() bool {
+	if len(v) > maxDevices {
+		return false
+	}
+	for device, count := range v {
+		if (!ValidDevice(device) && device != Unversioned) || count <= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidDevice reports whether a client may write as device.
+func ValidDevice(device string) bool {
+	return device != "" && len(device) <= maxDeviceID && device != Unversioned
+}
+
+// Scan reads a vector stored as JSONB.
+func (v *Vector) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	case nil:
+		*v = Vector{}
+		return nil
+	default:
+		return errors.New("vclock: cannot scan vector")
+	}
+
+	vector := Vector{}
+	if err := json.Unmarshal(data, &vector); err != nil {
+		return err
+	}
+	*v = vector
+
+	return nil
+}
+
+// Value stores a vector as JSONB. A nil vector is stored empty.
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(map[string]int64(v))
+}
@@ -0,0 +1,61 @@
+package vclock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCovers(t *testing.T) {
+	stored := Vector{"phone": 2, "laptop": 1}
+
+	assert.True(t, stored.Covers(Vector{}))
+	assert.True(t, stored.Covers(stored))
+	assert.True(t, Vector{"phone": 3, "laptop": 1}.Covers(stored))
+	assert.False(t, Vector{"phone": 1, "laptop": 1}.Covers(stored))
+	assert.False(t, Vector{"phone": 3}.Covers(stored))
+	assert.False(t, Vector{}.Covers(stored))
+}
+
+func TestNext(t *testing.T) {
+	base := Vector{"phone": 2}
+	next := base.Next("laptop")
+
+	assert.Equal(t, Vector{"phone": 2, "laptop": 1}, next)
+	assert.Equal(t, Vector{"phone": 2}, base)
+	assert.True(t, next.Covers(base))
+	assert.False(t, base.Covers(next))
+	assert.Equal(t, Vector{"phone": 1}, Vector(nil).Next("phone"))
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Vector{}.Valid())
+	assert.True(t, Vector{"phone": 1, Unversioned: 3}.Valid())
+	assert.False(t, Vector{"phone": 0}.Valid())
+	assert.False(t, Vector{"": 1}.Valid())
+	assert.False(t, Vector{strings.Repeat("d", 65): 1}.Valid())
+
+	assert.True(t, ValidDevice("phone"))
+	assert.False(t, ValidDevice(Unversioned))
+	assert.False(t, ValidDevice(""))
+}
+
+func TestScanValue(t *testing.T) {
+	value, err := Vector{"phone": 2}.Value()
+	require.NoError(t, err)
+
+	var v Vector
+	require.NoError(t, v.Scan(value))
+	assert.Equal(t, Vector{"phone": 2}, v)
+
+	require.NoError(t, v.Scan(nil))
+	assert.Equal(t, Vector{}, v)
+
+	value, err = Vector(nil).Value()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("{}"), value)
+
+	assert.Error(t, v.Scan(42))
+}
@@ -0,0 +1,45 @@
+package dto
+
+type (
+	AuthInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+	}
+	// MessageInput carries a body sealed by the sender to the recipient's
+	// public key. The server never sees the plaintext.
+	MessageInput struct {
+		UserAddress      string `json:"user_address" db:"user_address"`
+		Password         string `json:"password" db:"password"`
+		RecipientAddress string `json:"recipient_address" db:"recipient_address"`
+		EncryptedBody    []byte `json:"encrypted_body" db:"encrypted_body"`
+	}
+	SentMessage struct {
+		ID        string `json:"id" db:"id"`
+		CreatedAt string `json:"created_at" db:"created_at"`
+	}
+	Message struct {
+		ID            string  `json:"id" db:"id"`
+		Seq           int64   `json:"-" db:"seq"`
+		SenderAddress string  `json:"sender_address" db:"sender_address"`
+		EncryptedBody []byte  `json:"encrypted_body" db:"encrypted_body"`
+		Size          int     `json:"size" db:"size"`
+		ReadAt        *string `json:"read_at,omitempty" db:"read_at"`
+		CreatedAt     string  `json:"created_at" db:"created_at"`
+	}
+	// MessagePage is one page of an inbox, newest first. NextCursor is set
+	// when older messages follow and is passed back as the cursor parameter.
+	MessagePage struct {
+		Messages   []Message `json:"messages"`
+		NextCursor *int64    `json:"next_cursor,omitempty"`
+	}
+	InboxSummary struct {
+		Messages   int   `json:"messages" db:"messages"`
+		Unread     int   `json:"unread" db:"unread"`
+		UsedBytes  int64 `json:"used_bytes" db:"used_bytes"`
+		QuotaBytes int64 `json:"quota_bytes"`
+	}
+	BlockedSender struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		CreatedAt   string `json:"created_at" db:"created_at"`
+	}
+)
@@ -0,0 +1,261 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ObscuraNote/api-general/internal/messages/dto"
+	mService "github.com/ObscuraNote/api-general/internal/messages/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+type handler struct {
+	log         *logger.Logger
+	ms          mService.MessagesService
+	maxBodySize int64
+}
+
+func Register(router chi.Router, ms mService.MessagesService, maxSize int, log logger.Logger) {
+	h := &handler{
+		log: &log,
+		ms:  ms,
+		// Message bodies arrive base64 encoded inside a JSON document.
+		maxBodySize: int64(maxSize)*4/3 + 1024,
+	}
+
+	router.Post("/messages", h.SendMessage)
+	router.Get("/messages", h.GetMessages)
+	router.Get("/messages/summary", h.GetSummary)
+	router.Put("/messages/{id}/read", h.MarkRead)
+	router.Delete("/messages/{id}", h.DeleteMessage)
+
+	router.Get("/messages/blocks", h.GetBlockedSenders)
+	router.Put("/messages/blocks/{address}", h.BlockSender)
+	router.Delete("/messages/blocks/{address}", h.UnblockSender)
+}
+
+func (h *handler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+
+	var input dto.MessageInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	sent, err := h.ms.SendMessage(input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "SendMessage"}).
+			Error("Failed to send message")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, sent); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "SendMessage"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	cursor, err := utils.ParseInt64Query(w, r, "cursor", 0)
+	if err != nil {
+		return
+	}
+
+	limit, err := utils.ParseInt64Query(w, r, "limit", 0)
+	if err != nil {
+		return
+	}
+
+	unreadOnly, err := utils.ParseBoolQuery(w, r, "unread", false)
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.ms.GetMessages(cursor, limit, unreadOnly, auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "GetMessages"}).
+			Error("Failed to get messages")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, page); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "GetMessages"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := h.ms.GetSummary(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "GetSummary"}).
+			Error("Failed to get inbox summary")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, summary); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "GetSummary"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	messageID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ms.MarkRead(messageID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "MarkRead"}).
+			Error("Failed to mark message as read")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	messageID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ms.DeleteMessage(messageID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "DeleteMessage"}).
+			Error("Failed to delete message")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetBlockedSenders(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	blocked, err := h.ms.GetBlockedSenders(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "GetBlockedSenders"}).
+			Error("Failed to get blocked senders")
+
+		writeError(w, err)
+		return
+	}
+
+	if blocked == nil {
+		blocked = []dto.BlockedSender{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, blocked); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "GetBlockedSenders"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) BlockSender(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ms.BlockSender(chi.URLParam(r, "address"), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "BlockSender"}).
+			Error("Failed to block sender")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) UnblockSender(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ms.UnblockSender(chi.URLParam(r, "address"), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "messages", "function": "UnblockSender"}).
+			Error("Failed to unblock sender")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.Forbidden:
+		_ = utils.Fault(w, http.StatusForbidden, utils.Forbidden)
+	case utils.BadRequest:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.PayloadTooLarge:
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, err.Error())
+	case utils.UserNotFound, utils.MessageNotFound:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	case utils.InboxFull:
+		_ = utils.Fault(w, http.StatusConflict, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/ObscuraNote/api-general/internal/messages/dto"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
+)
+
+var _ MessagesRepository = (*Repository)(nil)
+
+var (
+	// ErrBlocked is returned when the recipient blocked the sender.
+	ErrBlocked = errors.New("sender is blocked by the recipient")
+	// ErrInboxFull is returned when a message would take the recipient's
+	// inbox over its quota.
+	ErrInboxFull = errors.New("inbox is full")
+)
+
+type (
+	MessagesRepository interface {
+		AddMessage(senderId, recipientId int64, encryptedBody []byte, quota int64) (*dto.SentMessage, error)
+		GetMessages(recipientId, beforeSeq int64, unreadOnly bool, limit int64) ([]dto.Message, error)
+		GetSummary(recipientId int64) (*dto.InboxSummary, error)
+		MarkRead(recipientId int64, id string) (bool, error)
+		DeleteMessage(recipientId int64, id string) (bool, error)
+		BlockSender(userId, blockedId int64) error
+		UnblockSender(userId, blockedId int64) (bool, error)
+		GetBlockedSenders(userId int64) ([]dto.BlockedSender, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+// AddMessage delivers a message to the recipient's inbox. The recipient row is
+// locked while the inbox usage is checked, so concurrent senders cannot take
+// the inbox over quota together. It returns ErrBlocked when the recipient
+// blocked the sender and ErrInboxFull when the message does not fit.
+func (r *Repository) AddMessage(senderId, recipientId int64, encryptedBody []byte, quota int64) (*dto.SentMessage, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var blocked bool
+		if err := tx.StmtxContext(ctx, r.statements.isBlocked.statement).
+			QueryRowContext(ctx, recipientId, senderId).Scan(&blocked); err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrBlocked
+		}
+
+		var id int64
+		if err := tx.StmtxContext(ctx, r.statements.lockInbox.statement).
+			QueryRowContext(ctx, recipientId).Scan(&id); err != nil {
+			return nil, err
+		}
+
+		var used int64
+		if err := tx.StmtxContext(ctx, r.statements.getInboxUsage.statement).
+			QueryRowContext(ctx, recipientId).Scan(&used); err != nil {
+			return nil, err
+		}
+		if used+int64(len(encryptedBody)) > quota {
+			return nil, ErrInboxFull
+		}
+
+		var sent dto.SentMessage
+		if err := tx.StmtxContext(ctx, r.statements.addMessage.statement).
+			QueryRowContext(ctx, senderId, recipientId, encryptedBody, len(encryptedBody)).
+			Scan(&sent.ID, &sent.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		return &sent, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, ErrBlocked) && !errors.Is(err, ErrInboxFull) {
+			log.Println("Error adding message")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.SentMessage), nil
+}
+
+// GetMessages returns up to limit messages older than beforeSeq, newest first.
+// A zero beforeSeq starts from the newest message.
+func (r *Repository) GetMessages(recipientId, beforeSeq int64, unreadOnly bool, limit int64) ([]dto.Message, error) {
+	rows, err := r.statements.getMessages.statement.
+		QueryContext(r.ctx, recipientId, beforeSeq, unreadOnly, limit)
+	if err != nil {
+		log.Println("Error getting messages")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []dto.Message
+	for rows.Next() {
+		var message dto.Message
+		if err := rows.Scan(&message.ID, &message.Seq, &message.SenderAddress, &message.EncryptedBody, &message.Size,
+			&message.ReadAt, &message.CreatedAt); err != nil {
+			log.Println("Error scanning message")
+
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *Repository) GetSummary(recipientId int64) (*dto.InboxSummary, error) {
+	var summary dto.InboxSummary
+	if err := r.statements.getSummary.statement.
+		QueryRowContext(r.ctx, recipientId).
+		Scan(&summary.Messages, &summary.Unread, &summary.UsedBytes); err != nil {
+		log.Println("Error getting inbox summary")
+
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+func (r *Repository) MarkRead(recipientId int64, id string) (bool, error) {
+	result, err := r.statements.markRead.statement.ExecContext(r.ctx, id, recipientId)
+	if err != nil {
+		log.Println("Error marking message as read")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+func (r *Repository) DeleteMessage(recipientId int64, id string) (bool, error) {
+	result, err := r.statements.deleteMessage.statement.ExecContext(r.ctx, id, recipientId)
+	if err != nil {
+		log.Println("Error deleting message")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+// BlockSender stops blockedId from sending to userId. Blocking twice is not an
+// error.
+func (r *Repository) BlockSender(userId, blockedId int64) error {
+	if _, err := r.statements.blockSender.statement.ExecContext(r.ctx, userId, blockedId); err != nil {
+		log.Println("Error blocking sender")
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) UnblockSender(userId, blockedId int64) (bool, error) {
+	result, err := r.statements.unblockSender.statement.ExecContext(r.ctx, userId, blockedId)
+	if err != nil {
+		log.Println("Error unblocking sender")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+func (r *Repository) GetBlockedSenders(userId int64) ([]dto.BlockedSender, error) {
+	rows, err := r.statements.getBlocked.statement.QueryContext(r.ctx, userId)
+	if err != nil {
+		log.Println("Error getting blocked senders")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocked []dto.BlockedSender
+	for rows.Next() {
+		var sender dto.BlockedSender
+		if err := rows.Scan(&sender.UserAddress, &sender.CreatedAt); err != nil {
+			log.Println("Error scanning blocked sender")
+
+			return nil, err
+		}
+		blocked = append(blocked, sender)
+	}
+
+	return blocked, rows.Err()
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.isBlocked.statement, err = r.db.PrepareStatement(statementsList.isBlocked.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockInbox.statement, err = r.db.PrepareStatement(statementsList.lockInbox.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getInboxUsage.statement, err = r.db.PrepareStatement(statementsList.getInboxUsage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.addMessage.statement, err = r.db.PrepareStatement(statementsList.addMessage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getMessages.statement, err = r.db.PrepareStatement(statementsList.getMessages.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getSummary.statement, err = r.db.PrepareStatement(statementsList.getSummary.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.markRead.statement, err = r.db.PrepareStatement(statementsList.markRead.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteMessage.statement, err = r.db.PrepareStatement(statementsList.deleteMessage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.blockSender.statement, err = r.db.PrepareStatement(statementsList.blockSender.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.unblockSender.statement, err = r.db.PrepareStatement(statementsList.unblockSender.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getBlocked.statement, err = r.db.PrepareStatement(statementsList.getBlocked.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
+
+func rowsAffected(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,134 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	db.GetClient().Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'),
+		       ('2222222222222222222222222222222222222222222222222222222222222222', 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb');
+	`)
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var senderId, recipientId int64
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '1111111111111111111111111111111111111111111111111111111111111111';
+	`).Scan(&senderId)
+	if err != nil {
+		t.Fatalf("failed to get user id: %v", err)
+	}
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+	`).Scan(&recipientId)
+	if err != nil {
+		t.Fatalf("failed to get user id: %v", err)
+	}
+
+	t.Run("AddMessage", func(t *testing.T) {
+		for _, body := range []string{"first", "second", "third"} {
+			sent, err := repo.AddMessage(senderId, recipientId, []byte(body), 16)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, sent.ID)
+		}
+
+		_, err := repo.AddMessage(senderId, recipientId, []byte("fourth"), 16)
+		assert.ErrorIs(t, err, ErrInboxFull)
+
+		summary, err := repo.GetSummary(recipientId)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, summary.Messages)
+		assert.Equal(t, 3, summary.Unread)
+		assert.Equal(t, int64(16), summary.UsedBytes)
+	})
+
+	t.Run("GetMessages", func(t *testing.T) {
+		messages, err := repo.GetMessages(recipientId, 0, false, 2)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, []byte("third"), messages[0].EncryptedBody)
+		assert.Equal(t, "1111111111111111111111111111111111111111111111111111111111111111", messages[0].SenderAddress)
+
+		older, err := repo.GetMessages(recipientId, messages[1].Seq, false, 2)
+		assert.NoError(t, err)
+		assert.Len(t, older, 1)
+		assert.Equal(t, []byte("first"), older[0].EncryptedBody)
+
+		updated, err := repo.MarkRead(recipientId, messages[0].ID)
+		assert.NoError(t, err)
+		assert.True(t, updated)
+
+		updated, err = repo.MarkRead(senderId, messages[1].ID)
+		assert.NoError(t, err)
+		assert.False(t, updated)
+
+		unread, err := repo.GetMessages(recipientId, 0, true, 10)
+		assert.NoError(t, err)
+		assert.Len(t, unread, 2)
+
+		deleted, err := repo.DeleteMessage(senderId, messages[0].ID)
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+
+		deleted, err = repo.DeleteMessage(recipientId, messages[0].ID)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+	})
+
+	t.Run("BlockSender", func(t *testing.T) {
+		err := repo.BlockSender(recipientId, senderId)
+		assert.NoError(t, err)
+
+		err = repo.BlockSender(recipientId, senderId)
+		assert.NoError(t, err)
+
+		blocked, err := repo.GetBlockedSenders(recipientId)
+		assert.NoError(t, err)
+		assert.Len(t, blocked, 1)
+
+		_, err = repo.AddMessage(senderId, recipientId, []byte("blocked"), 1024)
+		assert.ErrorIs(t, err, ErrBlocked)
+
+		_, err = repo.AddMessage(recipientId, senderId, []byte("reply"), 1024)
+		assert.NoError(t, err)
+
+		unblocked, err := repo.UnblockSender(recipientId, senderId)
+		assert.NoError(t, err)
+		assert.True(t, unblocked)
+
+		_, err = repo.AddMessage(senderId, recipientId, []byte("again"), 1024)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,115 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	isBlocked     statementsItem
+	lockInbox     statementsItem
+	getInboxUsage statementsItem
+	addMessage    statementsItem
+	getMessages   statementsItem
+	getSummary    statementsItem
+	markRead      statementsItem
+	deleteMessage statementsItem
+	blockSender   statementsItem
+	unblockSender statementsItem
+	getBlocked    statementsItem
+}
+
+var statementsList = statements{
+	isBlocked: statementsItem{
+		name: "isBlocked",
+		query: `
+			SELECT EXISTS (
+				SELECT 1
+				FROM message_blocks
+				WHERE user_id = $1
+				AND blocked_id = $2
+			);`,
+	},
+	lockInbox: statementsItem{
+		name: "lockInbox",
+		query: `
+			SELECT id
+			FROM users
+			WHERE id = $1
+			FOR NO KEY UPDATE;`,
+	},
+	getInboxUsage: statementsItem{
+		name: "getInboxUsage",
+		query: `
+			SELECT COALESCE(SUM(size), 0)
+			FROM messages
+			WHERE recipient_id = $1;`,
+	},
+	addMessage: statementsItem{
+		name: "addMessage",
+		query: `
+			INSERT INTO messages (sender_id, recipient_id, encrypted_body, size)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at;`,
+	},
+	getMessages: statementsItem{
+		name: "getMessages",
+		query: `
+			SELECT m.id, m.seq, u.user_address, m.encrypted_body, m.size, m.read_at, m.created_at
+			FROM messages m
+			JOIN users u ON u.id = m.sender_id
+			WHERE m.recipient_id = $1
+			AND ($2 = 0 OR m.seq < $2)
+			AND (NOT $3 OR m.read_at IS NULL)
+			ORDER BY m.seq DESC
+			LIMIT $4;`,
+	},
+	getSummary: statementsItem{
+		name: "getSummary",
+		query: `
+			SELECT COUNT(*), COUNT(*) FILTER (WHERE read_at IS NULL), COALESCE(SUM(size), 0)
+			FROM messages
+			WHERE recipient_id = $1;`,
+	},
+	markRead: statementsItem{
+		name: "markRead",
+		query: `
+			UPDATE messages
+			SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+			WHERE id = $1
+			AND recipient_id = $2;`,
+	},
+	deleteMessage: statementsItem{
+		name: "deleteMessage",
+		query: `
+			DELETE FROM messages
+			WHERE id = $1
+			AND recipient_id = $2;`,
+	},
+	blockSender: statementsItem{
+		name: "blockSender",
+		query: `
+			INSERT INTO message_blocks (user_id, blocked_id)
+			VALUES ($1, $2)
+			ON CONFLICT (user_id, blocked_id) DO NOTHING;`,
+	},
+	unblockSender: statementsItem{
+		name: "unblockSender",
+		query: `
+			DELETE FROM message_blocks
+			WHERE user_id = $1
+			AND blocked_id = $2;`,
+	},
+	getBlocked: statementsItem{
+		name: "getBlocked",
+		query: `
+			SELECT u.user_address, b.created_at
+			FROM message_blocks b
+			JOIN users u ON u.id = b.blocked_id
+			WHERE b.user_id = $1
+			ORDER BY b.created_at DESC;`,
+	},
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ObscuraNote/api-general/internal/messages/dto"
+	r "github.com/ObscuraNote/api-general/internal/messages/repository"
+	u "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/logger"
+)
+
+var _ MessagesService = (*Service)(nil)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type (
+	// MessagesService is a mailbox of sealed messages between addresses. The
+	// server only knows who sent what to whom and how large it is.
+	MessagesService interface {
+		SendMessage(input dto.MessageInput) (*dto.SentMessage, error)
+		GetMessages(cursor, limit int64, unreadOnly bool, auth dto.AuthInput) (*dto.MessagePage, error)
+		GetSummary(auth dto.AuthInput) (*dto.InboxSummary, error)
+		MarkRead(id string, auth dto.AuthInput) error
+		DeleteMessage(id string, auth dto.AuthInput) error
+		BlockSender(senderAddress string, auth dto.AuthInput) error
+		UnblockSender(senderAddress string, auth dto.AuthInput) error
+		GetBlockedSenders(auth dto.AuthInput) ([]dto.BlockedSender, error)
+	}
+
+	Service struct {
+		ctx context.Context
+		r   r.MessagesRepository
+		us  u.UserService
+		log *logger.Logger
+		cfg config.MessagesConfig
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.MessagesRepository, us u.UserService, cfg config.MessagesConfig) *Service {
+	return &Service{
+		ctx: ctx,
+		log: &log,
+		r:   repo,
+		us:  us,
+		cfg: cfg,
+	}
+}
+
+func (s *Service) SendMessage(input dto.MessageInput) (*dto.SentMessage, error) {
+	if len(input.EncryptedBody) == 0 || input.RecipientAddress == "" {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if len(input.EncryptedBody) > s.cfg.MaxSize {
+		return nil, fmt.Errorf(utils.PayloadTooLarge)
+	}
+
+	senderId, err := s.getUserId(input.UserAddress, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	recipientId, err := s.getUserIdByAddress(input.RecipientAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := s.r.AddMessage(senderId, recipientId, input.EncryptedBody, s.cfg.InboxQuota)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf(utils.UserNotFound)
+	case errors.Is(err, r.ErrBlocked):
+		return nil, fmt.Errorf(utils.Forbidden)
+	case errors.Is(err, r.ErrInboxFull):
+		return nil, fmt.Errorf(utils.InboxFull)
+	case err != nil:
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "messages_service", "function": "SendMessage"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return sent, nil
+}
+
+func (s *Service) GetMessages(cursor, limit int64, unreadOnly bool, auth dto.AuthInput) (*dto.MessagePage, error) {
+	if cursor < 0 || limit < 0 {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra row tells whether another page follows.
+	messages, err := s.r.GetMessages(userId, cursor, unreadOnly, limit+1)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "messages_service", "function": "GetMessages"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	page := &dto.MessagePage{Messages: messages}
+	if int64(len(messages)) > limit {
+		page.Messages = messages[:limit]
+		next := page.Messages[limit-1].Seq
+		page.NextCursor = &next
+	}
+	if page.Messages == nil {
+		page.Messages = []dto.Message{}
+	}
+
+	return page, nil
+}
+
+func (s *Service) GetSummary(auth dto.AuthInput) (*dto.InboxSummary, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.r.GetSummary(userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "messages_service", "function": "GetSummary"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+	summary.QuotaBytes = s.cfg.InboxQuota
+
+	return summary, nil
+}
+
+func (s *Service) MarkRead(id string, auth dto.AuthInput) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.r.MarkRead(userId, id)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "messages_service", "function": "MarkRead"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !updated {
+		return fmt.Errorf(utils.MessageNotFound)
+	}
+
+	return nil
+}
+
+func (s *Service) DeleteMessage(id string, auth dto.AuthInput) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.r.DeleteMessage(userId, id)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "messages_service", "function": "DeleteMessage"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !deleted {
+		return fmt.Errorf(utils.MessageNotFound)
+	}
+
+	return nil
+}
+
+func (s *Service) BlockSender(senderAddress string, auth dto.AuthInput) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	senderId, err := s.getUserIdByAddress(senderAddress)
+	if err != nil {
+		return err
+	}
+	if senderId == userId {
+		return fmt.Errorf(utils.BadRequest)
+	}
+
+	if err := s.r.BlockSender(userId, senderId); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "messages_service", "function": "BlockSender"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return nil
+}
+
+func (s *Service) UnblockSender(senderAddress string, auth dto.AuthInput) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	senderId, err := s.getUserIdByAddress(senderAddress)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.r.UnblockSender(userId, senderId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "messages_service", "function": "UnblockSender"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !deleted {
+		return fmt.Errorf(utils.UserNotFound)
+	}
+
+	return nil
+}
+
+func (s *Service) GetBlockedSenders(auth dto.AuthInput) ([]dto.BlockedSender, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := s.r.GetBlockedSenders(userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "messages_service", "function": "GetBlockedSenders"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return blocked, nil
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "messages_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
+
+func (s *Service) getUserIdByAddress(userAddress string) (int64, error) {
+	userId, err := s.us.GetUserIdByAddress(userAddress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.UserNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	return userId, nil
+}
@@ -0,0 +1,70 @@
+package dto
+
+const (
+	ContentTypeProposal = "proposal"
+	ContentTypeCommit   = "commit"
+	ContentTypeWelcome  = "welcome"
+)
+
+type (
+	AuthInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+	}
+	// KeyPackagesInput uploads a batch of serialized MLS KeyPackages for one
+	// of the caller's devices. LastResort, when set, replaces the device's
+	// last-resort KeyPackage, which is handed out once the others run out.
+	KeyPackagesInput struct {
+		UserAddress string   `json:"user_address" db:"user_address"`
+		Password    string   `json:"password" db:"password"`
+		DeviceID    string   `json:"device_id" db:"device_id"`
+		KeyPackages [][]byte `json:"key_packages"`
+		LastResort  []byte   `json:"last_resort,omitempty"`
+	}
+	KeyPackage struct {
+		DeviceID   string `json:"device_id" db:"device_id"`
+		KeyPackage []byte `json:"key_package" db:"key_package"`
+		LastResort bool   `json:"last_resort" db:"last_resort"`
+	}
+	KeyPackageCount struct {
+		DeviceID   string `json:"device_id" db:"device_id"`
+		Count      int    `json:"count" db:"count"`
+		LastResort bool   `json:"last_resort" db:"last_resort"`
+	}
+	Group struct {
+		VaultID   string `json:"vault_id" db:"vault_id"`
+		Epoch     int64  `json:"epoch" db:"epoch"`
+		LastSeq   int64  `json:"last_seq" db:"last_seq"`
+		CreatedAt string `json:"created_at" db:"created_at"`
+	}
+	// ProposalInput carries an MLS Proposal for the given epoch.
+	ProposalInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+		Epoch       int64  `json:"epoch" db:"epoch"`
+		Proposal    []byte `json:"proposal" db:"payload"`
+	}
+	// CommitInput carries an MLS Commit that moves the group from Epoch to
+	// Epoch+1, together with the Welcome messages for members it adds.
+	CommitInput struct {
+		UserAddress string    `json:"user_address" db:"user_address"`
+		Password    string    `json:"password" db:"password"`
+		Epoch       int64     `json:"epoch" db:"epoch"`
+		Commit      []byte    `json:"commit" db:"payload"`
+		Welcomes    []Welcome `json:"welcomes"`
+	}
+	Welcome struct {
+		RecipientAddress string `json:"recipient_address" db:"recipient_address"`
+		Welcome          []byte `json:"welcome" db:"payload"`
+		RecipientID      int64  `json:"-" db:"recipient_id"`
+	}
+	Message struct {
+		VaultID       string `json:"vault_id" db:"vault_id"`
+		Seq           int64  `json:"seq" db:"seq"`
+		Epoch         int64  `json:"epoch" db:"epoch"`
+		ContentType   string `json:"content_type" db:"content_type"`
+		SenderAddress string `json:"sender_address" db:"sender_address"`
+		Payload       []byte `json:"payload" db:"payload"`
+		CreatedAt     string `json:"created_at" db:"created_at"`
+	}
+)
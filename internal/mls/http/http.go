@@ -0,0 +1,339 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ObscuraNote/api-general/internal/mls/dto"
+	mService "github.com/ObscuraNote/api-general/internal/mls/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+// maxBodyMessages bounds how many maximum-size payloads, such as a Commit
+// and its Welcomes, a single request body may carry.
+const maxBodyMessages = 64
+
+type handler struct {
+	log         *logger.Logger
+	ms          mService.MLSService
+	maxBodySize int64
+}
+
+func Register(router chi.Router, ms mService.MLSService, maxSize int, log logger.Logger) {
+	h := &handler{
+		log: &log,
+		ms:  ms,
+		// Payloads arrive base64 encoded inside a JSON document.
+		maxBodySize: int64(maxSize)*4/3*maxBodyMessages + 1024,
+	}
+
+	router.Post("/mls/key-packages", h.UploadKeyPackages)
+	router.Get("/mls/key-packages", h.GetKeyPackageCounts)
+	router.Post("/mls/key-packages/{address}/claim", h.ClaimKeyPackages)
+	router.Get("/mls/welcomes", h.GetWelcomes)
+
+	router.Post("/mls/groups/{id}", h.CreateGroup)
+	router.Get("/mls/groups/{id}", h.GetGroup)
+	router.Post("/mls/groups/{id}/proposals", h.SendProposal)
+	router.Post("/mls/groups/{id}/commits", h.SendCommit)
+	router.Get("/mls/groups/{id}/messages", h.GetMessages)
+}
+
+func (h *handler) UploadKeyPackages(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+
+	var input dto.KeyPackagesInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	if err := h.ms.UploadKeyPackages(input); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "UploadKeyPackages"}).
+			Error("Failed to upload key packages")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetKeyPackageCounts(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	counts, err := h.ms.GetKeyPackageCounts(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "GetKeyPackageCounts"}).
+			Error("Failed to count key packages")
+
+		writeError(w, err)
+		return
+	}
+
+	if counts == nil {
+		counts = []dto.KeyPackageCount{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, counts); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "GetKeyPackageCounts"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) ClaimKeyPackages(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	keyPackages, err := h.ms.ClaimKeyPackages(chi.URLParam(r, "address"), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "ClaimKeyPackages"}).
+			Error("Failed to claim key packages")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, keyPackages); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "ClaimKeyPackages"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetWelcomes(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	welcomes, err := h.ms.GetWelcomes(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "GetWelcomes"}).
+			Error("Failed to get welcome messages")
+
+		writeError(w, err)
+		return
+	}
+
+	if welcomes == nil {
+		welcomes = []dto.Message{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, welcomes); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "GetWelcomes"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	group, err := h.ms.CreateGroup(vaultID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "CreateGroup"}).
+			Error("Failed to create group")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, group); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "CreateGroup"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	group, err := h.ms.GetGroup(vaultID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "GetGroup"}).
+			Error("Failed to get group")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, group); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "GetGroup"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) SendProposal(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+
+	var input dto.ProposalInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	message, err := h.ms.SendProposal(vaultID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "SendProposal"}).
+			Error("Failed to send proposal")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, message); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "SendProposal"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) SendCommit(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+
+	var input dto.CommitInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	message, err := h.ms.SendCommit(vaultID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "SendCommit"}).
+			Error("Failed to send commit")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, message); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "SendCommit"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	after, err := utils.ParseInt64Query(w, r, "after", 0)
+	if err != nil {
+		return
+	}
+
+	limit, err := utils.ParseInt64Query(w, r, "limit", 0)
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	messages, err := h.ms.GetMessages(vaultID.String(), after, limit, auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "GetMessages"}).
+			Error("Failed to get group messages")
+
+		writeError(w, err)
+		return
+	}
+
+	if messages == nil {
+		messages = []dto.Message{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, messages); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "mls", "function": "GetMessages"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.Forbidden:
+		_ = utils.Fault(w, http.StatusForbidden, utils.Forbidden)
+	case utils.NotEntitled:
+		_ = utils.Fault(w, http.StatusForbidden, err.Error())
+	case utils.BadRequest:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.PayloadTooLarge:
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, err.Error())
+	case utils.VaultNotFound, utils.GroupNotFound, utils.UserNotFound, utils.NoKeyPackages:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	case utils.GroupExists, utils.StaleEpoch:
+		_ = utils.Fault(w, http.StatusConflict, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
+}
@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/ObscuraNote/api-general/internal/mls/dto"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
+)
+
+var _ MLSRepository = (*Repository)(nil)
+
+var (
+	// ErrTooManyKeyPackages is returned when an upload would leave a device
+	// with more KeyPackages than allowed.
+	ErrTooManyKeyPackages = errors.New("too many key packages for device")
+	// ErrTooManyDevices is returned when a new device would take the account
+	// past the number of devices it is entitled to.
+	ErrTooManyDevices = errors.New("too many devices")
+)
+
+type (
+	MLSRepository interface {
+		AddKeyPackages(userId int64, deviceId string, keyPackages [][]byte, lastResort []byte, limit, maxDevices int) error
+		CountKeyPackages(userId int64) ([]dto.KeyPackageCount, error)
+		ClaimKeyPackages(userId int64) ([]dto.KeyPackage, error)
+		CreateGroup(vaultId string) (*dto.Group, error)
+		GetGroup(vaultId string) (*dto.Group, error)
+		AddProposal(vaultId string, senderId, epoch int64, proposal []byte) (*dto.Message, error)
+		AddCommit(vaultId string, senderId, epoch int64, commit []byte, welcomes []dto.Welcome) (*dto.Message, error)
+		GetMessages(vaultId string, userId, afterSeq, limit int64) ([]dto.Message, error)
+		GetWelcomes(userId int64) ([]dto.Message, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+// AddKeyPackages stores a batch of KeyPackages for one device, refusing the
+// whole batch when the device would end up holding more than limit, or when
+// it is a new device and the account already has maxDevices. A zero
+// maxDevices is unlimited. A non-nil lastResort replaces the device's
+// last-resort KeyPackage and does not count towards limit.
+func (r *Repository) AddKeyPackages(userId int64, deviceId string, keyPackages [][]byte, lastResort []byte, limit, maxDevices int) error {
+	_, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var stored, total int
+		if err := tx.StmtxContext(ctx, r.statements.countDevicePackage.statement).
+			QueryRowContext(ctx, userId, deviceId).Scan(&stored, &total); err != nil {
+			return nil, err
+		}
+
+		if stored+len(keyPackages) > limit {
+			return nil, ErrTooManyKeyPackages
+		}
+
+		if total == 0 && maxDevices > 0 {
+			if err := r.checkDevices(ctx, tx, userId, deviceId, maxDevices); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, keyPackage := range keyPackages {
+			if _, err := tx.StmtxContext(ctx, r.statements.addKeyPackage.statement).
+				ExecContext(ctx, userId, deviceId, keyPackage); err != nil {
+				return nil, err
+			}
+		}
+
+		if lastResort != nil {
+			if _, err := tx.StmtxContext(ctx, r.statements.setLastResort.statement).
+				ExecContext(ctx, userId, deviceId, lastResort); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, ErrTooManyKeyPackages) && !errors.Is(err, ErrTooManyDevices) {
+			log.Println("Error adding key packages")
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// checkDevices returns ErrTooManyDevices when userId already has maxDevices
+// other devices with KeyPackages. The user row is locked first so that two
+// new devices cannot both take the last slot.
+func (r *Repository) checkDevices(ctx context.Context, tx *sqlx.Tx, userId int64, deviceId string, maxDevices int) error {
+	var locked int64
+	if err := tx.StmtxContext(ctx, r.statements.lockUser.statement).
+		QueryRowContext(ctx, userId).Scan(&locked); err != nil {
+		return err
+	}
+
+	var devices int
+	if err := tx.StmtxContext(ctx, r.statements.countDevices.statement).
+		QueryRowContext(ctx, userId, deviceId).Scan(&devices); err != nil {
+		return err
+	}
+
+	if devices >= maxDevices {
+		return ErrTooManyDevices
+	}
+
+	return nil
+}
+
+func (r *Repository) CountKeyPackages(userId int64) ([]dto.KeyPackageCount, error) {
+	rows, err := r.statements.countKeyPackages.statement.
+		QueryContext(r.ctx, userId)
+	if err != nil {
+		log.Println("Error counting key packages")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []dto.KeyPackageCount
+	for rows.Next() {
+		var count dto.KeyPackageCount
+		if err := rows.Scan(&count.DeviceID, &count.Count, &count.LastResort); err != nil {
+			log.Println("Error scanning key package count")
+
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, nil
+}
+
+// ClaimKeyPackages removes and returns the oldest KeyPackage of each of the
+// user's devices, so that no KeyPackage is ever handed out twice. Devices
+// that ran out get their last-resort KeyPackage instead, which is kept.
+func (r *Repository) ClaimKeyPackages(userId int64) ([]dto.KeyPackage, error) {
+	rows, err := r.statements.claimKeyPackages.statement.
+		QueryContext(r.ctx, userId)
+	if err != nil {
+		log.Println("Error claiming key packages")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keyPackages []dto.KeyPackage
+	for rows.Next() {
+		var keyPackage dto.KeyPackage
+		if err := rows.Scan(&keyPackage.DeviceID, &keyPackage.KeyPackage, &keyPackage.LastResort); err != nil {
+			log.Println("Error scanning key package")
+
+			return nil, err
+		}
+		keyPackages = append(keyPackages, keyPackage)
+	}
+
+	return keyPackages, nil
+}
+
+// CreateGroup starts the delivery sequence of a vault at epoch 0. It returns
+// sql.ErrNoRows when the vault already has a group.
+func (r *Repository) CreateGroup(vaultId string) (*dto.Group, error) {
+	var group dto.Group
+	err := r.statements.createGroup.statement.
+		QueryRowContext(r.ctx, vaultId).
+		Scan(&group.VaultID, &group.Epoch, &group.LastSeq, &group.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (r *Repository) GetGroup(vaultId string) (*dto.Group, error) {
+	var group dto.Group
+	err := r.statements.getGroup.statement.
+		QueryRowContext(r.ctx, vaultId).
+		Scan(&group.VaultID, &group.Epoch, &group.LastSeq, &group.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// AddProposal appends a Proposal to the group sequence. It returns
+// sql.ErrNoRows when epoch is not the current epoch of the group.
+func (r *Repository) AddProposal(vaultId string, senderId, epoch int64, proposal []byte) (*dto.Message, error) {
+	message := dto.Message{VaultID: vaultId, ContentType: dto.ContentTypeProposal, Payload: proposal}
+	err := r.statements.addProposal.statement.
+		QueryRowContext(r.ctx, vaultId, epoch, senderId, proposal).
+		Scan(&message.Seq, &message.Epoch, &message.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// AddCommit moves the group from epoch to epoch+1 and appends the Commit
+// followed by its Welcome messages. It returns sql.ErrNoRows when epoch is not
+// the current epoch, so only the first Commit of an epoch is accepted.
+func (r *Repository) AddCommit(vaultId string, senderId, epoch int64, commit []byte, welcomes []dto.Welcome) (*dto.Message, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var lastSeq int64
+		if err := tx.StmtxContext(ctx, r.statements.advanceEpoch.statement).
+			QueryRowContext(ctx, vaultId, epoch, 1+len(welcomes)).Scan(&lastSeq); err != nil {
+			return nil, err
+		}
+
+		message := dto.Message{
+			VaultID:     vaultId,
+			Seq:         lastSeq - int64(len(welcomes)),
+			Epoch:       epoch,
+			ContentType: dto.ContentTypeCommit,
+			Payload:     commit,
+		}
+		if err := tx.StmtxContext(ctx, r.statements.addMessage.statement).
+			QueryRowContext(ctx, vaultId, message.Seq, epoch, message.ContentType, senderId, nil, commit).
+			Scan(&message.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		for i, welcome := range welcomes {
+			var createdAt string
+			if err := tx.StmtxContext(ctx, r.statements.addMessage.statement).
+				QueryRowContext(ctx, vaultId, message.Seq+int64(i)+1, epoch+1, dto.ContentTypeWelcome, senderId,
+					welcome.RecipientID, welcome.Welcome).
+				Scan(&createdAt); err != nil {
+				return nil, err
+			}
+		}
+
+		return &message, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("Error adding commit")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.Message), nil
+}
+
+// GetMessages returns the group messages after afterSeq in delivery order,
+// leaving out Welcome messages addressed to other users.
+func (r *Repository) GetMessages(vaultId string, userId, afterSeq, limit int64) ([]dto.Message, error) {
+	rows, err := r.statements.getMessages.statement.
+		QueryContext(r.ctx, vaultId, afterSeq, userId, limit)
+	if err != nil {
+		log.Println("Error getting group messages")
+
+		return nil, err
+	}
+
+	return scanMessages(rows)
+}
+
+func (r *Repository) GetWelcomes(userId int64) ([]dto.Message, error) {
+	rows, err := r.statements.getWelcomes.statement.
+		QueryContext(r.ctx, userId)
+	if err != nil {
+		log.Println("Error getting welcome messages")
+
+		return nil, err
+	}
+
+	return scanMessages(rows)
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.addKeyPackage.statement, err = r.db.PrepareStatement(statementsList.addKeyPackage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.setLastResort.statement, err = r.db.PrepareStatement(statementsList.setLastResort.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.countDevicePackage.statement, err = r.db.PrepareStatement(statementsList.countDevicePackage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockUser.statement, err = r.db.PrepareStatement(statementsList.lockUser.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.countDevices.statement, err = r.db.PrepareStatement(statementsList.countDevices.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.countKeyPackages.statement, err = r.db.PrepareStatement(statementsList.countKeyPackages.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.claimKeyPackages.statement, err = r.db.PrepareStatement(statementsList.claimKeyPackages.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.createGroup.statement, err = r.db.PrepareStatement(statementsList.createGroup.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getGroup.statement, err = r.db.PrepareStatement(statementsList.getGroup.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.addProposal.statement, err = r.db.PrepareStatement(statementsList.addProposal.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.advanceEpoch.statement, err = r.db.PrepareStatement(statementsList.advanceEpoch.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.addMessage.statement, err = r.db.PrepareStatement(statementsList.addMessage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getMessages.statement, err = r.db.PrepareStatement(statementsList.getMessages.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getWelcomes.statement, err = r.db.PrepareStatement(statementsList.getWelcomes.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
+
+func scanMessages(rows *sql.Rows) ([]dto.Message, error) {
+	defer rows.Close()
+
+	var messages []dto.Message
+	for rows.Next() {
+		var message dto.Message
+		if err := rows.Scan(&message.VaultID, &message.Seq, &message.Epoch, &message.ContentType, &message.SenderAddress,
+			&message.Payload, &message.CreatedAt); err != nil {
+			log.Println("Error scanning message")
+
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
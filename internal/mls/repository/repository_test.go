@@ -0,0 +1,164 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ObscuraNote/api-general/internal/mls/dto"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE vaults, mls_key_packages CASCADE;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE vaults, mls_key_packages CASCADE;")
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	db.GetClient().Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'),
+		       ('2222222222222222222222222222222222222222222222222222222222222222', 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb');
+	`)
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var senderId, recipientId int64
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '1111111111111111111111111111111111111111111111111111111111111111';
+	`).Scan(&senderId)
+	if err != nil {
+		t.Fatalf("failed to get sender id: %v", err)
+	}
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+	`).Scan(&recipientId)
+	if err != nil {
+		t.Fatalf("failed to get recipient id: %v", err)
+	}
+
+	var vaultId string
+	err = db.GetClient().QueryRow(`
+		INSERT INTO vaults (encrypted_name) VALUES ('name') RETURNING id;
+	`).Scan(&vaultId)
+	if err != nil {
+		t.Fatalf("failed to create vault: %v", err)
+	}
+
+	t.Run("KeyPackages", func(t *testing.T) {
+		err := repo.AddKeyPackages(recipientId, "phone", [][]byte{[]byte("kp-1"), []byte("kp-2")}, []byte("kp-last"), 3, 2)
+		assert.NoError(t, err)
+		err = repo.AddKeyPackages(recipientId, "laptop", [][]byte{[]byte("kp-3")}, nil, 3, 2)
+		assert.NoError(t, err)
+
+		err = repo.AddKeyPackages(recipientId, "tablet", [][]byte{[]byte("kp-6")}, nil, 3, 2)
+		assert.ErrorIs(t, err, ErrTooManyDevices)
+
+		err = repo.AddKeyPackages(recipientId, "phone", [][]byte{[]byte("kp-4"), []byte("kp-5")}, nil, 3, 2)
+		assert.ErrorIs(t, err, ErrTooManyKeyPackages)
+
+		counts, err := repo.CountKeyPackages(recipientId)
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.KeyPackageCount{{DeviceID: "laptop", Count: 1}, {DeviceID: "phone", Count: 2, LastResort: true}}, counts)
+
+		claimed, err := repo.ClaimKeyPackages(recipientId)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []dto.KeyPackage{
+			{DeviceID: "phone", KeyPackage: []byte("kp-1")},
+			{DeviceID: "laptop", KeyPackage: []byte("kp-3")},
+		}, claimed)
+
+		claimed, err = repo.ClaimKeyPackages(recipientId)
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.KeyPackage{{DeviceID: "phone", KeyPackage: []byte("kp-2")}}, claimed)
+
+		for i := 0; i < 2; i++ {
+			claimed, err = repo.ClaimKeyPackages(recipientId)
+			assert.NoError(t, err)
+			assert.Equal(t, []dto.KeyPackage{{DeviceID: "phone", KeyPackage: []byte("kp-last"), LastResort: true}}, claimed)
+		}
+
+		err = repo.AddKeyPackages(recipientId, "phone", nil, []byte("kp-last-2"), 3, 2)
+		assert.NoError(t, err)
+
+		claimed, err = repo.ClaimKeyPackages(recipientId)
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.KeyPackage{{DeviceID: "phone", KeyPackage: []byte("kp-last-2"), LastResort: true}}, claimed)
+	})
+
+	t.Run("CreateGroup", func(t *testing.T) {
+		group, err := repo.CreateGroup(vaultId)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), group.Epoch)
+
+		_, err = repo.CreateGroup(vaultId)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("AddProposal", func(t *testing.T) {
+		message, err := repo.AddProposal(vaultId, senderId, 0, []byte("proposal"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), message.Seq)
+
+		_, err = repo.AddProposal(vaultId, senderId, 1, []byte("proposal"))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("AddCommit", func(t *testing.T) {
+		message, err := repo.AddCommit(vaultId, senderId, 0, []byte("commit"), []dto.Welcome{
+			{RecipientID: recipientId, Welcome: []byte("welcome")},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), message.Seq)
+		assert.Equal(t, int64(0), message.Epoch)
+
+		_, err = repo.AddCommit(vaultId, senderId, 0, []byte("stale"), nil)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		group, err := repo.GetGroup(vaultId)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), group.Epoch)
+		assert.Equal(t, int64(3), group.LastSeq)
+	})
+
+	t.Run("GetMessages", func(t *testing.T) {
+		messages, err := repo.GetMessages(vaultId, senderId, 0, 10)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 2)
+
+		messages, err = repo.GetMessages(vaultId, recipientId, 1, 10)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, dto.ContentTypeWelcome, messages[1].ContentType)
+		assert.Equal(t, int64(1), messages[1].Epoch)
+
+		welcomes, err := repo.GetWelcomes(recipientId)
+		assert.NoError(t, err)
+		assert.Len(t, welcomes, 1)
+	})
+}
@@ -0,0 +1,170 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	addKeyPackage      statementsItem
+	setLastResort      statementsItem
+	countDevicePackage statementsItem
+	lockUser           statementsItem
+	countDevices       statementsItem
+	countKeyPackages   statementsItem
+	claimKeyPackages   statementsItem
+	createGroup        statementsItem
+	getGroup           statementsItem
+	addProposal        statementsItem
+	advanceEpoch       statementsItem
+	addMessage         statementsItem
+	getMessages        statementsItem
+	getWelcomes        statementsItem
+}
+
+var statementsList = statements{
+	addKeyPackage: statementsItem{
+		name: "addKeyPackage",
+		query: `
+			INSERT INTO mls_key_packages (user_id, device_id, key_package)
+			VALUES ($1, $2, $3);`,
+	},
+	setLastResort: statementsItem{
+		name: "setLastResort",
+		query: `
+			INSERT INTO mls_key_packages (user_id, device_id, key_package, last_resort)
+			VALUES ($1, $2, $3, TRUE)
+			ON CONFLICT (user_id, device_id) WHERE last_resort
+			DO UPDATE SET key_package = EXCLUDED.key_package, created_at = CURRENT_TIMESTAMP;`,
+	},
+	countDevicePackage: statementsItem{
+		name: "countDevicePackage",
+		query: `
+			SELECT COUNT(*) FILTER (WHERE NOT last_resort), COUNT(*)
+			FROM mls_key_packages
+			WHERE user_id = $1
+			AND device_id = $2;`,
+	},
+	lockUser: statementsItem{
+		name: "lockUser",
+		query: `
+			SELECT id
+			FROM users
+			WHERE id = $1
+			FOR NO KEY UPDATE;`,
+	},
+	countDevices: statementsItem{
+		name: "countDevices",
+		query: `
+			SELECT COUNT(DISTINCT device_id)
+			FROM mls_key_packages
+			WHERE user_id = $1
+			AND device_id <> $2;`,
+	},
+	countKeyPackages: statementsItem{
+		name: "countKeyPackages",
+		query: `
+			SELECT device_id, COUNT(*) FILTER (WHERE NOT last_resort), BOOL_OR(last_resort)
+			FROM mls_key_packages
+			WHERE user_id = $1
+			GROUP BY device_id
+			ORDER BY device_id;`,
+	},
+	claimKeyPackages: statementsItem{
+		name: "claimKeyPackages",
+		query: `
+			WITH claimed AS (
+				DELETE FROM mls_key_packages
+				WHERE id IN (
+					SELECT id
+					FROM (
+						SELECT id, ROW_NUMBER() OVER (PARTITION BY device_id ORDER BY id) AS position
+						FROM mls_key_packages
+						WHERE user_id = $1
+						AND NOT last_resort
+					) oldest
+					WHERE position = 1
+				)
+				RETURNING device_id, key_package
+			)
+			SELECT device_id, key_package, FALSE
+			FROM claimed
+			UNION ALL
+			SELECT k.device_id, k.key_package, TRUE
+			FROM mls_key_packages k
+			WHERE k.user_id = $1
+			AND k.last_resort
+			AND NOT EXISTS (SELECT 1 FROM claimed c WHERE c.device_id = k.device_id);`,
+	},
+	createGroup: statementsItem{
+		name: "createGroup",
+		query: `
+			INSERT INTO mls_groups (vault_id)
+			VALUES ($1)
+			ON CONFLICT (vault_id) DO NOTHING
+			RETURNING vault_id, epoch, last_seq, created_at;`,
+	},
+	getGroup: statementsItem{
+		name: "getGroup",
+		query: `
+			SELECT vault_id, epoch, last_seq, created_at
+			FROM mls_groups
+			WHERE vault_id = $1;`,
+	},
+	addProposal: statementsItem{
+		name: "addProposal",
+		query: `
+			WITH g AS (
+				UPDATE mls_groups
+				SET last_seq = last_seq + 1, updated_at = CURRENT_TIMESTAMP
+				WHERE vault_id = $1
+				AND epoch = $2
+				RETURNING vault_id, epoch, last_seq
+			)
+			INSERT INTO mls_messages (vault_id, seq, epoch, content_type, sender_id, payload)
+			SELECT vault_id, last_seq, epoch, 'proposal', $3, $4
+			FROM g
+			RETURNING seq, epoch, created_at;`,
+	},
+	advanceEpoch: statementsItem{
+		name: "advanceEpoch",
+		query: `
+			UPDATE mls_groups
+			SET epoch = epoch + 1, last_seq = last_seq + $3, updated_at = CURRENT_TIMESTAMP
+			WHERE vault_id = $1
+			AND epoch = $2
+			RETURNING last_seq;`,
+	},
+	addMessage: statementsItem{
+		name: "addMessage",
+		query: `
+			INSERT INTO mls_messages (vault_id, seq, epoch, content_type, sender_id, recipient_id, payload)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING created_at;`,
+	},
+	getMessages: statementsItem{
+		name: "getMessages",
+		query: `
+			SELECT m.vault_id, m.seq, m.epoch, m.content_type, COALESCE(u.user_address, ''), m.payload, m.created_at
+			FROM mls_messages m
+			LEFT JOIN users u ON u.id = m.sender_id
+			WHERE m.vault_id = $1
+			AND m.seq > $2
+			AND (m.recipient_id IS NULL OR m.recipient_id = $3)
+			ORDER BY m.seq
+			LIMIT $4;`,
+	},
+	getWelcomes: statementsItem{
+		name: "getWelcomes",
+		query: `
+			SELECT m.vault_id, m.seq, m.epoch, m.content_type, COALESCE(u.user_address, ''), m.payload, m.created_at
+			FROM mls_messages m
+			LEFT JOIN users u ON u.id = m.sender_id
+			WHERE m.recipient_id = $1
+			AND m.content_type = 'welcome'
+			ORDER BY m.created_at DESC, m.seq DESC;`,
+	},
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ObscuraNote/api-general/internal/mls/dto"
+	r "github.com/ObscuraNote/api-general/internal/mls/repository"
+	u "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	vDto "github.com/ObscuraNote/api-general/internal/vaults/dto"
+	v "github.com/ObscuraNote/api-general/internal/vaults/service"
+	"github.com/philippe-berto/logger"
+)
+
+var _ MLSService = (*Service)(nil)
+
+const (
+	maxDeviceIDLength = 64
+	defaultPageSize   = 100
+	maxPageSize       = 500
+)
+
+type (
+	// MLSService is an RFC 9420 delivery service for vault groups. It never
+	// looks inside MLS messages; it only hands out KeyPackages and keeps a
+	// strictly ordered, epoch-checked message sequence per vault.
+	MLSService interface {
+		UploadKeyPackages(input dto.KeyPackagesInput) error
+		GetKeyPackageCounts(auth dto.AuthInput) ([]dto.KeyPackageCount, error)
+		ClaimKeyPackages(userAddress string, auth dto.AuthInput) ([]dto.KeyPackage, error)
+		CreateGroup(vaultId string, auth dto.AuthInput) (*dto.Group, error)
+		GetGroup(vaultId string, auth dto.AuthInput) (*dto.Group, error)
+		SendProposal(vaultId string, input dto.ProposalInput) (*dto.Message, error)
+		SendCommit(vaultId string, input dto.CommitInput) (*dto.Message, error)
+		GetMessages(vaultId string, afterSeq, limit int64, auth dto.AuthInput) ([]dto.Message, error)
+		GetWelcomes(auth dto.AuthInput) ([]dto.Message, error)
+	}
+
+	Service struct {
+		ctx context.Context
+		r   r.MLSRepository
+		us  u.UserService
+		vs  v.VaultsService
+		cfg config.MLSConfig
+		log *logger.Logger
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.MLSRepository, us u.UserService, vs v.VaultsService, cfg config.MLSConfig) *Service {
+	return &Service{
+		ctx: ctx,
+		log: &log,
+		r:   repo,
+		us:  us,
+		vs:  vs,
+		cfg: cfg,
+	}
+}
+
+func (s *Service) UploadKeyPackages(input dto.KeyPackagesInput) error {
+	if input.DeviceID == "" || len(input.DeviceID) > maxDeviceIDLength || (len(input.KeyPackages) == 0 && input.LastResort == nil) {
+		return fmt.Errorf(utils.BadRequest)
+	}
+
+	keyPackages := input.KeyPackages
+	if input.LastResort != nil {
+		keyPackages = append([][]byte{input.LastResort}, input.KeyPackages...)
+	}
+	for _, keyPackage := range keyPackages {
+		if len(keyPackage) == 0 {
+			return fmt.Errorf(utils.BadRequest)
+		}
+		if len(keyPackage) > s.cfg.MaxMessageSize {
+			return fmt.Errorf(utils.PayloadTooLarge)
+		}
+	}
+
+	userId, err := s.getUserId(input.UserAddress, input.Password)
+	if err != nil {
+		return err
+	}
+
+	entitlements, err := s.us.GetEntitlements(userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.r.AddKeyPackages(userId, input.DeviceID, input.KeyPackages, input.LastResort, s.cfg.MaxKeyPackages, entitlements.MaxDevices)
+	if errors.Is(err, r.ErrTooManyKeyPackages) {
+		return fmt.Errorf(utils.BadRequest)
+	}
+	if errors.Is(err, r.ErrTooManyDevices) {
+		return fmt.Errorf(utils.NotEntitled)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "UploadKeyPackages"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return nil
+}
+
+func (s *Service) GetKeyPackageCounts(auth dto.AuthInput) ([]dto.KeyPackageCount, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.r.CountKeyPackages(userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "GetKeyPackageCounts"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return counts, nil
+}
+
+// ClaimKeyPackages hands out one KeyPackage per device of userAddress. Each
+// KeyPackage is given to a single caller only; devices that ran out fall
+// back to their last-resort KeyPackage, so repeated claims cannot leave the
+// user unreachable. Devices with neither are left out of the result.
+func (s *Service) ClaimKeyPackages(userAddress string, auth dto.AuthInput) ([]dto.KeyPackage, error) {
+	if _, err := s.getUserId(auth.UserAddress, auth.Password); err != nil {
+		return nil, err
+	}
+
+	userId, err := s.us.GetUserIdByAddress(userAddress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.UserNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	keyPackages, err := s.r.ClaimKeyPackages(userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "ClaimKeyPackages"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if len(keyPackages) == 0 {
+		return nil, fmt.Errorf(utils.NoKeyPackages)
+	}
+
+	return keyPackages, nil
+}
+
+func (s *Service) CreateGroup(vaultId string, auth dto.AuthInput) (*dto.Group, error) {
+	if _, err := s.authorize(vaultId, auth, vDto.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	group, err := s.r.CreateGroup(vaultId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.GroupExists)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "CreateGroup"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return group, nil
+}
+
+func (s *Service) GetGroup(vaultId string, auth dto.AuthInput) (*dto.Group, error) {
+	if _, err := s.authorize(vaultId, auth, vDto.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.getGroup(vaultId)
+}
+
+// SendProposal appends a Proposal. Any accepted vault member may propose, as
+// long as the proposal targets the current epoch.
+func (s *Service) SendProposal(vaultId string, input dto.ProposalInput) (*dto.Message, error) {
+	if err := s.validatePayload(input.Proposal); err != nil {
+		return nil, err
+	}
+
+	userId, err := s.authorize(vaultId, dto.AuthInput{UserAddress: input.UserAddress, Password: input.Password}, vDto.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.r.AddProposal(vaultId, userId, input.Epoch, input.Proposal)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, s.epochError(vaultId)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "SendProposal"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	message.SenderAddress = input.UserAddress
+
+	return message, nil
+}
+
+// SendCommit appends a Commit and its Welcomes and advances the group epoch.
+// Commits built on a stale epoch are rejected with STALE_EPOCH; the client must
+// fetch the winning Commit, process it and try again.
+func (s *Service) SendCommit(vaultId string, input dto.CommitInput) (*dto.Message, error) {
+	if err := s.validatePayload(input.Commit); err != nil {
+		return nil, err
+	}
+
+	userId, err := s.authorize(vaultId, dto.AuthInput{UserAddress: input.UserAddress, Password: input.Password}, vDto.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, welcome := range input.Welcomes {
+		if err := s.validatePayload(welcome.Welcome); err != nil {
+			return nil, err
+		}
+
+		recipientId, err := s.us.GetUserIdByAddress(welcome.RecipientAddress)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf(utils.UserNotFound)
+		}
+		if err != nil {
+			return nil, fmt.Errorf(utils.ErrDatabase)
+		}
+		input.Welcomes[i].RecipientID = recipientId
+	}
+
+	message, err := s.r.AddCommit(vaultId, userId, input.Epoch, input.Commit, input.Welcomes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, s.epochError(vaultId)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "SendCommit"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	message.SenderAddress = input.UserAddress
+
+	return message, nil
+}
+
+func (s *Service) GetMessages(vaultId string, afterSeq, limit int64, auth dto.AuthInput) ([]dto.Message, error) {
+	if afterSeq < 0 || limit < 0 {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	userId, err := s.authorize(vaultId, auth, vDto.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.r.GetMessages(vaultId, userId, afterSeq, limit)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "GetMessages"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return messages, nil
+}
+
+// GetWelcomes lists the Welcome messages addressed to the caller. Invited
+// members read them here before they can join the vault group.
+func (s *Service) GetWelcomes(auth dto.AuthInput) ([]dto.Message, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	welcomes, err := s.r.GetWelcomes(userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "GetWelcomes"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return welcomes, nil
+}
+
+func (s *Service) authorize(vaultId string, auth dto.AuthInput, minRole string) (int64, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.vs.RequireRole(vaultId, userId, minRole); err != nil {
+		return 0, err
+	}
+
+	return userId, nil
+}
+
+func (s *Service) getGroup(vaultId string) (*dto.Group, error) {
+	group, err := s.r.GetGroup(vaultId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.GroupNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "getGroup"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return group, nil
+}
+
+// epochError explains a rejected Proposal or Commit: either the group does not
+// exist, or the message was built on an epoch that is no longer current.
+func (s *Service) epochError(vaultId string) error {
+	if _, err := s.getGroup(vaultId); err != nil {
+		return err
+	}
+
+	return fmt.Errorf(utils.StaleEpoch)
+}
+
+func (s *Service) validatePayload(payload []byte) error {
+	if len(payload) == 0 {
+		return fmt.Errorf(utils.BadRequest)
+	}
+	if len(payload) > s.cfg.MaxMessageSize {
+		return fmt.Errorf(utils.PayloadTooLarge)
+	}
+
+	return nil
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "mls_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
@@ -0,0 +1,56 @@
+package dto
+
+type (
+	AuthInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+	}
+	// OperationInput is one CRDT operation encrypted with the entry key. The
+	// server stores it as is and only assigns its sequence number.
+	OperationInput struct {
+		EncryptedOp []byte `json:"encrypted_op" db:"encrypted_op"`
+		OpIV        []byte `json:"op_iv" db:"op_iv"`
+	}
+	PushInput struct {
+		UserAddress string           `json:"user_address" db:"user_address"`
+		Password    string           `json:"password" db:"password"`
+		Operations  []OperationInput `json:"operations"`
+	}
+	// PushResult holds the sequence numbers given to a pushed batch, which are
+	// consecutive and in request order.
+	PushResult struct {
+		FirstSeq int64 `json:"first_seq" db:"first_seq"`
+		LastSeq  int64 `json:"last_seq" db:"last_seq"`
+	}
+	Operation struct {
+		Seq           int64  `json:"seq" db:"seq"`
+		AuthorAddress string `json:"author_address" db:"author_address"`
+		EncryptedOp   []byte `json:"encrypted_op" db:"encrypted_op"`
+		OpIV          []byte `json:"op_iv" db:"op_iv"`
+		CreatedAt     string `json:"created_at" db:"created_at"`
+	}
+	// SnapshotInput is the encrypted entry state after applying every
+	// operation up to and including Seq.
+	SnapshotInput struct {
+		UserAddress       string `json:"user_address" db:"user_address"`
+		Password          string `json:"password" db:"password"`
+		Seq               int64  `json:"seq" db:"seq"`
+		EncryptedSnapshot []byte `json:"encrypted_snapshot" db:"encrypted_snapshot"`
+		SnapshotIV        []byte `json:"snapshot_iv" db:"snapshot_iv"`
+	}
+	Snapshot struct {
+		Seq               int64  `json:"seq" db:"seq"`
+		EncryptedSnapshot []byte `json:"encrypted_snapshot" db:"encrypted_snapshot"`
+		SnapshotIV        []byte `json:"snapshot_iv" db:"snapshot_iv"`
+		CreatedAt         string `json:"created_at" db:"created_at"`
+	}
+	// OperationsPage answers a pull. Snapshot is set when operations the
+	// client has not seen were compacted into it; the client loads it first
+	// and then applies Operations. LatestSeq is the last sequence number
+	// assigned to the entry.
+	OperationsPage struct {
+		Snapshot   *Snapshot   `json:"snapshot,omitempty"`
+		Operations []Operation `json:"operations"`
+		LatestSeq  int64       `json:"latest_seq" db:"latest_seq"`
+	}
+)
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ObscuraNote/api-general/internal/oplog/dto"
+	oService "github.com/ObscuraNote/api-general/internal/oplog/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+type handler struct {
+	log *logger.Logger
+	ol  oService.OplogService
+}
+
+func Register(router chi.Router, ol oService.OplogService, log logger.Logger) {
+	h := &handler{
+		log: &log,
+		ol:  ol,
+	}
+
+	router.Post("/keys/{id}/ops", h.PushOperations)
+	router.Get("/keys/{id}/ops", h.PullOperations)
+	router.Put("/keys/{id}/snapshot", h.SaveSnapshot)
+}
+
+func (h *handler) PushOperations(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var input dto.PushInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	result, err := h.ol.PushOperations(keyID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "oplog", "function": "PushOperations"}).
+			Error("Failed to push operations")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, result); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "oplog", "function": "PushOperations"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) PullOperations(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	after, err := utils.ParseInt64Query(w, r, "after", 0)
+	if err != nil {
+		return
+	}
+
+	limit, err := utils.ParseInt64Query(w, r, "limit", 0)
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.ol.PullOperations(keyID.String(), after, limit, auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "oplog", "function": "PullOperations"}).
+			Error("Failed to pull operations")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, page); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "oplog", "function": "PullOperations"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) SaveSnapshot(w http.ResponseWriter, r *http.Request) {
+	keyID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var input dto.SnapshotInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	snapshot, err := h.ol.SaveSnapshot(keyID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "oplog", "function": "SaveSnapshot"}).
+			Error("Failed to save snapshot")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, snapshot); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "oplog", "function": "SaveSnapshot"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.Forbidden:
+		_ = utils.Fault(w, http.StatusForbidden, utils.Forbidden)
+	case utils.BadRequest:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.PayloadTooLarge:
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, err.Error())
+	case utils.KeyNotFound:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	case utils.VersionConflict:
+		_ = utils.Fault(w, http.StatusConflict, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/ObscuraNote/api-general/internal/oplog/dto"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
+)
+
+var _ OplogRepository = (*Repository)(nil)
+
+// ErrSnapshotAhead is returned when a snapshot claims to cover operations that
+// were never pushed.
+var ErrSnapshotAhead = errors.New("snapshot is ahead of the operation log")
+
+type (
+	OplogRepository interface {
+		PushOperations(keyId string, authorId int64, operations []dto.OperationInput) (*dto.PushResult, error)
+		PullOperations(keyId string, afterSeq, limit int64) (*dto.OperationsPage, error)
+		SaveSnapshot(keyId string, snapshot dto.SnapshotInput, keep int) (*dto.Snapshot, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+// PushOperations appends a batch of operations to the entry log. The batch gets
+// consecutive sequence numbers from the entry's counter, so concurrent pushes
+// never interleave or reuse a number. It returns sql.ErrNoRows when the entry
+// does not exist.
+func (r *Repository) PushOperations(keyId string, authorId int64, operations []dto.OperationInput) (*dto.PushResult, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var lastSeq int64
+		if err := tx.StmtxContext(ctx, r.statements.reserveSeq.statement).
+			QueryRowContext(ctx, keyId, len(operations)).Scan(&lastSeq); err != nil {
+			return nil, err
+		}
+
+		firstSeq := lastSeq - int64(len(operations)) + 1
+		for i, operation := range operations {
+			if _, err := tx.StmtxContext(ctx, r.statements.addOperation.statement).
+				ExecContext(ctx, keyId, firstSeq+int64(i), authorId, operation.EncryptedOp, operation.OpIV); err != nil {
+				return nil, err
+			}
+		}
+
+		return &dto.PushResult{FirstSeq: firstSeq, LastSeq: lastSeq}, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("Error pushing operations")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.PushResult), nil
+}
+
+// PullOperations returns up to limit operations after afterSeq. When some of
+// them were already compacted and are not kept as history, the snapshot is
+// returned too and the operations start right after it. The entry row is share-locked so that a concurrent
+// compaction cannot open a gap between the snapshot and the operations.
+func (r *Repository) PullOperations(keyId string, afterSeq, limit int64) (*dto.OperationsPage, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var page dto.OperationsPage
+		if err := tx.StmtxContext(ctx, r.statements.getLatestSeq.statement).
+			QueryRowContext(ctx, keyId).Scan(&page.LatestSeq); err != nil {
+			return nil, err
+		}
+
+		var snapshot dto.Snapshot
+		err := tx.StmtxContext(ctx, r.statements.getSnapshot.statement).
+			QueryRowContext(ctx, keyId).
+			Scan(&snapshot.Seq, &snapshot.EncryptedSnapshot, &snapshot.SnapshotIV, &snapshot.CreatedAt)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if err == nil && afterSeq < snapshot.Seq {
+			var oldestSeq int64
+			if err := tx.StmtxContext(ctx, r.statements.getOldestSeq.statement).
+				QueryRowContext(ctx, keyId).Scan(&oldestSeq); err != nil {
+				return nil, err
+			}
+
+			// Operations are always dropped from the start of the log, so
+			// the kept ones run without gaps from oldestSeq.
+			if oldestSeq == 0 || oldestSeq > afterSeq+1 {
+				page.Snapshot = &snapshot
+				afterSeq = snapshot.Seq
+			}
+		}
+
+		rows, err := tx.StmtxContext(ctx, r.statements.getOperations.statement).
+			QueryContext(ctx, keyId, afterSeq, limit)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var operation dto.Operation
+			if err := rows.Scan(&operation.Seq, &operation.AuthorAddress, &operation.EncryptedOp, &operation.OpIV,
+				&operation.CreatedAt); err != nil {
+				return nil, err
+			}
+			page.Operations = append(page.Operations, operation)
+		}
+
+		return &page, rows.Err()
+	}))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("Error pulling operations")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.OperationsPage), nil
+}
+
+// SaveSnapshot stores a snapshot and drops the operations it covers, except
+// for the last keep of them that stay available as history. It
+// returns ErrSnapshotAhead when the snapshot covers unknown operations, and
+// sql.ErrNoRows when the entry does not exist or already has a snapshot at
+// the same or a later sequence number.
+func (r *Repository) SaveSnapshot(keyId string, snapshot dto.SnapshotInput, keep int) (*dto.Snapshot, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var latestSeq int64
+		if err := tx.StmtxContext(ctx, r.statements.lockKey.statement).
+			QueryRowContext(ctx, keyId).Scan(&latestSeq); err != nil {
+			return nil, err
+		}
+
+		if snapshot.Seq > latestSeq {
+			return nil, ErrSnapshotAhead
+		}
+
+		var saved dto.Snapshot
+		if err := tx.StmtxContext(ctx, r.statements.upsertSnapshot.statement).
+			QueryRowContext(ctx, keyId, snapshot.Seq, snapshot.EncryptedSnapshot, snapshot.SnapshotIV).
+			Scan(&saved.Seq, &saved.EncryptedSnapshot, &saved.SnapshotIV, &saved.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.StmtxContext(ctx, r.statements.deleteOperations.statement).
+			ExecContext(ctx, keyId, snapshot.Seq-int64(keep)); err != nil {
+			return nil, err
+		}
+
+		return &saved, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, ErrSnapshotAhead) {
+			log.Println("Error saving snapshot")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.Snapshot), nil
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.reserveSeq.statement, err = r.db.PrepareStatement(statementsList.reserveSeq.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.addOperation.statement, err = r.db.PrepareStatement(statementsList.addOperation.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getLatestSeq.statement, err = r.db.PrepareStatement(statementsList.getLatestSeq.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockKey.statement, err = r.db.PrepareStatement(statementsList.lockKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getOperations.statement, err = r.db.PrepareStatement(statementsList.getOperations.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getOldestSeq.statement, err = r.db.PrepareStatement(statementsList.getOldestSeq.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getSnapshot.statement, err = r.db.PrepareStatement(statementsList.getSnapshot.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.upsertSnapshot.statement, err = r.db.PrepareStatement(statementsList.upsertSnapshot.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteOperations.statement, err = r.db.PrepareStatement(statementsList.deleteOperations.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
@@ -0,0 +1,118 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ObscuraNote/api-general/internal/oplog/dto"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE keys CASCADE;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE keys CASCADE;")
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	db.GetClient().Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa');
+	`)
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var userId int64
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '1111111111111111111111111111111111111111111111111111111111111111';
+	`).Scan(&userId)
+	if err != nil {
+		t.Fatalf("failed to get user id: %v", err)
+	}
+
+	var keyId string
+	err = db.GetClient().QueryRow(`
+		INSERT INTO keys (user_id, user_address, encrypted_key, key_iv, encrypted_data, data_iv)
+		VALUES ($1, '1111111111111111111111111111111111111111111111111111111111111111', 'key', 'iv', 'data', 'iv')
+		RETURNING id;
+	`, userId).Scan(&keyId)
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+
+	t.Run("PushOperations", func(t *testing.T) {
+		result, err := repo.PushOperations(keyId, userId, []dto.OperationInput{{EncryptedOp: []byte("op-1")}, {EncryptedOp: []byte("op-2")}})
+		assert.NoError(t, err)
+		assert.Equal(t, dto.PushResult{FirstSeq: 1, LastSeq: 2}, *result)
+
+		result, err = repo.PushOperations(keyId, userId, []dto.OperationInput{{EncryptedOp: []byte("op-3")}})
+		assert.NoError(t, err)
+		assert.Equal(t, dto.PushResult{FirstSeq: 3, LastSeq: 3}, *result)
+
+		_, err = repo.PushOperations("00000000-0000-0000-0000-000000000000", userId, []dto.OperationInput{{EncryptedOp: []byte("op")}})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("PullOperations", func(t *testing.T) {
+		page, err := repo.PullOperations(keyId, 1, 10)
+		assert.NoError(t, err)
+		assert.Nil(t, page.Snapshot)
+		assert.Equal(t, int64(3), page.LatestSeq)
+		assert.Len(t, page.Operations, 2)
+		assert.Equal(t, []byte("op-2"), page.Operations[0].EncryptedOp)
+	})
+
+	t.Run("SaveSnapshot", func(t *testing.T) {
+		_, err := repo.SaveSnapshot(keyId, dto.SnapshotInput{Seq: 4, EncryptedSnapshot: []byte("state")}, 0)
+		assert.ErrorIs(t, err, ErrSnapshotAhead)
+
+		snapshot, err := repo.SaveSnapshot(keyId, dto.SnapshotInput{Seq: 2, EncryptedSnapshot: []byte("state-2")}, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), snapshot.Seq)
+
+		_, err = repo.SaveSnapshot(keyId, dto.SnapshotInput{Seq: 1, EncryptedSnapshot: []byte("state-1")}, 0)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		page, err := repo.PullOperations(keyId, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("state-2"), page.Snapshot.EncryptedSnapshot)
+		assert.Len(t, page.Operations, 1)
+		assert.Equal(t, int64(3), page.Operations[0].Seq)
+
+		page, err = repo.PullOperations(keyId, 1, 10)
+		assert.NoError(t, err)
+		assert.Nil(t, page.Snapshot)
+		assert.Len(t, page.Operations, 2)
+		assert.Equal(t, int64(2), page.Operations[0].Seq)
+
+		page, err = repo.PullOperations(keyId, 3, 10)
+		assert.NoError(t, err)
+		assert.Nil(t, page.Snapshot)
+		assert.Len(t, page.Operations, 0)
+	})
+}
@@ -0,0 +1,97 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	reserveSeq       statementsItem
+	addOperation     statementsItem
+	getLatestSeq     statementsItem
+	lockKey          statementsItem
+	getOperations    statementsItem
+	getOldestSeq     statementsItem
+	getSnapshot      statementsItem
+	upsertSnapshot   statementsItem
+	deleteOperations statementsItem
+}
+
+var statementsList = statements{
+	reserveSeq: statementsItem{
+		name: "reserveSeq",
+		query: `
+			UPDATE keys
+			SET op_seq = op_seq + $2
+			WHERE id = $1
+			RETURNING op_seq;`,
+	},
+	addOperation: statementsItem{
+		name: "addOperation",
+		query: `
+			INSERT INTO key_operations (key_id, seq, author_id, encrypted_op, op_iv)
+			VALUES ($1, $2, $3, $4, $5);`,
+	},
+	getLatestSeq: statementsItem{
+		name: "getLatestSeq",
+		query: `
+			SELECT op_seq
+			FROM keys
+			WHERE id = $1
+			FOR SHARE;`,
+	},
+	lockKey: statementsItem{
+		name: "lockKey",
+		query: `
+			SELECT op_seq
+			FROM keys
+			WHERE id = $1
+			FOR UPDATE;`,
+	},
+	getOperations: statementsItem{
+		name: "getOperations",
+		query: `
+			SELECT o.seq, COALESCE(u.user_address, ''), o.encrypted_op, o.op_iv, o.created_at
+			FROM key_operations o
+			LEFT JOIN users u ON u.id = o.author_id
+			WHERE o.key_id = $1
+			AND o.seq > $2
+			ORDER BY o.seq
+			LIMIT $3;`,
+	},
+	getOldestSeq: statementsItem{
+		name: "getOldestSeq",
+		query: `
+			SELECT COALESCE(MIN(seq), 0)
+			FROM key_operations
+			WHERE key_id = $1;`,
+	},
+	getSnapshot: statementsItem{
+		name: "getSnapshot",
+		query: `
+			SELECT seq, encrypted_snapshot, snapshot_iv, created_at
+			FROM key_snapshots
+			WHERE key_id = $1;`,
+	},
+	upsertSnapshot: statementsItem{
+		name: "upsertSnapshot",
+		query: `
+			INSERT INTO key_snapshots (key_id, seq, encrypted_snapshot, snapshot_iv)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (key_id) DO UPDATE
+			SET seq = EXCLUDED.seq, encrypted_snapshot = EXCLUDED.encrypted_snapshot,
+				snapshot_iv = EXCLUDED.snapshot_iv, created_at = CURRENT_TIMESTAMP
+			WHERE key_snapshots.seq < EXCLUDED.seq
+			RETURNING seq, encrypted_snapshot, snapshot_iv, created_at;`,
+	},
+	deleteOperations: statementsItem{
+		name: "deleteOperations",
+		query: `
+			DELETE FROM key_operations
+			WHERE key_id = $1
+			AND seq <= $2;`,
+	},
+}
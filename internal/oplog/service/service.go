@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	k "github.com/ObscuraNote/api-general/internal/keys/service"
+	"github.com/ObscuraNote/api-general/internal/oplog/dto"
+	r "github.com/ObscuraNote/api-general/internal/oplog/repository"
+	u "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/philippe-berto/logger"
+)
+
+var _ OplogService = (*Service)(nil)
+
+const (
+	maxOperationSize = 64 * 1024
+	maxSnapshotSize  = 4 * 1024 * 1024
+	maxPushBatch     = 100
+	defaultPageSize  = 500
+	maxPageSize      = 1000
+)
+
+type (
+	// OplogService keeps an append-only log of encrypted CRDT operations per
+	// entry. It orders and stores operations but never merges them; clients
+	// do that after decrypting.
+	OplogService interface {
+		PushOperations(keyId string, input dto.PushInput) (*dto.PushResult, error)
+		PullOperations(keyId string, afterSeq, limit int64, auth dto.AuthInput) (*dto.OperationsPage, error)
+		SaveSnapshot(keyId string, input dto.SnapshotInput) (*dto.Snapshot, error)
+	}
+
+	Service struct {
+		ctx context.Context
+		r   r.OplogRepository
+		us  u.UserService
+		ks  k.KeysService
+		log *logger.Logger
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.OplogRepository, us u.UserService, ks k.KeysService) *Service {
+	return &Service{
+		ctx: ctx,
+		log: &log,
+		r:   repo,
+		us:  us,
+		ks:  ks,
+	}
+}
+
+func (s *Service) PushOperations(keyId string, input dto.PushInput) (*dto.PushResult, error) {
+	if len(input.Operations) == 0 || len(input.Operations) > maxPushBatch {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	for _, operation := range input.Operations {
+		if len(operation.EncryptedOp) == 0 {
+			return nil, fmt.Errorf(utils.BadRequest)
+		}
+		if len(operation.EncryptedOp) > maxOperationSize {
+			return nil, fmt.Errorf(utils.PayloadTooLarge)
+		}
+	}
+
+	userId, err := s.getUserId(input.UserAddress, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ks.AuthorizeKeyWrite(keyId, userId); err != nil {
+		return nil, err
+	}
+
+	result, err := s.r.PushOperations(keyId, userId, input.Operations)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.KeyNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "oplog_service", "function": "PushOperations"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return result, nil
+}
+
+func (s *Service) PullOperations(keyId string, afterSeq, limit int64, auth dto.AuthInput) (*dto.OperationsPage, error) {
+	if afterSeq < 0 || limit < 0 {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.ks.AuthorizeKey(keyId, userId); err != nil {
+		return nil, err
+	}
+
+	page, err := s.r.PullOperations(keyId, afterSeq, limit)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.KeyNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "oplog_service", "function": "PullOperations"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if page.Operations == nil {
+		page.Operations = []dto.Operation{}
+	}
+
+	return page, nil
+}
+
+// SaveSnapshot compacts the log, keeping as many operations behind the
+// snapshot as the caller's plan allows as history. Snapshots older than the
+// stored one are rejected with VERSION_CONFLICT, and snapshots past the last
+// pushed operation with BAD_REQUEST.
+func (s *Service) SaveSnapshot(keyId string, input dto.SnapshotInput) (*dto.Snapshot, error) {
+	if input.Seq <= 0 || len(input.EncryptedSnapshot) == 0 {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if len(input.EncryptedSnapshot) > maxSnapshotSize {
+		return nil, fmt.Errorf(utils.PayloadTooLarge)
+	}
+
+	userId, err := s.getUserId(input.UserAddress, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ks.AuthorizeKeyWrite(keyId, userId); err != nil {
+		return nil, err
+	}
+
+	entitlements, err := s.us.GetEntitlements(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.r.SaveSnapshot(keyId, input, entitlements.HistoryDepth)
+	if errors.Is(err, r.ErrSnapshotAhead) {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.VersionConflict)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "oplog_service", "function": "SaveSnapshot"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return snapshot, nil
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "oplog_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "encoding/json"
+
+const (
+	EventUserCreated         = "user.created"
+	EventKeyAdded            = "key.added"
+	EventUserPasswordChanged = "user.password_changed"
+	EventKeyUpdated          = "key.updated"
+	EventKeyDeleted          = "key.deleted"
+	EventShareCreated        = "share.created"
+	EventShareUpdated        = "share.updated"
+	EventShareRevoked        = "share.revoked"
+	EventReleaseScheduled    = "release.scheduled"
+	EventVaultMemberAdded    = "vault.member_added"
+	EventVaultKeyChanged     = "vault.key_changed"
+	EventVaultMemberRemoved  = "vault.member_removed"
+)
+
+type (
+	// Message is an outbox event as sinks deliver it. Delivery is at least
+	// once, so consumers drop the IDs they already handled.
+	Message struct {
+		ID        int64           `json:"id" db:"id"`
+		Type      string          `json:"type" db:"type"`
+		Payload   json.RawMessage `json:"payload" db:"payload"`
+		CreatedAt string          `json:"created_at" db:"created_at"`
+		Attempts  int             `json:"-" db:"attempts"`
+	}
+)
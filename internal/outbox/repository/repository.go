@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/outbox/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/ObscuraNote/api-general/internal/utils/lock"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+)
+
+var _ OutboxRepository = (*Repository)(nil)
+
+type (
+	// OutboxRepository hands out the domain events triggers write to the
+	// outbox and records how their delivery went.
+	OutboxRepository interface {
+		ClaimMessages(limit int) ([]dto.Message, error)
+		MarkDelivered(id int64) error
+		MarkFailed(id int64, reason string, backoff time.Duration, dead bool) error
+		DeleteOldDelivered() (int64, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+		cfg        config.OutboxConfig
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client, cfg config.OutboxConfig) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+		cfg:        cfg,
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+// ClaimMessages returns up to limit messages due for delivery, oldest first,
+// and keeps them from the other replicas for the lease period. A message
+// neither delivered nor failed by then is claimed again.
+func (r *Repository) ClaimMessages(limit int) ([]dto.Message, error) {
+	rows, err := r.statements.claimMessages.statement.
+		QueryContext(r.ctx, limit, r.cfg.Lease.Seconds())
+	if err != nil {
+		log.Println("Error claiming outbox messages")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []dto.Message
+	for rows.Next() {
+		var message dto.Message
+		var payload []byte
+		if err := rows.Scan(&message.ID, &message.Type, &payload, &message.CreatedAt, &message.Attempts); err != nil {
+			log.Println("Error scanning outbox message")
+
+			return nil, err
+		}
+		message.Payload = payload
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+
+	return messages, nil
+}
+
+func (r *Repository) MarkDelivered(id int64) error {
+	_, err := r.statements.markDelivered.statement.ExecContext(r.ctx, id)
+	if err != nil {
+		log.Println("Error marking outbox message delivered")
+	}
+
+	return err
+}
+
+// MarkFailed records a failed attempt and schedules the next one after
+// backoff. A dead message is not attempted again.
+func (r *Repository) MarkFailed(id int64, reason string, backoff time.Duration, dead bool) error {
+	_, err := r.statements.markFailed.statement.ExecContext(r.ctx, id, reason, backoff.Seconds(), dead)
+	if err != nil {
+		log.Println("Error marking outbox message failed")
+	}
+
+	return err
+}
+
+// DeleteOldDelivered purges the messages delivered before the retention
+// period. Dead messages are kept for an operator to look at. When another
+// replica is already purging, it does nothing and returns zero.
+func (r *Repository) DeleteOldDelivered() (int64, error) {
+	deleted, err := lock.TryExclusive(r.ctx, r.db, lock.OutboxReaper, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		result, err := tx.StmtxContext(ctx, r.statements.deleteOldDelivered.statement).
+			ExecContext(ctx, r.cfg.Retention.Seconds())
+		if err != nil {
+			return 0, err
+		}
+
+		return result.RowsAffected()
+	})
+	if err != nil {
+		log.Println("Error deleting delivered outbox messages")
+
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.claimMessages.statement, err = r.db.PrepareStatement(statementsList.claimMessages.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.markDelivered.statement, err = r.db.PrepareStatement(statementsList.markDelivered.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.markFailed.statement, err = r.db.PrepareStatement(statementsList.markFailed.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteOldDelivered.statement, err = r.db.PrepareStatement(statementsList.deleteOldDelivered.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
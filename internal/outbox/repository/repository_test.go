@@ -0,0 +1,180 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/outbox/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	db.GetClient().Exec("TRUNCATE TABLE outbox;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE outbox;")
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	repo, err := New(ctx, db, config.OutboxConfig{Lease: time.Minute, Retention: time.Hour})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var userId int64
+	err = db.GetClient().QueryRow(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa')
+		RETURNING id;
+	`).Scan(&userId)
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	var keyId string
+	err = db.GetClient().QueryRow(`
+		INSERT INTO keys (user_id, user_address, encrypted_key, key_iv, encrypted_data, data_iv)
+		VALUES ($1, '1111111111111111111111111111111111111111111111111111111111111111', 'key', 'iv', 'data', 'iv')
+		RETURNING id;
+	`, userId).Scan(&keyId)
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+
+	_, err = db.GetClient().Exec(`
+		UPDATE users SET password = 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb' WHERE id = $1;
+	`, userId)
+	assert.NoError(t, err)
+
+	// A change that rolls back leaves nothing in the outbox.
+	tx, err := db.GetClient().Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	_, err = tx.Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('2222222222222222222222222222222222222222222222222222222222222222', 'cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc');
+	`)
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Rollback())
+
+	messages, err := repo.ClaimMessages(10)
+	assert.NoError(t, err)
+	if !assert.Len(t, messages, 3) {
+		return
+	}
+	assert.Equal(t, dto.EventUserCreated, messages[0].Type)
+	assert.Equal(t, dto.EventKeyAdded, messages[1].Type)
+	assert.Equal(t, dto.EventUserPasswordChanged, messages[2].Type)
+
+	var payload struct {
+		KeyID   string  `json:"key_id"`
+		UserID  int64   `json:"user_id"`
+		VaultID *string `json:"vault_id"`
+	}
+	assert.NoError(t, json.Unmarshal(messages[1].Payload, &payload))
+	assert.Equal(t, keyId, payload.KeyID)
+	assert.Equal(t, userId, payload.UserID)
+	assert.Nil(t, payload.VaultID)
+
+	// Claimed messages are leased.
+	claimed, err := repo.ClaimMessages(10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	assert.NoError(t, repo.MarkDelivered(messages[0].ID))
+	assert.NoError(t, repo.MarkFailed(messages[1].ID, "unavailable", 0, false))
+	assert.NoError(t, repo.MarkFailed(messages[2].ID, "unavailable", 0, true))
+
+	claimed, err = repo.ClaimMessages(10)
+	assert.NoError(t, err)
+	if assert.Len(t, claimed, 1) {
+		assert.Equal(t, messages[1].ID, claimed[0].ID)
+		assert.Equal(t, 1, claimed[0].Attempts)
+	}
+
+	deleted, err := repo.DeleteOldDelivered()
+	assert.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	purge, err := New(ctx, db, config.OutboxConfig{Retention: -time.Hour})
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	deleted, err = purge.DeleteOldDelivered()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// Later changes of entries, shares, releases and vault keys.
+	_, err = db.GetClient().Exec("TRUNCATE TABLE outbox;")
+	assert.NoError(t, err)
+
+	var recipientId int64
+	err = db.GetClient().QueryRow(`
+		INSERT INTO users (user_address, password)
+		VALUES ('2222222222222222222222222222222222222222222222222222222222222222', 'cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc')
+		RETURNING id;
+	`).Scan(&recipientId)
+	if err != nil {
+		t.Fatalf("failed to create recipient: %v", err)
+	}
+
+	var vaultId string
+	err = db.GetClient().QueryRow(`INSERT INTO vaults (encrypted_name) VALUES ('name') RETURNING id;`).Scan(&vaultId)
+	if err != nil {
+		t.Fatalf("failed to create vault: %v", err)
+	}
+
+	for _, change := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{`UPDATE keys SET encrypted_data = 'data2', data_iv = 'iv2' WHERE user_id = $1;`, []interface{}{userId}},
+		{`INSERT INTO key_shares (key_id, owner_id, recipient_id, encrypted_key)
+		SELECT id, $1, $2, 'key' FROM keys WHERE user_id = $1;`, []interface{}{userId, recipientId}},
+		{`UPDATE key_shares SET status = 'accepted' WHERE owner_id = $1;`, []interface{}{userId}},
+		{`INSERT INTO key_releases (key_id, owner_id, recipient_id, encrypted_key, release_at)
+		SELECT id, $2, $1, 'key', CURRENT_TIMESTAMP FROM keys WHERE user_id = $1;`, []interface{}{userId, recipientId}},
+		{`INSERT INTO vault_members (vault_id, user_id, role, encrypted_vault_key, key_version)
+		VALUES ($1, $2, 'owner', 'vault-key', 1);`, []interface{}{vaultId, userId}},
+		{`UPDATE vault_members SET encrypted_vault_key = 'vault-key2', key_version = 2 WHERE vault_id = $1;`, []interface{}{vaultId}},
+		{`DELETE FROM vault_members WHERE vault_id = $1;`, []interface{}{vaultId}},
+		{`DELETE FROM keys WHERE user_id = $1;`, []interface{}{userId}},
+	} {
+		_, err = db.GetClient().Exec(change.query, change.args...)
+		assert.NoError(t, err, change.query)
+	}
+
+	messages, err = repo.ClaimMessages(20)
+	assert.NoError(t, err)
+
+	var types []string
+	for _, message := range messages {
+		types = append(types, message.Type)
+	}
+	assert.ElementsMatch(t, []string{dto.EventUserCreated, dto.EventKeyUpdated, dto.EventShareCreated, dto.EventShareUpdated,
+		dto.EventReleaseScheduled, dto.EventVaultMemberAdded, dto.EventVaultKeyChanged, dto.EventVaultMemberRemoved,
+		dto.EventShareRevoked, dto.EventKeyDeleted}, types)
+}
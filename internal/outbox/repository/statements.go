@@ -0,0 +1,58 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	claimMessages      statementsItem
+	markDelivered      statementsItem
+	markFailed         statementsItem
+	deleteOldDelivered statementsItem
+}
+
+var statementsList = statements{
+	claimMessages: statementsItem{
+		name: "claimMessages",
+		query: `
+			UPDATE outbox o
+			SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+			FROM (
+				SELECT id
+				FROM outbox
+				WHERE delivered_at IS NULL AND dead_at IS NULL AND next_attempt_at <= CURRENT_TIMESTAMP
+				ORDER BY id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			) due
+			WHERE o.id = due.id
+			RETURNING o.id, o.type, o.payload, o.created_at, o.attempts;`,
+	},
+	markDelivered: statementsItem{
+		name: "markDelivered",
+		query: `
+			UPDATE outbox
+			SET delivered_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+			WHERE id = $1;`,
+	},
+	markFailed: statementsItem{
+		name: "markFailed",
+		query: `
+			UPDATE outbox
+			SET attempts = attempts + 1,
+				last_error = $2,
+				next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $3),
+				dead_at = CASE WHEN $4 THEN CURRENT_TIMESTAMP END
+			WHERE id = $1;`,
+	},
+	deleteOldDelivered: statementsItem{
+		name: "deleteOldDelivered",
+		query: `
+			DELETE FROM outbox
+			WHERE delivered_at < CURRENT_TIMESTAMP - make_interval(secs => $1);`,
+	},
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	r "github.com/ObscuraNote/api-general/internal/outbox/repository"
+	"github.com/ObscuraNote/api-general/internal/outbox/sink"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/logger"
+)
+
+var _ OutboxService = (*Service)(nil)
+
+// maxErrorLength bounds the error kept on a failed message.
+const maxErrorLength = 1024
+
+type (
+	// OutboxService delivers the outbox to the configured sink, at least once
+	// and in order of creation within a batch. Several replicas can dispatch
+	// at once: each claims its own batch.
+	OutboxService interface {
+		Dispatch() (int64, error)
+	}
+
+	Service struct {
+		ctx  context.Context
+		r    r.OutboxRepository
+		sink sink.Sink
+		log  *logger.Logger
+		cfg  config.OutboxConfig
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.OutboxRepository, out sink.Sink, cfg config.OutboxConfig) *Service {
+	return &Service{
+		ctx:  ctx,
+		log:  &log,
+		r:    repo,
+		sink: out,
+		cfg:  cfg,
+	}
+}
+
+// Dispatch sends a batch of due messages and returns how many were
+// delivered. It stops at the first failure, which usually means the sink is
+// down: the rest of the batch is claimed again once its lease is over,
+// without counting an attempt against it.
+func (s *Service) Dispatch() (int64, error) {
+	messages, err := s.r.ClaimMessages(s.cfg.Batch)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "outbox_service", "function": "Dispatch"}).Error(utils.ErrDatabase)
+		return 0, err
+	}
+
+	// Messages not sent within the lease may already be claimed by another
+	// replica; they are left to it.
+	ctx, cancel := context.WithTimeout(s.ctx, s.cfg.Lease)
+	defer cancel()
+
+	var delivered int64
+	for _, message := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+
+		sendCtx, cancelSend := context.WithTimeout(ctx, s.cfg.Timeout)
+		err := s.sink.Send(sendCtx, message)
+		cancelSend()
+
+		if err == nil {
+			if err := s.r.MarkDelivered(message.ID); err != nil {
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		attempts := message.Attempts + 1
+		dead := s.cfg.MaxAttempts > 0 && attempts >= s.cfg.MaxAttempts
+		reason := err.Error()
+		if len(reason) > maxErrorLength {
+			reason = reason[:maxErrorLength]
+		}
+
+		fields := logger.Fields{"error": reason, "id": message.ID, "attempts": attempts, "component": "outbox_service", "function": "Dispatch"}
+		if dead {
+			s.log.WithFields(fields).Error("Outbox message gave up")
+		} else {
+			s.log.WithFields(fields).Warn("Outbox delivery failed")
+		}
+
+		if err := s.r.MarkFailed(message.ID, reason, backoff(attempts, s.cfg.MinBackoff, s.cfg.MaxBackoff), dead); err != nil {
+			return delivered, err
+		}
+		break
+	}
+
+	return delivered, nil
+}
+
+// backoff returns the delay before the next attempt of a message that
+// failed attempts times: min doubled for every failure after the first,
+// capped at max.
+func backoff(attempts int, min, max time.Duration) time.Duration {
+	delay := min
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	return delay
+}
@@ -0,0 +1,34 @@
+package sink
+
+import (
+	"context"
+
+	"github.com/ObscuraNote/api-general/internal/outbox/dto"
+	"github.com/philippe-berto/logger"
+)
+
+// Log writes messages to the application log. It never fails, which makes it
+// the sink for development and for deployments nothing listens to.
+type Log struct {
+	log *logger.Logger
+}
+
+func NewLog(log logger.Logger) *Log {
+	return &Log{log: &log}
+}
+
+func (l *Log) Send(ctx context.Context, message dto.Message) error {
+	l.log.WithFields(logger.Fields{
+		"id":        message.ID,
+		"type":      message.Type,
+		"payload":   string(message.Payload),
+		"component": "outbox",
+		"function":  "Send",
+	}).Info("Outbox event")
+
+	return nil
+}
+
+func (l *Log) Close() error {
+	return nil
+}
@@ -0,0 +1,254 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/outbox/dto"
+)
+
+// Nats publishes each message as JSON on <subject>.<type> to a server that
+// speaks the NATS client protocol. Every publish is followed by a PING, so a
+// nil error means the server processed it. The connection is opened on the
+// first Send and again after any failure.
+type Nats struct {
+	addr      string
+	tls       bool
+	tlsConfig *tls.Config
+	subject   string
+	user      string
+	pass      string
+	token     string
+	timeout   time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+type natsConnect struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Version   string `json:"version"`
+	Protocol  int    `json:"protocol"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+	TLS       bool   `json:"tls_required"`
+}
+
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+}
+
+// NewNats accepts nats:// and tls:// URLs. A tls:// URL always upgrades to
+// TLS; a nats:// URL does so when the server requires it. Credentials come
+// from the user info: user:pass, or a lone token.
+func NewNats(rawURL, subject string, timeout time.Duration) (*Nats, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "nats" && parsed.Scheme != "tls") || parsed.Hostname() == "" {
+		return nil, errors.New("sink: nats url must be a nats or tls url")
+	}
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return nil, errors.New("sink: invalid nats subject")
+	}
+
+	n := &Nats{
+		addr:      parsed.Host,
+		tls:       parsed.Scheme == "tls",
+		tlsConfig: &tls.Config{ServerName: parsed.Hostname(), MinVersion: tls.VersionTLS12},
+		subject:   subject,
+		timeout:   timeout,
+	}
+	if parsed.Port() == "" {
+		n.addr = net.JoinHostPort(parsed.Hostname(), "4222")
+	}
+	if parsed.User != nil {
+		if pass, ok := parsed.User.Password(); ok {
+			n.user, n.pass = parsed.User.Username(), pass
+		} else {
+			n.token = parsed.User.Username()
+		}
+	}
+
+	return n, nil
+}
+
+func (n *Nats) Send(ctx context.Context, message dto.Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.conn == nil {
+		if err := n.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	n.setDeadline(ctx)
+	_, err = fmt.Fprintf(n.conn, "PUB %s.%s %d\r\n%s\r\nPING\r\n", n.subject, message.Type, len(body), body)
+	if err == nil {
+		err = n.awaitPong()
+	}
+	if err != nil {
+		n.reset()
+		return err
+	}
+
+	return nil
+}
+
+func (n *Nats) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.reset()
+
+	return nil
+}
+
+// connect dials the server, reads its INFO in plaintext, upgrades to TLS
+// when needed and authenticates. The caller holds n.mu.
+func (n *Nats) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: n.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	n.conn = conn
+	n.reader = bufio.NewReader(conn)
+	n.setDeadline(ctx)
+
+	info, err := n.readInfo()
+	secure := n.tls || info.TLSRequired
+	if err == nil && secure {
+		err = n.upgrade(ctx)
+	}
+	if err != nil {
+		n.reset()
+		return err
+	}
+
+	connect, err := json.Marshal(natsConnect{
+		Name:      "obscuranote-outbox",
+		Lang:      "go",
+		Version:   "1.0.0",
+		User:      n.user,
+		Pass:      n.pass,
+		AuthToken: n.token,
+		TLS:       secure,
+	})
+	if err != nil {
+		n.reset()
+		return err
+	}
+
+	_, err = fmt.Fprintf(n.conn, "CONNECT %s\r\nPING\r\n", connect)
+	if err == nil {
+		err = n.awaitPong()
+	}
+	if err != nil {
+		n.reset()
+		return err
+	}
+
+	return nil
+}
+
+// readInfo parses the INFO line every server greets its clients with.
+func (n *Nats) readInfo() (natsInfo, error) {
+	var info natsInfo
+	line, err := n.readLine()
+	if err != nil {
+		return info, err
+	}
+
+	payload, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return info, fmt.Errorf("sink: nats: unexpected greeting %q", line)
+	}
+	if err := json.Unmarshal([]byte(payload), &info); err != nil {
+		return info, fmt.Errorf("sink: nats: invalid INFO: %w", err)
+	}
+
+	return info, nil
+}
+
+// upgrade runs the TLS handshake over the plaintext connection, which NATS
+// servers expect right after INFO.
+func (n *Nats) upgrade(ctx context.Context) error {
+	if n.reader.Buffered() > 0 {
+		return errors.New("sink: nats: unexpected data before TLS handshake")
+	}
+
+	conn := tls.Client(n.conn, n.tlsConfig)
+	n.conn = conn
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return err
+	}
+	n.reader = bufio.NewReader(conn)
+
+	return nil
+}
+
+// awaitPong reads until the server answers our PING, answering its own
+// PINGs and skipping INFO updates on the way.
+func (n *Nats) awaitPong() error {
+	for {
+		line, err := n.readLine()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := n.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("sink: nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (n *Nats) readLine() (string, error) {
+	line, err := n.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (n *Nats) setDeadline(ctx context.Context) {
+	deadline := time.Now().Add(n.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = n.conn.SetDeadline(deadline)
+}
+
+func (n *Nats) reset() {
+	if n.conn != nil {
+		_ = n.conn.Close()
+	}
+	n.conn = nil
+	n.reader = nil
+}
@@ -0,0 +1,38 @@
+// Package sink delivers outbox messages to downstream systems. A Send that
+// returns nil means the system has the message; any error makes the
+// dispatcher retry it later, so a sink may deliver a message more than once.
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ObscuraNote/api-general/internal/outbox/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/logger"
+)
+
+const (
+	SinkLog     = "log"
+	SinkWebhook = "webhook"
+	SinkNats    = "nats"
+)
+
+type Sink interface {
+	Send(ctx context.Context, message dto.Message) error
+	Close() error
+}
+
+// New builds the sink selected by cfg.Sink.
+func New(cfg config.OutboxConfig, log logger.Logger) (Sink, error) {
+	switch cfg.Sink {
+	case SinkLog:
+		return NewLog(log), nil
+	case SinkWebhook:
+		return NewWebhook(cfg.WebhookURL, cfg.WebhookSecret, cfg.Timeout)
+	case SinkNats:
+		return NewNats(cfg.NatsURL, cfg.NatsSubject, cfg.Timeout)
+	default:
+		return nil, fmt.Errorf("sink: unknown sink %q", cfg.Sink)
+	}
+}
@@ -0,0 +1,216 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/outbox/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+var message = dto.Message{
+	ID:        42,
+	Type:      dto.EventKeyAdded,
+	Payload:   json.RawMessage(`{"key_id":"3fa146de-e36d-411d-bfb6-6a7a1bb1fd63","user_id":1,"vault_id":null}`),
+	CreatedAt: "2026-10-19T12:00:00Z",
+}
+
+func TestNew(t *testing.T) {
+	log := *logger.New(context.Background())
+
+	s, err := New(config.OutboxConfig{Sink: SinkLog}, log)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Send(context.Background(), message))
+
+	_, err = New(config.OutboxConfig{Sink: SinkWebhook, WebhookURL: "ftp://example.com"}, log)
+	assert.Error(t, err)
+	_, err = New(config.OutboxConfig{Sink: SinkNats, NatsURL: "http://localhost:4222", NatsSubject: "obscuranote"}, log)
+	assert.Error(t, err)
+	_, err = New(config.OutboxConfig{Sink: "kafka"}, log)
+	assert.Error(t, err)
+}
+
+func TestWebhook(t *testing.T) {
+	status := http.StatusNoContent
+	var received []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		header = r.Header
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(server.URL, "secret", time.Second)
+	if err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	defer webhook.Close()
+
+	assert.NoError(t, webhook.Send(context.Background(), message))
+	assert.Equal(t, "42", header.Get(idHeader))
+	assert.Equal(t, dto.EventKeyAdded, header.Get(typeHeader))
+	assert.Equal(t, "sha256="+Sign([]byte("secret"), received), header.Get(signatureHeader))
+
+	var got dto.Message
+	assert.NoError(t, json.Unmarshal(received, &got))
+	assert.Equal(t, message.ID, got.ID)
+	assert.JSONEq(t, string(message.Payload), string(got.Payload))
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, webhook.Send(context.Background(), message))
+}
+
+// natsServer accepts one client at a time and answers like a NATS server.
+// A publish on a subject ending in ".rejected" gets an -ERR. With a TLS
+// config it requires TLS, upgrading after the plaintext INFO.
+type natsServer struct {
+	listener  net.Listener
+	tls       *tls.Config
+	published chan string
+}
+
+func newNatsServer(t *testing.T, config *tls.Config) *natsServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &natsServer{listener: listener, tls: config, published: make(chan string, 10)}
+	go server.serve()
+
+	return server
+}
+
+func (n *natsServer) serve() {
+	for {
+		conn, err := n.listener.Accept()
+		if err != nil {
+			return
+		}
+		go n.handle(conn)
+	}
+}
+
+func (n *natsServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+
+	if n.tls == nil {
+		_, _ = io.WriteString(conn, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+	} else {
+		_, _ = io.WriteString(conn, "INFO {\"server_id\":\"test\",\"max_payload\":1048576,\"tls_required\":true}\r\n")
+		secure := tls.Server(conn, n.tls)
+		if secure.Handshake() != nil {
+			return
+		}
+		conn = secure
+	}
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			var connect natsConnect
+			if json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &connect) != nil || connect.AuthToken != "token" ||
+				connect.TLS != (n.tls != nil) {
+				_, _ = io.WriteString(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case line == "PING":
+			_, _ = io.WriteString(conn, "PING\r\nPONG\r\n")
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			size, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			if strings.HasSuffix(fields[1], ".rejected") {
+				_, _ = io.WriteString(conn, "-ERR 'Permissions Violation'\r\n")
+				return
+			}
+			n.published <- fields[1] + " " + string(payload[:size])
+		}
+	}
+}
+
+func TestNats(t *testing.T) {
+	server := newNatsServer(t, nil)
+	defer server.listener.Close()
+
+	nats, err := NewNats("nats://token@"+server.listener.Addr().String(), "obscuranote", time.Second)
+	if err != nil {
+		t.Fatalf("failed to create nats sink: %v", err)
+	}
+	defer nats.Close()
+
+	assert.NoError(t, nats.Send(context.Background(), message))
+	published := <-server.published
+	subject, body, _ := strings.Cut(published, " ")
+	assert.Equal(t, "obscuranote.key.added", subject)
+
+	var got dto.Message
+	assert.NoError(t, json.Unmarshal([]byte(body), &got))
+	assert.Equal(t, message.ID, got.ID)
+
+	rejected := message
+	rejected.Type = "rejected"
+	assert.Error(t, nats.Send(context.Background(), rejected))
+
+	// The connection is opened again after a failure.
+	assert.NoError(t, nats.Send(context.Background(), message))
+	<-server.published
+
+	unauthorized, err := NewNats("nats://wrong@"+server.listener.Addr().String(), "obscuranote", time.Second)
+	if err != nil {
+		t.Fatalf("failed to create nats sink: %v", err)
+	}
+	assert.Error(t, unauthorized.Send(context.Background(), message))
+}
+
+func TestNatsTLS(t *testing.T) {
+	// httptest provides a certificate for 127.0.0.1.
+	certified := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certified.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(certified.Certificate())
+
+	server := newNatsServer(t, &tls.Config{Certificates: certified.TLS.Certificates})
+	defer server.listener.Close()
+
+	for _, scheme := range []string{"nats", "tls"} {
+		nats, err := NewNats(scheme+"://token@"+server.listener.Addr().String(), "obscuranote", time.Second)
+		if err != nil {
+			t.Fatalf("failed to create nats sink: %v", err)
+		}
+		nats.tlsConfig.RootCAs = roots
+
+		assert.NoError(t, nats.Send(context.Background(), message), scheme)
+		assert.Equal(t, "obscuranote.key.added", strings.Fields(<-server.published)[0])
+		nats.Close()
+	}
+
+	untrusted, err := NewNats("nats://token@"+server.listener.Addr().String(), "obscuranote", time.Second)
+	if err != nil {
+		t.Fatalf("failed to create nats sink: %v", err)
+	}
+	assert.Error(t, untrusted.Send(context.Background(), message))
+}
@@ -0,0 +1,93 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/outbox/dto"
+)
+
+const (
+	idHeader        = "X-Outbox-Id"
+	typeHeader      = "X-Outbox-Type"
+	signatureHeader = "X-Outbox-Signature"
+)
+
+// Webhook posts each message as JSON to a URL and counts any 2xx response as
+// delivered. With a secret, the body is signed with HMAC-SHA256 in the
+// X-Outbox-Signature header as "sha256=<hex>".
+type Webhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func NewWebhook(rawURL, secret string, timeout time.Duration) (*Webhook, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.New("sink: webhook url must be an http or https url")
+	}
+
+	return &Webhook{
+		url:    rawURL,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (w *Webhook) Send(ctx context.Context, message dto.Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idHeader, strconv.FormatInt(message.ID, 10))
+	req.Header.Set(typeHeader, message.Type)
+	if len(w.secret) > 0 {
+		req.Header.Set(signatureHeader, "sha256="+Sign(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sink: webhook responded %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (w *Webhook) Close() error {
+	w.client.CloseIdleConnections()
+
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body, as receivers compute it
+// to check the signature header.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,19 @@
+package dto
+
+type (
+	// SecretInput is an anonymous secret. Ciphertext is encrypted by the client
+	// with a key that only travels in the URL fragment, so the server never sees it.
+	SecretInput struct {
+		Ciphertext []byte `json:"ciphertext" db:"ciphertext"`
+		MaxViews   int    `json:"max_views" db:"remaining_views"`
+		TTLSeconds int64  `json:"ttl_seconds"`
+	}
+	SecretCreated struct {
+		ID        string `json:"id"`
+		ExpiresAt string `json:"expires_at" db:"expires_at"`
+	}
+	SecretOutput struct {
+		Ciphertext     []byte `json:"ciphertext" db:"ciphertext"`
+		RemainingViews int    `json:"remaining_views" db:"remaining_views"`
+	}
+)
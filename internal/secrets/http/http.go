@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ObscuraNote/api-general/internal/secrets/dto"
+	sService "github.com/ObscuraNote/api-general/internal/secrets/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+type handler struct {
+	log         *logger.Logger
+	ss          sService.SecretsService
+	maxBodySize int64
+}
+
+// Register mounts the anonymous secret endpoints. They take no credentials, and
+// secret IDs are never logged.
+func Register(router chi.Router, ss sService.SecretsService, maxSize int, log logger.Logger) {
+	h := &handler{
+		log: &log,
+		ss:  ss,
+		// Ciphertext arrives base64 encoded inside a JSON document.
+		maxBodySize: int64(maxSize)*4/3 + 1024,
+	}
+
+	router.Post("/secrets", h.CreateSecret)
+	router.Get("/secrets/{id}", h.GetSecret)
+}
+
+func (h *handler) CreateSecret(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+
+	var input dto.SecretInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	created, err := h.ss.CreateSecret(input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "secrets", "function": "CreateSecret"}).
+			Error("Failed to create secret")
+
+		switch err.Error() {
+		case utils.BadRequest:
+			_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		case utils.InvalidExpiration:
+			_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidExpiration)
+		case utils.PayloadTooLarge:
+			_ = utils.Fault(w, http.StatusRequestEntityTooLarge, utils.PayloadTooLarge)
+		default:
+			_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+		}
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, created); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "secrets", "function": "CreateSecret"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetSecret(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	secret, err := h.ss.GetSecret(chi.URLParam(r, "id"))
+	if err != nil {
+		if err.Error() == utils.SecretNotFound {
+			_ = utils.Fault(w, http.StatusNotFound, utils.SecretNotFound)
+		} else {
+			h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "secrets", "function": "GetSecret"}).
+				Error("Failed to get secret")
+
+			_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+		}
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, secret); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "secrets", "function": "GetSecret"}).
+			Error("Failed to write response")
+		return
+	}
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/secrets/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/lock"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
+)
+
+var _ SecretsRepository = (*Repository)(nil)
+
+type (
+	SecretsRepository interface {
+		CreateSecret(id string, ciphertext []byte, maxViews int, expiresAt time.Time) (string, error)
+		ConsumeSecret(id string) (*dto.SecretOutput, error)
+		DeleteExpiredSecrets() (int64, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+func (r *Repository) CreateSecret(id string, ciphertext []byte, maxViews int, expiresAt time.Time) (string, error) {
+	var createdExpiresAt string
+	err := r.statements.createSecret.statement.
+		QueryRowContext(r.ctx, id, ciphertext, maxViews, expiresAt).Scan(&createdExpiresAt)
+	if err != nil {
+		log.Println("Error creating secret")
+
+		return "", err
+	}
+
+	return createdExpiresAt, nil
+}
+
+// ConsumeSecret reads a secret and spends one of its views in the same
+// transaction. The row is deleted on its last view, so a burned secret cannot be
+// told apart from one that never existed. Missing secrets return sql.ErrNoRows.
+func (r *Repository) ConsumeSecret(id string) (*dto.SecretOutput, error) {
+	tx := transaction.New(r.db, false)
+	result, err := tx.ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var secret dto.SecretOutput
+		if err := tx.StmtxContext(ctx, r.statements.lockSecret.statement).
+			QueryRowContext(ctx, id).Scan(&secret.Ciphertext, &secret.RemainingViews); err != nil {
+			return nil, err
+		}
+
+		secret.RemainingViews--
+		if secret.RemainingViews == 0 {
+			if _, err := tx.StmtxContext(ctx, r.statements.deleteSecret.statement).ExecContext(ctx, id); err != nil {
+				return nil, err
+			}
+		} else {
+			if _, err := tx.StmtxContext(ctx, r.statements.decrementSecretViews.statement).ExecContext(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+
+		return &secret, nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*dto.SecretOutput), nil
+}
+
+func (r *Repository) DeleteExpiredSecrets() (int64, error) {
+	deleted, err := lock.TryExclusive(r.ctx, r.db, lock.SecretsReaper, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		res, err := tx.StmtxContext(ctx, r.statements.deleteExpiredSecrets.statement).ExecContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		return res.RowsAffected()
+	})
+	if err != nil {
+		log.Println("Error deleting expired secrets")
+
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.createSecret.statement, err = r.db.PrepareStatement(statementsList.createSecret.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockSecret.statement, err = r.db.PrepareStatement(statementsList.lockSecret.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.decrementSecretViews.statement, err = r.db.PrepareStatement(statementsList.decrementSecretViews.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteSecret.statement, err = r.db.PrepareStatement(statementsList.deleteSecret.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteExpiredSecrets.statement, err = r.db.PrepareStatement(statementsList.deleteExpiredSecrets.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
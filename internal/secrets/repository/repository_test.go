@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+const (
+	testSecretID  = "1111111111111111111111111111111111111111111111111111111111111111"
+	testExpiredID = "2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE secrets;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE secrets;")
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	t.Run("CreateSecret", func(t *testing.T) {
+		expiresAt, err := repo.CreateSecret(testSecretID, []byte("enc"), 2, time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.NotEmpty(t, expiresAt)
+	})
+
+	t.Run("ConsumeSecret_BurnAfterRead", func(t *testing.T) {
+		secret, err := repo.ConsumeSecret(testSecretID)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("enc"), secret.Ciphertext)
+		assert.Equal(t, 1, secret.RemainingViews)
+
+		secret, err = repo.ConsumeSecret(testSecretID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, secret.RemainingViews)
+
+		_, err = repo.ConsumeSecret(testSecretID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("DeleteExpiredSecrets", func(t *testing.T) {
+		_, err := repo.CreateSecret(testExpiredID, []byte("enc"), 1, time.Now().Add(-time.Minute))
+		assert.NoError(t, err)
+
+		_, err = repo.ConsumeSecret(testExpiredID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		deleted, err := repo.DeleteExpiredSecrets()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+}
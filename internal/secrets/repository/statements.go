@@ -0,0 +1,55 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	createSecret         statementsItem
+	lockSecret           statementsItem
+	decrementSecretViews statementsItem
+	deleteSecret         statementsItem
+	deleteExpiredSecrets statementsItem
+}
+
+var statementsList = statements{
+	createSecret: statementsItem{
+		name: "createSecret",
+		query: `
+			INSERT INTO secrets (id, ciphertext, remaining_views, expires_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING expires_at;`,
+	},
+	lockSecret: statementsItem{
+		name: "lockSecret",
+		query: `
+			SELECT ciphertext, remaining_views
+			FROM secrets
+			WHERE id = $1
+			AND expires_at > CURRENT_TIMESTAMP
+			FOR UPDATE;`,
+	},
+	decrementSecretViews: statementsItem{
+		name: "decrementSecretViews",
+		query: `
+			UPDATE secrets
+			SET remaining_views = remaining_views - 1
+			WHERE id = $1;`,
+	},
+	deleteSecret: statementsItem{
+		name: "deleteSecret",
+		query: `
+			DELETE FROM secrets
+			WHERE id = $1;`,
+	},
+	deleteExpiredSecrets: statementsItem{
+		name: "deleteExpiredSecrets",
+		query: `
+			DELETE FROM secrets
+			WHERE expires_at <= CURRENT_TIMESTAMP;`,
+	},
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/secrets/dto"
+	r "github.com/ObscuraNote/api-general/internal/secrets/repository"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/logger"
+)
+
+var _ SecretsService = (*Service)(nil)
+
+// secretIDSize is the number of random bytes behind a secret ID.
+const secretIDSize = 32
+
+type (
+	SecretsService interface {
+		CreateSecret(input dto.SecretInput) (*dto.SecretCreated, error)
+		GetSecret(id string) (*dto.SecretOutput, error)
+	}
+
+	Service struct {
+		ctx context.Context
+		r   r.SecretsRepository
+		cfg config.SecretsConfig
+		log *logger.Logger
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.SecretsRepository, cfg config.SecretsConfig) *Service {
+	return &Service{
+		ctx: ctx,
+		log: &log,
+		r:   repo,
+		cfg: cfg,
+	}
+}
+
+func (s *Service) CreateSecret(input dto.SecretInput) (*dto.SecretCreated, error) {
+	if len(input.Ciphertext) == 0 || input.MaxViews <= 0 || input.MaxViews > s.cfg.MaxViews {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	ttl := time.Duration(input.TTLSeconds) * time.Second
+	if ttl <= 0 || ttl > s.cfg.MaxTTL {
+		return nil, fmt.Errorf(utils.InvalidExpiration)
+	}
+
+	if len(input.Ciphertext) > s.cfg.MaxSize {
+		return nil, fmt.Errorf(utils.PayloadTooLarge)
+	}
+
+	raw := make([]byte, secretIDSize)
+	if _, err := rand.Read(raw); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "secrets_service", "function": "CreateSecret"}).Error(utils.InternalCode)
+		return nil, fmt.Errorf(utils.InternalCode)
+	}
+	id := base64.RawURLEncoding.EncodeToString(raw)
+
+	expiresAt, err := s.r.CreateSecret(hashID(id), input.Ciphertext, input.MaxViews, time.Now().Add(ttl))
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "secrets_service", "function": "CreateSecret"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return &dto.SecretCreated{ID: id, ExpiresAt: expiresAt}, nil
+}
+
+// GetSecret spends one view of a secret. Every call lasts a whole multiple
+// of ReadDuration, so a missing, expired or burned secret answers with the
+// same error and the same timing as one that exists, even when the database
+// is slow.
+func (s *Service) GetSecret(id string) (*dto.SecretOutput, error) {
+	start := time.Now()
+	defer func() { time.Sleep(padding(time.Since(start), s.cfg.ReadDuration)) }()
+
+	secret, err := s.r.ConsumeSecret(hashID(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.SecretNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "secrets_service", "function": "GetSecret"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return secret, nil
+}
+
+// padding is what is left of elapsed until the next multiple of step.
+func padding(elapsed, step time.Duration) time.Duration {
+	if step <= 0 {
+		return 0
+	}
+
+	rest := elapsed % step
+	if rest == 0 && elapsed > 0 {
+		return 0
+	}
+
+	return step - rest
+}
+
+// hashID keeps only a digest of the secret ID in the database, so the table
+// contents alone are not enough to open a link.
+func hashID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
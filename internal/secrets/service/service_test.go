@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/secrets/dto"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/ObscuraNote/api-general/mocks"
+	"github.com/philippe-berto/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPadding(t *testing.T) {
+	step := 100 * time.Millisecond
+
+	assert.Equal(t, step, padding(0, step))
+	assert.Equal(t, 70*time.Millisecond, padding(30*time.Millisecond, step))
+	assert.Equal(t, time.Duration(0), padding(step, step))
+	assert.Equal(t, 50*time.Millisecond, padding(250*time.Millisecond, step))
+	assert.Equal(t, time.Duration(0), padding(30*time.Millisecond, 0))
+}
+
+func TestGetSecretTiming(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockSecretsRepository(ctrl)
+	log := *logger.New(context.Background())
+	step := 50 * time.Millisecond
+	s := New(context.Background(), log, repo, config.SecretsConfig{ReadDuration: step})
+
+	t.Run("Fast", func(t *testing.T) {
+		repo.EXPECT().ConsumeSecret(gomock.Any()).Return(nil, sql.ErrNoRows)
+
+		start := time.Now()
+		_, err := s.GetSecret("missing")
+		elapsed := time.Since(start)
+
+		assert.EqualError(t, err, utils.SecretNotFound)
+		assert.GreaterOrEqual(t, elapsed, step)
+		assert.Less(t, elapsed, 2*step)
+	})
+
+	t.Run("Slow", func(t *testing.T) {
+		repo.EXPECT().ConsumeSecret(gomock.Any()).DoAndReturn(func(string) (*dto.SecretOutput, error) {
+			time.Sleep(step + step/2)
+			return &dto.SecretOutput{Ciphertext: []byte("ciphertext")}, nil
+		})
+
+		start := time.Now()
+		secret, err := s.GetSecret("slow")
+		elapsed := time.Since(start)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("ciphertext"), secret.Ciphertext)
+		assert.GreaterOrEqual(t, elapsed, 2*step)
+	})
+}
@@ -0,0 +1,56 @@
+package dto
+
+const (
+	OperationPublish = "publish"
+	OperationDelete  = "delete"
+)
+
+type (
+	// LogEntry is one change to the public key directory. Its JSON encoding is
+	// the leaf data the Merkle leaf hash is computed over.
+	LogEntry struct {
+		Operation         string `json:"operation"`
+		UserAddress       string `json:"user_address"`
+		Version           int    `json:"version"`
+		EncryptionKey     []byte `json:"encryption_key,omitempty"`
+		SigningKey        []byte `json:"signing_key,omitempty"`
+		Signature         []byte `json:"signature,omitempty"`
+		RotationSignature []byte `json:"rotation_signature,omitempty"`
+		Timestamp         int64  `json:"timestamp"`
+	}
+
+	LogLeaf struct {
+		LeafIndex int64  `json:"leaf_index" db:"leaf_index"`
+		LeafData  []byte `json:"leaf_data" db:"leaf_data"`
+		LeafHash  []byte `json:"leaf_hash" db:"leaf_hash"`
+	}
+
+	// TreeHead is a signed tree head. Signature is an Ed25519 signature by the
+	// log key over:
+	//
+	//	"obscuranote-tree-head-v1" || uint64be(tree_size) || uint64be(timestamp) || root_hash
+	TreeHead struct {
+		TreeSize  int64  `json:"tree_size" db:"tree_size"`
+		RootHash  []byte `json:"root_hash" db:"root_hash"`
+		Timestamp int64  `json:"timestamp" db:"timestamp"`
+		Signature []byte `json:"signature" db:"signature"`
+		Frontier  []byte `json:"-" db:"frontier"`
+	}
+
+	InclusionProof struct {
+		LeafIndex int64    `json:"leaf_index"`
+		TreeSize  int64    `json:"tree_size"`
+		LeafHash  []byte   `json:"leaf_hash"`
+		AuditPath [][]byte `json:"audit_path"`
+	}
+
+	ConsistencyProof struct {
+		First  int64    `json:"first"`
+		Second int64    `json:"second"`
+		Proof  [][]byte `json:"proof"`
+	}
+
+	LogPublicKey struct {
+		PublicKey []byte `json:"public_key"`
+	}
+)
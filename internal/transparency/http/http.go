@@ -0,0 +1,156 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ObscuraNote/api-general/internal/transparency/dto"
+	tService "github.com/ObscuraNote/api-general/internal/transparency/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+type handler struct {
+	log *logger.Logger
+	ts  tService.TransparencyService
+}
+
+// Register mounts the public, read-only endpoints clients and auditors use to
+// check the key transparency log.
+func Register(router chi.Router, ts tService.TransparencyService, log logger.Logger) {
+	h := &handler{
+		log: &log,
+		ts:  ts,
+	}
+
+	router.Get("/transparency/public-key", h.GetPublicKey)
+	router.Get("/transparency/sth", h.GetTreeHead)
+	router.Get("/transparency/sth/{size}", h.GetTreeHead)
+	router.Get("/transparency/entries", h.GetEntries)
+	router.Get("/transparency/proof/inclusion", h.GetInclusionProof)
+	router.Get("/transparency/proof/consistency", h.GetConsistencyProof)
+}
+
+func (h *handler) GetPublicKey(w http.ResponseWriter, r *http.Request) {
+	h.write(w, "GetPublicKey", h.ts.PublicKey())
+}
+
+func (h *handler) GetTreeHead(w http.ResponseWriter, r *http.Request) {
+	var treeSize int64
+	if chi.URLParam(r, "size") != "" {
+		size, err := utils.ParseIDParam(w, r, "size")
+		if err != nil {
+			return
+		}
+		treeSize = size
+	}
+
+	head, err := h.ts.GetTreeHead(treeSize)
+	if err != nil {
+		h.fault(w, "GetTreeHead", err)
+		return
+	}
+
+	h.write(w, "GetTreeHead", head)
+}
+
+func (h *handler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	start, err := utils.ParseInt64Query(w, r, "start", 0)
+	if err != nil {
+		return
+	}
+
+	end, err := utils.ParseInt64Query(w, r, "end", start+100)
+	if err != nil {
+		return
+	}
+
+	leaves, err := h.ts.GetEntries(start, end)
+	if err != nil {
+		h.fault(w, "GetEntries", err)
+		return
+	}
+
+	if leaves == nil {
+		leaves = []dto.LogLeaf{}
+	}
+
+	h.write(w, "GetEntries", leaves)
+}
+
+// GetInclusionProof proves a leaf either by leaf_index or by the user_address
+// and version of a published key.
+func (h *handler) GetInclusionProof(w http.ResponseWriter, r *http.Request) {
+	treeSize, err := utils.ParseInt64Query(w, r, "tree_size", 0)
+	if err != nil {
+		return
+	}
+
+	var proof *dto.InclusionProof
+	if userAddress := r.URL.Query().Get("user_address"); userAddress != "" {
+		version, err := utils.ParseInt64Query(w, r, "version", 0)
+		if err != nil {
+			return
+		}
+
+		proof, err = h.ts.GetInclusionProofByKey(userAddress, int(version), treeSize)
+		if err != nil {
+			h.fault(w, "GetInclusionProof", err)
+			return
+		}
+	} else {
+		leafIndex, err := utils.ParseInt64Query(w, r, "leaf_index", -1)
+		if err != nil {
+			return
+		}
+
+		proof, err = h.ts.GetInclusionProof(leafIndex, treeSize)
+		if err != nil {
+			h.fault(w, "GetInclusionProof", err)
+			return
+		}
+	}
+
+	h.write(w, "GetInclusionProof", proof)
+}
+
+func (h *handler) GetConsistencyProof(w http.ResponseWriter, r *http.Request) {
+	first, err := utils.ParseInt64Query(w, r, "first", 0)
+	if err != nil {
+		return
+	}
+
+	second, err := utils.ParseInt64Query(w, r, "second", 0)
+	if err != nil {
+		return
+	}
+
+	proof, err := h.ts.GetConsistencyProof(first, second)
+	if err != nil {
+		h.fault(w, "GetConsistencyProof", err)
+		return
+	}
+
+	h.write(w, "GetConsistencyProof", proof)
+}
+
+func (h *handler) fault(w http.ResponseWriter, function string, err error) {
+	switch err.Error() {
+	case utils.InvalidParam:
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidParam)
+	case utils.TreeNotFound, utils.LeafNotFound:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	default:
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "transparency", "function": function}).
+			Error("Failed to read transparency log")
+
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func (h *handler) write(w http.ResponseWriter, function string, body interface{}) {
+	if err := utils.WriteBody(w, http.StatusOK, body); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "transparency", "function": function}).
+			Error("Failed to write response")
+	}
+}
@@ -0,0 +1,288 @@
+// Package merkle implements the Merkle tree of RFC 9162 (Certificate
+// Transparency 2.0): leaf and node hashing, inclusion and consistency proofs,
+// and their verification. Proofs are described as the subtrees whose root
+// hashes they are made of, so a caller can build them from stored nodes
+// without reading every leaf.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/bits"
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+var ErrInvalidProof = errors.New("merkle: invalid proof")
+
+type (
+	// Node names the perfect subtree of 2^Height leaves that starts at leaf
+	// Index<<Height. Its hash never changes once the tree holds its last leaf.
+	Node struct {
+		Height uint8
+		Index  uint64
+	}
+
+	// Subtree is the range of leaves [Start, End) a proof element is the root
+	// hash of.
+	Subtree struct {
+		Start uint64
+		End   uint64
+	}
+)
+
+// EmptyRoot is the root hash of a tree without leaves.
+func EmptyRoot() []byte {
+	sum := sha256.Sum256(nil)
+	return sum[:]
+}
+
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func NodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Root computes the root hash over leaf hashes.
+func Root(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return EmptyRoot()
+	case 1:
+		return leaves[0]
+	}
+
+	k := split(uint64(len(leaves)))
+	return NodeHash(Root(leaves[:k]), Root(leaves[k:]))
+}
+
+// Append adds a leaf hash to a compact frontier of a tree with size leaves and
+// returns the frontier of the tree with size+1 leaves. The frontier holds the
+// roots of the perfect subtrees along the right edge, largest first, which is
+// enough to compute the root without reading the other leaves.
+//
+// It also returns the hashes of the perfect subtrees the leaf completes, from
+// the leaf itself up: the one at position h is the hash of Node{h, size>>h}.
+func Append(frontier [][]byte, size uint64, leafHash []byte) ([][]byte, [][]byte) {
+	hash := leafHash
+	completed := [][]byte{leafHash}
+	for s := size; s&1 == 1; s >>= 1 {
+		hash = NodeHash(frontier[len(frontier)-1], hash)
+		frontier = frontier[:len(frontier)-1]
+		completed = append(completed, hash)
+	}
+
+	return append(frontier, hash), completed
+}
+
+// FrontierRoot computes the root hash of the tree a frontier describes.
+func FrontierRoot(frontier [][]byte) []byte {
+	if len(frontier) == 0 {
+		return EmptyRoot()
+	}
+
+	root := frontier[len(frontier)-1]
+	for i := len(frontier) - 2; i >= 0; i-- {
+		root = NodeHash(frontier[i], root)
+	}
+
+	return root
+}
+
+// Nodes splits s into perfect subtrees, largest first; FrontierRoot of their
+// hashes is the root hash of s. s must be a subtree of a proof path, whose
+// start is aligned on a power of two at least as large as it is.
+func (s Subtree) Nodes() []Node {
+	var nodes []Node
+	for start := s.Start; start < s.End; {
+		height := uint8(bits.Len64(s.End-start) - 1)
+		nodes = append(nodes, Node{Height: height, Index: start >> height})
+		start += 1 << height
+	}
+
+	return nodes
+}
+
+// InclusionPath returns the subtrees whose root hashes make up the audit path
+// of leaf index in the tree of size leaves, in proof order.
+func InclusionPath(index, size uint64) []Subtree {
+	return inclusionPath(index, 0, size)
+}
+
+func inclusionPath(index, start, end uint64) []Subtree {
+	if end-start <= 1 {
+		return []Subtree{}
+	}
+
+	k := start + split(end-start)
+	if index < k {
+		return append(inclusionPath(index, start, k), Subtree{Start: k, End: end})
+	}
+
+	return append(inclusionPath(index, k, end), Subtree{Start: start, End: k})
+}
+
+// ConsistencyPath returns the subtrees whose root hashes prove that the tree
+// of first leaves is a prefix of the tree of size leaves, in proof order.
+func ConsistencyPath(first, size uint64) []Subtree {
+	if first == 0 || first >= size {
+		return []Subtree{}
+	}
+
+	return subPath(first, 0, size, true)
+}
+
+func subPath(m, start, end uint64, complete bool) []Subtree {
+	if m == end-start {
+		if complete {
+			return []Subtree{}
+		}
+		return []Subtree{{Start: start, End: end}}
+	}
+
+	k := split(end - start)
+	if m <= k {
+		return append(subPath(m, start, start+k, complete), Subtree{Start: start + k, End: end})
+	}
+
+	return append(subPath(m-k, start+k, end, false), Subtree{Start: start, End: start + k})
+}
+
+// InclusionProof returns the audit path of leaf index in the tree of leaves.
+func InclusionProof(index int, leaves [][]byte) [][]byte {
+	return rootsOf(InclusionPath(uint64(index), uint64(len(leaves))), leaves)
+}
+
+// ConsistencyProof proves that the tree of the first size leaves is a prefix
+// of the tree of leaves.
+func ConsistencyProof(size int, leaves [][]byte) [][]byte {
+	if size <= 0 {
+		return [][]byte{}
+	}
+
+	return rootsOf(ConsistencyPath(uint64(size), uint64(len(leaves))), leaves)
+}
+
+func rootsOf(path []Subtree, leaves [][]byte) [][]byte {
+	proof := make([][]byte, 0, len(path))
+	for _, subtree := range path {
+		proof = append(proof, Root(leaves[subtree.Start:subtree.End]))
+	}
+
+	return proof
+}
+
+// VerifyInclusion checks an audit path against a root, following RFC 9162
+// section 2.1.3.2.
+func VerifyInclusion(index, size uint64, leafHash []byte, proof [][]byte, root []byte) error {
+	if index >= size {
+		return ErrInvalidProof
+	}
+
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			if fn&1 == 0 {
+				for fn&1 == 0 && fn != 0 {
+					fn >>= 1
+					sn >>= 1
+				}
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || !bytes.Equal(r, root) {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// VerifyConsistency checks a consistency proof between two roots, following
+// RFC 9162 section 2.1.4.2.
+func VerifyConsistency(first, second uint64, firstRoot, secondRoot []byte, proof [][]byte) error {
+	switch {
+	case first > second:
+		return ErrInvalidProof
+	case first == second:
+		if len(proof) != 0 || !bytes.Equal(firstRoot, secondRoot) {
+			return ErrInvalidProof
+		}
+		return nil
+	case first == 0:
+		if len(proof) != 0 {
+			return ErrInvalidProof
+		}
+		return nil
+	}
+
+	if len(proof) == 0 {
+		return ErrInvalidProof
+	}
+
+	if first&(first-1) == 0 {
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			if fn&1 == 0 {
+				for fn&1 == 0 && fn != 0 {
+					fn >>= 1
+					sn >>= 1
+				}
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || !bytes.Equal(fr, firstRoot) || !bytes.Equal(sr, secondRoot) {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// split returns the largest power of two smaller than n.
+func split(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
@@ -0,0 +1,97 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func leaves(n int) [][]byte {
+	result := make([][]byte, n)
+	for i := range result {
+		result[i] = LeafHash([]byte(fmt.Sprintf("leaf-%d", i)))
+	}
+	return result
+}
+
+func TestFrontierRoot(t *testing.T) {
+	assert.Equal(t, EmptyRoot(), FrontierRoot(nil))
+
+	var frontier [][]byte
+	all := leaves(40)
+	for i, leaf := range all {
+		var completed [][]byte
+		frontier, completed = Append(frontier, uint64(i), leaf)
+		assert.Equal(t, Root(all[:i+1]), FrontierRoot(frontier), "size %d", i+1)
+
+		for height, hash := range completed {
+			start := (uint64(i) >> height) << height
+			assert.Equal(t, Root(all[start:uint64(i)+1]), hash, "size %d height %d", i+1, height)
+		}
+	}
+}
+
+// TestSubtreeNodes rebuilds every proof from the hashes of perfect subtrees,
+// the way the log serves them from stored nodes.
+func TestSubtreeNodes(t *testing.T) {
+	nodeHash := func(tree [][]byte, node Node) []byte {
+		start := node.Index << node.Height
+		return Root(tree[start : start+1<<node.Height])
+	}
+	fromNodes := func(tree [][]byte, path []Subtree) [][]byte {
+		proof := [][]byte{}
+		for _, subtree := range path {
+			var hashes [][]byte
+			for _, node := range subtree.Nodes() {
+				hashes = append(hashes, nodeHash(tree, node))
+			}
+			proof = append(proof, FrontierRoot(hashes))
+		}
+		return proof
+	}
+
+	for n := 1; n <= 33; n++ {
+		tree := leaves(n)
+		for i := 0; i < n; i++ {
+			path := InclusionPath(uint64(i), uint64(n))
+			assert.Equal(t, InclusionProof(i, tree), fromNodes(tree, path), "leaf %d of %d", i, n)
+			for _, subtree := range path {
+				assert.LessOrEqual(t, len(subtree.Nodes()), 6)
+			}
+		}
+		for m := 1; m <= n; m++ {
+			assert.Equal(t, ConsistencyProof(m, tree), fromNodes(tree, ConsistencyPath(uint64(m), uint64(n))), "%d to %d", m, n)
+		}
+	}
+}
+
+func TestInclusionProof(t *testing.T) {
+	for n := 1; n <= 33; n++ {
+		tree := leaves(n)
+		root := Root(tree)
+		for i := 0; i < n; i++ {
+			proof := InclusionProof(i, tree)
+			assert.NoError(t, VerifyInclusion(uint64(i), uint64(n), tree[i], proof, root), "leaf %d of %d", i, n)
+
+			if n > 1 {
+				assert.Error(t, VerifyInclusion(uint64((i+1)%n), uint64(n), tree[i], proof, root))
+			}
+		}
+	}
+}
+
+func TestConsistencyProof(t *testing.T) {
+	for n := 1; n <= 33; n++ {
+		tree := leaves(n)
+		root := Root(tree)
+		for m := 1; m <= n; m++ {
+			proof := ConsistencyProof(m, tree)
+			assert.NoError(t, VerifyConsistency(uint64(m), uint64(n), Root(tree[:m]), root, proof), "%d to %d", m, n)
+
+			if m < n {
+				assert.Error(t, VerifyConsistency(uint64(m), uint64(n), LeafHash([]byte("forged")), root, proof))
+			}
+		}
+	}
+}
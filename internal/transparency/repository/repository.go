@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"github.com/ObscuraNote/api-general/internal/transparency/dto"
+	"github.com/ObscuraNote/api-general/internal/transparency/merkle"
+	"github.com/ObscuraNote/api-general/internal/utils/lock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/philippe-berto/database/postgresdb"
+)
+
+var _ TransparencyRepository = (*Repository)(nil)
+
+type (
+	// TransparencyRepository stores the key transparency log. The Tx methods run
+	// inside the caller's transaction so that a log entry is committed together
+	// with the change it records.
+	TransparencyRepository interface {
+		LockLogTx(ctx context.Context, tx *sqlx.Tx) error
+		GetLatestTreeHeadTx(ctx context.Context, tx *sqlx.Tx) (*dto.TreeHead, error)
+		InsertLeafTx(ctx context.Context, tx *sqlx.Tx, leaf dto.LogLeaf, entry dto.LogEntry) error
+		InsertTreeHeadTx(ctx context.Context, tx *sqlx.Tx, head dto.TreeHead) error
+		InsertNodesTx(ctx context.Context, tx *sqlx.Tx, leafIndex int64, hashes [][]byte) error
+		GetLatestTreeHead() (*dto.TreeHead, error)
+		GetTreeHead(treeSize int64) (*dto.TreeHead, error)
+		GetNodes(nodes []merkle.Node) (map[merkle.Node][]byte, error)
+		GetLeaves(start, end int64) ([]dto.LogLeaf, error)
+		FindLeafIndex(userAddress string, version int) (int64, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+// LockLogTx serializes appends across replicas until tx ends.
+func (r *Repository) LockLogTx(ctx context.Context, tx *sqlx.Tx) error {
+	_, err := tx.StmtxContext(ctx, r.statements.lockLog.statement).ExecContext(ctx, lock.TransparencyLog)
+	return err
+}
+
+func (r *Repository) GetLatestTreeHeadTx(ctx context.Context, tx *sqlx.Tx) (*dto.TreeHead, error) {
+	var head dto.TreeHead
+	err := tx.StmtxContext(ctx, r.statements.getLatestHead.statement).QueryRowContext(ctx).
+		Scan(&head.TreeSize, &head.RootHash, &head.Frontier, &head.Timestamp, &head.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &head, nil
+}
+
+func (r *Repository) InsertLeafTx(ctx context.Context, tx *sqlx.Tx, leaf dto.LogLeaf, entry dto.LogEntry) error {
+	_, err := tx.StmtxContext(ctx, r.statements.insertLeaf.statement).
+		ExecContext(ctx, leaf.LeafIndex, entry.Operation, entry.UserAddress, entry.Version, leaf.LeafData, leaf.LeafHash)
+	if err != nil {
+		log.Println("Error inserting transparency leaf")
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) InsertTreeHeadTx(ctx context.Context, tx *sqlx.Tx, head dto.TreeHead) error {
+	_, err := tx.StmtxContext(ctx, r.statements.insertHead.statement).
+		ExecContext(ctx, head.TreeSize, head.RootHash, head.Frontier, head.Timestamp, head.Signature)
+	if err != nil {
+		log.Println("Error inserting tree head")
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetLatestTreeHead() (*dto.TreeHead, error) {
+	var head dto.TreeHead
+	err := r.statements.getLatestHead.statement.QueryRowContext(r.ctx).
+		Scan(&head.TreeSize, &head.RootHash, &head.Frontier, &head.Timestamp, &head.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &head, nil
+}
+
+func (r *Repository) GetTreeHead(treeSize int64) (*dto.TreeHead, error) {
+	var head dto.TreeHead
+	err := r.statements.getHead.statement.QueryRowContext(r.ctx, treeSize).
+		Scan(&head.TreeSize, &head.RootHash, &head.Frontier, &head.Timestamp, &head.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &head, nil
+}
+
+// InsertNodesTx stores the hashes of the perfect subtrees completed by the
+// leaf at leafIndex, as merkle.Append returns them.
+func (r *Repository) InsertNodesTx(ctx context.Context, tx *sqlx.Tx, leafIndex int64, hashes [][]byte) error {
+	heights := make([]int64, len(hashes))
+	indexes := make([]int64, len(hashes))
+	for height := range hashes {
+		heights[height] = int64(height)
+		indexes[height] = leafIndex >> height
+	}
+
+	_, err := tx.StmtxContext(ctx, r.statements.insertNodes.statement).
+		ExecContext(ctx, pq.Array(heights), pq.Array(indexes), pq.Array(hashes))
+	if err != nil {
+		log.Println("Error inserting transparency nodes")
+
+		return err
+	}
+
+	return nil
+}
+
+// GetNodes returns the stored hashes of nodes. Nodes the log has not
+// completed yet are missing from the result.
+func (r *Repository) GetNodes(nodes []merkle.Node) (map[merkle.Node][]byte, error) {
+	heights := make([]int64, len(nodes))
+	indexes := make([]int64, len(nodes))
+	for i, node := range nodes {
+		heights[i] = int64(node.Height)
+		indexes[i] = int64(node.Index)
+	}
+
+	rows, err := r.statements.getNodes.statement.QueryContext(r.ctx, pq.Array(heights), pq.Array(indexes))
+	if err != nil {
+		log.Println("Error getting transparency nodes")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make(map[merkle.Node][]byte, len(nodes))
+	for rows.Next() {
+		var node merkle.Node
+		var hash []byte
+		if err := rows.Scan(&node.Height, &node.Index, &hash); err != nil {
+			return nil, err
+		}
+		hashes[node] = hash
+	}
+
+	return hashes, rows.Err()
+}
+
+func (r *Repository) GetLeaves(start, end int64) ([]dto.LogLeaf, error) {
+	rows, err := r.statements.getLeaves.statement.QueryContext(r.ctx, start, end)
+	if err != nil {
+		log.Println("Error getting leaves")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var leaves []dto.LogLeaf
+	for rows.Next() {
+		var leaf dto.LogLeaf
+		if err := rows.Scan(&leaf.LeafIndex, &leaf.LeafData, &leaf.LeafHash); err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leaf)
+	}
+
+	return leaves, nil
+}
+
+func (r *Repository) FindLeafIndex(userAddress string, version int) (int64, error) {
+	var index int64
+	err := r.statements.findLeafIndex.statement.QueryRowContext(r.ctx, userAddress, version).Scan(&index)
+	if err != nil {
+		return 0, err
+	}
+
+	return index, nil
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.lockLog.statement, err = r.db.PrepareStatement(statementsList.lockLog.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getLatestHead.statement, err = r.db.PrepareStatement(statementsList.getLatestHead.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getHead.statement, err = r.db.PrepareStatement(statementsList.getHead.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.insertLeaf.statement, err = r.db.PrepareStatement(statementsList.insertLeaf.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.insertHead.statement, err = r.db.PrepareStatement(statementsList.insertHead.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.insertNodes.statement, err = r.db.PrepareStatement(statementsList.insertNodes.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getNodes.statement, err = r.db.PrepareStatement(statementsList.getNodes.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getLeaves.statement, err = r.db.PrepareStatement(statementsList.getLeaves.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.findLeafIndex.statement, err = r.db.PrepareStatement(statementsList.findLeafIndex.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
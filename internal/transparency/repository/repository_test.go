@@ -0,0 +1,100 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ObscuraNote/api-general/internal/transparency/dto"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+const testUserAddress = "1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+// The log is append-only, so every test works inside a transaction that is
+// rolled back at the end.
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	t.Run("AppendInTransaction", func(t *testing.T) {
+		tx, err := db.GetClient().BeginTxx(ctx, nil)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		require.NoError(t, repo.LockLogTx(ctx, tx))
+
+		var size int64
+		head, err := repo.GetLatestTreeHeadTx(ctx, tx)
+		if err != sql.ErrNoRows {
+			require.NoError(t, err)
+			size = head.TreeSize
+		}
+
+		entry := dto.LogEntry{Operation: dto.OperationPublish, UserAddress: testUserAddress, Version: 1}
+		leaf := dto.LogLeaf{LeafIndex: size, LeafData: []byte("leaf"), LeafHash: make([]byte, 32)}
+		assert.NoError(t, repo.InsertLeafTx(ctx, tx, leaf, entry))
+
+		next := dto.TreeHead{TreeSize: size + 1, RootHash: make([]byte, 32), Frontier: make([]byte, 32), Timestamp: 1, Signature: []byte("sig")}
+		assert.NoError(t, repo.InsertTreeHeadTx(ctx, tx, next))
+
+		latest, err := repo.GetLatestTreeHeadTx(ctx, tx)
+		assert.NoError(t, err)
+		assert.Equal(t, size+1, latest.TreeSize)
+	})
+
+	t.Run("Nodes", func(t *testing.T) {
+		tx, err := db.GetClient().BeginTxx(ctx, nil)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		leafIndex := int64(1<<40 + 3)
+		hashes := [][]byte{[]byte("leaf"), []byte("pair"), []byte("four")}
+		require.NoError(t, repo.InsertNodesTx(ctx, tx, leafIndex, hashes))
+
+		var hash []byte
+		err = tx.QueryRowContext(ctx, "SELECT hash FROM transparency_nodes WHERE height = 2 AND node_index = $1;", leafIndex>>2).Scan(&hash)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("four"), hash)
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM transparency_nodes WHERE height = 0 AND node_index = $1;", leafIndex)
+		assert.Error(t, err)
+	})
+
+	t.Run("AppendOnly", func(t *testing.T) {
+		tx, err := db.GetClient().BeginTxx(ctx, nil)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		entry := dto.LogEntry{Operation: dto.OperationPublish, UserAddress: testUserAddress, Version: 1}
+		leaf := dto.LogLeaf{LeafIndex: 1 << 40, LeafData: []byte("leaf"), LeafHash: make([]byte, 32)}
+		require.NoError(t, repo.InsertLeafTx(ctx, tx, leaf, entry))
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM transparency_log WHERE leaf_index = $1;", leaf.LeafIndex)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,90 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	lockLog       statementsItem
+	getLatestHead statementsItem
+	getHead       statementsItem
+	insertLeaf    statementsItem
+	insertHead    statementsItem
+	insertNodes   statementsItem
+	getNodes      statementsItem
+	getLeaves     statementsItem
+	findLeafIndex statementsItem
+}
+
+var statementsList = statements{
+	lockLog: statementsItem{
+		name: "lockLog",
+		query: `
+			SELECT pg_advisory_xact_lock($1);`,
+	},
+	getLatestHead: statementsItem{
+		name: "getLatestHead",
+		query: `
+			SELECT tree_size, root_hash, frontier, timestamp, signature
+			FROM transparency_tree_heads
+			ORDER BY tree_size DESC
+			LIMIT 1;`,
+	},
+	getHead: statementsItem{
+		name: "getHead",
+		query: `
+			SELECT tree_size, root_hash, frontier, timestamp, signature
+			FROM transparency_tree_heads
+			WHERE tree_size = $1;`,
+	},
+	insertLeaf: statementsItem{
+		name: "insertLeaf",
+		query: `
+			INSERT INTO transparency_log (leaf_index, operation, user_address, version, leaf_data, leaf_hash)
+			VALUES ($1, $2, $3, $4, $5, $6);`,
+	},
+	insertHead: statementsItem{
+		name: "insertHead",
+		query: `
+			INSERT INTO transparency_tree_heads (tree_size, root_hash, frontier, timestamp, signature)
+			VALUES ($1, $2, $3, $4, $5);`,
+	},
+	insertNodes: statementsItem{
+		name: "insertNodes",
+		query: `
+			INSERT INTO transparency_nodes (height, node_index, hash)
+			SELECT * FROM unnest($1::smallint[], $2::bigint[], $3::bytea[]);`,
+	},
+	getNodes: statementsItem{
+		name: "getNodes",
+		query: `
+			SELECT n.height, n.node_index, n.hash
+			FROM transparency_nodes n
+			JOIN unnest($1::smallint[], $2::bigint[]) AS q (height, node_index)
+			ON n.height = q.height AND n.node_index = q.node_index;`,
+	},
+	getLeaves: statementsItem{
+		name: "getLeaves",
+		query: `
+			SELECT leaf_index, leaf_data, leaf_hash
+			FROM transparency_log
+			WHERE leaf_index >= $1
+			AND leaf_index < $2
+			ORDER BY leaf_index;`,
+	},
+	findLeafIndex: statementsItem{
+		name: "findLeafIndex",
+		query: `
+			SELECT leaf_index
+			FROM transparency_log
+			WHERE user_address = $1
+			AND version = $2
+			AND operation = 'publish'
+			ORDER BY leaf_index DESC
+			LIMIT 1;`,
+	},
+}
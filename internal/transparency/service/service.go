@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/transparency/dto"
+	"github.com/ObscuraNote/api-general/internal/transparency/merkle"
+	r "github.com/ObscuraNote/api-general/internal/transparency/repository"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/logger"
+)
+
+var _ TransparencyService = (*Service)(nil)
+
+const (
+	treeHeadContext = "obscuranote-tree-head-v1"
+	maxEntries      = 1000
+	hashSize        = 32
+)
+
+type (
+	// Appender records directory changes in the log. Append must be called
+	// inside the transaction that makes the change.
+	Appender interface {
+		Append(ctx context.Context, tx *sqlx.Tx, entry dto.LogEntry) error
+	}
+
+	TransparencyService interface {
+		Appender
+		GetTreeHead(treeSize int64) (*dto.TreeHead, error)
+		GetEntries(start, end int64) ([]dto.LogLeaf, error)
+		GetInclusionProof(leafIndex, treeSize int64) (*dto.InclusionProof, error)
+		GetInclusionProofByKey(userAddress string, version int, treeSize int64) (*dto.InclusionProof, error)
+		GetConsistencyProof(first, second int64) (*dto.ConsistencyProof, error)
+		PublicKey() dto.LogPublicKey
+	}
+
+	Service struct {
+		ctx context.Context
+		r   r.TransparencyRepository
+		key ed25519.PrivateKey
+		log *logger.Logger
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.TransparencyRepository, cfg config.TransparencyConfig) (*Service, error) {
+	s := &Service{
+		ctx: ctx,
+		log: &log,
+		r:   repo,
+	}
+
+	if cfg.SigningKey == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		s.key = key
+		s.log.Warn("TRANSPARENCY_SIGNING_KEY is not set, tree heads are signed with an ephemeral key")
+
+		return s, nil
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(cfg.SigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("transparency: signing key must be a base64 encoded 32 byte seed")
+	}
+	s.key = ed25519.NewKeyFromSeed(seed)
+
+	return s, nil
+}
+
+// Append adds entry as the next leaf and signs the resulting tree head. The
+// log lock is held until tx ends, so leaves get gap-free indexes across
+// replicas and a rolled back change leaves no trace in the log.
+func (s *Service) Append(ctx context.Context, tx *sqlx.Tx, entry dto.LogEntry) error {
+	if err := s.r.LockLogTx(ctx, tx); err != nil {
+		return err
+	}
+
+	var size int64
+	var frontier [][]byte
+	head, err := s.r.GetLatestTreeHeadTx(ctx, tx)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return err
+	default:
+		size = head.TreeSize
+		frontier = splitHashes(head.Frontier)
+	}
+
+	entry.Timestamp = time.Now().UnixMilli()
+	leafData, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	leaf := dto.LogLeaf{
+		LeafIndex: size,
+		LeafData:  leafData,
+		LeafHash:  merkle.LeafHash(leafData),
+	}
+	frontier, completed := merkle.Append(frontier, uint64(size), leaf.LeafHash)
+
+	next := dto.TreeHead{
+		TreeSize:  size + 1,
+		RootHash:  merkle.FrontierRoot(frontier),
+		Timestamp: entry.Timestamp,
+		Frontier:  joinHashes(frontier),
+	}
+	next.Signature = ed25519.Sign(s.key, treeHeadMessage(next))
+
+	if err := s.r.InsertLeafTx(ctx, tx, leaf, entry); err != nil {
+		return err
+	}
+	if err := s.r.InsertNodesTx(ctx, tx, size, completed); err != nil {
+		return err
+	}
+
+	return s.r.InsertTreeHeadTx(ctx, tx, next)
+}
+
+// GetTreeHead returns the signed tree head of the given size, or the latest
+// one when treeSize is zero.
+func (s *Service) GetTreeHead(treeSize int64) (*dto.TreeHead, error) {
+	var head *dto.TreeHead
+	var err error
+	if treeSize == 0 {
+		head, err = s.r.GetLatestTreeHead()
+	} else {
+		head, err = s.r.GetTreeHead(treeSize)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.TreeNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "transparency_service", "function": "GetTreeHead"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return head, nil
+}
+
+func (s *Service) GetEntries(start, end int64) ([]dto.LogLeaf, error) {
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf(utils.InvalidParam)
+	}
+	if end-start > maxEntries {
+		end = start + maxEntries
+	}
+
+	leaves, err := s.r.GetLeaves(start, end)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "transparency_service", "function": "GetEntries"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return leaves, nil
+}
+
+func (s *Service) GetInclusionProof(leafIndex, treeSize int64) (*dto.InclusionProof, error) {
+	head, err := s.GetTreeHead(treeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if leafIndex < 0 || leafIndex >= head.TreeSize {
+		return nil, fmt.Errorf(utils.LeafNotFound)
+	}
+
+	leaf := merkle.Subtree{Start: uint64(leafIndex), End: uint64(leafIndex) + 1}
+	path := merkle.InclusionPath(uint64(leafIndex), uint64(head.TreeSize))
+	hashes, err := s.subtreeHashes(append([]merkle.Subtree{leaf}, path...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.InclusionProof{
+		LeafIndex: leafIndex,
+		TreeSize:  head.TreeSize,
+		LeafHash:  hashes[0],
+		AuditPath: hashes[1:],
+	}, nil
+}
+
+// GetInclusionProofByKey proves the publication of a given public key version.
+func (s *Service) GetInclusionProofByKey(userAddress string, version int, treeSize int64) (*dto.InclusionProof, error) {
+	leafIndex, err := s.r.FindLeafIndex(userAddress, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.LeafNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "transparency_service", "function": "GetInclusionProofByKey"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return s.GetInclusionProof(leafIndex, treeSize)
+}
+
+// GetConsistencyProof proves that the tree of size first is a prefix of the
+// tree of size second, or of the latest tree when second is zero.
+func (s *Service) GetConsistencyProof(first, second int64) (*dto.ConsistencyProof, error) {
+	head, err := s.GetTreeHead(second)
+	if err != nil {
+		return nil, err
+	}
+
+	if first <= 0 || first > head.TreeSize {
+		return nil, fmt.Errorf(utils.InvalidParam)
+	}
+
+	proof, err := s.subtreeHashes(merkle.ConsistencyPath(uint64(first), uint64(head.TreeSize)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ConsistencyProof{
+		First:  first,
+		Second: head.TreeSize,
+		Proof:  proof,
+	}, nil
+}
+
+func (s *Service) PublicKey() dto.LogPublicKey {
+	return dto.LogPublicKey{PublicKey: s.key.Public().(ed25519.PublicKey)}
+}
+
+// subtreeHashes returns the root hash of each subtree, reading only the
+// stored nodes they are made of: O(log n) of them for a proof path.
+func (s *Service) subtreeHashes(subtrees []merkle.Subtree) ([][]byte, error) {
+	var nodes []merkle.Node
+	for _, subtree := range subtrees {
+		nodes = append(nodes, subtree.Nodes()...)
+	}
+
+	stored, err := s.r.GetNodes(nodes)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "transparency_service", "function": "subtreeHashes"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	hashes := make([][]byte, 0, len(subtrees))
+	for _, subtree := range subtrees {
+		var parts [][]byte
+		for _, node := range subtree.Nodes() {
+			hash, ok := stored[node]
+			if !ok {
+				s.log.WithFields(logger.Fields{"height": node.Height, "index": node.Index, "component": "transparency_service", "function": "subtreeHashes"}).
+					Error("Transparency log is missing nodes")
+				return nil, fmt.Errorf(utils.InternalCode)
+			}
+			parts = append(parts, hash)
+		}
+		hashes = append(hashes, merkle.FrontierRoot(parts))
+	}
+
+	return hashes, nil
+}
+
+func treeHeadMessage(head dto.TreeHead) []byte {
+	message := []byte(treeHeadContext)
+	message = binary.BigEndian.AppendUint64(message, uint64(head.TreeSize))
+	message = binary.BigEndian.AppendUint64(message, uint64(head.Timestamp))
+	message = append(message, head.RootHash...)
+
+	return message
+}
+
+func splitHashes(joined []byte) [][]byte {
+	hashes := make([][]byte, 0, len(joined)/hashSize)
+	for i := 0; i+hashSize <= len(joined); i += hashSize {
+		hashes = append(hashes, joined[i:i+hashSize])
+	}
+
+	return hashes
+}
+
+func joinHashes(hashes [][]byte) []byte {
+	joined := make([]byte, 0, len(hashes)*hashSize)
+	for _, hash := range hashes {
+		joined = append(joined, hash...)
+	}
+
+	return joined
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type (
+	AuthInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+	}
+	// Upload is a resumable upload of Length bytes, of which Offset have been
+	// received. Metadata is the raw Upload-Metadata header of the creation
+	// request.
+	Upload struct {
+		ID          string    `json:"id" db:"id"`
+		Length      int64     `json:"length" db:"length"`
+		Offset      int64     `json:"offset" db:"upload_offset"`
+		Metadata    *string   `json:"metadata,omitempty" db:"metadata"`
+		CreatedAt   string    `json:"created_at" db:"created_at"`
+		ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+		CompletedAt *string   `json:"completed_at,omitempty" db:"completed_at"`
+	}
+	Segment struct {
+		Offset  int64  `db:"start_offset"`
+		Size    int64  `db:"size"`
+		BlobKey string `db:"blob_key"`
+	}
+)
@@ -0,0 +1,233 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ObscuraNote/api-general/internal/uploads/dto"
+	upService "github.com/ObscuraNote/api-general/internal/uploads/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+// Resumable uploads follow the tus 1.0 protocol, https://tus.io/protocols/resumable-upload.
+const (
+	tusVersion        = "1.0.0"
+	tusExtensions     = "creation,termination,expiration"
+	offsetContentType = "application/offset+octet-stream"
+)
+
+type handler struct {
+	log     *logger.Logger
+	ups     upService.UploadsService
+	maxSize int64
+}
+
+func Register(router chi.Router, ups upService.UploadsService, maxSize int64, log logger.Logger) {
+	h := &handler{
+		log:     &log,
+		ups:     ups,
+		maxSize: maxSize,
+	}
+
+	router.Options("/uploads", h.Options)
+	router.Post("/uploads", h.CreateUpload)
+	router.Options("/uploads/{id}", h.Options)
+	router.Head("/uploads/{id}", h.GetUpload)
+	router.Patch("/uploads/{id}", h.AppendUpload)
+	router.Delete("/uploads/{id}", h.DeleteUpload)
+}
+
+func (h *handler) Options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.maxSize, 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	if !checkResumable(w, r) {
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidParam)
+		return
+	}
+	if length > h.maxSize {
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, utils.PayloadTooLarge)
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	upload, err := h.ups.CreateUpload(length, r.Header.Get("Upload-Metadata"), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "uploads", "function": "CreateUpload"}).
+			Error("Failed to create upload")
+
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/uploads/"+upload.ID)
+	setExpires(w, upload)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *handler) GetUpload(w http.ResponseWriter, r *http.Request) {
+	if !checkResumable(w, r) {
+		return
+	}
+
+	uploadID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	upload, err := h.ups.GetUpload(uploadID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "uploads", "function": "GetUpload"}).
+			Error("Failed to get upload")
+
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if upload.Metadata != nil {
+		w.Header().Set("Upload-Metadata", *upload.Metadata)
+	}
+	setExpires(w, upload)
+	w.WriteHeader(http.StatusOK)
+}
+
+// AppendUpload streams the request body into the upload. A body cut short by
+// a dropped connection still advances the offset by what was received.
+func (h *handler) AppendUpload(w http.ResponseWriter, r *http.Request) {
+	if !checkResumable(w, r) {
+		return
+	}
+
+	uploadID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	if r.Header.Get(utils.ContentType) != offsetContentType {
+		_ = utils.Fault(w, http.StatusUnsupportedMediaType, utils.BadRequest)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidParam)
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	upload, err := h.ups.AppendUpload(uploadID.String(), offset, r.Body, auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "uploads", "function": "AppendUpload"}).
+			Error("Failed to append to upload")
+
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	setExpires(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) DeleteUpload(w http.ResponseWriter, r *http.Request) {
+	if !checkResumable(w, r) {
+		return
+	}
+
+	uploadID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ups.DeleteUpload(uploadID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "uploads", "function": "DeleteUpload"}).
+			Error("Failed to delete upload")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkResumable rejects requests made for another protocol version. Every
+// response carries the version the server speaks.
+func checkResumable(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		_ = utils.Fault(w, http.StatusPreconditionFailed, utils.BadRequest)
+		return false
+	}
+
+	return true
+}
+
+func setExpires(w http.ResponseWriter, upload *dto.Upload) {
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.BadRequest:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.PayloadTooLarge:
+		_ = utils.Fault(w, http.StatusRequestEntityTooLarge, err.Error())
+	case utils.UploadNotFound:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	case utils.UploadExpired:
+		_ = utils.Fault(w, http.StatusGone, err.Error())
+	case utils.OffsetConflict:
+		_ = utils.Fault(w, http.StatusConflict, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
+}
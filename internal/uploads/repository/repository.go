@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/uploads/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/lock"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
+)
+
+var _ UploadsRepository = (*Repository)(nil)
+
+// ErrOffsetConflict is returned when a segment does not start at the current
+// offset of its upload or would run past its length.
+var ErrOffsetConflict = errors.New("upload offset conflict")
+
+type (
+	UploadsRepository interface {
+		CreateUpload(userId, length int64, metadata *string, expiresAt time.Time) (*dto.Upload, error)
+		GetUpload(userId int64, id string) (*dto.Upload, error)
+		AddSegment(id string, offset, size int64, blobKey string, expiresAt time.Time) (*dto.Upload, error)
+		GetSegments(id string) ([]dto.Segment, error)
+		DeleteUpload(userId int64, id string) (bool, error)
+		DeleteExpiredUploads() (int64, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+func (r *Repository) CreateUpload(userId, length int64, metadata *string, expiresAt time.Time) (*dto.Upload, error) {
+	var upload dto.Upload
+	err := r.statements.createUpload.statement.
+		QueryRowContext(r.ctx, userId, length, metadata, expiresAt).
+		Scan(&upload.ID, &upload.Length, &upload.Offset, &upload.Metadata, &upload.CreatedAt, &upload.ExpiresAt,
+			&upload.CompletedAt)
+	if err != nil {
+		log.Println("Error creating upload")
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+func (r *Repository) GetUpload(userId int64, id string) (*dto.Upload, error) {
+	var upload dto.Upload
+	err := r.statements.getUpload.statement.
+		QueryRowContext(r.ctx, id, userId).
+		Scan(&upload.ID, &upload.Length, &upload.Offset, &upload.Metadata, &upload.CreatedAt, &upload.ExpiresAt,
+			&upload.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+// AddSegment records a stored segment and advances the upload offset past
+// it, pushing the expiration to expiresAt. It returns sql.ErrNoRows when the
+// upload is missing or expired and ErrOffsetConflict when the segment does
+// not start at the current offset.
+func (r *Repository) AddSegment(id string, offset, size int64, blobKey string, expiresAt time.Time) (*dto.Upload, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var current, length int64
+		if err := tx.StmtxContext(ctx, r.statements.lockUpload.statement).
+			QueryRowContext(ctx, id).Scan(&current, &length); err != nil {
+			return nil, err
+		}
+		if current != offset || offset+size > length {
+			return nil, ErrOffsetConflict
+		}
+
+		if _, err := tx.StmtxContext(ctx, r.statements.addSegment.statement).
+			ExecContext(ctx, id, offset, size, blobKey); err != nil {
+			return nil, err
+		}
+
+		var upload dto.Upload
+		if err := tx.StmtxContext(ctx, r.statements.advanceUpload.statement).
+			QueryRowContext(ctx, id, size, expiresAt).
+			Scan(&upload.ID, &upload.Length, &upload.Offset, &upload.Metadata, &upload.CreatedAt, &upload.ExpiresAt,
+				&upload.CompletedAt); err != nil {
+			return nil, err
+		}
+
+		return &upload, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, ErrOffsetConflict) {
+			log.Println("Error adding upload segment")
+		}
+
+		return nil, err
+	}
+
+	return result.(*dto.Upload), nil
+}
+
+func (r *Repository) GetSegments(id string) ([]dto.Segment, error) {
+	rows, err := r.statements.getSegments.statement.QueryContext(r.ctx, id)
+	if err != nil {
+		log.Println("Error getting upload segments")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var segments []dto.Segment
+	for rows.Next() {
+		var segment dto.Segment
+		if err := rows.Scan(&segment.Offset, &segment.Size, &segment.BlobKey); err != nil {
+			log.Println("Error scanning upload segment")
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
+
+// DeleteUpload removes an upload. Its segment blobs are queued for deletion.
+func (r *Repository) DeleteUpload(userId int64, id string) (bool, error) {
+	result, err := r.statements.deleteUpload.statement.ExecContext(r.ctx, id, userId)
+	if err != nil {
+		log.Println("Error deleting upload")
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+func (r *Repository) DeleteExpiredUploads() (int64, error) {
+	deleted, err := lock.TryExclusive(r.ctx, r.db, lock.UploadsReaper, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		res, err := tx.StmtxContext(ctx, r.statements.deleteExpiredUploads.statement).ExecContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		return res.RowsAffected()
+	})
+	if err != nil {
+		log.Println("Error deleting expired uploads")
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.createUpload.statement, err = r.db.PrepareStatement(statementsList.createUpload.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getUpload.statement, err = r.db.PrepareStatement(statementsList.getUpload.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.lockUpload.statement, err = r.db.PrepareStatement(statementsList.lockUpload.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.addSegment.statement, err = r.db.PrepareStatement(statementsList.addSegment.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.advanceUpload.statement, err = r.db.PrepareStatement(statementsList.advanceUpload.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getSegments.statement, err = r.db.PrepareStatement(statementsList.getSegments.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteUpload.statement, err = r.db.PrepareStatement(statementsList.deleteUpload.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteExpiredUploads.statement, err = r.db.PrepareStatement(statementsList.deleteExpiredUploads.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
+
+func rowsAffected(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
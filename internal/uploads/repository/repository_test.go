@@ -0,0 +1,131 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	db.GetClient().Exec("TRUNCATE TABLE blob_garbage;")
+	defer db.GetClient().Exec("TRUNCATE TABLE blob_garbage;")
+
+	db.GetClient().Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'),
+		       ('2222222222222222222222222222222222222222222222222222222222222222', 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb');
+	`)
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var userId, otherId int64
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '1111111111111111111111111111111111111111111111111111111111111111';
+	`).Scan(&userId)
+	if err != nil {
+		t.Fatalf("failed to get user id: %v", err)
+	}
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+	`).Scan(&otherId)
+	if err != nil {
+		t.Fatalf("failed to get other user id: %v", err)
+	}
+
+	metadata := "filename bm90ZS5iaW4="
+	upload, err := repo.CreateUpload(userId, 10, &metadata, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create upload: %v", err)
+	}
+
+	t.Run("GetUpload", func(t *testing.T) {
+		got, err := repo.GetUpload(userId, upload.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), got.Length)
+		assert.Equal(t, int64(0), got.Offset)
+		assert.Equal(t, metadata, *got.Metadata)
+
+		_, err = repo.GetUpload(otherId, upload.ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("AddSegment", func(t *testing.T) {
+		updated, err := repo.AddSegment(upload.ID, 0, 6, "uploads/a/0", time.Now().Add(2*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(6), updated.Offset)
+		assert.Nil(t, updated.CompletedAt)
+
+		_, err = repo.AddSegment(upload.ID, 0, 4, "uploads/a/0-again", time.Now().Add(2*time.Hour))
+		assert.ErrorIs(t, err, ErrOffsetConflict)
+
+		_, err = repo.AddSegment(upload.ID, 6, 5, "uploads/a/6-long", time.Now().Add(2*time.Hour))
+		assert.ErrorIs(t, err, ErrOffsetConflict)
+
+		updated, err = repo.AddSegment(upload.ID, 6, 4, "uploads/a/6", time.Now().Add(2*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), updated.Offset)
+		assert.NotNil(t, updated.CompletedAt)
+
+		segments, err := repo.GetSegments(upload.ID)
+		assert.NoError(t, err)
+		assert.Len(t, segments, 2)
+		assert.Equal(t, int64(6), segments[1].Offset)
+	})
+
+	t.Run("DeleteExpiredUploads", func(t *testing.T) {
+		expired, err := repo.CreateUpload(userId, 10, nil, time.Now().Add(-time.Minute))
+		assert.NoError(t, err)
+
+		_, err = repo.AddSegment(expired.ID, 0, 1, "uploads/b/0", time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		deleted, err := repo.DeleteExpiredUploads()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("DeleteUpload", func(t *testing.T) {
+		deleted, err := repo.DeleteUpload(otherId, upload.ID)
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+
+		deleted, err = repo.DeleteUpload(userId, upload.ID)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+
+		var queued int
+		err = db.GetClient().QueryRow(`SELECT COUNT(*) FROM blob_garbage;`).Scan(&queued)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, queued)
+	})
+}
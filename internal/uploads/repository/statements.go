@@ -0,0 +1,84 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	createUpload         statementsItem
+	getUpload            statementsItem
+	lockUpload           statementsItem
+	addSegment           statementsItem
+	advanceUpload        statementsItem
+	getSegments          statementsItem
+	deleteUpload         statementsItem
+	deleteExpiredUploads statementsItem
+}
+
+var statementsList = statements{
+	createUpload: statementsItem{
+		name: "createUpload",
+		query: `
+			INSERT INTO uploads (user_id, length, metadata, expires_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, length, upload_offset, metadata, created_at, expires_at, completed_at;`,
+	},
+	getUpload: statementsItem{
+		name: "getUpload",
+		query: `
+			SELECT id, length, upload_offset, metadata, created_at, expires_at, completed_at
+			FROM uploads
+			WHERE id = $1
+			AND user_id = $2;`,
+	},
+	lockUpload: statementsItem{
+		name: "lockUpload",
+		query: `
+			SELECT upload_offset, length
+			FROM uploads
+			WHERE id = $1
+			AND expires_at > CURRENT_TIMESTAMP
+			FOR UPDATE;`,
+	},
+	addSegment: statementsItem{
+		name: "addSegment",
+		query: `
+			INSERT INTO upload_segments (upload_id, start_offset, size, blob_key)
+			VALUES ($1, $2, $3, $4);`,
+	},
+	advanceUpload: statementsItem{
+		name: "advanceUpload",
+		query: `
+			UPDATE uploads
+			SET upload_offset = upload_offset + $2,
+				expires_at = $3,
+				completed_at = CASE WHEN upload_offset + $2 = length THEN CURRENT_TIMESTAMP END
+			WHERE id = $1
+			RETURNING id, length, upload_offset, metadata, created_at, expires_at, completed_at;`,
+	},
+	getSegments: statementsItem{
+		name: "getSegments",
+		query: `
+			SELECT start_offset, size, blob_key
+			FROM upload_segments
+			WHERE upload_id = $1
+			ORDER BY start_offset ASC;`,
+	},
+	deleteUpload: statementsItem{
+		name: "deleteUpload",
+		query: `
+			DELETE FROM uploads
+			WHERE id = $1
+			AND user_id = $2;`,
+	},
+	deleteExpiredUploads: statementsItem{
+		name: "deleteExpiredUploads",
+		query: `
+			DELETE FROM uploads
+			WHERE expires_at <= CURRENT_TIMESTAMP;`,
+	},
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/uploads/dto"
+	r "github.com/ObscuraNote/api-general/internal/uploads/repository"
+	u "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/blobstore"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/google/uuid"
+	"github.com/philippe-berto/logger"
+)
+
+var _ UploadsService = (*Service)(nil)
+
+const (
+	maxMetadataSize = 4096
+	blobIdSize      = 8
+)
+
+type (
+	// UploadsService implements resumable uploads. Received data is stored in
+	// segments as it arrives, so a client that loses its connection resumes
+	// from the last stored byte. Uploads belong to the user who created them.
+	UploadsService interface {
+		CreateUpload(length int64, metadata string, auth dto.AuthInput) (*dto.Upload, error)
+		GetUpload(id string, auth dto.AuthInput) (*dto.Upload, error)
+		AppendUpload(id string, offset int64, body io.Reader, auth dto.AuthInput) (*dto.Upload, error)
+		DeleteUpload(id string, auth dto.AuthInput) error
+		ReadUpload(id string, userId int64) ([]byte, error)
+		ReleaseUpload(id string, userId int64) error
+	}
+
+	Service struct {
+		ctx   context.Context
+		r     r.UploadsRepository
+		us    u.UserService
+		store blobstore.BlobStore
+		log   *logger.Logger
+		cfg   config.UploadsConfig
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.UploadsRepository, us u.UserService, store blobstore.BlobStore,
+	cfg config.UploadsConfig) *Service {
+	return &Service{
+		ctx:   ctx,
+		log:   &log,
+		r:     repo,
+		us:    us,
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+func (s *Service) CreateUpload(length int64, metadata string, auth dto.AuthInput) (*dto.Upload, error) {
+	if length <= 0 || !validMetadata(metadata) {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if length > s.cfg.MaxSize {
+		return nil, fmt.Errorf(utils.PayloadTooLarge)
+	}
+
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta *string
+	if metadata != "" {
+		meta = &metadata
+	}
+
+	upload, err := s.r.CreateUpload(userId, length, meta, time.Now().Add(s.cfg.TTL))
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "CreateUpload"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return upload, nil
+}
+
+func (s *Service) GetUpload(id string, auth dto.AuthInput) (*dto.Upload, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getUpload(userId, id)
+}
+
+// AppendUpload stores body at offset, which must be the current offset of the
+// upload. Data is stored in segments of at most SegmentSize bytes; when the
+// body ends early, everything received so far is kept.
+func (s *Service) AppendUpload(id string, offset int64, body io.Reader, auth dto.AuthInput) (*dto.Upload, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := s.getUpload(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Offset != offset {
+		return nil, fmt.Errorf(utils.OffsetConflict)
+	}
+
+	remaining := upload.Length - offset
+	buffer := make([]byte, min(s.cfg.SegmentSize, remaining))
+	for remaining > 0 {
+		n, readErr := io.ReadFull(body, buffer[:min(int64(len(buffer)), remaining)])
+		if n > 0 {
+			upload, err = s.storeSegment(id, offset, buffer[:n])
+			if err != nil {
+				return nil, err
+			}
+			offset += int64(n)
+			remaining -= int64(n)
+		}
+
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf(utils.BadRequest)
+		}
+	}
+
+	return upload, nil
+}
+
+// DeleteUpload terminates an upload and discards its data.
+func (s *Service) DeleteUpload(id string, auth dto.AuthInput) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.r.DeleteUpload(userId, id)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "DeleteUpload"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !deleted {
+		return fmt.Errorf(utils.UploadNotFound)
+	}
+
+	return nil
+}
+
+// ReadUpload returns the content of one of userId's completed uploads so that
+// it can be used as an entry payload.
+func (s *Service) ReadUpload(id string, userId int64) ([]byte, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf(utils.UploadNotFound)
+	}
+
+	upload, err := s.getUpload(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.CompletedAt == nil {
+		return nil, fmt.Errorf(utils.UploadPending)
+	}
+
+	segments, err := s.r.GetSegments(id)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "ReadUpload"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	var content bytes.Buffer
+	content.Grow(int(upload.Length))
+	for _, segment := range segments {
+		if err := s.readSegment(&content, segment); err != nil {
+			s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "ReadUpload"}).Error(utils.InternalCode)
+			return nil, fmt.Errorf(utils.InternalCode)
+		}
+	}
+	if int64(content.Len()) != upload.Length {
+		s.log.WithFields(logger.Fields{"error": "upload content is truncated", "component": "uploads_service", "function": "ReadUpload"}).Error(utils.InternalCode)
+		return nil, fmt.Errorf(utils.InternalCode)
+	}
+
+	return content.Bytes(), nil
+}
+
+// ReleaseUpload discards an upload once its content has been consumed.
+func (s *Service) ReleaseUpload(id string, userId int64) error {
+	if _, err := s.r.DeleteUpload(userId, id); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "ReleaseUpload"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return nil
+}
+
+func (s *Service) storeSegment(id string, offset int64, data []byte) (*dto.Upload, error) {
+	suffix := make([]byte, blobIdSize)
+	if _, err := rand.Read(suffix); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "storeSegment"}).Error(utils.InternalCode)
+		return nil, fmt.Errorf(utils.InternalCode)
+	}
+	blobKey := fmt.Sprintf("uploads/%s/%d-%s", id, offset, hex.EncodeToString(suffix))
+
+	if err := s.store.Put(s.ctx, blobKey, bytes.NewReader(data), int64(len(data))); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "storeSegment"}).Error(utils.InternalCode)
+		return nil, fmt.Errorf(utils.InternalCode)
+	}
+
+	upload, err := s.r.AddSegment(id, offset, int64(len(data)), blobKey, time.Now().Add(s.cfg.TTL))
+	if err != nil {
+		if deleteErr := s.store.Delete(s.ctx, blobKey); deleteErr != nil {
+			s.log.WithFields(logger.Fields{"error": deleteErr.Error(), "component": "uploads_service", "function": "storeSegment"}).
+				Warn("Failed to delete unreferenced segment")
+		}
+	}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf(utils.UploadNotFound)
+	case errors.Is(err, r.ErrOffsetConflict):
+		return nil, fmt.Errorf(utils.OffsetConflict)
+	case err != nil:
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "storeSegment"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return upload, nil
+}
+
+func (s *Service) readSegment(w io.Writer, segment dto.Segment) error {
+	body, err := s.store.Get(s.ctx, segment.BlobKey, 0, segment.Size)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+	return err
+}
+
+// getUpload returns one of userId's uploads. Uploads past their expiration
+// are reported as expired until the reaper removes them.
+func (s *Service) getUpload(userId int64, id string) (*dto.Upload, error) {
+	upload, err := s.r.GetUpload(userId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.UploadNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "getUpload"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+	if !upload.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf(utils.UploadExpired)
+	}
+
+	return upload, nil
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "uploads_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
+
+// validMetadata checks the Upload-Metadata format: comma separated pairs of a
+// key and an optional base64 encoded value, with unique keys.
+func validMetadata(metadata string) bool {
+	if metadata == "" {
+		return true
+	}
+	if len(metadata) > maxMetadataSize {
+		return false
+	}
+
+	seen := map[string]bool{}
+	for _, pair := range strings.Split(metadata, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" || seen[key] {
+			return false
+		}
+		seen[key] = true
+		if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -1,5 +1,14 @@
 package dto
 
+const (
+	EmergencyStatusIdle      = "idle"
+	EmergencyStatusRequested = "requested"
+	EmergencyStatusGranted   = "granted"
+
+	// DefaultPlan names the entitlements of accounts without an active plan.
+	DefaultPlan = "default"
+)
+
 type (
 	User struct {
 		ID          string `json:"id" db:"id"`
@@ -16,4 +25,126 @@ type (
 		Password    string `json:"password" db:"password"`
 		NewPassword string `json:"new_password" db:"new_password"`
 	}
+
+	// PublicKeysInput publishes a version of the account's public keys.
+	// Signature is made by SigningKey and RotationSignature by the signing key
+	// of the previous version, both over:
+	//
+	//	"obscuranote-public-keys-v1" || user_address || uint32be(version) || encryption_key || signing_key
+	//
+	// RotationSignature is omitted for the first version.
+	PublicKeysInput struct {
+		UserAddress       string `json:"user_address" db:"user_address"`
+		Password          string `json:"password" db:"password"`
+		Version           int    `json:"version" db:"version"`
+		EncryptionKey     []byte `json:"encryption_key" db:"encryption_key"`
+		SigningKey        []byte `json:"signing_key" db:"signing_key"`
+		Signature         []byte `json:"signature" db:"signature"`
+		RotationSignature []byte `json:"rotation_signature,omitempty" db:"rotation_signature"`
+	}
+
+	PublicKeys struct {
+		Version           int    `json:"version" db:"version"`
+		EncryptionKey     []byte `json:"encryption_key" db:"encryption_key"`
+		SigningKey        []byte `json:"signing_key" db:"signing_key"`
+		Signature         []byte `json:"signature" db:"signature"`
+		RotationSignature []byte `json:"rotation_signature,omitempty" db:"rotation_signature"`
+		CreatedAt         string `json:"created_at" db:"created_at"`
+	}
+
+	// PublicKeyDirectory is the published key history of a user, newest first.
+	PublicKeyDirectory struct {
+		UserAddress string       `json:"user_address" db:"user_address"`
+		Current     PublicKeys   `json:"current"`
+		History     []PublicKeys `json:"history"`
+	}
+
+	// EmergencyContactInput names a contact who may request access to the
+	// account. EncryptedKey is the owner's vault key wrapped to the contact's
+	// public key; it is only handed over once access is granted. When
+	// InactivityDays is set, a request is opened on the contact's behalf after
+	// that many days without a successful authentication, and withdrawn again
+	// by the next one.
+	EmergencyContactInput struct {
+		UserAddress    string `json:"user_address" db:"user_address"`
+		Password       string `json:"password" db:"password"`
+		ContactAddress string `json:"contact_address" db:"contact_address"`
+		EncryptedKey   []byte `json:"encrypted_key" db:"encrypted_key"`
+		KeyIV          []byte `json:"key_iv" db:"key_iv"`
+		WaitHours      int    `json:"wait_hours" db:"wait_hours"`
+		InactivityDays *int   `json:"inactivity_days,omitempty" db:"inactivity_days"`
+	}
+
+	EmergencyContact struct {
+		ContactAddress string  `json:"contact_address" db:"contact_address"`
+		WaitHours      int     `json:"wait_hours" db:"wait_hours"`
+		InactivityDays *int    `json:"inactivity_days,omitempty" db:"inactivity_days"`
+		Status         string  `json:"status" db:"status"`
+		RequestedAt    *string `json:"requested_at,omitempty" db:"requested_at"`
+		GrantAt        *string `json:"grant_at,omitempty" db:"grant_at"`
+		GrantedAt      *string `json:"granted_at,omitempty" db:"granted_at"`
+		CreatedAt      string  `json:"created_at" db:"created_at"`
+	}
+
+	// EmergencyGrant is an emergency contact's view of an account that named
+	// them. EncryptedKey is only set once access is granted.
+	EmergencyGrant struct {
+		OwnerAddress string  `json:"owner_address" db:"owner_address"`
+		WaitHours    int     `json:"wait_hours" db:"wait_hours"`
+		Status       string  `json:"status" db:"status"`
+		RequestedAt  *string `json:"requested_at,omitempty" db:"requested_at"`
+		GrantAt      *string `json:"grant_at,omitempty" db:"grant_at"`
+		GrantedAt    *string `json:"granted_at,omitempty" db:"granted_at"`
+		EncryptedKey []byte  `json:"encrypted_key,omitempty" db:"encrypted_key"`
+		KeyIV        []byte  `json:"key_iv,omitempty" db:"key_iv"`
+	}
+
+	// Usage reports the storage an account consumes against its limits.
+	// Bytes counts entry ciphertext and AttachmentBytes the attachments of
+	// the account's entries.
+	Usage struct {
+		Entries         UsageItem `json:"entries"`
+		Bytes           UsageItem `json:"bytes"`
+		AttachmentBytes UsageItem `json:"attachment_bytes"`
+	}
+
+	// UsageItem is one quota. A zero Limit is unlimited.
+	UsageItem struct {
+		Used  int64 `json:"used"`
+		Limit int64 `json:"limit"`
+	}
+
+	// Plan is a tier that vouchers grant. A zero limit is unlimited;
+	// HistoryDepth is the number of operations kept behind a snapshot.
+	Plan struct {
+		ID                 string `json:"id" db:"id"`
+		Name               string `json:"name" db:"name"`
+		MaxEntries         int64  `json:"max_entries" db:"max_entries"`
+		MaxBytes           int64  `json:"max_bytes" db:"max_bytes"`
+		MaxAttachmentBytes int64  `json:"max_attachment_bytes" db:"max_attachment_bytes"`
+		MaxDevices         int    `json:"max_devices" db:"max_devices"`
+		SharingAllowed     bool   `json:"sharing_allowed" db:"sharing_allowed"`
+		HistoryDepth       int    `json:"history_depth" db:"history_depth"`
+	}
+
+	// Entitlements are what an account may use: those of its plan until
+	// ExpiresAt, the configured defaults otherwise. Limits read as in Plan.
+	Entitlements struct {
+		Plan               string  `json:"plan" db:"plan_id"`
+		ExpiresAt          *string `json:"expires_at,omitempty" db:"plan_expires_at"`
+		MaxEntries         int64   `json:"max_entries" db:"max_entries"`
+		MaxBytes           int64   `json:"max_bytes" db:"max_bytes"`
+		MaxAttachmentBytes int64   `json:"max_attachment_bytes" db:"max_attachment_bytes"`
+		MaxDevices         int     `json:"max_devices" db:"max_devices"`
+		SharingAllowed     bool    `json:"sharing_allowed" db:"sharing_allowed"`
+		HistoryDepth       int     `json:"history_depth" db:"history_depth"`
+	}
+
+	// VoucherInput redeems a voucher code. Redeeming a voucher of the current
+	// plan extends it; another plan can only be redeemed once it expired.
+	VoucherInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+		Code        string `json:"code" db:"code"`
+	}
 )
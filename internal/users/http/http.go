@@ -2,7 +2,6 @@ package http
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/ObscuraNote/api-general/internal/users/dto"
 	"github.com/ObscuraNote/api-general/internal/users/service"
@@ -26,6 +25,19 @@ func Register(router chi.Router, us service.UserService, log logger.Logger) {
 	router.Get("/users/check", h.CheckUserExists)
 	router.Put("/users/password", h.UpdatePassword)
 	router.Delete("/users", h.DeleteUser)
+	router.Put("/users/keys", h.PublishPublicKeys)
+	router.Get("/users/{address}/keys", h.GetPublicKeys)
+
+	router.Put("/users/emergency-contacts", h.AddEmergencyContact)
+	router.Get("/users/emergency-contacts", h.GetEmergencyContacts)
+	router.Delete("/users/emergency-contacts/{address}", h.RemoveEmergencyContact)
+	router.Post("/users/emergency-contacts/{address}/reject", h.RejectEmergencyRequest)
+	router.Get("/users/emergency-access", h.GetEmergencyGrants)
+	router.Post("/users/emergency-access/{address}/request", h.RequestEmergencyAccess)
+
+	router.Get("/users/usage", h.GetUsage)
+	router.Get("/users/plan", h.GetPlan)
+	router.Post("/users/vouchers/redeem", h.RedeemVoucher)
 }
 
 func (h *handler) CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -50,7 +62,7 @@ func (h *handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) CheckUserExists(w http.ResponseWriter, r *http.Request) {
 	var input dto.UserInput
-	input.UserAddress, input.Password = getCredentials(r)
+	input.UserAddress, input.Password = utils.GetCredentials(r)
 	if input.UserAddress == "" || input.Password == "" {
 		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
 
@@ -125,16 +137,301 @@ func (h *handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func getCredentials(r *http.Request) (string, string) {
-	authHeader := r.Header.Get("Authorization")
-	if strings.HasPrefix(authHeader, "Bearer ") {
-		credentials := strings.TrimPrefix(authHeader, "Bearer ")
-		parts := strings.Split(credentials, ":")
-		userAddress := parts[0]
-		password := parts[1]
+func (h *handler) PublishPublicKeys(w http.ResponseWriter, r *http.Request) {
+	var input dto.PublicKeysInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+
+		return
+	}
+
+	published, err := h.service.PublishPublicKeys(input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "PublishPublicKeys"}).
+			Error("Failed to publish public keys")
+
+		switch err.Error() {
+		case utils.UserNotFound:
+			_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+		case utils.BadRequest, utils.InvalidSignature:
+			_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+		case utils.VersionConflict:
+			_ = utils.Fault(w, http.StatusConflict, utils.VersionConflict)
+		default:
+			_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+		}
+
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, published); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "PublishPublicKeys"}).
+			Error("Failed to write response")
+	}
+}
+
+func (h *handler) GetPublicKeys(w http.ResponseWriter, r *http.Request) {
+	userAddress := chi.URLParam(r, "address")
+	if userAddress == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidParam)
+
+		return
+	}
+
+	directory, err := h.service.GetPublicKeys(userAddress)
+	if err != nil {
+		if err.Error() == utils.KeysNotFound {
+			_ = utils.Fault(w, http.StatusNotFound, utils.KeysNotFound)
+		} else {
+			_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+		}
+
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, directory); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "GetPublicKeys"}).
+			Error("Failed to write response")
+	}
+}
+
+func (h *handler) AddEmergencyContact(w http.ResponseWriter, r *http.Request) {
+	var input dto.EmergencyContactInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+
+		return
+	}
 
-		return userAddress, password
+	contact, err := h.service.AddEmergencyContact(input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "AddEmergencyContact"}).
+			Error("Failed to add emergency contact")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, contact); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "AddEmergencyContact"}).
+			Error("Failed to write response")
+	}
+}
+
+func (h *handler) GetEmergencyContacts(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	contacts, err := h.service.GetEmergencyContacts(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "GetEmergencyContacts"}).
+			Error("Failed to get emergency contacts")
+
+		writeError(w, err)
+		return
+	}
+
+	if contacts == nil {
+		contacts = []dto.EmergencyContact{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, contacts); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "GetEmergencyContacts"}).
+			Error("Failed to write response")
+	}
+}
+
+func (h *handler) RemoveEmergencyContact(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveEmergencyContact(chi.URLParam(r, "address"), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "RemoveEmergencyContact"}).
+			Error("Failed to remove emergency contact")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) RejectEmergencyRequest(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RejectEmergencyRequest(chi.URLParam(r, "address"), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "RejectEmergencyRequest"}).
+			Error("Failed to reject emergency request")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetEmergencyGrants(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	grants, err := h.service.GetEmergencyGrants(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "GetEmergencyGrants"}).
+			Error("Failed to get emergency grants")
+
+		writeError(w, err)
+		return
+	}
+
+	if grants == nil {
+		grants = []dto.EmergencyGrant{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, grants); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "GetEmergencyGrants"}).
+			Error("Failed to write response")
+	}
+}
+
+func (h *handler) RequestEmergencyAccess(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	grant, err := h.service.RequestEmergencyAccess(chi.URLParam(r, "address"), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "RequestEmergencyAccess"}).
+			Error("Failed to request emergency access")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, grant); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "RequestEmergencyAccess"}).
+			Error("Failed to write response")
+	}
+}
+
+func (h *handler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	usage, err := h.service.GetUsage(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "GetUsage"}).
+			Error("Failed to get usage")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, usage); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "GetUsage"}).
+			Error("Failed to write response")
+	}
+}
+
+func (h *handler) GetPlan(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	entitlements, err := h.service.GetPlan(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "GetPlan"}).
+			Error("Failed to get plan")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, entitlements); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "GetPlan"}).
+			Error("Failed to write response")
+	}
+}
+
+func (h *handler) RedeemVoucher(w http.ResponseWriter, r *http.Request) {
+	var input dto.VoucherInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" || input.Code == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+
+		return
+	}
+
+	entitlements, err := h.service.RedeemVoucher(input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "RedeemVoucher"}).
+			Error("Failed to redeem voucher")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, entitlements); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "users", "function": "RedeemVoucher"}).
+			Error("Failed to write response")
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.BadRequest:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.InvalidVoucher:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.UserNotFound, utils.ContactNotFound:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	case utils.PlanActive:
+		_ = utils.Fault(w, http.StatusConflict, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.UserInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.UserInput{}, false
 	}
 
-	return "", ""
+	return dto.UserInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
 }
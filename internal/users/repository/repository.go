@@ -2,20 +2,51 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"log"
 
+	"github.com/ObscuraNote/api-general/internal/users/dto"
+	"github.com/ObscuraNote/api-general/internal/utils/lock"
+	"github.com/jmoiron/sqlx"
 	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
 )
 
 var _ UsersRepository = (*Repository)(nil)
 
+// ErrPlanActive is returned when a voucher is redeemed for another plan than
+// the account's active one.
+var ErrPlanActive = errors.New("another plan is active")
+
 type (
 	UsersRepository interface {
 		CreateUser(userAddress, password string) error
 		GetUserId(userAddress, password string) (int64, error)
+		GetUserIdByAddress(userAddress string) (int64, error)
 		CheckUserExists(userAddress, password string) (bool, error)
 		UpdatePassword(userId int64, password string) error
-		DeleteUser(userId int64) (bool, error)
+		DeleteUser(userId int64, hook TxHook) (bool, error)
+		AddPublicKeys(userId int64, keys dto.PublicKeysInput, hook TxHook) (*dto.PublicKeys, error)
+		GetPublicKeys(userAddress string) ([]dto.PublicKeys, error)
+		GetLatestPublicKeys(userId int64) (*dto.PublicKeys, error)
+		UpsertEmergencyContact(ownerId, contactId int64, contact dto.EmergencyContactInput) (*dto.EmergencyContact, error)
+		GetEmergencyContacts(ownerId int64) ([]dto.EmergencyContact, error)
+		DeleteEmergencyContact(ownerId, contactId int64) (bool, error)
+		RejectEmergencyRequest(ownerId, contactId int64) (bool, error)
+		RequestEmergencyAccess(ownerId, contactId int64) (*dto.EmergencyGrant, error)
+		GetEmergencyGrants(contactId int64) ([]dto.EmergencyGrant, error)
+		ProcessEmergencyAccess() (int64, error)
+		GetUsage(userId int64) (*dto.Usage, error)
+		GetPlan(userId int64) (*dto.Entitlements, error)
+		RedeemVoucher(userId int64, codeHash []byte) error
+		UpsertPlan(plan dto.Plan) error
+		AddVouchers(planId string, durationDays int, codeHashes [][]byte) error
 	}
+	// TxHook runs inside the transaction of a write, right after the change.
+	// Returning an error rolls the change back.
+	TxHook func(ctx context.Context, tx *sqlx.Tx) error
+
 	Repository struct {
 		ctx        context.Context
 		db         *postgresdb.Client
@@ -60,6 +91,17 @@ func (r *Repository) GetUserId(userAddress, password string) (int64, error) {
 	return id, nil
 }
 
+func (r *Repository) GetUserIdByAddress(userAddress string) (int64, error) {
+	var id int64
+	err := r.statements.getUserIdByAddr.statement.
+		QueryRowContext(r.ctx, userAddress).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
 func (r *Repository) CheckUserExists(userAddress, password string) (bool, error) {
 	var exists bool
 	err := r.statements.checkUserExists.statement.
@@ -81,9 +123,128 @@ func (r *Repository) UpdatePassword(userId int64, password string) error {
 	return nil
 }
 
-func (r *Repository) DeleteUser(userId int64) (bool, error) {
-	result, err := r.statements.deleteUser.statement.
-		ExecContext(r.ctx, userId)
+func (r *Repository) DeleteUser(userId int64, hook TxHook) (bool, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		result, err := tx.StmtxContext(ctx, r.statements.deleteUser.statement).ExecContext(ctx, userId)
+		if err != nil {
+			return false, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return false, err
+		}
+
+		if rowsAffected > 0 && hook != nil {
+			if err := hook(ctx, tx); err != nil {
+				return false, err
+			}
+		}
+
+		return rowsAffected > 0, nil
+	}))
+	if err != nil {
+		return false, err
+	}
+
+	return result.(bool), nil
+}
+
+func (r *Repository) AddPublicKeys(userId int64, keys dto.PublicKeysInput, hook TxHook) (*dto.PublicKeys, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var published dto.PublicKeys
+		err := tx.StmtxContext(ctx, r.statements.addPublicKeys.statement).
+			QueryRowContext(ctx, userId, keys.Version, keys.EncryptionKey, keys.SigningKey, keys.Signature, keys.RotationSignature).
+			Scan(&published.Version, &published.EncryptionKey, &published.SigningKey, &published.Signature, &published.RotationSignature, &published.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if hook != nil {
+			if err := hook(ctx, tx); err != nil {
+				return nil, err
+			}
+		}
+
+		return &published, nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*dto.PublicKeys), nil
+}
+
+func (r *Repository) GetPublicKeys(userAddress string) ([]dto.PublicKeys, error) {
+	rows, err := r.statements.getPublicKeys.statement.
+		QueryContext(r.ctx, userAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []dto.PublicKeys
+	for rows.Next() {
+		var keys dto.PublicKeys
+		if err := rows.Scan(&keys.Version, &keys.EncryptionKey, &keys.SigningKey, &keys.Signature, &keys.RotationSignature, &keys.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, keys)
+	}
+
+	return history, nil
+}
+
+func (r *Repository) GetLatestPublicKeys(userId int64) (*dto.PublicKeys, error) {
+	var keys dto.PublicKeys
+	err := r.statements.getLatestKeys.statement.
+		QueryRowContext(r.ctx, userId).
+		Scan(&keys.Version, &keys.EncryptionKey, &keys.SigningKey, &keys.Signature, &keys.RotationSignature, &keys.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keys, nil
+}
+
+// UpsertEmergencyContact names contactId as an emergency contact of ownerId.
+// Naming the same contact again replaces the wrapped key and settings and
+// drops any pending request or grant.
+func (r *Repository) UpsertEmergencyContact(ownerId, contactId int64, contact dto.EmergencyContactInput) (*dto.EmergencyContact, error) {
+	var result dto.EmergencyContact
+	err := r.statements.upsertContact.statement.
+		QueryRowContext(r.ctx, ownerId, contactId, contact.EncryptedKey, contact.KeyIV, contact.WaitHours, contact.InactivityDays).
+		Scan(&result.ContactAddress, &result.WaitHours, &result.InactivityDays, &result.Status, &result.RequestedAt,
+			&result.GrantAt, &result.GrantedAt, &result.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *Repository) GetEmergencyContacts(ownerId int64) ([]dto.EmergencyContact, error) {
+	rows, err := r.statements.getContacts.statement.QueryContext(r.ctx, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []dto.EmergencyContact
+	for rows.Next() {
+		var contact dto.EmergencyContact
+		if err := rows.Scan(&contact.ContactAddress, &contact.WaitHours, &contact.InactivityDays, &contact.Status,
+			&contact.RequestedAt, &contact.GrantAt, &contact.GrantedAt, &contact.CreatedAt); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, rows.Err()
+}
+
+func (r *Repository) DeleteEmergencyContact(ownerId, contactId int64) (bool, error) {
+	result, err := r.statements.deleteContact.statement.ExecContext(r.ctx, ownerId, contactId)
 	if err != nil {
 		return false, err
 	}
@@ -96,6 +257,205 @@ func (r *Repository) DeleteUser(userId int64) (bool, error) {
 	return rowsAffected > 0, nil
 }
 
+// RejectEmergencyRequest cancels a pending request. It reports false when
+// there is no pending request, including when access was already granted.
+func (r *Repository) RejectEmergencyRequest(ownerId, contactId int64) (bool, error) {
+	result, err := r.statements.rejectRequest.statement.ExecContext(r.ctx, ownerId, contactId)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RequestEmergencyAccess starts the waiting period of an idle contact and
+// returns the contact's view of the account. Requests that are already
+// pending or granted are left untouched. It returns sql.ErrNoRows when
+// contactId is not an emergency contact of ownerId.
+func (r *Repository) RequestEmergencyAccess(ownerId, contactId int64) (*dto.EmergencyGrant, error) {
+	if _, err := r.statements.requestAccess.statement.ExecContext(r.ctx, ownerId, contactId); err != nil {
+		return nil, err
+	}
+
+	var grant dto.EmergencyGrant
+	err := r.statements.getGrant.statement.
+		QueryRowContext(r.ctx, ownerId, contactId).
+		Scan(&grant.OwnerAddress, &grant.WaitHours, &grant.Status, &grant.RequestedAt, &grant.GrantAt, &grant.GrantedAt,
+			&grant.EncryptedKey, &grant.KeyIV)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grant, nil
+}
+
+func (r *Repository) GetEmergencyGrants(contactId int64) ([]dto.EmergencyGrant, error) {
+	rows, err := r.statements.getGrants.statement.QueryContext(r.ctx, contactId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []dto.EmergencyGrant
+	for rows.Next() {
+		var grant dto.EmergencyGrant
+		if err := rows.Scan(&grant.OwnerAddress, &grant.WaitHours, &grant.Status, &grant.RequestedAt, &grant.GrantAt,
+			&grant.GrantedAt, &grant.EncryptedKey, &grant.KeyIV); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// ProcessEmergencyAccess opens requests for owners inactive for longer than
+// their contacts' inactivity period, then grants every request whose waiting
+// period is over. When another replica is already processing, it does
+// nothing and returns zero.
+func (r *Repository) ProcessEmergencyAccess() (int64, error) {
+	processed, err := lock.TryExclusive(r.ctx, r.db, lock.EmergencyAccess, func(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+		triggered, err := tx.StmtxContext(ctx, r.statements.triggerInactive.statement).ExecContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		granted, err := tx.StmtxContext(ctx, r.statements.grantDue.statement).ExecContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		triggeredRows, err := triggered.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+
+		grantedRows, err := granted.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+
+		return triggeredRows + grantedRows, nil
+	})
+	if err != nil {
+		log.Println("Error processing emergency access")
+		return 0, err
+	}
+
+	return processed, nil
+}
+
+// GetUsage returns the storage userId consumes. Limits are left to the
+// caller.
+func (r *Repository) GetUsage(userId int64) (*dto.Usage, error) {
+	var usage dto.Usage
+	err := r.statements.getUsage.statement.
+		QueryRowContext(r.ctx, userId).
+		Scan(&usage.Entries.Used, &usage.Bytes.Used, &usage.AttachmentBytes.Used)
+	if err != nil {
+		log.Println("Error getting usage")
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+// GetPlan returns the entitlements of userId's plan. It returns
+// sql.ErrNoRows when the account has no plan or it expired.
+func (r *Repository) GetPlan(userId int64) (*dto.Entitlements, error) {
+	var plan dto.Entitlements
+	err := r.statements.getPlan.statement.
+		QueryRowContext(r.ctx, userId).
+		Scan(&plan.Plan, &plan.ExpiresAt, &plan.MaxEntries, &plan.MaxBytes, &plan.MaxAttachmentBytes,
+			&plan.MaxDevices, &plan.SharingAllowed, &plan.HistoryDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// RedeemVoucher consumes the voucher and applies its plan to userId. The
+// voucher row is deleted, so nothing links it to the account afterwards. It
+// returns sql.ErrNoRows when the voucher does not exist or was already
+// redeemed, and ErrPlanActive when another plan is still running; the
+// voucher is left untouched then.
+func (r *Repository) RedeemVoucher(userId int64, codeHash []byte) error {
+	_, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var planId string
+		var durationDays int
+		if err := tx.StmtxContext(ctx, r.statements.redeemVoucher.statement).
+			QueryRowContext(ctx, codeHash).Scan(&planId, &durationDays); err != nil {
+			return nil, err
+		}
+
+		result, err := tx.StmtxContext(ctx, r.statements.applyPlan.statement).
+			ExecContext(ctx, userId, planId, durationDays)
+		if err != nil {
+			return nil, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if rowsAffected == 0 {
+			return nil, ErrPlanActive
+		}
+
+		return nil, nil
+	}))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, ErrPlanActive) {
+			log.Println("Error redeeming voucher")
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// UpsertPlan creates the plan or replaces its entitlements. Accounts on the
+// plan get the new entitlements right away.
+func (r *Repository) UpsertPlan(plan dto.Plan) error {
+	_, err := r.statements.upsertPlan.statement.
+		ExecContext(r.ctx, plan.ID, plan.Name, plan.MaxEntries, plan.MaxBytes, plan.MaxAttachmentBytes,
+			plan.MaxDevices, plan.SharingAllowed, plan.HistoryDepth)
+	if err != nil {
+		log.Println("Error upserting plan")
+		return err
+	}
+
+	return nil
+}
+
+// AddVouchers stores the hashes of new voucher codes for planId, all or
+// none of them.
+func (r *Repository) AddVouchers(planId string, durationDays int, codeHashes [][]byte) error {
+	_, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		for _, codeHash := range codeHashes {
+			if _, err := tx.StmtxContext(ctx, r.statements.addVoucher.statement).
+				ExecContext(ctx, codeHash, planId, durationDays); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	}))
+	if err != nil {
+		log.Println("Error adding vouchers")
+		return err
+	}
+
+	return nil
+}
+
 func (r *Repository) prepareStatements() (statements, error) {
 	var err error
 
@@ -109,6 +469,11 @@ func (r *Repository) prepareStatements() (statements, error) {
 		return statements{}, err
 	}
 
+	statementsList.getUserIdByAddr.statement, err = r.db.PrepareStatement(statementsList.getUserIdByAddr.query)
+	if err != nil {
+		return statements{}, err
+	}
+
 	statementsList.checkUserExists.statement, err = r.db.PrepareStatement(statementsList.checkUserExists.query)
 	if err != nil {
 		return statements{}, err
@@ -124,5 +489,95 @@ func (r *Repository) prepareStatements() (statements, error) {
 		return statements{}, err
 	}
 
+	statementsList.addPublicKeys.statement, err = r.db.PrepareStatement(statementsList.addPublicKeys.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getPublicKeys.statement, err = r.db.PrepareStatement(statementsList.getPublicKeys.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getLatestKeys.statement, err = r.db.PrepareStatement(statementsList.getLatestKeys.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.upsertContact.statement, err = r.db.PrepareStatement(statementsList.upsertContact.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getContacts.statement, err = r.db.PrepareStatement(statementsList.getContacts.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteContact.statement, err = r.db.PrepareStatement(statementsList.deleteContact.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.rejectRequest.statement, err = r.db.PrepareStatement(statementsList.rejectRequest.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.requestAccess.statement, err = r.db.PrepareStatement(statementsList.requestAccess.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getGrant.statement, err = r.db.PrepareStatement(statementsList.getGrant.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getGrants.statement, err = r.db.PrepareStatement(statementsList.getGrants.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.triggerInactive.statement, err = r.db.PrepareStatement(statementsList.triggerInactive.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.grantDue.statement, err = r.db.PrepareStatement(statementsList.grantDue.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getUsage.statement, err = r.db.PrepareStatement(statementsList.getUsage.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getPlan.statement, err = r.db.PrepareStatement(statementsList.getPlan.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.redeemVoucher.statement, err = r.db.PrepareStatement(statementsList.redeemVoucher.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.applyPlan.statement, err = r.db.PrepareStatement(statementsList.applyPlan.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.upsertPlan.statement, err = r.db.PrepareStatement(statementsList.upsertPlan.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.addVoucher.statement, err = r.db.PrepareStatement(statementsList.addVoucher.query)
+	if err != nil {
+		return statements{}, err
+	}
+
 	return statementsList, nil
 }
@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/ObscuraNote/api-general/internal/users/dto"
 	"github.com/philippe-berto/database/postgresdb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -47,7 +48,8 @@ func (suite *RepositoryTestSuite) SetupSuite() {
 	require.NoError(suite.T(), err)
 
 	suite.db = db
-	suite.repo = New(suite.ctx, db)
+	suite.repo, err = New(suite.ctx, db)
+	require.NoError(suite.T(), err)
 }
 
 func (suite *RepositoryTestSuite) TearDownSuite() {
@@ -124,6 +126,58 @@ func (suite *RepositoryTestSuite) TestGetUserId() {
 	assert.Equal(suite.T(), sql.ErrNoRows, err)
 }
 
+func (suite *RepositoryTestSuite) TestGetUserIdByAddress() {
+	err := suite.repo.CreateUser(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+
+	id, err := suite.repo.GetUserIdByAddress(testUserAddress)
+	assert.NoError(suite.T(), err)
+	assert.Greater(suite.T(), id, int64(0))
+
+	_, err = suite.repo.GetUserIdByAddress(testNonExistentAddr)
+	assert.Equal(suite.T(), sql.ErrNoRows, err)
+}
+
+func (suite *RepositoryTestSuite) TestPublicKeys() {
+	err := suite.repo.CreateUser(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+
+	userId, err := suite.repo.GetUserId(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+
+	// No keys published yet
+	_, err = suite.repo.GetLatestPublicKeys(userId)
+	assert.Equal(suite.T(), sql.ErrNoRows, err)
+
+	keys := dto.PublicKeysInput{
+		Version:       1,
+		EncryptionKey: make([]byte, 32),
+		SigningKey:    make([]byte, 32),
+		Signature:     []byte("signature"),
+	}
+	published, err := suite.repo.AddPublicKeys(userId, keys, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, published.Version)
+
+	// Same version again is rejected
+	_, err = suite.repo.AddPublicKeys(userId, keys, nil)
+	assert.Error(suite.T(), err)
+
+	keys.Version = 2
+	keys.RotationSignature = []byte("rotation")
+	_, err = suite.repo.AddPublicKeys(userId, keys, nil)
+	assert.NoError(suite.T(), err)
+
+	latest, err := suite.repo.GetLatestPublicKeys(userId)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, latest.Version)
+
+	history, err := suite.repo.GetPublicKeys(testUserAddress)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), history, 2)
+	assert.Equal(suite.T(), 2, history[0].Version)
+}
+
 func (suite *RepositoryTestSuite) TestUpdatePassword() {
 	// Create user
 	err := suite.repo.CreateUser(testUserAddress, testPassword)
@@ -172,7 +226,7 @@ func (suite *RepositoryTestSuite) TestDeleteUser() {
 	assert.Greater(suite.T(), userId, int64(0))
 
 	// Delete user
-	deleted, err := suite.repo.DeleteUser(userId)
+	deleted, err := suite.repo.DeleteUser(userId, nil)
 	assert.NoError(suite.T(), err)
 	assert.True(suite.T(), deleted) // Verify that a row was actually deleted
 
@@ -185,7 +239,7 @@ func (suite *RepositoryTestSuite) TestDeleteUser() {
 func (suite *RepositoryTestSuite) TestDeleteUser_NonExistent() {
 	// Try to delete non-existent user
 	nonExistentUserId := int64(99999)
-	deleted, err := suite.repo.DeleteUser(nonExistentUserId)
+	deleted, err := suite.repo.DeleteUser(nonExistentUserId, nil)
 	assert.NoError(suite.T(), err)
 	assert.False(suite.T(), deleted) // Verify that no rows were affected
 }
@@ -223,7 +277,7 @@ func (suite *RepositoryTestSuite) TestMultipleUsers() {
 	require.NoError(suite.T(), err)
 	assert.Greater(suite.T(), userIdToDelete, int64(0))
 
-	deleted, err := suite.repo.DeleteUser(userIdToDelete)
+	deleted, err := suite.repo.DeleteUser(userIdToDelete, nil)
 	assert.NoError(suite.T(), err)
 	assert.True(suite.T(), deleted) // Verify that a row was actually deleted
 
@@ -243,6 +297,173 @@ func (suite *RepositoryTestSuite) TestMultipleUsers() {
 	}
 }
 
+func (suite *RepositoryTestSuite) TestEmergencyAccess() {
+	err := suite.repo.CreateUser(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+	err = suite.repo.CreateUser(testNonExistentAddr, testPassword2)
+	require.NoError(suite.T(), err)
+
+	ownerId, err := suite.repo.GetUserId(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+	contactId, err := suite.repo.GetUserId(testNonExistentAddr, testPassword2)
+	require.NoError(suite.T(), err)
+
+	inactivityDays := 30
+	contact, err := suite.repo.UpsertEmergencyContact(ownerId, contactId, dto.EmergencyContactInput{
+		EncryptedKey:   []byte("wrapped"),
+		WaitHours:      48,
+		InactivityDays: &inactivityDays,
+	})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), testNonExistentAddr, contact.ContactAddress)
+	assert.Equal(suite.T(), dto.EmergencyStatusIdle, contact.Status)
+
+	_, err = suite.repo.RequestEmergencyAccess(contactId, ownerId)
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+
+	grant, err := suite.repo.RequestEmergencyAccess(ownerId, contactId)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), dto.EmergencyStatusRequested, grant.Status)
+	assert.NotNil(suite.T(), grant.GrantAt)
+	assert.Nil(suite.T(), grant.EncryptedKey)
+
+	// Only the owner withdraws a request the contact asked for.
+	_, err = suite.repo.GetUserId(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+	contacts, err := suite.repo.GetEmergencyContacts(ownerId)
+	assert.NoError(suite.T(), err)
+	require.Len(suite.T(), contacts, 1)
+	assert.Equal(suite.T(), dto.EmergencyStatusRequested, contacts[0].Status)
+
+	rejected, err := suite.repo.RejectEmergencyRequest(ownerId, contactId)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), rejected)
+
+	// An owner inactive for longer than the inactivity period gets a request
+	// opened on the contact's behalf, withdrawn as soon as they authenticate.
+	_, err = suite.db.GetClient().Exec(`UPDATE users SET last_auth_at = CURRENT_TIMESTAMP - INTERVAL '31 days' WHERE id = $1;`, ownerId)
+	require.NoError(suite.T(), err)
+
+	processed, err := suite.repo.ProcessEmergencyAccess()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), processed)
+
+	_, err = suite.repo.GetUserId(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+	contacts, err = suite.repo.GetEmergencyContacts(ownerId)
+	assert.NoError(suite.T(), err)
+	require.Len(suite.T(), contacts, 1)
+	assert.Equal(suite.T(), dto.EmergencyStatusIdle, contacts[0].Status)
+
+	// Otherwise it is granted once the waiting period ends.
+	_, err = suite.db.GetClient().Exec(`UPDATE users SET last_auth_at = CURRENT_TIMESTAMP - INTERVAL '31 days' WHERE id = $1;`, ownerId)
+	require.NoError(suite.T(), err)
+
+	processed, err = suite.repo.ProcessEmergencyAccess()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), processed)
+
+	_, err = suite.db.GetClient().Exec(`UPDATE emergency_contacts SET grant_at = CURRENT_TIMESTAMP - INTERVAL '1 minute';`)
+	require.NoError(suite.T(), err)
+
+	processed, err = suite.repo.ProcessEmergencyAccess()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), processed)
+
+	grants, err := suite.repo.GetEmergencyGrants(contactId)
+	assert.NoError(suite.T(), err)
+	require.Len(suite.T(), grants, 1)
+	assert.Equal(suite.T(), dto.EmergencyStatusGranted, grants[0].Status)
+	assert.Equal(suite.T(), []byte("wrapped"), grants[0].EncryptedKey)
+
+	rejected, err = suite.repo.RejectEmergencyRequest(ownerId, contactId)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), rejected)
+
+	// Authenticating records activity.
+	_, err = suite.repo.GetUserId(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+	var inactive bool
+	err = suite.db.GetClient().QueryRow(`SELECT last_auth_at < CURRENT_TIMESTAMP - INTERVAL '1 day' FROM users WHERE id = $1;`, ownerId).
+		Scan(&inactive)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), inactive)
+
+	deleted, err := suite.repo.DeleteEmergencyContact(ownerId, contactId)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), deleted)
+
+	contacts, err = suite.repo.GetEmergencyContacts(ownerId)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), contacts, 0)
+}
+
+func (suite *RepositoryTestSuite) TestPlans() {
+	err := suite.repo.CreateUser(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+	userId, err := suite.repo.GetUserId(testUserAddress, testPassword)
+	require.NoError(suite.T(), err)
+
+	for _, plan := range []dto.Plan{
+		{ID: "test-premium", Name: "Premium", MaxEntries: 100, MaxDevices: 5, SharingAllowed: true, HistoryDepth: 50},
+		{ID: "test-basic", Name: "Basic", MaxEntries: 10, MaxDevices: 1},
+	} {
+		require.NoError(suite.T(), suite.repo.UpsertPlan(plan))
+	}
+
+	premium := [][]byte{[]byte("test-voucher-premium-1"), []byte("test-voucher-premium-2")}
+	basic := []byte("test-voucher-basic-1")
+	_, err = suite.db.GetClient().Exec(`DELETE FROM vouchers WHERE plan_id LIKE 'test-%';`)
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), suite.repo.AddVouchers("test-premium", 30, premium))
+	require.NoError(suite.T(), suite.repo.AddVouchers("test-basic", 30, [][]byte{basic}))
+
+	_, err = suite.repo.GetPlan(userId)
+	assert.Equal(suite.T(), sql.ErrNoRows, err)
+
+	err = suite.repo.RedeemVoucher(userId, premium[0])
+	assert.NoError(suite.T(), err)
+
+	plan, err := suite.repo.GetPlan(userId)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "test-premium", plan.Plan)
+	assert.Equal(suite.T(), int64(100), plan.MaxEntries)
+	assert.Equal(suite.T(), 5, plan.MaxDevices)
+	assert.True(suite.T(), plan.SharingAllowed)
+	assert.Equal(suite.T(), 50, plan.HistoryDepth)
+	require.NotNil(suite.T(), plan.ExpiresAt)
+
+	// Vouchers are single use.
+	err = suite.repo.RedeemVoucher(userId, premium[0])
+	assert.Equal(suite.T(), sql.ErrNoRows, err)
+
+	// Another plan cannot replace an active one, and its voucher stays usable.
+	err = suite.repo.RedeemVoucher(userId, basic)
+	assert.ErrorIs(suite.T(), err, ErrPlanActive)
+
+	// A voucher of the same plan extends it.
+	err = suite.repo.RedeemVoucher(userId, premium[1])
+	assert.NoError(suite.T(), err)
+	var remainingDays int
+	err = suite.db.GetClient().QueryRow(`SELECT EXTRACT(DAY FROM plan_expires_at - CURRENT_TIMESTAMP) FROM users WHERE id = $1;`, userId).
+		Scan(&remainingDays)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 59, remainingDays)
+
+	// Expired plans no longer apply and can be replaced.
+	_, err = suite.db.GetClient().Exec(`UPDATE users SET plan_expires_at = CURRENT_TIMESTAMP - INTERVAL '1 minute' WHERE id = $1;`, userId)
+	require.NoError(suite.T(), err)
+	_, err = suite.repo.GetPlan(userId)
+	assert.Equal(suite.T(), sql.ErrNoRows, err)
+
+	err = suite.repo.RedeemVoucher(userId, basic)
+	assert.NoError(suite.T(), err)
+	plan, err = suite.repo.GetPlan(userId)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "test-basic", plan.Plan)
+	assert.False(suite.T(), plan.SharingAllowed)
+}
+
 func TestRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(RepositoryTestSuite))
 }
@@ -11,9 +11,28 @@ type statementsItem struct {
 type statements struct {
 	createUser      statementsItem
 	getUserId       statementsItem
+	getUserIdByAddr statementsItem
 	checkUserExists statementsItem
 	updatePassword  statementsItem
 	deleteUser      statementsItem
+	addPublicKeys   statementsItem
+	getPublicKeys   statementsItem
+	getLatestKeys   statementsItem
+	upsertContact   statementsItem
+	getContacts     statementsItem
+	deleteContact   statementsItem
+	rejectRequest   statementsItem
+	requestAccess   statementsItem
+	getGrant        statementsItem
+	getGrants       statementsItem
+	triggerInactive statementsItem
+	grantDue        statementsItem
+	getUsage        statementsItem
+	getPlan         statementsItem
+	redeemVoucher   statementsItem
+	applyPlan       statementsItem
+	upsertPlan      statementsItem
+	addVoucher      statementsItem
 }
 
 var statementsList = statements{
@@ -26,19 +45,60 @@ var statementsList = statements{
 	getUserId: statementsItem{
 		name: "getUserId",
 		query: `
+            WITH u AS (
+                SELECT id, last_auth_at
+                FROM users
+                WHERE user_address = $1
+                AND password = $2
+            ), touched AS (
+                UPDATE users
+                SET last_auth_at = CURRENT_TIMESTAMP
+                FROM u
+                WHERE users.id = u.id
+                AND (u.last_auth_at IS NULL OR u.last_auth_at < CURRENT_TIMESTAMP - INTERVAL '1 hour')
+            ), withdrawn AS (
+                UPDATE emergency_contacts c
+                SET status = 'idle', requested_at = NULL, grant_at = NULL,
+                    inactivity_requested = FALSE, updated_at = CURRENT_TIMESTAMP
+                FROM u
+                WHERE c.owner_id = u.id
+                AND c.status = 'requested'
+                AND c.inactivity_requested
+            )
+            SELECT id
+            FROM u;`,
+	},
+	getUserIdByAddr: statementsItem{
+		name: "getUserIdByAddr",
+		query: `
             SELECT id
             FROM users
-            WHERE user_address = $1
-            AND password = $2;`,
+            WHERE user_address = $1;`,
 	},
 	checkUserExists: statementsItem{
 		name: "checkUserExists",
 		query: `
-            SELECT EXISTS(
-            SELECT 1 FROM users 
-            WHERE user_address = $1
-						AND password = $2
-        );`,
+            WITH u AS (
+                SELECT id, last_auth_at
+                FROM users
+                WHERE user_address = $1
+                AND password = $2
+            ), touched AS (
+                UPDATE users
+                SET last_auth_at = CURRENT_TIMESTAMP
+                FROM u
+                WHERE users.id = u.id
+                AND (u.last_auth_at IS NULL OR u.last_auth_at < CURRENT_TIMESTAMP - INTERVAL '1 hour')
+            ), withdrawn AS (
+                UPDATE emergency_contacts c
+                SET status = 'idle', requested_at = NULL, grant_at = NULL,
+                    inactivity_requested = FALSE, updated_at = CURRENT_TIMESTAMP
+                FROM u
+                WHERE c.owner_id = u.id
+                AND c.status = 'requested'
+                AND c.inactivity_requested
+            )
+            SELECT EXISTS(SELECT 1 FROM u);`,
 	},
 	updatePassword: statementsItem{
 		name: "updatePassword",
@@ -53,4 +113,199 @@ var statementsList = statements{
             DELETE FROM users
             WHERE id = $1;`,
 	},
+	addPublicKeys: statementsItem{
+		name: "addPublicKeys",
+		query: `
+            INSERT INTO user_public_keys (user_id, version, encryption_key, signing_key, signature, rotation_signature)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            RETURNING version, encryption_key, signing_key, signature, rotation_signature, created_at;`,
+	},
+	getPublicKeys: statementsItem{
+		name: "getPublicKeys",
+		query: `
+            SELECT k.version, k.encryption_key, k.signing_key, k.signature, k.rotation_signature, k.created_at
+            FROM user_public_keys k
+            JOIN users u ON u.id = k.user_id
+            WHERE u.user_address = $1
+            ORDER BY k.version DESC;`,
+	},
+	getLatestKeys: statementsItem{
+		name: "getLatestKeys",
+		query: `
+            SELECT version, encryption_key, signing_key, signature, rotation_signature, created_at
+            FROM user_public_keys
+            WHERE user_id = $1
+            ORDER BY version DESC
+            LIMIT 1;`,
+	},
+	upsertContact: statementsItem{
+		name: "upsertContact",
+		query: `
+            WITH c AS (
+                INSERT INTO emergency_contacts (owner_id, contact_id, encrypted_key, key_iv, wait_hours, inactivity_days)
+                VALUES ($1, $2, $3, $4, $5, $6)
+                ON CONFLICT (owner_id, contact_id) DO UPDATE
+                SET encrypted_key = EXCLUDED.encrypted_key, key_iv = EXCLUDED.key_iv,
+                    wait_hours = EXCLUDED.wait_hours, inactivity_days = EXCLUDED.inactivity_days,
+                    status = 'idle', requested_at = NULL, grant_at = NULL, granted_at = NULL,
+                    inactivity_requested = FALSE, updated_at = CURRENT_TIMESTAMP
+                RETURNING contact_id, wait_hours, inactivity_days, status, requested_at, grant_at, granted_at, created_at
+            )
+            SELECT u.user_address, c.wait_hours, c.inactivity_days, c.status, c.requested_at, c.grant_at, c.granted_at,
+                c.created_at
+            FROM c
+            JOIN users u ON u.id = c.contact_id;`,
+	},
+	getContacts: statementsItem{
+		name: "getContacts",
+		query: `
+            SELECT u.user_address, c.wait_hours, c.inactivity_days, c.status, c.requested_at, c.grant_at, c.granted_at,
+                c.created_at
+            FROM emergency_contacts c
+            JOIN users u ON u.id = c.contact_id
+            WHERE c.owner_id = $1
+            ORDER BY c.created_at;`,
+	},
+	deleteContact: statementsItem{
+		name: "deleteContact",
+		query: `
+            DELETE FROM emergency_contacts
+            WHERE owner_id = $1
+            AND contact_id = $2;`,
+	},
+	rejectRequest: statementsItem{
+		name: "rejectRequest",
+		query: `
+            UPDATE emergency_contacts
+            SET status = 'idle', requested_at = NULL, grant_at = NULL,
+                inactivity_requested = FALSE, updated_at = CURRENT_TIMESTAMP
+            WHERE owner_id = $1
+            AND contact_id = $2
+            AND status = 'requested';`,
+	},
+	requestAccess: statementsItem{
+		name: "requestAccess",
+		query: `
+            UPDATE emergency_contacts
+            SET status = 'requested', requested_at = CURRENT_TIMESTAMP,
+                grant_at = CURRENT_TIMESTAMP + wait_hours * INTERVAL '1 hour', updated_at = CURRENT_TIMESTAMP
+            WHERE owner_id = $1
+            AND contact_id = $2
+            AND status = 'idle';`,
+	},
+	getGrant: statementsItem{
+		name: "getGrant",
+		query: `
+            SELECT u.user_address, c.wait_hours, c.status, c.requested_at, c.grant_at, c.granted_at,
+                CASE WHEN c.status = 'granted' THEN c.encrypted_key END,
+                CASE WHEN c.status = 'granted' THEN c.key_iv END
+            FROM emergency_contacts c
+            JOIN users u ON u.id = c.owner_id
+            WHERE c.owner_id = $1
+            AND c.contact_id = $2;`,
+	},
+	getGrants: statementsItem{
+		name: "getGrants",
+		query: `
+            SELECT u.user_address, c.wait_hours, c.status, c.requested_at, c.grant_at, c.granted_at,
+                CASE WHEN c.status = 'granted' THEN c.encrypted_key END,
+                CASE WHEN c.status = 'granted' THEN c.key_iv END
+            FROM emergency_contacts c
+            JOIN users u ON u.id = c.owner_id
+            WHERE c.contact_id = $1
+            ORDER BY c.created_at;`,
+	},
+	triggerInactive: statementsItem{
+		name: "triggerInactive",
+		query: `
+            UPDATE emergency_contacts c
+            SET status = 'requested', requested_at = CURRENT_TIMESTAMP,
+                grant_at = CURRENT_TIMESTAMP + c.wait_hours * INTERVAL '1 hour',
+                inactivity_requested = TRUE, updated_at = CURRENT_TIMESTAMP
+            FROM users u
+            WHERE u.id = c.owner_id
+            AND c.status = 'idle'
+            AND c.inactivity_days IS NOT NULL
+            AND u.last_auth_at < CURRENT_TIMESTAMP - c.inactivity_days * INTERVAL '1 day';`,
+	},
+	grantDue: statementsItem{
+		name: "grantDue",
+		query: `
+            UPDATE emergency_contacts
+            SET status = 'granted', granted_at = CURRENT_TIMESTAMP,
+                inactivity_requested = FALSE, updated_at = CURRENT_TIMESTAMP
+            WHERE status = 'requested'
+            AND grant_at <= CURRENT_TIMESTAMP;`,
+	},
+	getUsage: statementsItem{
+		name: "getUsage",
+		query: `
+            SELECT COUNT(*), COALESCE(SUM(COALESCE(k.data_size, octet_length(k.encrypted_data))), 0)
+                + COALESCE((
+                    SELECT SUM(COALESCE(kc.data_size, octet_length(kc.encrypted_data)))
+                    FROM key_conflicts kc
+                    JOIN keys ck ON ck.id = kc.key_id
+                    WHERE ck.user_id = $1
+                ), 0),
+                COALESCE((
+                    SELECT SUM(c.size)
+                    FROM attachment_chunks c
+                    JOIN attachments a ON a.id = c.attachment_id
+                    JOIN keys ak ON ak.id = a.key_id
+                    WHERE ak.user_id = $1
+                ), 0)
+            FROM keys k
+            WHERE k.user_id = $1;`,
+	},
+	getPlan: statementsItem{
+		name: "getPlan",
+		query: `
+            SELECT u.plan_id, u.plan_expires_at, p.max_entries, p.max_bytes, p.max_attachment_bytes,
+                p.max_devices, p.sharing_allowed, p.history_depth
+            FROM users u
+            JOIN plans p ON p.id = u.plan_id
+            WHERE u.id = $1
+            AND u.plan_expires_at > CURRENT_TIMESTAMP;`,
+	},
+	redeemVoucher: statementsItem{
+		name: "redeemVoucher",
+		query: `
+            DELETE FROM vouchers
+            WHERE code_hash = $1
+            RETURNING plan_id, duration_days;`,
+	},
+	applyPlan: statementsItem{
+		name: "applyPlan",
+		query: `
+            UPDATE users
+            SET plan_expires_at = CASE
+                    WHEN plan_id = $2 AND plan_expires_at > CURRENT_TIMESTAMP THEN plan_expires_at
+                    ELSE CURRENT_TIMESTAMP
+                END + $3 * INTERVAL '1 day',
+                plan_id = $2
+            WHERE id = $1
+            AND (plan_id IS NULL OR plan_id = $2 OR plan_expires_at <= CURRENT_TIMESTAMP);`,
+	},
+	upsertPlan: statementsItem{
+		name: "upsertPlan",
+		query: `
+            INSERT INTO plans (id, name, max_entries, max_bytes, max_attachment_bytes, max_devices,
+                sharing_allowed, history_depth)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            ON CONFLICT (id) DO UPDATE
+            SET name = EXCLUDED.name,
+                max_entries = EXCLUDED.max_entries,
+                max_bytes = EXCLUDED.max_bytes,
+                max_attachment_bytes = EXCLUDED.max_attachment_bytes,
+                max_devices = EXCLUDED.max_devices,
+                sharing_allowed = EXCLUDED.sharing_allowed,
+                history_depth = EXCLUDED.history_depth,
+                updated_at = CURRENT_TIMESTAMP;`,
+	},
+	addVoucher: statementsItem{
+		name: "addVoucher",
+		query: `
+            INSERT INTO vouchers (code_hash, plan_id, duration_days)
+            VALUES ($1, $2, $3);`,
+	},
 }
@@ -2,36 +2,91 @@ package service
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"strings"
 
+	tdto "github.com/ObscuraNote/api-general/internal/transparency/dto"
+	ts "github.com/ObscuraNote/api-general/internal/transparency/service"
+	"github.com/ObscuraNote/api-general/internal/users/dto"
 	ur "github.com/ObscuraNote/api-general/internal/users/repository"
 	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/ObscuraNote/api-general/internal/utils/eventbus"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
 	"github.com/philippe-berto/logger"
 )
 
 var _ UserService = (*Service)(nil)
 
+const (
+	// publicKeysContext prefixes every signed public key bundle so the
+	// signatures cannot be replayed in another protocol.
+	publicKeysContext = "obscuranote-public-keys-v1"
+
+	minWaitHours      = 1
+	maxWaitHours      = 90 * 24
+	minInactivityDays = 7
+	maxInactivityDays = 10 * 365
+
+	// voucherSize is the entropy of a voucher code in bytes, written as 32
+	// base32 characters in groups of four.
+	voucherSize      = 20
+	voucherGroupSize = 4
+)
+
+var voucherEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
 type (
 	UserService interface {
 		CreateUser(userAddress, password string) error
 		GetUserId(userAddress, password string) (int64, error)
+		GetUserIdByAddress(userAddress string) (int64, error)
 		CheckUserExists(userAddress, password string) (bool, error)
 		UpdatePassword(userAddress, password, newPassword string) error
 		DeleteUser(userAddress, password string) (bool, error)
+		PublishPublicKeys(keys dto.PublicKeysInput) (*dto.PublicKeys, error)
+		GetPublicKeys(userAddress string) (*dto.PublicKeyDirectory, error)
+		AddEmergencyContact(contact dto.EmergencyContactInput) (*dto.EmergencyContact, error)
+		GetEmergencyContacts(auth dto.UserInput) ([]dto.EmergencyContact, error)
+		RemoveEmergencyContact(contactAddress string, auth dto.UserInput) error
+		RejectEmergencyRequest(contactAddress string, auth dto.UserInput) error
+		RequestEmergencyAccess(ownerAddress string, auth dto.UserInput) (*dto.EmergencyGrant, error)
+		GetEmergencyGrants(auth dto.UserInput) ([]dto.EmergencyGrant, error)
+		GetUsage(auth dto.UserInput) (*dto.Usage, error)
+		GetEntitlements(userId int64) (*dto.Entitlements, error)
+		GetPlan(auth dto.UserInput) (*dto.Entitlements, error)
+		RedeemVoucher(input dto.VoucherInput) (*dto.Entitlements, error)
 	}
 
 	Service struct {
-		ctx  context.Context
-		repo ur.UsersRepository
-		log  *logger.Logger
+		ctx          context.Context
+		repo         ur.UsersRepository
+		tl           ts.Appender
+		bus          eventbus.Bus
+		log          *logger.Logger
+		quota        config.QuotaConfig
+		entitlements config.EntitlementsConfig
 	}
 )
 
-func New(ctx context.Context, repo ur.UsersRepository) *Service {
+func New(ctx context.Context, repo ur.UsersRepository, tl ts.Appender, bus eventbus.Bus, quota config.QuotaConfig,
+	entitlements config.EntitlementsConfig) *Service {
 	s := &Service{
-		ctx:  ctx,
-		repo: repo,
-		log:  logger.New(ctx),
+		ctx:          ctx,
+		repo:         repo,
+		tl:           tl,
+		bus:          bus,
+		log:          logger.New(ctx),
+		quota:        quota,
+		entitlements: entitlements,
 	}
 
 	return s
@@ -60,6 +115,20 @@ func (s *Service) GetUserId(userAddress, password string) (int64, error) {
 	return userId, nil
 }
 
+// GetUserIdByAddress resolves another user's ID without their password, e.g.
+// the recipient of a share. Unknown addresses return sql.ErrNoRows.
+func (s *Service) GetUserIdByAddress(userAddress string) (int64, error) {
+	userId, err := s.repo.GetUserIdByAddress(userAddress)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "GetUserIdByAddress"}).
+			Error(utils.ErrDatabase)
+
+		return userId, err
+	}
+
+	return userId, nil
+}
+
 func (s *Service) CheckUserExists(userAddress, password string) (bool, error) {
 	exists, err := s.repo.CheckUserExists(userAddress, password)
 	if err != nil {
@@ -81,7 +150,18 @@ func (s *Service) UpdatePassword(userAddress, currentPassword, newPassword strin
 	}
 
 	if userId > 0 {
-		return s.repo.UpdatePassword(userId, newPassword)
+		if err := s.repo.UpdatePassword(userId, newPassword); err != nil {
+			return err
+		}
+
+		// The password is the session: streams opened with the old one end.
+		event := eventbus.Event{UserID: userId, Type: eventbus.SessionRevoked}
+		if err := s.bus.Publish(s.ctx, event); err != nil {
+			s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "UpdatePassword"}).
+				Error("Failed to publish event")
+		}
+
+		return nil
 	} else {
 		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "UpdatePassword"}).
 			Error(utils.UserNotFound)
@@ -99,7 +179,7 @@ func (s *Service) DeleteUser(userAddress, password string) (bool, error) {
 	}
 
 	if userId > 0 {
-		return s.repo.DeleteUser(userId)
+		return s.deleteUser(userAddress, userId)
 	} else {
 		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "DeleteUser"}).
 			Error(utils.UserNotFound)
@@ -107,3 +187,423 @@ func (s *Service) DeleteUser(userAddress, password string) (bool, error) {
 		return false, fmt.Errorf(utils.UserNotFound)
 	}
 }
+
+// PublishPublicKeys stores a new version of the caller's public keys. Versions
+// are strictly sequential; every version after the first must be endorsed by the
+// signing key it replaces, so the history forms a verifiable chain.
+func (s *Service) PublishPublicKeys(keys dto.PublicKeysInput) (*dto.PublicKeys, error) {
+	if len(keys.EncryptionKey) != 32 || len(keys.SigningKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	userId, err := s.repo.GetUserId(keys.UserAddress, keys.Password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.UserNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "PublishPublicKeys"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	message := publicKeysMessage(keys)
+	if !ed25519.Verify(keys.SigningKey, message, keys.Signature) {
+		return nil, fmt.Errorf(utils.InvalidSignature)
+	}
+
+	previous, err := s.repo.GetLatestPublicKeys(userId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "PublishPublicKeys"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if previous == nil {
+		if keys.Version != 1 {
+			return nil, fmt.Errorf(utils.VersionConflict)
+		}
+		keys.RotationSignature = nil
+	} else {
+		if keys.Version != previous.Version+1 {
+			return nil, fmt.Errorf(utils.VersionConflict)
+		}
+		if !ed25519.Verify(previous.SigningKey, message, keys.RotationSignature) {
+			return nil, fmt.Errorf(utils.InvalidSignature)
+		}
+	}
+
+	published, err := s.repo.AddPublicKeys(userId, keys, s.logChange(tdto.LogEntry{
+		Operation:         tdto.OperationPublish,
+		UserAddress:       keys.UserAddress,
+		Version:           keys.Version,
+		EncryptionKey:     keys.EncryptionKey,
+		SigningKey:        keys.SigningKey,
+		Signature:         keys.Signature,
+		RotationSignature: keys.RotationSignature,
+	}))
+	if err != nil {
+		if postgresdb.GetConstraintIdentifier(err) == "user_public_keys_version" {
+			return nil, fmt.Errorf(utils.VersionConflict)
+		}
+
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "PublishPublicKeys"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return published, nil
+}
+
+func (s *Service) GetPublicKeys(userAddress string) (*dto.PublicKeyDirectory, error) {
+	history, err := s.repo.GetPublicKeys(userAddress)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "GetPublicKeys"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if len(history) == 0 {
+		return nil, fmt.Errorf(utils.KeysNotFound)
+	}
+
+	return &dto.PublicKeyDirectory{
+		UserAddress: userAddress,
+		Current:     history[0],
+		History:     history,
+	}, nil
+}
+
+// AddEmergencyContact names another user who may ask for access to the
+// caller's vault key. The owner can reject a request during the waiting
+// period; otherwise the contact receives the key when it ends.
+func (s *Service) AddEmergencyContact(contact dto.EmergencyContactInput) (*dto.EmergencyContact, error) {
+	if contact.ContactAddress == "" || len(contact.EncryptedKey) == 0 ||
+		contact.WaitHours < minWaitHours || contact.WaitHours > maxWaitHours {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if contact.InactivityDays != nil &&
+		(*contact.InactivityDays < minInactivityDays || *contact.InactivityDays > maxInactivityDays) {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	ownerId, err := s.authenticate(contact.UserAddress, contact.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	contactId, err := s.lookupUser(contact.ContactAddress)
+	if err != nil {
+		return nil, err
+	}
+	if contactId == ownerId {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	created, err := s.repo.UpsertEmergencyContact(ownerId, contactId, contact)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "AddEmergencyContact"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return created, nil
+}
+
+func (s *Service) GetEmergencyContacts(auth dto.UserInput) ([]dto.EmergencyContact, error) {
+	ownerId, err := s.authenticate(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := s.repo.GetEmergencyContacts(ownerId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "GetEmergencyContacts"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return contacts, nil
+}
+
+func (s *Service) RemoveEmergencyContact(contactAddress string, auth dto.UserInput) error {
+	ownerId, err := s.authenticate(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	contactId, err := s.lookupUser(contactAddress)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteEmergencyContact(ownerId, contactId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "RemoveEmergencyContact"}).
+			Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !deleted {
+		return fmt.Errorf(utils.ContactNotFound)
+	}
+
+	return nil
+}
+
+// RejectEmergencyRequest cancels a contact's pending request. Access that was
+// already granted cannot be taken back this way.
+func (s *Service) RejectEmergencyRequest(contactAddress string, auth dto.UserInput) error {
+	ownerId, err := s.authenticate(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	contactId, err := s.lookupUser(contactAddress)
+	if err != nil {
+		return err
+	}
+
+	rejected, err := s.repo.RejectEmergencyRequest(ownerId, contactId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "RejectEmergencyRequest"}).
+			Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+	if !rejected {
+		return fmt.Errorf(utils.ContactNotFound)
+	}
+
+	return nil
+}
+
+func (s *Service) RequestEmergencyAccess(ownerAddress string, auth dto.UserInput) (*dto.EmergencyGrant, error) {
+	contactId, err := s.authenticate(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerId, err := s.repo.GetUserIdByAddress(ownerAddress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.ContactNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "RequestEmergencyAccess"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	grant, err := s.repo.RequestEmergencyAccess(ownerId, contactId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.ContactNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "RequestEmergencyAccess"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return grant, nil
+}
+
+func (s *Service) GetEmergencyGrants(auth dto.UserInput) ([]dto.EmergencyGrant, error) {
+	contactId, err := s.authenticate(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := s.repo.GetEmergencyGrants(contactId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "GetEmergencyGrants"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return grants, nil
+}
+
+// GetUsage reports the caller's storage consumption against their quota.
+func (s *Service) GetUsage(auth dto.UserInput) (*dto.Usage, error) {
+	userId, err := s.authenticate(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	entitlements, err := s.GetEntitlements(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repo.GetUsage(userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "GetUsage"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+	usage.Entries.Limit = entitlements.MaxEntries
+	usage.Bytes.Limit = entitlements.MaxBytes
+	usage.AttachmentBytes.Limit = entitlements.MaxAttachmentBytes
+
+	return usage, nil
+}
+
+// GetEntitlements resolves what userId may use. Once a plan expires the
+// account falls back to the configured defaults; data above the default
+// quota is kept, but only writes that shrink it are accepted.
+func (s *Service) GetEntitlements(userId int64) (*dto.Entitlements, error) {
+	entitlements, err := s.repo.GetPlan(userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &dto.Entitlements{
+			Plan:               dto.DefaultPlan,
+			MaxEntries:         s.quota.MaxEntries,
+			MaxBytes:           s.quota.MaxBytes,
+			MaxAttachmentBytes: s.quota.MaxAttachmentBytes,
+			MaxDevices:         s.entitlements.MaxDevices,
+			SharingAllowed:     s.entitlements.SharingAllowed,
+			HistoryDepth:       s.entitlements.HistoryDepth,
+		}, nil
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "GetEntitlements"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return entitlements, nil
+}
+
+func (s *Service) GetPlan(auth dto.UserInput) (*dto.Entitlements, error) {
+	userId, err := s.authenticate(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetEntitlements(userId)
+}
+
+// RedeemVoucher applies a voucher to the caller's account and returns the
+// resulting entitlements. Unknown and already redeemed codes are both
+// reported as INVALID_VOUCHER.
+func (s *Service) RedeemVoucher(input dto.VoucherInput) (*dto.Entitlements, error) {
+	codeHash, ok := HashVoucherCode(input.Code)
+	if !ok {
+		return nil, fmt.Errorf(utils.InvalidVoucher)
+	}
+
+	userId, err := s.authenticate(input.UserAddress, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.RedeemVoucher(userId, codeHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.InvalidVoucher)
+	}
+	if errors.Is(err, ur.ErrPlanActive) {
+		return nil, fmt.Errorf(utils.PlanActive)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "RedeemVoucher"}).
+			Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return s.GetEntitlements(userId)
+}
+
+// NewVoucherCode returns a random voucher code and the hash to store for it.
+func NewVoucherCode() (string, []byte, error) {
+	raw := make([]byte, voucherSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+
+	encoded := voucherEncoding.EncodeToString(raw)
+	groups := make([]string, 0, len(encoded)/voucherGroupSize)
+	for i := 0; i < len(encoded); i += voucherGroupSize {
+		groups = append(groups, encoded[i:i+voucherGroupSize])
+	}
+	code := strings.Join(groups, "-")
+
+	codeHash, _ := HashVoucherCode(code)
+
+	return code, codeHash, nil
+}
+
+// HashVoucherCode normalizes a code as typed by a user, ignoring case,
+// dashes and spaces, and returns its hash. It reports false when the input
+// cannot be a voucher code.
+func HashVoucherCode(code string) ([]byte, bool) {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	raw, err := voucherEncoding.DecodeString(normalized)
+	if err != nil || len(raw) != voucherSize {
+		return nil, false
+	}
+
+	sum := sha256.Sum256(raw)
+
+	return sum[:], true
+}
+
+func (s *Service) authenticate(userAddress, password string) (int64, error) {
+	userId, err := s.repo.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "authenticate"}).
+			Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
+
+func (s *Service) lookupUser(userAddress string) (int64, error) {
+	userId, err := s.repo.GetUserIdByAddress(userAddress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.UserNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "lookupUser"}).
+			Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	return userId, nil
+}
+
+// deleteUser removes the account. When it had published public keys, the
+// removal from the directory is recorded in the transparency log.
+func (s *Service) deleteUser(userAddress string, userId int64) (bool, error) {
+	latest, err := s.repo.GetLatestPublicKeys(userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.repo.DeleteUser(userId, nil)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "user service", "function": "deleteUser"}).
+			Error(utils.ErrDatabase)
+		return false, err
+	}
+
+	return s.repo.DeleteUser(userId, s.logChange(tdto.LogEntry{
+		Operation:   tdto.OperationDelete,
+		UserAddress: userAddress,
+		Version:     latest.Version,
+	}))
+}
+
+func (s *Service) logChange(entry tdto.LogEntry) ur.TxHook {
+	return func(ctx context.Context, tx *sqlx.Tx) error {
+		return s.tl.Append(ctx, tx, entry)
+	}
+}
+
+func publicKeysMessage(keys dto.PublicKeysInput) []byte {
+	message := []byte(publicKeysContext)
+	message = append(message, keys.UserAddress...)
+	message = binary.BigEndian.AppendUint32(message, uint32(keys.Version))
+	message = append(message, keys.EncryptionKey...)
+	message = append(message, keys.SigningKey...)
+
+	return message
+}
@@ -0,0 +1,70 @@
+// Package blobstore keeps opaque encrypted blobs outside the database. The
+// server never sees plaintext; it only stores and streams ciphertext by key.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+)
+
+const (
+	BackendFS = "fs"
+	BackendS3 = "s3"
+)
+
+var (
+	ErrNotFound   = errors.New("blobstore: blob not found")
+	ErrInvalidKey = errors.New("blobstore: invalid key")
+)
+
+// BlobStore stores blobs under slash separated keys. Get returns length bytes
+// starting at offset; a negative length reads to the end of the blob.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+	Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New builds the backend selected by cfg.Backend.
+func New(cfg config.BlobConfig) (BlobStore, error) {
+	switch cfg.Backend {
+	case BackendFS:
+		return NewFS(cfg.Dir)
+	case BackendS3:
+		return NewS3(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("blobstore: unknown backend %q", cfg.Backend)
+	}
+}
+
+// validKey accepts keys made of non-empty segments of letters, digits, '-',
+// '_' and '.', that are not "." or "..".
+func validKey(key string) bool {
+	if key == "" || len(key) > 1024 {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+		for _, c := range segment {
+			switch {
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v10"
 	"github.com/philippe-berto/database/postgresdb"
@@ -15,6 +16,24 @@ type BaseConfig struct {
 	Debug            bool `env:"DEBUG" envDefault:"true"`
 	Port             int  `env:"PORT" envDefault:"8080"`
 	Metrics          MetricsConfig
+	Reaper           ReaperConfig
+	Secrets          SecretsConfig
+	Transparency     TransparencyConfig
+	MLS              MLSConfig
+	Dropbox          DropboxConfig
+	Messages         MessagesConfig
+	Emergency        EmergencyConfig
+	Blob             BlobConfig
+	Attachments      AttachmentsConfig
+	Uploads          UploadsConfig
+	Tiering          TieringConfig
+	Quota            QuotaConfig
+	Entitlements     EntitlementsConfig
+	Export           ExportConfig
+	Sync             SyncConfig
+	Events           EventsConfig
+	EventBus         EventBusConfig
+	Outbox           OutboxConfig
 	Tracer           tracer.Config
 	Service          string `env:"APP_SERVICE" envDefault:"cryple_general"`
 	Name             string `env:"APP_NAME" envDefault:"cryple"`
@@ -27,6 +46,167 @@ type MetricsConfig struct {
 	Enable bool  `env:"METRICS_ENABLE" envDefault:"0"`
 }
 
+// ReaperConfig drives the keys jobs. Enable only switches the expired keys
+// reaper; due releases are delivered regardless.
+type ReaperConfig struct {
+	Enable          bool          `env:"KEYS_REAPER_ENABLE"    envDefault:"1"`
+	Interval        time.Duration `env:"KEYS_REAPER_INTERVAL"  envDefault:"1m"`
+	ReleaseInterval time.Duration `env:"KEYS_RELEASE_INTERVAL" envDefault:"1m"`
+}
+
+type SecretsConfig struct {
+	MaxSize        int           `env:"SECRETS_MAX_SIZE"        envDefault:"65536"`
+	MaxViews       int           `env:"SECRETS_MAX_VIEWS"       envDefault:"10"`
+	MaxTTL         time.Duration `env:"SECRETS_MAX_TTL"         envDefault:"168h"`
+	ReadDuration   time.Duration `env:"SECRETS_READ_DURATION"   envDefault:"250ms"`
+	ReaperEnable   bool          `env:"SECRETS_REAPER_ENABLE"   envDefault:"1"`
+	ReaperInterval time.Duration `env:"SECRETS_REAPER_INTERVAL" envDefault:"1m"`
+}
+
+type TransparencyConfig struct {
+	// SigningKey is the base64 encoded Ed25519 seed that signs tree heads.
+	SigningKey string `env:"TRANSPARENCY_SIGNING_KEY"`
+}
+
+type MLSConfig struct {
+	MaxMessageSize int `env:"MLS_MAX_MESSAGE_SIZE" envDefault:"262144"`
+	MaxKeyPackages int `env:"MLS_MAX_KEY_PACKAGES" envDefault:"100"`
+}
+
+type DropboxConfig struct {
+	MaxSize        int           `env:"DROPBOX_MAX_SIZE"        envDefault:"10485760"`
+	MaxSubmissions int           `env:"DROPBOX_MAX_SUBMISSIONS" envDefault:"500"`
+	Difficulty     int           `env:"DROPBOX_POW_DIFFICULTY"  envDefault:"20"`
+	ChallengeTTL   time.Duration `env:"DROPBOX_CHALLENGE_TTL"   envDefault:"10m"`
+	ReaperEnable   bool          `env:"DROPBOX_REAPER_ENABLE"   envDefault:"1"`
+	ReaperInterval time.Duration `env:"DROPBOX_REAPER_INTERVAL" envDefault:"10m"`
+	// PowKey is the base64 encoded HMAC key that authenticates challenges.
+	PowKey string `env:"DROPBOX_POW_KEY"`
+}
+
+type MessagesConfig struct {
+	MaxSize    int   `env:"MESSAGES_MAX_SIZE"    envDefault:"65536"`
+	InboxQuota int64 `env:"MESSAGES_INBOX_QUOTA" envDefault:"10485760"`
+}
+
+type EmergencyConfig struct {
+	Interval time.Duration `env:"EMERGENCY_ACCESS_INTERVAL" envDefault:"5m"`
+}
+
+type BlobConfig struct {
+	Backend     string `env:"BLOB_BACKEND"       envDefault:"fs"`
+	Dir         string `env:"BLOB_DIR"           envDefault:"./data/blobs"`
+	S3Endpoint  string `env:"BLOB_S3_ENDPOINT"`
+	S3Region    string `env:"BLOB_S3_REGION"     envDefault:"us-east-1"`
+	S3Bucket    string `env:"BLOB_S3_BUCKET"`
+	S3AccessKey string `env:"BLOB_S3_ACCESS_KEY"`
+	S3SecretKey string `env:"BLOB_S3_SECRET_KEY"`
+}
+
+type AttachmentsConfig struct {
+	MaxChunkSize   int64         `env:"ATTACHMENTS_MAX_CHUNK_SIZE"  envDefault:"8388608"`
+	MaxChunks      int           `env:"ATTACHMENTS_MAX_CHUNKS"      envDefault:"10000"`
+	UploadTTL      time.Duration `env:"ATTACHMENTS_UPLOAD_TTL"      envDefault:"24h"`
+	ReaperEnable   bool          `env:"ATTACHMENTS_REAPER_ENABLE"   envDefault:"1"`
+	ReaperInterval time.Duration `env:"ATTACHMENTS_REAPER_INTERVAL" envDefault:"10m"`
+}
+
+type UploadsConfig struct {
+	MaxSize        int64         `env:"UPLOADS_MAX_SIZE"        envDefault:"104857600"`
+	SegmentSize    int64         `env:"UPLOADS_SEGMENT_SIZE"    envDefault:"4194304"`
+	TTL            time.Duration `env:"UPLOADS_TTL"             envDefault:"24h"`
+	ReaperEnable   bool          `env:"UPLOADS_REAPER_ENABLE"   envDefault:"1"`
+	ReaperInterval time.Duration `env:"UPLOADS_REAPER_INTERVAL" envDefault:"10m"`
+}
+
+type TieringConfig struct {
+	// Threshold is the size in bytes above which entry payloads are kept in
+	// the blob store. Zero keeps every payload in Postgres.
+	Threshold int           `env:"KEYS_TIERING_THRESHOLD" envDefault:"65536"`
+	Batch     int           `env:"KEYS_TIERING_BATCH"     envDefault:"50"`
+	Interval  time.Duration `env:"KEYS_TIERING_INTERVAL"  envDefault:"10m"`
+}
+
+// QuotaConfig limits the storage of each account. A zero limit is unlimited.
+type QuotaConfig struct {
+	MaxEntries         int64 `env:"QUOTA_MAX_ENTRIES"          envDefault:"10000"`
+	MaxBytes           int64 `env:"QUOTA_MAX_BYTES"            envDefault:"1073741824"`
+	MaxAttachmentBytes int64 `env:"QUOTA_MAX_ATTACHMENT_BYTES" envDefault:"5368709120"`
+}
+
+// EntitlementsConfig completes QuotaConfig with what accounts without an
+// active plan are entitled to. A zero MaxDevices is unlimited, HistoryDepth
+// is the number of operations kept behind a snapshot.
+type EntitlementsConfig struct {
+	MaxDevices     int  `env:"ENTITLEMENTS_MAX_DEVICES"     envDefault:"0"`
+	SharingAllowed bool `env:"ENTITLEMENTS_SHARING_ALLOWED" envDefault:"1"`
+	HistoryDepth   int  `env:"ENTITLEMENTS_HISTORY_DEPTH"   envDefault:"0"`
+}
+
+type ExportConfig struct {
+	// SigningKey is the base64 encoded Ed25519 seed that signs export
+	// manifests.
+	SigningKey string `env:"EXPORT_SIGNING_KEY"`
+	// TrustedKeys are the base64 encoded Ed25519 public keys of the other
+	// servers whose archives may be imported. Archives signed by this
+	// server are always accepted.
+	TrustedKeys   []string `env:"EXPORT_TRUSTED_KEYS" envSeparator:","`
+	MaxImportSize int64    `env:"EXPORT_MAX_IMPORT_SIZE" envDefault:"268435456"`
+}
+
+type SyncConfig struct {
+	// TombstoneRetention is how long deletes are kept for delta sync. Older
+	// cursors force a full resync.
+	TombstoneRetention time.Duration `env:"SYNC_TOMBSTONE_RETENTION" envDefault:"720h"`
+	ReaperEnable       bool          `env:"SYNC_REAPER_ENABLE"       envDefault:"1"`
+	ReaperInterval     time.Duration `env:"SYNC_REAPER_INTERVAL"     envDefault:"1h"`
+}
+
+// EventsConfig tunes the event streams. MaxConnections bounds the streams
+// each account keeps open on one replica, so an account may hold that many on
+// every replica behind the load balancer; events older than Retention cannot
+// be resumed.
+type EventsConfig struct {
+	PollInterval   time.Duration `env:"EVENTS_POLL_INTERVAL"   envDefault:"10s"`
+	Heartbeat      time.Duration `env:"EVENTS_HEARTBEAT"       envDefault:"25s"`
+	MaxConnections int           `env:"EVENTS_MAX_CONNECTIONS" envDefault:"5"`
+	Retention      time.Duration `env:"EVENTS_RETENTION"       envDefault:"24h"`
+	ReaperEnable   bool          `env:"EVENTS_REAPER_ENABLE"   envDefault:"1"`
+	ReaperInterval time.Duration `env:"EVENTS_REAPER_INTERVAL" envDefault:"10m"`
+}
+
+// EventBusConfig selects how committed changes are announced. The postgres
+// backend reaches every replica; memory only reaches the local one.
+type EventBusConfig struct {
+	Backend      string        `env:"EVENTBUS_BACKEND"       envDefault:"postgres"`
+	Channel      string        `env:"EVENTBUS_CHANNEL"       envDefault:"obscuranote_events"`
+	MinReconnect time.Duration `env:"EVENTBUS_MIN_RECONNECT" envDefault:"1s"`
+	MaxReconnect time.Duration `env:"EVENTBUS_MAX_RECONNECT" envDefault:"1m"`
+}
+
+// OutboxConfig selects where domain events are delivered. A failed delivery
+// is retried after a backoff doubling from MinBackoff up to MaxBackoff, until
+// MaxAttempts; zero retries forever. Lease is how long a claimed batch is
+// kept from the other replicas.
+type OutboxConfig struct {
+	Enable         bool          `env:"OUTBOX_ENABLE"          envDefault:"1"`
+	Sink           string        `env:"OUTBOX_SINK"            envDefault:"log"`
+	Interval       time.Duration `env:"OUTBOX_INTERVAL"        envDefault:"1s"`
+	Batch          int           `env:"OUTBOX_BATCH"           envDefault:"100"`
+	Lease          time.Duration `env:"OUTBOX_LEASE"           envDefault:"1m"`
+	Timeout        time.Duration `env:"OUTBOX_TIMEOUT"         envDefault:"10s"`
+	MinBackoff     time.Duration `env:"OUTBOX_MIN_BACKOFF"     envDefault:"1s"`
+	MaxBackoff     time.Duration `env:"OUTBOX_MAX_BACKOFF"     envDefault:"1h"`
+	MaxAttempts    int           `env:"OUTBOX_MAX_ATTEMPTS"    envDefault:"20"`
+	Retention      time.Duration `env:"OUTBOX_RETENTION"       envDefault:"168h"`
+	ReaperEnable   bool          `env:"OUTBOX_REAPER_ENABLE"   envDefault:"1"`
+	ReaperInterval time.Duration `env:"OUTBOX_REAPER_INTERVAL" envDefault:"1h"`
+	WebhookURL     string        `env:"OUTBOX_WEBHOOK_URL"`
+	WebhookSecret  string        `env:"OUTBOX_WEBHOOK_SECRET"`
+	NatsURL        string        `env:"OUTBOX_NATS_URL"        envDefault:"nats://localhost:4222"`
+	NatsSubject    string        `env:"OUTBOX_NATS_SUBJECT"    envDefault:"obscuranote"`
+}
+
 func loadEnvFile() {
 	file, err := os.Open(".env")
 	if err != nil {
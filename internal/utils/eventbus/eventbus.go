@@ -0,0 +1,108 @@
+// Package eventbus notifies the parts of the API of committed changes, on
+// this replica or across replicas. Notifications are hints telling
+// subscribers to look again: one can be lost, e.g. while the bus reconnects,
+// so subscribers keep a slower way to catch up.
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/logger"
+)
+
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+
+	KeyCreated     = "key.created"
+	KeyUpdated     = "key.updated"
+	KeyDeleted     = "key.deleted"
+	ShareReceived  = "share.received"
+	ShareUpdated   = "share.updated"
+	ShareRevoked   = "share.revoked"
+	SessionRevoked = "session.revoked"
+	// Resync is delivered when notifications may have been lost. It names no
+	// account: subscribers look again for all of them.
+	Resync = "resync"
+)
+
+var (
+	ErrClosed          = errors.New("eventbus: closed")
+	ErrPayloadTooLarge = errors.New("eventbus: payload too large")
+)
+
+type (
+	// Event is a committed change of an account. Subject names the entry or
+	// share it is about; Truncated is set when the transport had to drop it.
+	Event struct {
+		UserID    int64  `json:"user_id"`
+		Type      string `json:"type"`
+		Subject   string `json:"subject,omitempty"`
+		Truncated bool   `json:"truncated,omitempty"`
+	}
+
+	// Handler receives the events of a subscription. Handlers run on the
+	// delivery path of the bus and must not block.
+	Handler func(event Event)
+
+	// Bus publishes events to every subscription, including the ones of the
+	// publishing replica. Publish is called once the change is committed.
+	Bus interface {
+		Publish(ctx context.Context, event Event) error
+		Subscribe(handler Handler) (unsubscribe func())
+		Close() error
+	}
+
+	// handlers is the set of subscriptions of a bus.
+	handlers struct {
+		mu       sync.RWMutex
+		next     int
+		handlers map[int]Handler
+	}
+)
+
+// New builds the backend selected by cfg.Backend. The postgres backend opens
+// its own connection with the settings of db.
+func New(cfg config.EventBusConfig, db postgresdb.Config, client *postgresdb.Client, log logger.Logger) (Bus, error) {
+	switch cfg.Backend {
+	case BackendMemory:
+		return NewMemory(), nil
+	case BackendPostgres:
+		return NewPostgres(cfg, db, client, log)
+	default:
+		return nil, fmt.Errorf("eventbus: unknown backend %q", cfg.Backend)
+	}
+}
+
+func (h *handlers) Subscribe(handler Handler) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.handlers == nil {
+		h.handlers = map[int]Handler{}
+	}
+	id := h.next
+	h.next++
+	h.handlers[id] = handler
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.handlers, id)
+	}
+}
+
+func (h *handlers) dispatch(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, handler := range h.handlers {
+		handler(event)
+	}
+}
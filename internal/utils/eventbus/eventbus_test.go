@@ -0,0 +1,59 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	bus := NewMemory()
+
+	var first, second []Event
+	unsubscribe := bus.Subscribe(func(event Event) { first = append(first, event) })
+	bus.Subscribe(func(event Event) { second = append(second, event) })
+
+	event := Event{UserID: 1, Type: KeyCreated, Subject: "3fa146de-e36d-411d-bfb6-6a7a1bb1fd63"}
+	assert.NoError(t, bus.Publish(context.Background(), event))
+	assert.Equal(t, []Event{event}, first)
+	assert.Equal(t, []Event{event}, second)
+
+	unsubscribe()
+	assert.NoError(t, bus.Publish(context.Background(), Event{UserID: 1, Type: KeyDeleted}))
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 2)
+
+	assert.NoError(t, bus.Close())
+	assert.ErrorIs(t, bus.Publish(context.Background(), event), ErrClosed)
+}
+
+func TestEncode(t *testing.T) {
+	payload, err := encode(Event{UserID: 7, Type: SessionRevoked})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"user_id":7,"type":"session.revoked"}`, payload)
+
+	payload, err = encode(Event{UserID: 7, Type: KeyUpdated, Subject: strings.Repeat("a", maxPayload)})
+	assert.NoError(t, err)
+
+	var event Event
+	assert.NoError(t, json.Unmarshal([]byte(payload), &event))
+	assert.Equal(t, Event{UserID: 7, Type: KeyUpdated, Truncated: true}, event)
+
+	_, err = encode(Event{UserID: 7, Type: strings.Repeat("a", maxPayload)})
+	assert.ErrorIs(t, err, ErrPayloadTooLarge)
+}
+
+func TestNew(t *testing.T) {
+	bus, err := New(config.EventBusConfig{Backend: BackendMemory}, postgresdb.Config{}, nil, *logger.New(context.Background()))
+	assert.NoError(t, err)
+	assert.IsType(t, &Memory{}, bus)
+
+	_, err = New(config.EventBusConfig{Backend: "kafka"}, postgresdb.Config{}, nil, *logger.New(context.Background()))
+	assert.Error(t, err)
+}
@@ -0,0 +1,32 @@
+package eventbus
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Memory delivers events within the process. With several replicas, the
+// subscribers of the others are not notified.
+type Memory struct {
+	handlers
+	closed atomic.Bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Publish(ctx context.Context, event Event) error {
+	if m.closed.Load() {
+		return ErrClosed
+	}
+	m.dispatch(event)
+
+	return nil
+}
+
+func (m *Memory) Close() error {
+	m.closed.Store(true)
+
+	return nil
+}
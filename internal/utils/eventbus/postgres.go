@@ -0,0 +1,149 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/lib/pq"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/logger"
+)
+
+const (
+	// maxPayload keeps notifications under the 8000 bytes Postgres accepts.
+	maxPayload = 7900
+	// pingInterval is how often an idle listener checks its connection.
+	pingInterval = 90 * time.Second
+)
+
+// Postgres fans events out to every replica with LISTEN/NOTIFY. Events are
+// published through the shared pool and received on a dedicated connection
+// that reconnects on its own; a Resync is delivered after each reconnect.
+type Postgres struct {
+	handlers
+	db       *postgresdb.Client
+	listener *pq.Listener
+	channel  string
+	log      *logger.Logger
+	done     chan struct{}
+	once     sync.Once
+}
+
+func NewPostgres(cfg config.EventBusConfig, db postgresdb.Config, client *postgresdb.Client, log logger.Logger) (*Postgres, error) {
+	p := &Postgres{
+		db:      client,
+		channel: cfg.Channel,
+		log:     &log,
+		done:    make(chan struct{}),
+	}
+
+	p.listener = pq.NewListener(db.GetDataBaseURL(), cfg.MinReconnect, cfg.MaxReconnect, p.report)
+	if err := p.listener.Listen(cfg.Channel); err != nil {
+		_ = p.listener.Close()
+		return nil, err
+	}
+
+	go p.run()
+
+	return p, nil
+}
+
+// Publish notifies every replica of event. An event whose subject does not
+// fit in a notification is sent without it and marked truncated.
+func (p *Postgres) Publish(ctx context.Context, event Event) error {
+	select {
+	case <-p.done:
+		return ErrClosed
+	default:
+	}
+
+	payload, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.GetClient().ExecContext(ctx, "SELECT pg_notify($1, $2)", p.channel, payload)
+
+	return err
+}
+
+func (p *Postgres) Close() error {
+	var err error
+	p.once.Do(func() {
+		close(p.done)
+		err = p.listener.Close()
+	})
+
+	return err
+}
+
+func (p *Postgres) run() {
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ping.C:
+			go func() { _ = p.listener.Ping() }()
+		case notification, ok := <-p.listener.Notify:
+			if !ok {
+				return
+			}
+			// A nil notification follows a reconnect: whatever was sent
+			// while the connection was down is lost.
+			if notification == nil {
+				p.dispatch(Event{Type: Resync})
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				p.log.WithFields(logger.Fields{"error": err.Error(), "component": "eventbus", "function": "run"}).
+					Error("Failed to decode event")
+				continue
+			}
+			p.dispatch(event)
+		}
+	}
+}
+
+// report logs the state changes of the listener connection.
+func (p *Postgres) report(event pq.ListenerEventType, err error) {
+	fields := logger.Fields{"component": "eventbus", "function": "report"}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	switch event {
+	case pq.ListenerEventConnectionAttemptFailed:
+		p.log.WithFields(fields).Error("Failed to connect event listener")
+	case pq.ListenerEventDisconnected:
+		p.log.WithFields(fields).Error("Event listener disconnected")
+	case pq.ListenerEventReconnected:
+		p.log.WithFields(fields).Info("Event listener reconnected")
+	}
+}
+
+func encode(event Event) (string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	if len(payload) > maxPayload && event.Subject != "" {
+		event.Subject = ""
+		event.Truncated = true
+		if payload, err = json.Marshal(event); err != nil {
+			return "", err
+		}
+	}
+	if len(payload) > maxPayload {
+		return "", ErrPayloadTooLarge
+	}
+
+	return string(payload), nil
+}
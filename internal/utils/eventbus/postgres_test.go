@@ -0,0 +1,66 @@
+//go:build integration
+// +build integration
+
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ObscuraNote/api-general/internal/utils/config"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: false,
+}
+
+func TestPostgres(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	busCfg := config.EventBusConfig{
+		Channel:      "obscuranote_events_test",
+		MinReconnect: 10 * time.Millisecond,
+		MaxReconnect: time.Second,
+	}
+	publisher, err := NewPostgres(busCfg, cfg, db, *logger.New(ctx))
+	if err != nil {
+		t.Fatalf("failed to create publisher: %v", err)
+	}
+	defer publisher.Close()
+	receiver, err := NewPostgres(busCfg, cfg, db, *logger.New(ctx))
+	if err != nil {
+		t.Fatalf("failed to create receiver: %v", err)
+	}
+	defer receiver.Close()
+
+	received := make(chan Event, 1)
+	receiver.Subscribe(func(event Event) { received <- event })
+
+	event := Event{UserID: 1, Type: KeyCreated, Subject: "3fa146de-e36d-411d-bfb6-6a7a1bb1fd63"}
+	assert.NoError(t, publisher.Publish(ctx, event))
+
+	select {
+	case got := <-received:
+		assert.Equal(t, event, got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not received")
+	}
+
+	assert.NoError(t, receiver.Close())
+	assert.ErrorIs(t, receiver.Publish(ctx, event), ErrClosed)
+}
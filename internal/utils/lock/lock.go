@@ -0,0 +1,51 @@
+package lock
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
+)
+
+// Advisory lock keys shared by every replica. Each background job or
+// serialized writer owns one so that they never block each other.
+const (
+	KeysReaper       int64 = 26001
+	SecretsReaper    int64 = 27001
+	TransparencyLog  int64 = 30001
+	DropboxReaper    int64 = 35001
+	KeyReleases      int64 = 37001
+	EmergencyAccess  int64 = 38001
+	BlobGarbage      int64 = 39001
+	UploadsReaper    int64 = 40001
+	PayloadTiering   int64 = 41001
+	TombstonesReaper int64 = 46001
+	EventsReaper     int64 = 48001
+	OutboxReaper     int64 = 50001
+)
+
+type LockedFunc func(ctx context.Context, tx *sqlx.Tx) (int64, error)
+
+// TryExclusive runs fn inside a transaction holding the advisory lock id. When
+// another session already holds the lock, fn is skipped and zero is returned.
+// The lock is released with the transaction.
+func TryExclusive(ctx context.Context, db *postgresdb.Client, id int64, fn LockedFunc) (int64, error) {
+	result, err := transaction.New(db, false).ExecTx(ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var locked bool
+		if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1);", id).Scan(&locked); err != nil {
+			return int64(0), err
+		}
+
+		if !locked {
+			return int64(0), nil
+		}
+
+		return fn(ctx, tx)
+	}))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.(int64), nil
+}
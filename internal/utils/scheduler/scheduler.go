@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/philippe-berto/logger"
+)
+
+// Job is a unit of periodic background work. Run reports how many rows it
+// affected so the scheduler can log only ticks that did something.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() (int64, error)
+}
+
+// Start runs job on every tick and blocks until ctx is cancelled.
+func Start(ctx context.Context, log logger.Logger, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			affected, err := job.Run()
+			if err != nil {
+				log.WithFields(logger.Fields{"error": err.Error(), "component": job.Name, "function": "Start"}).
+					Error("Scheduled job failed")
+				continue
+			}
+
+			if affected > 0 {
+				log.WithFields(logger.Fields{"affected": affected, "component": job.Name, "function": "Start"}).
+					Info("Scheduled job completed")
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -14,12 +15,55 @@ import (
 const (
 	ErrDatabase     = "DATABASE_ERROR"
 	ErrUnauthorized = "UNAUTHORIZED"
+	Forbidden       = "FORBIDDEN"
 	UserNotFound    = "USER_NOT_FOUND"
+	KeyNotFound     = "KEY_NOT_FOUND"
+	SecretNotFound  = "SECRET_NOT_FOUND"
+	ShareNotFound   = "SHARE_NOT_FOUND"
+	KeysNotFound    = "PUBLIC_KEYS_NOT_FOUND"
+	TreeNotFound    = "TREE_HEAD_NOT_FOUND"
+	LeafNotFound    = "LEAF_NOT_FOUND"
+	VaultNotFound   = "VAULT_NOT_FOUND"
+	MemberNotFound  = "MEMBER_NOT_FOUND"
+	MemberExists    = "MEMBER_EXISTS"
+	GroupNotFound   = "GROUP_NOT_FOUND"
+	GroupExists     = "GROUP_EXISTS"
+	StaleEpoch      = "STALE_EPOCH"
+	NoKeyPackages   = "KEY_PACKAGES_NOT_FOUND"
+	CommentNotFound = "COMMENT_NOT_FOUND"
+	DropboxNotFound = "DROPBOX_NOT_FOUND"
+	DropboxFull     = "DROPBOX_FULL"
+	NoSubmission    = "SUBMISSION_NOT_FOUND"
+	MessageNotFound = "MESSAGE_NOT_FOUND"
+	InboxFull       = "INBOX_FULL"
+	ReleaseNotFound = "RELEASE_NOT_FOUND"
+	ContactNotFound = "EMERGENCY_CONTACT_NOT_FOUND"
+	NoAttachment    = "ATTACHMENT_NOT_FOUND"
+	UploadPending   = "UPLOAD_INCOMPLETE"
+	UploadNotFound  = "UPLOAD_NOT_FOUND"
+	UploadExpired   = "UPLOAD_EXPIRED"
+	CursorExpired   = "CURSOR_EXPIRED"
+	OffsetConflict  = "OFFSET_CONFLICT"
+	QuotaExceeded   = "QUOTA_EXCEEDED"
+	NotEntitled     = "NOT_ENTITLED"
+	PlanActive      = "PLAN_ACTIVE"
 	BadRequest      = "BAD_REQUEST"
 
 	InvalidBody        = "INVALID_BODY"
 	InvalidParam       = "INVALID_PARAM"
 	InvalidCredentials = "INVALID_CREDENTIALS"
+	InvalidExpiration  = "INVALID_EXPIRATION"
+	InvalidReleaseAt   = "INVALID_RELEASE_AT"
+	PayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	InvalidSignature   = "INVALID_SIGNATURE"
+	VersionConflict    = "VERSION_CONFLICT"
+	InvalidProof       = "INVALID_PROOF"
+	InvalidRange       = "INVALID_RANGE"
+	InvalidVoucher     = "INVALID_VOUCHER"
+	InvalidArchive     = "INVALID_ARCHIVE"
+	UntrustedSigner    = "UNTRUSTED_SIGNER"
+	BatchAborted       = "BATCH_ABORTED"
+	TooManyConnections = "TOO_MANY_CONNECTIONS"
 	InternalCode       = "INTERNAL_SERVER_ERROR"
 
 	ContentType     = "Content-Type"
@@ -114,6 +158,22 @@ func FaultWithData(w http.ResponseWriter, httpStatus int, code, additionalData m
 	return enc.Encode(response)
 }
 
+// GetCredentials reads the "Bearer address:password" Authorization header.
+// A missing or malformed header yields empty credentials.
+func GetCredentials(r *http.Request) (string, string) {
+	credentials, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", ""
+	}
+
+	userAddress, password, ok := strings.Cut(credentials, ":")
+	if !ok {
+		return "", ""
+	}
+
+	return userAddress, password
+}
+
 func ParseParam(w http.ResponseWriter, r *http.Request, param string) string {
 	return chi.URLParam(r, param)
 }
@@ -180,3 +240,37 @@ func ParseFloatQuery(w http.ResponseWriter, r *http.Request, param string) (floa
 
 	return parsedID, nil
 }
+
+// ParseInt64Query parses an optional integer query parameter, returning
+// defaultValue when it is absent.
+func ParseInt64Query(w http.ResponseWriter, r *http.Request, param string, defaultValue int64) (int64, error) {
+	parsedParam := r.URL.Query().Get(param)
+	if parsedParam == "" {
+		return defaultValue, nil
+	}
+
+	parsedValue, err := strconv.ParseInt(parsedParam, 10, 64)
+	if err != nil {
+		_ = Fault(w, http.StatusBadRequest, InvalidParam)
+
+		return 0, errors.New("parameter invalid")
+	}
+
+	return parsedValue, nil
+}
+
+func ParseBoolQuery(w http.ResponseWriter, r *http.Request, param string, defaultValue bool) (bool, error) {
+	parsedParam := r.URL.Query().Get(param)
+	if parsedParam == "" {
+		return defaultValue, nil
+	}
+
+	parsedValue, err := strconv.ParseBool(parsedParam)
+	if err != nil {
+		_ = Fault(w, http.StatusBadRequest, InvalidParam)
+
+		return false, errors.New("parameter invalid")
+	}
+
+	return parsedValue, nil
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCredentials(t *testing.T) {
+	tests := []struct {
+		header   string
+		address  string
+		password string
+	}{
+		{"Bearer address:password", "address", "password"},
+		{"Bearer address:pass:word", "address", "pass:word"},
+		{"Bearer address", "", ""},
+		{"Bearer ", "", ""},
+		{"Basic address:password", "", ""},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.header != "" {
+			r.Header.Set("Authorization", test.header)
+		}
+
+		address, password := GetCredentials(r)
+		assert.Equal(t, test.address, address, test.header)
+		assert.Equal(t, test.password, password, test.header)
+	}
+}
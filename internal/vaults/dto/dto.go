@@ -0,0 +1,84 @@
+package dto
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+
+	MemberStatusPending  = "pending"
+	MemberStatusAccepted = "accepted"
+)
+
+type (
+	AuthInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+	}
+	// VaultInput creates a vault. EncryptedVaultKey is the new vault key
+	// wrapped to the creator's own public key.
+	VaultInput struct {
+		UserAddress       string `json:"user_address" db:"user_address"`
+		Password          string `json:"password" db:"password"`
+		EncryptedName     []byte `json:"encrypted_name" db:"encrypted_name"`
+		EncryptedVaultKey []byte `json:"encrypted_vault_key" db:"encrypted_vault_key"`
+	}
+	// Vault is a vault as seen by one member, with that member's wrapped key.
+	Vault struct {
+		ID                string `json:"id" db:"id"`
+		EncryptedName     []byte `json:"encrypted_name" db:"encrypted_name"`
+		KeyVersion        int    `json:"key_version" db:"key_version"`
+		NeedsRotation     bool   `json:"needs_rotation" db:"needs_rotation"`
+		Role              string `json:"role" db:"role"`
+		Status            string `json:"status" db:"status"`
+		EncryptedVaultKey []byte `json:"encrypted_vault_key" db:"encrypted_vault_key"`
+		MemberKeyVersion  int    `json:"member_key_version" db:"member_key_version"`
+		CreatedAt         string `json:"created_at" db:"created_at"`
+	}
+	// MemberInput invites a user. EncryptedVaultKey is the current vault key
+	// wrapped to the invitee's public key.
+	MemberInput struct {
+		UserAddress       string `json:"user_address" db:"user_address"`
+		Password          string `json:"password" db:"password"`
+		MemberAddress     string `json:"member_address" db:"member_address"`
+		Role              string `json:"role" db:"role"`
+		EncryptedVaultKey []byte `json:"encrypted_vault_key" db:"encrypted_vault_key"`
+		KeyVersion        int    `json:"key_version" db:"key_version"`
+	}
+	Member struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Role        string `json:"role" db:"role"`
+		Status      string `json:"status" db:"status"`
+		KeyVersion  int    `json:"key_version" db:"key_version"`
+		CreatedAt   string `json:"created_at" db:"created_at"`
+	}
+	Membership struct {
+		Role   string `db:"role"`
+		Status string `db:"status"`
+	}
+	RoleInput struct {
+		UserAddress string `json:"user_address" db:"user_address"`
+		Password    string `json:"password" db:"password"`
+		Role        string `json:"role" db:"role"`
+	}
+	// RotationInput replaces the vault key. KeyVersion is the version being
+	// replaced, and MemberKeys must hold the new key wrapped for every member.
+	RotationInput struct {
+		UserAddress string      `json:"user_address" db:"user_address"`
+		Password    string      `json:"password" db:"password"`
+		KeyVersion  int         `json:"key_version" db:"key_version"`
+		MemberKeys  []MemberKey `json:"member_keys"`
+	}
+	MemberKey struct {
+		MemberAddress     string `json:"member_address" db:"member_address"`
+		EncryptedVaultKey []byte `json:"encrypted_vault_key" db:"encrypted_vault_key"`
+		UserID            int64  `json:"-" db:"user_id"`
+	}
+	// RotationStatus reports whether the vault key must be rotated after a
+	// member left, and which entries are still encrypted under an older key.
+	RotationStatus struct {
+		KeyVersion    int      `json:"key_version" db:"key_version"`
+		NeedsRotation bool     `json:"needs_rotation" db:"needs_rotation"`
+		StaleKeys     []string `json:"stale_keys"`
+	}
+)
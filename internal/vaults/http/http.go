@@ -0,0 +1,338 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/vaults/dto"
+	vService "github.com/ObscuraNote/api-general/internal/vaults/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/philippe-berto/logger"
+)
+
+type handler struct {
+	log *logger.Logger
+	vs  vService.VaultsService
+}
+
+func Register(router chi.Router, vs vService.VaultsService, log logger.Logger) {
+	h := &handler{
+		log: &log,
+		vs:  vs,
+	}
+
+	router.Post("/vaults", h.CreateVault)
+	router.Get("/vaults", h.GetVaults)
+	router.Delete("/vaults/{id}", h.DeleteVault)
+
+	router.Get("/vaults/{id}/members", h.GetMembers)
+	router.Post("/vaults/{id}/members", h.InviteMember)
+	router.Post("/vaults/{id}/accept", h.AcceptInvitation)
+	router.Put("/vaults/{id}/members/{address}", h.ChangeMemberRole)
+	router.Delete("/vaults/{id}/members/{address}", h.RemoveMember)
+
+	router.Post("/vaults/{id}/rotate", h.RotateKey)
+	router.Get("/vaults/{id}/rotation", h.GetRotationStatus)
+}
+
+func (h *handler) CreateVault(w http.ResponseWriter, r *http.Request) {
+	var input dto.VaultInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	vault, err := h.vs.CreateVault(input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "CreateVault"}).
+			Error("Failed to create vault")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, vault); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "CreateVault"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetVaults(w http.ResponseWriter, r *http.Request) {
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	vaults, err := h.vs.GetVaults(auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "GetVaults"}).
+			Error("Failed to get vaults")
+
+		writeError(w, err)
+		return
+	}
+
+	if vaults == nil {
+		vaults = []dto.Vault{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, vaults); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "GetVaults"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) DeleteVault(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.vs.DeleteVault(vaultID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "DeleteVault"}).
+			Error("Failed to delete vault")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.vs.GetMembers(vaultID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "GetMembers"}).
+			Error("Failed to get vault members")
+
+		writeError(w, err)
+		return
+	}
+
+	if members == nil {
+		members = []dto.Member{}
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, members); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "GetMembers"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var input dto.MemberInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	member, err := h.vs.InviteMember(vaultID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "InviteMember"}).
+			Error("Failed to invite member")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusCreated, member); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "InviteMember"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.vs.AcceptInvitation(vaultID.String(), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "AcceptInvitation"}).
+			Error("Failed to accept invitation")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) ChangeMemberRole(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var input dto.RoleInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	if err := h.vs.ChangeMemberRole(vaultID.String(), chi.URLParam(r, "address"), input); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "ChangeMemberRole"}).
+			Error("Failed to change member role")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.vs.RemoveMember(vaultID.String(), chi.URLParam(r, "address"), auth); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "RemoveMember"}).
+			Error("Failed to remove member")
+
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var input dto.RotationInput
+	if err := utils.ReadBody(r, &input); err != nil {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.InvalidBody)
+		return
+	}
+
+	if input.UserAddress == "" || input.Password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return
+	}
+
+	status, err := h.vs.RotateKey(vaultID.String(), input)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "RotateKey"}).
+			Error("Failed to rotate vault key")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, status); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "RotateKey"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func (h *handler) GetRotationStatus(w http.ResponseWriter, r *http.Request) {
+	vaultID, err := utils.ParseParamUUID(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	auth, ok := getAuth(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.vs.GetRotationStatus(vaultID.String(), auth)
+	if err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "GetRotationStatus"}).
+			Error("Failed to get rotation status")
+
+		writeError(w, err)
+		return
+	}
+
+	if err := utils.WriteBody(w, http.StatusOK, status); err != nil {
+		h.log.WithFields(logger.Fields{"error": err.Error(), "domain": "vaults", "function": "GetRotationStatus"}).
+			Error("Failed to write response")
+		return
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case utils.ErrUnauthorized:
+		_ = utils.Fault(w, http.StatusUnauthorized, utils.InvalidCredentials)
+	case utils.Forbidden:
+		_ = utils.Fault(w, http.StatusForbidden, utils.Forbidden)
+	case utils.BadRequest:
+		_ = utils.Fault(w, http.StatusBadRequest, err.Error())
+	case utils.VaultNotFound, utils.MemberNotFound, utils.UserNotFound:
+		_ = utils.Fault(w, http.StatusNotFound, err.Error())
+	case utils.MemberExists, utils.VersionConflict:
+		_ = utils.Fault(w, http.StatusConflict, err.Error())
+	default:
+		_ = utils.Fault(w, http.StatusInternalServerError, utils.InternalCode)
+	}
+}
+
+func getAuth(w http.ResponseWriter, r *http.Request) (dto.AuthInput, bool) {
+	userAddress, password := utils.GetCredentials(r)
+	if userAddress == "" || password == "" {
+		_ = utils.Fault(w, http.StatusBadRequest, utils.BadRequest)
+		return dto.AuthInput{}, false
+	}
+
+	return dto.AuthInput{
+		UserAddress: userAddress,
+		Password:    password,
+	}, true
+}
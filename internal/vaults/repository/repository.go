@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/ObscuraNote/api-general/internal/vaults/dto"
+	"github.com/jmoiron/sqlx"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/philippe-berto/database/transaction"
+)
+
+var _ VaultsRepository = (*Repository)(nil)
+
+// ErrIncompleteRotation is returned when a key rotation does not cover every
+// member of the vault.
+var ErrIncompleteRotation = errors.New("rotation must wrap the new key for every member")
+
+type (
+	VaultsRepository interface {
+		CreateVault(userId int64, vault dto.VaultInput) (*dto.Vault, error)
+		GetVaultsByUser(userId int64) ([]dto.Vault, error)
+		GetMembership(vaultId string, userId int64) (*dto.Membership, error)
+		GetMembers(vaultId string) ([]dto.Member, error)
+		GetMemberIds(vaultId string) ([]int64, error)
+		AddMember(vaultId string, userId int64, member dto.MemberInput) (*dto.Member, error)
+		AcceptMember(vaultId string, userId int64) (bool, error)
+		UpdateMemberRole(vaultId string, userId int64, role string) (bool, error)
+		RemoveMember(vaultId string, userId int64) (bool, error)
+		RotateVaultKey(vaultId string, keyVersion int, memberKeys []dto.MemberKey) (int, error)
+		GetRotationStatus(vaultId string) (*dto.RotationStatus, error)
+		DeleteVault(vaultId string) (bool, error)
+	}
+	Repository struct {
+		ctx        context.Context
+		db         *postgresdb.Client
+		statements statements
+	}
+)
+
+func New(ctx context.Context, db *postgresdb.Client) (*Repository, error) {
+	r := &Repository{
+		ctx:        ctx,
+		db:         db,
+		statements: statements{},
+	}
+	statements, err := r.prepareStatements()
+	if err != nil {
+		return &Repository{}, err
+	}
+
+	r.statements = statements
+
+	return r, nil
+}
+
+// CreateVault creates a vault with the creator as its accepted owner.
+func (r *Repository) CreateVault(userId int64, vault dto.VaultInput) (*dto.Vault, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var created dto.Vault
+		err := tx.StmtxContext(ctx, r.statements.createVault.statement).
+			QueryRowContext(ctx, vault.EncryptedName).
+			Scan(&created.ID, &created.EncryptedName, &created.KeyVersion, &created.NeedsRotation, &created.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		var createdAt string
+		err = tx.StmtxContext(ctx, r.statements.addMember.statement).
+			QueryRowContext(ctx, created.ID, userId, dto.RoleOwner, dto.MemberStatusAccepted, vault.EncryptedVaultKey, created.KeyVersion).
+			Scan(&created.Role, &created.Status, &created.MemberKeyVersion, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		created.EncryptedVaultKey = vault.EncryptedVaultKey
+
+		return &created, nil
+	}))
+	if err != nil {
+		log.Println("Error creating vault")
+
+		return nil, err
+	}
+
+	return result.(*dto.Vault), nil
+}
+
+func (r *Repository) GetVaultsByUser(userId int64) ([]dto.Vault, error) {
+	rows, err := r.statements.getVaultsByUser.statement.
+		QueryContext(r.ctx, userId)
+	if err != nil {
+		log.Println("Error getting vaults by user")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vaults []dto.Vault
+	for rows.Next() {
+		var vault dto.Vault
+		if err := rows.Scan(&vault.ID, &vault.EncryptedName, &vault.KeyVersion, &vault.NeedsRotation, &vault.Role, &vault.Status,
+			&vault.EncryptedVaultKey, &vault.MemberKeyVersion, &vault.CreatedAt); err != nil {
+			log.Println("Error scanning vault")
+
+			return nil, err
+		}
+		vaults = append(vaults, vault)
+	}
+
+	return vaults, nil
+}
+
+func (r *Repository) GetMembership(vaultId string, userId int64) (*dto.Membership, error) {
+	var membership dto.Membership
+	err := r.statements.getMembership.statement.
+		QueryRowContext(r.ctx, vaultId, userId).Scan(&membership.Role, &membership.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+func (r *Repository) GetMembers(vaultId string) ([]dto.Member, error) {
+	rows, err := r.statements.getMembers.statement.
+		QueryContext(r.ctx, vaultId)
+	if err != nil {
+		log.Println("Error getting vault members")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []dto.Member
+	for rows.Next() {
+		var member dto.Member
+		if err := rows.Scan(&member.UserAddress, &member.Role, &member.Status, &member.KeyVersion, &member.CreatedAt); err != nil {
+			log.Println("Error scanning vault member")
+
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// GetMemberIds lists the accepted members of the vault.
+func (r *Repository) GetMemberIds(vaultId string) ([]int64, error) {
+	rows, err := r.statements.getMemberIds.statement.
+		QueryContext(r.ctx, vaultId)
+	if err != nil {
+		log.Println("Error getting vault member ids")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memberIds []int64
+	for rows.Next() {
+		var memberId int64
+		if err := rows.Scan(&memberId); err != nil {
+			log.Println("Error scanning vault member id")
+
+			return nil, err
+		}
+		memberIds = append(memberIds, memberId)
+	}
+
+	return memberIds, nil
+}
+
+// AddMember invites a user to the vault. Inviting an existing member returns
+// sql.ErrNoRows.
+func (r *Repository) AddMember(vaultId string, userId int64, member dto.MemberInput) (*dto.Member, error) {
+	result := dto.Member{UserAddress: member.MemberAddress}
+	err := r.statements.addMember.statement.
+		QueryRowContext(r.ctx, vaultId, userId, member.Role, dto.MemberStatusPending, member.EncryptedVaultKey, member.KeyVersion).
+		Scan(&result.Role, &result.Status, &result.KeyVersion, &result.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *Repository) AcceptMember(vaultId string, userId int64) (bool, error) {
+	result, err := r.statements.acceptMember.statement.
+		ExecContext(r.ctx, vaultId, userId)
+	if err != nil {
+		log.Println("Error accepting vault membership")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+func (r *Repository) UpdateMemberRole(vaultId string, userId int64, role string) (bool, error) {
+	result, err := r.statements.updateMemberRole.statement.
+		ExecContext(r.ctx, vaultId, userId, role)
+	if err != nil {
+		log.Println("Error updating vault member role")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+// RemoveMember removes a member and flags the vault for key rotation, since
+// the removed member still knows the current vault key.
+func (r *Repository) RemoveMember(vaultId string, userId int64) (bool, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		result, err := tx.StmtxContext(ctx, r.statements.removeMember.statement).ExecContext(ctx, vaultId, userId)
+		if err != nil {
+			return false, err
+		}
+
+		removed, err := rowsAffected(result)
+		if err != nil || !removed {
+			return false, err
+		}
+
+		if _, err := tx.StmtxContext(ctx, r.statements.flagRotation.statement).ExecContext(ctx, vaultId); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}))
+	if err != nil {
+		log.Println("Error removing vault member")
+
+		return false, err
+	}
+
+	return result.(bool), nil
+}
+
+// RotateVaultKey moves the vault from keyVersion to the next version and
+// stores the new wrapped key of every member. It returns sql.ErrNoRows when
+// keyVersion is not the current version, and ErrIncompleteRotation when a
+// member is missing from memberKeys.
+func (r *Repository) RotateVaultKey(vaultId string, keyVersion int, memberKeys []dto.MemberKey) (int, error) {
+	result, err := transaction.New(r.db, false).ExecTx(r.ctx, transaction.TxFunc(func(ctx context.Context, tx *sqlx.Tx) (interface{}, error) {
+		var newVersion int
+		if err := tx.StmtxContext(ctx, r.statements.bumpKeyVersion.statement).
+			QueryRowContext(ctx, vaultId, keyVersion).Scan(&newVersion); err != nil {
+			return 0, err
+		}
+
+		updated := 0
+		for _, memberKey := range memberKeys {
+			result, err := tx.StmtxContext(ctx, r.statements.updateMemberKey.statement).
+				ExecContext(ctx, vaultId, memberKey.UserID, memberKey.EncryptedVaultKey, newVersion)
+			if err != nil {
+				return 0, err
+			}
+
+			if ok, err := rowsAffected(result); err != nil {
+				return 0, err
+			} else if ok {
+				updated++
+			}
+		}
+
+		var members int
+		if err := tx.StmtxContext(ctx, r.statements.countMembers.statement).
+			QueryRowContext(ctx, vaultId).Scan(&members); err != nil {
+			return 0, err
+		}
+
+		if updated != members {
+			return 0, ErrIncompleteRotation
+		}
+
+		return newVersion, nil
+	}))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.(int), nil
+}
+
+func (r *Repository) GetRotationStatus(vaultId string) (*dto.RotationStatus, error) {
+	var status dto.RotationStatus
+	err := r.statements.getRotation.statement.
+		QueryRowContext(r.ctx, vaultId).Scan(&status.KeyVersion, &status.NeedsRotation)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.statements.getStaleKeys.statement.
+		QueryContext(r.ctx, vaultId, status.KeyVersion)
+	if err != nil {
+		log.Println("Error getting stale vault keys")
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	status.StaleKeys = []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		status.StaleKeys = append(status.StaleKeys, id)
+	}
+
+	return &status, nil
+}
+
+func (r *Repository) DeleteVault(vaultId string) (bool, error) {
+	result, err := r.statements.deleteVault.statement.
+		ExecContext(r.ctx, vaultId)
+	if err != nil {
+		log.Println("Error deleting vault")
+
+		return false, err
+	}
+
+	return rowsAffected(result)
+}
+
+func (r *Repository) prepareStatements() (statements, error) {
+	var err error
+
+	statementsList.createVault.statement, err = r.db.PrepareStatement(statementsList.createVault.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.addMember.statement, err = r.db.PrepareStatement(statementsList.addMember.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getVaultsByUser.statement, err = r.db.PrepareStatement(statementsList.getVaultsByUser.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getMembership.statement, err = r.db.PrepareStatement(statementsList.getMembership.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getMembers.statement, err = r.db.PrepareStatement(statementsList.getMembers.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getMemberIds.statement, err = r.db.PrepareStatement(statementsList.getMemberIds.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.acceptMember.statement, err = r.db.PrepareStatement(statementsList.acceptMember.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.updateMemberRole.statement, err = r.db.PrepareStatement(statementsList.updateMemberRole.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.removeMember.statement, err = r.db.PrepareStatement(statementsList.removeMember.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.flagRotation.statement, err = r.db.PrepareStatement(statementsList.flagRotation.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.bumpKeyVersion.statement, err = r.db.PrepareStatement(statementsList.bumpKeyVersion.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.updateMemberKey.statement, err = r.db.PrepareStatement(statementsList.updateMemberKey.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.countMembers.statement, err = r.db.PrepareStatement(statementsList.countMembers.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getRotation.statement, err = r.db.PrepareStatement(statementsList.getRotation.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.getStaleKeys.statement, err = r.db.PrepareStatement(statementsList.getStaleKeys.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	statementsList.deleteVault.statement, err = r.db.PrepareStatement(statementsList.deleteVault.query)
+	if err != nil {
+		return statements{}, err
+	}
+
+	return statementsList, nil
+}
+
+func rowsAffected(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,164 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ObscuraNote/api-general/internal/vaults/dto"
+	"github.com/philippe-berto/database/postgresdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+var cfg = postgresdb.Config{
+	Host:         "localhost",
+	Name:         "crypter",
+	Password:     "password",
+	User:         "user",
+	Port:         5432,
+	Driver:       "postgres",
+	RunMigration: true,
+}
+
+func TestRepository(t *testing.T) {
+	db, err := postgresdb.New(ctx, cfg, false, "file://../../../migrations")
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	db.GetClient().Exec("TRUNCATE TABLE vaults CASCADE;")
+	defer db.Close()
+	defer db.GetClient().Exec("TRUNCATE TABLE vaults CASCADE;")
+
+	db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+	defer db.GetClient().Exec("TRUNCATE TABLE users CASCADE;")
+
+	db.GetClient().Exec(`
+		INSERT INTO users (user_address, password)
+		VALUES ('1111111111111111111111111111111111111111111111111111111111111111', 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa'),
+		       ('2222222222222222222222222222222222222222222222222222222222222222', 'bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb');
+	`)
+
+	repo, err := New(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var ownerId, memberId int64
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '1111111111111111111111111111111111111111111111111111111111111111';
+	`).Scan(&ownerId)
+	if err != nil {
+		t.Fatalf("failed to get owner id: %v", err)
+	}
+	err = db.GetClient().QueryRow(`
+		SELECT id FROM users WHERE user_address = '2222222222222222222222222222222222222222222222222222222222222222';
+	`).Scan(&memberId)
+	if err != nil {
+		t.Fatalf("failed to get member id: %v", err)
+	}
+
+	var vaultId string
+
+	t.Run("CreateVault", func(t *testing.T) {
+		vault, err := repo.CreateVault(ownerId, dto.VaultInput{
+			EncryptedName:     []byte("name"),
+			EncryptedVaultKey: []byte("owner-key"),
+		})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, vault.ID)
+		assert.Equal(t, dto.RoleOwner, vault.Role)
+		assert.Equal(t, dto.MemberStatusAccepted, vault.Status)
+		assert.Equal(t, 1, vault.KeyVersion)
+		vaultId = vault.ID
+	})
+
+	t.Run("AddMember", func(t *testing.T) {
+		member, err := repo.AddMember(vaultId, memberId, dto.MemberInput{
+			Role:              dto.RoleEditor,
+			EncryptedVaultKey: []byte("member-key"),
+			KeyVersion:        1,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, dto.MemberStatusPending, member.Status)
+
+		_, err = repo.AddMember(vaultId, memberId, dto.MemberInput{
+			Role:              dto.RoleViewer,
+			EncryptedVaultKey: []byte("member-key"),
+			KeyVersion:        1,
+		})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("AcceptMember", func(t *testing.T) {
+		accepted, err := repo.AcceptMember(vaultId, memberId)
+		assert.NoError(t, err)
+		assert.True(t, accepted)
+
+		membership, err := repo.GetMembership(vaultId, memberId)
+		assert.NoError(t, err)
+		assert.Equal(t, dto.MemberStatusAccepted, membership.Status)
+
+		vaults, err := repo.GetVaultsByUser(memberId)
+		assert.NoError(t, err)
+		assert.Len(t, vaults, 1)
+		assert.Equal(t, []byte("member-key"), vaults[0].EncryptedVaultKey)
+	})
+
+	t.Run("UpdateMemberRole", func(t *testing.T) {
+		updated, err := repo.UpdateMemberRole(vaultId, memberId, dto.RoleViewer)
+		assert.NoError(t, err)
+		assert.True(t, updated)
+
+		members, err := repo.GetMembers(vaultId)
+		assert.NoError(t, err)
+		assert.Len(t, members, 2)
+
+		memberIds, err := repo.GetMemberIds(vaultId)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []int64{ownerId, memberId}, memberIds)
+	})
+
+	t.Run("RotateVaultKey", func(t *testing.T) {
+		_, err := repo.RotateVaultKey(vaultId, 1, []dto.MemberKey{{UserID: ownerId, EncryptedVaultKey: []byte("owner-key-2")}})
+		assert.ErrorIs(t, err, ErrIncompleteRotation)
+
+		version, err := repo.RotateVaultKey(vaultId, 1, []dto.MemberKey{
+			{UserID: ownerId, EncryptedVaultKey: []byte("owner-key-2")},
+			{UserID: memberId, EncryptedVaultKey: []byte("member-key-2")},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, version)
+
+		_, err = repo.RotateVaultKey(vaultId, 1, []dto.MemberKey{{UserID: ownerId, EncryptedVaultKey: []byte("owner-key-3")}})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("RemoveMember", func(t *testing.T) {
+		removed, err := repo.RemoveMember(vaultId, memberId)
+		assert.NoError(t, err)
+		assert.True(t, removed)
+
+		status, err := repo.GetRotationStatus(vaultId)
+		assert.NoError(t, err)
+		assert.True(t, status.NeedsRotation)
+		assert.Equal(t, 2, status.KeyVersion)
+
+		_, err = repo.GetMembership(vaultId, memberId)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("DeleteVault", func(t *testing.T) {
+		deleted, err := repo.DeleteVault(vaultId)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+
+		vaults, err := repo.GetVaultsByUser(ownerId)
+		assert.NoError(t, err)
+		assert.Len(t, vaults, 0)
+	})
+}
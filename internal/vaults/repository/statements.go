@@ -0,0 +1,160 @@
+package repository
+
+import "github.com/jmoiron/sqlx"
+
+type statementsItem struct {
+	name      string
+	query     string
+	statement *sqlx.Stmt
+}
+
+type statements struct {
+	createVault      statementsItem
+	addMember        statementsItem
+	getVaultsByUser  statementsItem
+	getMembership    statementsItem
+	getMembers       statementsItem
+	getMemberIds     statementsItem
+	acceptMember     statementsItem
+	updateMemberRole statementsItem
+	removeMember     statementsItem
+	flagRotation     statementsItem
+	bumpKeyVersion   statementsItem
+	updateMemberKey  statementsItem
+	countMembers     statementsItem
+	getRotation      statementsItem
+	getStaleKeys     statementsItem
+	deleteVault      statementsItem
+}
+
+var statementsList = statements{
+	createVault: statementsItem{
+		name: "createVault",
+		query: `
+			INSERT INTO vaults (encrypted_name)
+			VALUES ($1)
+			RETURNING id, encrypted_name, key_version, needs_rotation, created_at;`,
+	},
+	addMember: statementsItem{
+		name: "addMember",
+		query: `
+			INSERT INTO vault_members (vault_id, user_id, role, status, encrypted_vault_key, key_version)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (vault_id, user_id) DO NOTHING
+			RETURNING role, status, key_version, created_at;`,
+	},
+	getVaultsByUser: statementsItem{
+		name: "getVaultsByUser",
+		query: `
+			SELECT v.id, v.encrypted_name, v.key_version, v.needs_rotation, m.role, m.status,
+				m.encrypted_vault_key, m.key_version, v.created_at
+			FROM vault_members m
+			JOIN vaults v ON v.id = m.vault_id
+			WHERE m.user_id = $1
+			ORDER BY v.created_at DESC;`,
+	},
+	getMembership: statementsItem{
+		name: "getMembership",
+		query: `
+			SELECT role, status
+			FROM vault_members
+			WHERE vault_id = $1
+			AND user_id = $2;`,
+	},
+	getMembers: statementsItem{
+		name: "getMembers",
+		query: `
+			SELECT u.user_address, m.role, m.status, m.key_version, m.created_at
+			FROM vault_members m
+			JOIN users u ON u.id = m.user_id
+			WHERE m.vault_id = $1
+			ORDER BY m.created_at;`,
+	},
+	getMemberIds: statementsItem{
+		name: "getMemberIds",
+		query: `
+			SELECT user_id
+			FROM vault_members
+			WHERE vault_id = $1
+			AND status = 'accepted'
+			ORDER BY user_id;`,
+	},
+	acceptMember: statementsItem{
+		name: "acceptMember",
+		query: `
+			UPDATE vault_members
+			SET status = 'accepted', updated_at = CURRENT_TIMESTAMP
+			WHERE vault_id = $1
+			AND user_id = $2
+			AND status = 'pending';`,
+	},
+	updateMemberRole: statementsItem{
+		name: "updateMemberRole",
+		query: `
+			UPDATE vault_members
+			SET role = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE vault_id = $1
+			AND user_id = $2;`,
+	},
+	removeMember: statementsItem{
+		name: "removeMember",
+		query: `
+			DELETE FROM vault_members
+			WHERE vault_id = $1
+			AND user_id = $2;`,
+	},
+	flagRotation: statementsItem{
+		name: "flagRotation",
+		query: `
+			UPDATE vaults
+			SET needs_rotation = TRUE, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1;`,
+	},
+	bumpKeyVersion: statementsItem{
+		name: "bumpKeyVersion",
+		query: `
+			UPDATE vaults
+			SET key_version = key_version + 1, needs_rotation = FALSE, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+			AND key_version = $2
+			RETURNING key_version;`,
+	},
+	updateMemberKey: statementsItem{
+		name: "updateMemberKey",
+		query: `
+			UPDATE vault_members
+			SET encrypted_vault_key = $3, key_version = $4, updated_at = CURRENT_TIMESTAMP
+			WHERE vault_id = $1
+			AND user_id = $2;`,
+	},
+	countMembers: statementsItem{
+		name: "countMembers",
+		query: `
+			SELECT COUNT(*)
+			FROM vault_members
+			WHERE vault_id = $1;`,
+	},
+	getRotation: statementsItem{
+		name: "getRotation",
+		query: `
+			SELECT key_version, needs_rotation
+			FROM vaults
+			WHERE id = $1;`,
+	},
+	getStaleKeys: statementsItem{
+		name: "getStaleKeys",
+		query: `
+			SELECT id
+			FROM keys
+			WHERE vault_id = $1
+			AND (vault_key_version IS NULL OR vault_key_version < $2)
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			ORDER BY created_at;`,
+	},
+	deleteVault: statementsItem{
+		name: "deleteVault",
+		query: `
+			DELETE FROM vaults
+			WHERE id = $1;`,
+	},
+}
@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	u "github.com/ObscuraNote/api-general/internal/users/service"
+	"github.com/ObscuraNote/api-general/internal/utils"
+	"github.com/ObscuraNote/api-general/internal/vaults/dto"
+	r "github.com/ObscuraNote/api-general/internal/vaults/repository"
+	"github.com/philippe-berto/logger"
+)
+
+var _ VaultsService = (*Service)(nil)
+
+// roleRank orders vault roles from least to most privileged.
+var roleRank = map[string]int{
+	dto.RoleViewer: 1,
+	dto.RoleEditor: 2,
+	dto.RoleAdmin:  3,
+	dto.RoleOwner:  4,
+}
+
+type (
+	VaultsService interface {
+		CreateVault(vault dto.VaultInput) (*dto.Vault, error)
+		GetVaults(auth dto.AuthInput) ([]dto.Vault, error)
+		GetMembers(vaultId string, auth dto.AuthInput) ([]dto.Member, error)
+		InviteMember(vaultId string, member dto.MemberInput) (*dto.Member, error)
+		AcceptInvitation(vaultId string, auth dto.AuthInput) error
+		ChangeMemberRole(vaultId, memberAddress string, input dto.RoleInput) error
+		RemoveMember(vaultId, memberAddress string, auth dto.AuthInput) error
+		RotateKey(vaultId string, rotation dto.RotationInput) (*dto.RotationStatus, error)
+		GetRotationStatus(vaultId string, auth dto.AuthInput) (*dto.RotationStatus, error)
+		DeleteVault(vaultId string, auth dto.AuthInput) error
+		RequireRole(vaultId string, userId int64, minRole string) error
+		GetMemberIds(vaultId string) ([]int64, error)
+	}
+
+	Service struct {
+		ctx context.Context
+		r   r.VaultsRepository
+		us  u.UserService
+		log *logger.Logger
+	}
+)
+
+func New(ctx context.Context, log logger.Logger, repo r.VaultsRepository, us u.UserService) *Service {
+	return &Service{
+		ctx: ctx,
+		log: &log,
+		r:   repo,
+		us:  us,
+	}
+}
+
+func (s *Service) CreateVault(vault dto.VaultInput) (*dto.Vault, error) {
+	if len(vault.EncryptedName) == 0 || len(vault.EncryptedVaultKey) == 0 {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	userId, err := s.getUserId(vault.UserAddress, vault.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.r.CreateVault(userId, vault)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "CreateVault"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return created, nil
+}
+
+func (s *Service) GetVaults(auth dto.AuthInput) ([]dto.Vault, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	vaults, err := s.r.GetVaultsByUser(userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "GetVaults"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return vaults, nil
+}
+
+func (s *Service) GetMembers(vaultId string, auth dto.AuthInput) ([]dto.Member, error) {
+	if _, _, err := s.authorize(vaultId, auth, dto.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	members, err := s.r.GetMembers(vaultId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "GetMembers"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return members, nil
+}
+
+// InviteMember adds a pending member. Admins may invite editors and viewers;
+// only the owner may invite another admin.
+func (s *Service) InviteMember(vaultId string, member dto.MemberInput) (*dto.Member, error) {
+	if member.MemberAddress == "" || len(member.EncryptedVaultKey) == 0 || !assignable(member.Role) {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	_, role, err := s.authorize(vaultId, dto.AuthInput{UserAddress: member.UserAddress, Password: member.Password}, dto.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.Role == dto.RoleAdmin && role != dto.RoleOwner {
+		return nil, fmt.Errorf(utils.Forbidden)
+	}
+
+	memberId, err := s.getUserIdByAddress(member.MemberAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := s.r.AddMember(vaultId, memberId, member)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.MemberExists)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "InviteMember"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return added, nil
+}
+
+func (s *Service) AcceptInvitation(vaultId string, auth dto.AuthInput) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	accepted, err := s.r.AcceptMember(vaultId, userId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "AcceptInvitation"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if !accepted {
+		return fmt.Errorf(utils.VaultNotFound)
+	}
+
+	return nil
+}
+
+// ChangeMemberRole changes another member's role. The owner role cannot be
+// granted or taken away, and admins can only manage editors and viewers.
+func (s *Service) ChangeMemberRole(vaultId, memberAddress string, input dto.RoleInput) error {
+	if !assignable(input.Role) {
+		return fmt.Errorf(utils.BadRequest)
+	}
+
+	userId, role, err := s.authorize(vaultId, dto.AuthInput{UserAddress: input.UserAddress, Password: input.Password}, dto.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	memberId, target, err := s.getMember(vaultId, memberAddress)
+	if err != nil {
+		return err
+	}
+
+	if memberId == userId || !canManage(role, target.Role) || !canManage(role, input.Role) {
+		return fmt.Errorf(utils.Forbidden)
+	}
+
+	if _, err := s.r.UpdateMemberRole(vaultId, memberId, input.Role); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "ChangeMemberRole"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return nil
+}
+
+// RemoveMember removes a member, or lets a member other than the owner leave.
+// Either way the vault is flagged for key rotation.
+func (s *Service) RemoveMember(vaultId, memberAddress string, auth dto.AuthInput) error {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	membership, err := s.getMembership(vaultId, userId)
+	if err != nil {
+		return err
+	}
+
+	memberId, target, err := s.getMember(vaultId, memberAddress)
+	if err != nil {
+		return err
+	}
+
+	leaving := memberId == userId
+	if target.Role == dto.RoleOwner {
+		return fmt.Errorf(utils.Forbidden)
+	}
+	if !leaving && (membership.Status != dto.MemberStatusAccepted || !canManage(membership.Role, target.Role)) {
+		return fmt.Errorf(utils.Forbidden)
+	}
+
+	removed, err := s.r.RemoveMember(vaultId, memberId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "RemoveMember"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	if !removed {
+		return fmt.Errorf(utils.MemberNotFound)
+	}
+
+	return nil
+}
+
+// RotateKey installs a new vault key for every remaining member. Entries keep
+// their old encryption until clients re-encrypt them; GetRotationStatus lists
+// the ones still pending.
+func (s *Service) RotateKey(vaultId string, rotation dto.RotationInput) (*dto.RotationStatus, error) {
+	if len(rotation.MemberKeys) == 0 {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+
+	if _, _, err := s.authorize(vaultId, dto.AuthInput{UserAddress: rotation.UserAddress, Password: rotation.Password}, dto.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	for i, memberKey := range rotation.MemberKeys {
+		if len(memberKey.EncryptedVaultKey) == 0 {
+			return nil, fmt.Errorf(utils.BadRequest)
+		}
+
+		memberId, err := s.getUserIdByAddress(memberKey.MemberAddress)
+		if err != nil {
+			return nil, err
+		}
+		rotation.MemberKeys[i].UserID = memberId
+	}
+
+	_, err := s.r.RotateVaultKey(vaultId, rotation.KeyVersion, rotation.MemberKeys)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.VersionConflict)
+	}
+	if errors.Is(err, r.ErrIncompleteRotation) {
+		return nil, fmt.Errorf(utils.BadRequest)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "RotateKey"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return s.rotationStatus(vaultId)
+}
+
+func (s *Service) GetRotationStatus(vaultId string, auth dto.AuthInput) (*dto.RotationStatus, error) {
+	if _, _, err := s.authorize(vaultId, auth, dto.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.rotationStatus(vaultId)
+}
+
+func (s *Service) DeleteVault(vaultId string, auth dto.AuthInput) error {
+	if _, _, err := s.authorize(vaultId, auth, dto.RoleOwner); err != nil {
+		return err
+	}
+
+	if _, err := s.r.DeleteVault(vaultId); err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "DeleteVault"}).Error(utils.ErrDatabase)
+		return fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return nil
+}
+
+// RequireRole checks that userId is an accepted member of the vault with at
+// least minRole. Non-members get VAULT_NOT_FOUND so that vault IDs do not leak.
+func (s *Service) RequireRole(vaultId string, userId int64, minRole string) error {
+	membership, err := s.getMembership(vaultId, userId)
+	if err != nil {
+		return err
+	}
+
+	if membership.Status != dto.MemberStatusAccepted {
+		return fmt.Errorf(utils.VaultNotFound)
+	}
+
+	if roleRank[membership.Role] < roleRank[minRole] {
+		return fmt.Errorf(utils.Forbidden)
+	}
+
+	return nil
+}
+
+// GetMemberIds lists the accepted members of a vault. Like RequireRole it is
+// meant for other services and does not authenticate anyone.
+func (s *Service) GetMemberIds(vaultId string) ([]int64, error) {
+	memberIds, err := s.r.GetMemberIds(vaultId)
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "GetMemberIds"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return memberIds, nil
+}
+
+func (s *Service) authorize(vaultId string, auth dto.AuthInput, minRole string) (int64, string, error) {
+	userId, err := s.getUserId(auth.UserAddress, auth.Password)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if err := s.RequireRole(vaultId, userId, minRole); err != nil {
+		return 0, "", err
+	}
+
+	membership, err := s.getMembership(vaultId, userId)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return userId, membership.Role, nil
+}
+
+func (s *Service) rotationStatus(vaultId string) (*dto.RotationStatus, error) {
+	status, err := s.r.GetRotationStatus(vaultId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.VaultNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "rotationStatus"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return status, nil
+}
+
+func (s *Service) getMembership(vaultId string, userId int64) (*dto.Membership, error) {
+	membership, err := s.r.GetMembership(vaultId, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(utils.VaultNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "getMembership"}).Error(utils.ErrDatabase)
+		return nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return membership, nil
+}
+
+func (s *Service) getMember(vaultId, memberAddress string) (int64, *dto.Membership, error) {
+	memberId, err := s.getUserIdByAddress(memberAddress)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	membership, err := s.r.GetMembership(vaultId, memberId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, fmt.Errorf(utils.MemberNotFound)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "getMember"}).Error(utils.ErrDatabase)
+		return 0, nil, fmt.Errorf(utils.ErrDatabase)
+	}
+
+	return memberId, membership, nil
+}
+
+func (s *Service) getUserId(userAddress, password string) (int64, error) {
+	userId, err := s.us.GetUserId(userAddress, password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	if err != nil {
+		s.log.WithFields(logger.Fields{"error": err.Error(), "component": "vaults_service", "function": "getUserId"}).Error(utils.ErrDatabase)
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	if userId <= 0 {
+		return 0, fmt.Errorf(utils.ErrUnauthorized)
+	}
+	return userId, nil
+}
+
+func (s *Service) getUserIdByAddress(userAddress string) (int64, error) {
+	userId, err := s.us.GetUserIdByAddress(userAddress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf(utils.UserNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf(utils.ErrDatabase)
+	}
+	return userId, nil
+}
+
+// assignable reports whether role can be given through invitations or role
+// changes. Ownership is fixed at vault creation.
+func assignable(role string) bool {
+	return role == dto.RoleAdmin || role == dto.RoleEditor || role == dto.RoleViewer
+}
+
+// canManage reports whether a member with role may act on a member, or grant
+// a role, of rank target.
+func canManage(role, target string) bool {
+	if role == dto.RoleOwner {
+		return target != dto.RoleOwner
+	}
+
+	return role == dto.RoleAdmin && roleRank[target] < roleRank[dto.RoleAdmin]
+}
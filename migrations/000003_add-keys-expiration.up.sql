@@ -0,0 +1,5 @@
+ALTER TABLE keys ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_keys_expires_at ON keys (expires_at)
+WHERE
+    expires_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_secrets_expires_at;
+
+DROP TABLE IF EXISTS secrets;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS secrets (
+    id CHAR(64) NOT NULL PRIMARY KEY,
+    ciphertext BYTEA NOT NULL,
+    remaining_views INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT secret_id_format CHECK (id ~ '^[0-9a-f]{64}$'),
+    CONSTRAINT remaining_views_positive CHECK (remaining_views > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_secrets_expires_at ON secrets (expires_at);
\ No newline at end of file
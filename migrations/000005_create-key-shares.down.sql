@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_key_shares_recipient_id;
+
+DROP TABLE IF EXISTS key_shares;
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS key_shares (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    key_id UUID NOT NULL REFERENCES keys (id) ON DELETE CASCADE,
+    owner_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    encrypted_key BYTEA NOT NULL,
+    key_iv BYTEA,
+    permission VARCHAR(16) NOT NULL DEFAULT 'read',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT key_share_unique UNIQUE (key_id, recipient_id),
+    CONSTRAINT key_share_permission CHECK (permission IN ('read', 'edit')),
+    CONSTRAINT key_share_status CHECK (
+        status IN ('pending', 'accepted', 'declined')
+    ),
+    CONSTRAINT key_share_not_owner CHECK (owner_id <> recipient_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_key_shares_recipient_id ON key_shares (recipient_id);
\ No newline at end of file
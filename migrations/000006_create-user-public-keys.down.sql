@@ -0,0 +1 @@
+DROP TABLE IF EXISTS user_public_keys;
\ No newline at end of file
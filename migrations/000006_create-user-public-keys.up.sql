@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS user_public_keys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    encryption_key BYTEA NOT NULL,
+    signing_key BYTEA NOT NULL,
+    signature BYTEA NOT NULL,
+    rotation_signature BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT user_public_keys_version UNIQUE (user_id, version),
+    CONSTRAINT user_public_keys_version_positive CHECK (version > 0),
+    CONSTRAINT encryption_key_size CHECK (octet_length(encryption_key) = 32),
+    CONSTRAINT signing_key_size CHECK (octet_length(signing_key) = 32)
+);
\ No newline at end of file
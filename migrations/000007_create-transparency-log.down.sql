@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS transparency_nodes;
+
+DROP TABLE IF EXISTS transparency_tree_heads;
+
+DROP TABLE IF EXISTS transparency_log;
+
+DROP FUNCTION IF EXISTS transparency_append_only;
\ No newline at end of file
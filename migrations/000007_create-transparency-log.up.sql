@@ -0,0 +1,63 @@
+CREATE TABLE IF NOT EXISTS transparency_log (
+    leaf_index BIGINT NOT NULL PRIMARY KEY,
+    operation VARCHAR(16) NOT NULL,
+    user_address CHAR(64) NOT NULL,
+    version INTEGER NOT NULL,
+    leaf_data BYTEA NOT NULL,
+    leaf_hash BYTEA NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT leaf_index_positive CHECK (leaf_index >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transparency_log_user ON transparency_log (user_address, version, operation);
+
+CREATE TABLE IF NOT EXISTS transparency_tree_heads (
+    tree_size BIGINT NOT NULL PRIMARY KEY,
+    root_hash BYTEA NOT NULL,
+    frontier BYTEA NOT NULL,
+    timestamp BIGINT NOT NULL,
+    signature BYTEA NOT NULL,
+    CONSTRAINT tree_size_positive CHECK (tree_size > 0)
+);
+
+CREATE OR REPLACE FUNCTION transparency_append_only () RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transparency_log_append_only
+BEFORE UPDATE OR DELETE ON transparency_log
+FOR EACH ROW EXECUTE FUNCTION transparency_append_only ();
+
+CREATE TRIGGER transparency_log_no_truncate
+BEFORE TRUNCATE ON transparency_log
+FOR EACH STATEMENT EXECUTE FUNCTION transparency_append_only ();
+
+CREATE TRIGGER transparency_tree_heads_append_only
+BEFORE UPDATE OR DELETE ON transparency_tree_heads
+FOR EACH ROW EXECUTE FUNCTION transparency_append_only ();
+
+CREATE TRIGGER transparency_tree_heads_no_truncate
+BEFORE TRUNCATE ON transparency_tree_heads
+FOR EACH STATEMENT EXECUTE FUNCTION transparency_append_only ();
+
+-- Hashes of the perfect subtrees of the log: the node at height h and index
+-- i covers leaves [i * 2^h, (i + 1) * 2^h). A node is written with the leaf
+-- that completes it and never changes, so proofs read the O(log n) nodes
+-- they need instead of every leaf. Height 0 holds the leaf hashes.
+CREATE TABLE IF NOT EXISTS transparency_nodes (
+    height SMALLINT NOT NULL,
+    node_index BIGINT NOT NULL,
+    hash BYTEA NOT NULL,
+    PRIMARY KEY (height, node_index),
+    CONSTRAINT node_index_positive CHECK (node_index >= 0)
+);
+
+CREATE TRIGGER transparency_nodes_append_only
+BEFORE UPDATE OR DELETE ON transparency_nodes
+FOR EACH ROW EXECUTE FUNCTION transparency_append_only ();
+
+CREATE TRIGGER transparency_nodes_no_truncate
+BEFORE TRUNCATE ON transparency_nodes
+FOR EACH STATEMENT EXECUTE FUNCTION transparency_append_only ();
\ No newline at end of file
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_keys_vault_id;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS vault_key_version;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS vault_id;
+
+DROP INDEX IF EXISTS idx_vault_members_user_id;
+
+DROP TABLE IF EXISTS vault_members;
+
+DROP TABLE IF EXISTS vaults;
\ No newline at end of file
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS vaults (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    encrypted_name BYTEA NOT NULL,
+    key_version INTEGER NOT NULL DEFAULT 1,
+    needs_rotation BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS vault_members (
+    vault_id UUID NOT NULL REFERENCES vaults (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    encrypted_vault_key BYTEA NOT NULL,
+    key_version INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (vault_id, user_id),
+    CONSTRAINT vault_member_role CHECK (
+        role IN ('owner', 'admin', 'editor', 'viewer')
+    ),
+    CONSTRAINT vault_member_status CHECK (
+        status IN ('pending', 'accepted')
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_vault_members_user_id ON vault_members (user_id);
+
+ALTER TABLE keys
+ADD COLUMN IF NOT EXISTS vault_id UUID REFERENCES vaults (id) ON DELETE CASCADE;
+
+ALTER TABLE keys ADD COLUMN IF NOT EXISTS vault_key_version INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_keys_vault_id ON keys (vault_id)
+WHERE
+    vault_id IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_mls_messages_recipient_id;
+
+DROP TABLE IF EXISTS mls_messages;
+
+DROP TABLE IF EXISTS mls_groups;
+
+DROP INDEX IF EXISTS idx_mls_key_packages_last_resort;
+
+DROP INDEX IF EXISTS idx_mls_key_packages_user_device;
+
+DROP TABLE IF EXISTS mls_key_packages;
\ No newline at end of file
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS mls_key_packages (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    device_id VARCHAR(64) NOT NULL,
+    key_package BYTEA NOT NULL,
+    last_resort BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mls_key_packages_user_device ON mls_key_packages (user_id, device_id, id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mls_key_packages_last_resort ON mls_key_packages (user_id, device_id) WHERE last_resort;
+
+CREATE TABLE IF NOT EXISTS mls_groups (
+    vault_id UUID NOT NULL PRIMARY KEY REFERENCES vaults (id) ON DELETE CASCADE,
+    epoch BIGINT NOT NULL DEFAULT 0,
+    last_seq BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS mls_messages (
+    vault_id UUID NOT NULL REFERENCES mls_groups (vault_id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,
+    epoch BIGINT NOT NULL,
+    content_type VARCHAR(16) NOT NULL,
+    sender_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    recipient_id BIGINT REFERENCES users (id) ON DELETE CASCADE,
+    payload BYTEA NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (vault_id, seq),
+    CONSTRAINT mls_message_content_type CHECK (
+        content_type IN ('proposal', 'commit', 'welcome')
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_mls_messages_recipient_id ON mls_messages (recipient_id)
+WHERE
+    recipient_id IS NOT NULL;
\ No newline at end of file
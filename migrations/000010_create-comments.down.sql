@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_comments_key_id_seq;
+
+DROP TABLE IF EXISTS comments;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    seq BIGSERIAL NOT NULL UNIQUE,
+    key_id UUID NOT NULL REFERENCES keys (id) ON DELETE CASCADE,
+    author_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    encrypted_body BYTEA NOT NULL,
+    body_iv BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_key_id_seq ON comments (key_id, seq);
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS key_snapshots;
+
+DROP TABLE IF EXISTS key_operations;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS op_seq;
\ No newline at end of file
@@ -0,0 +1,19 @@
+ALTER TABLE keys ADD COLUMN IF NOT EXISTS op_seq BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS key_operations (
+    key_id UUID NOT NULL REFERENCES keys (id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,
+    author_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    encrypted_op BYTEA NOT NULL,
+    op_iv BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (key_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS key_snapshots (
+    key_id UUID NOT NULL PRIMARY KEY REFERENCES keys (id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,
+    encrypted_snapshot BYTEA NOT NULL,
+    snapshot_iv BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS drop_challenges;
+
+DROP INDEX IF EXISTS idx_drop_submissions_dropbox_id;
+
+DROP TABLE IF EXISTS drop_submissions;
+
+DROP INDEX IF EXISTS idx_dropboxes_user_id;
+
+DROP TABLE IF EXISTS dropboxes;
\ No newline at end of file
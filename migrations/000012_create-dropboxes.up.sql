@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS dropboxes (
+    id VARCHAR(32) NOT NULL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    encrypted_label BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dropboxes_user_id ON dropboxes (user_id);
+
+-- Submissions keep no sender metadata. The submission time is only kept to
+-- the hour so that it is harder to correlate with network logs.
+CREATE TABLE IF NOT EXISTS drop_submissions (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    dropbox_id VARCHAR(32) NOT NULL REFERENCES dropboxes (id) ON DELETE CASCADE,
+    sealed_blob BYTEA NOT NULL,
+    created_at TIMESTAMP DEFAULT date_trunc('hour', CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX IF NOT EXISTS idx_drop_submissions_dropbox_id ON drop_submissions (dropbox_id);
+
+CREATE TABLE IF NOT EXISTS drop_challenges (
+    digest CHAR(64) NOT NULL PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS message_blocks;
+
+DROP INDEX IF EXISTS idx_messages_sender_id;
+
+DROP INDEX IF EXISTS idx_messages_recipient_seq;
+
+DROP TABLE IF EXISTS messages;
\ No newline at end of file
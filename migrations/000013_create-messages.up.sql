@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS messages (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    seq BIGSERIAL NOT NULL,
+    sender_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    encrypted_body BYTEA NOT NULL,
+    size INTEGER NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_recipient_seq ON messages (recipient_id, seq);
+
+CREATE INDEX IF NOT EXISTS idx_messages_sender_id ON messages (sender_id);
+
+CREATE TABLE IF NOT EXISTS message_blocks (
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    blocked_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, blocked_id)
+);
\ No newline at end of file
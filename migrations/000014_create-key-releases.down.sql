@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_key_releases_release_at;
+
+DROP TABLE IF EXISTS key_releases;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS key_releases (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    key_id UUID NOT NULL REFERENCES keys (id) ON DELETE CASCADE,
+    owner_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    encrypted_key BYTEA NOT NULL,
+    key_iv BYTEA,
+    permission VARCHAR(16) NOT NULL DEFAULT 'read',
+    release_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT key_release_unique UNIQUE (key_id, recipient_id),
+    CONSTRAINT key_release_permission CHECK (permission IN ('read', 'edit')),
+    CONSTRAINT key_release_not_owner CHECK (owner_id <> recipient_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_key_releases_release_at ON key_releases (release_at);
\ No newline at end of file
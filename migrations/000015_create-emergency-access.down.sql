@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_emergency_contacts_grant_at;
+
+DROP INDEX IF EXISTS idx_emergency_contacts_contact_id;
+
+DROP TABLE IF EXISTS emergency_contacts;
+
+ALTER TABLE users DROP COLUMN IF EXISTS last_auth_at;
\ No newline at end of file
@@ -0,0 +1,27 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS last_auth_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS emergency_contacts (
+    owner_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    contact_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    encrypted_key BYTEA NOT NULL,
+    key_iv BYTEA,
+    wait_hours INTEGER NOT NULL,
+    inactivity_days INTEGER,
+    status VARCHAR(16) NOT NULL DEFAULT 'idle',
+    requested_at TIMESTAMPTZ,
+    grant_at TIMESTAMPTZ,
+    granted_at TIMESTAMPTZ,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner_id, contact_id),
+    CONSTRAINT emergency_contact_status CHECK (
+        status IN ('idle', 'requested', 'granted')
+    ),
+    CONSTRAINT emergency_contact_not_owner CHECK (owner_id <> contact_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_emergency_contacts_contact_id ON emergency_contacts (contact_id);
+
+CREATE INDEX IF NOT EXISTS idx_emergency_contacts_grant_at ON emergency_contacts (grant_at)
+WHERE status = 'requested';
\ No newline at end of file
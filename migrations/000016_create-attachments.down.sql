@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS attachment_chunks_blob_garbage ON attachment_chunks;
+
+DROP FUNCTION IF EXISTS queue_blob_garbage ();
+
+DROP TABLE IF EXISTS blob_garbage;
+
+DROP TABLE IF EXISTS attachment_chunks;
+
+DROP INDEX IF EXISTS idx_attachments_uploading;
+
+DROP INDEX IF EXISTS idx_attachments_key_id;
+
+DROP TABLE IF EXISTS attachments;
\ No newline at end of file
@@ -0,0 +1,54 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    key_id UUID NOT NULL REFERENCES keys (id) ON DELETE CASCADE,
+    uploader_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    encrypted_name BYTEA,
+    name_iv BYTEA,
+    chunk_size INTEGER NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'uploading',
+    size BIGINT NOT NULL DEFAULT 0,
+    chunk_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    CONSTRAINT attachment_status CHECK (
+        status IN ('uploading', 'complete')
+    ),
+    CONSTRAINT attachment_chunk_size_positive CHECK (chunk_size > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_key_id ON attachments (key_id);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_uploading ON attachments (created_at)
+WHERE status = 'uploading';
+
+CREATE TABLE IF NOT EXISTS attachment_chunks (
+    attachment_id UUID NOT NULL REFERENCES attachments (id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL,
+    size BIGINT NOT NULL,
+    blob_key TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (attachment_id, chunk_index),
+    CONSTRAINT attachment_chunk_index_positive CHECK (chunk_index >= 0)
+);
+
+-- Blobs live outside the database, so removing a chunk row, including through
+-- a cascade from its entry, queues its blob for deletion from the store.
+CREATE TABLE IF NOT EXISTS blob_garbage (
+    blob_key TEXT NOT NULL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE OR REPLACE FUNCTION queue_blob_garbage () RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' OR OLD.blob_key <> NEW.blob_key THEN
+        INSERT INTO blob_garbage (blob_key)
+        VALUES (OLD.blob_key)
+        ON CONFLICT (blob_key) DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attachment_chunks_blob_garbage
+AFTER UPDATE OR DELETE ON attachment_chunks
+FOR EACH ROW EXECUTE FUNCTION queue_blob_garbage ();
\ No newline at end of file
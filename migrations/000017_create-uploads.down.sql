@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS upload_segments_blob_garbage ON upload_segments;
+
+DROP TABLE IF EXISTS upload_segments;
+
+DROP INDEX IF EXISTS idx_uploads_expires_at;
+
+DROP INDEX IF EXISTS idx_uploads_user_id;
+
+DROP TABLE IF EXISTS uploads;
\ No newline at end of file
@@ -0,0 +1,35 @@
+-- Resumable uploads. Data arrives in segments that are stored as blobs as
+-- soon as they are received, so an interrupted upload resumes from the last
+-- stored segment, even across restarts.
+CREATE TABLE IF NOT EXISTS uploads (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    metadata TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMP,
+    CONSTRAINT upload_length_positive CHECK (length > 0),
+    CONSTRAINT upload_offset_range CHECK (
+        upload_offset >= 0
+        AND upload_offset <= length
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_uploads_user_id ON uploads (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_uploads_expires_at ON uploads (expires_at);
+
+CREATE TABLE IF NOT EXISTS upload_segments (
+    upload_id UUID NOT NULL REFERENCES uploads (id) ON DELETE CASCADE,
+    start_offset BIGINT NOT NULL,
+    size BIGINT NOT NULL,
+    blob_key TEXT NOT NULL,
+    PRIMARY KEY (upload_id, start_offset)
+);
+
+-- Segment blobs go through the same garbage queue as attachment chunks.
+CREATE TRIGGER upload_segments_blob_garbage
+AFTER DELETE ON upload_segments
+FOR EACH ROW EXECUTE FUNCTION queue_blob_garbage ();
\ No newline at end of file
@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS keys_data_blob_garbage ON keys;
+
+DROP FUNCTION IF EXISTS queue_key_data_garbage ();
+
+DROP INDEX IF EXISTS idx_keys_offloaded;
+
+ALTER TABLE keys DROP CONSTRAINT IF EXISTS keys_data_location;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS data_checksum;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS data_size;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS data_ref;
+
+ALTER TABLE keys ALTER COLUMN encrypted_data SET NOT NULL;
\ No newline at end of file
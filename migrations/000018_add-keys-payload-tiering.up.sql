@@ -0,0 +1,45 @@
+-- Large payloads move to the blob store. Postgres keeps a reference, the
+-- payload size and its SHA-256 checksum instead of the ciphertext. Moving
+-- every payload back inline, with a tiering threshold of 0, is required
+-- before rolling this migration back.
+ALTER TABLE keys ALTER COLUMN encrypted_data DROP NOT NULL;
+
+ALTER TABLE keys ADD COLUMN IF NOT EXISTS data_ref TEXT;
+
+ALTER TABLE keys ADD COLUMN IF NOT EXISTS data_size BIGINT;
+
+ALTER TABLE keys ADD COLUMN IF NOT EXISTS data_checksum BYTEA;
+
+ALTER TABLE keys ADD CONSTRAINT keys_data_location CHECK (
+    (
+        encrypted_data IS NOT NULL
+        AND data_ref IS NULL
+    )
+    OR (
+        encrypted_data IS NULL
+        AND data_ref IS NOT NULL
+        AND data_size IS NOT NULL
+        AND data_checksum IS NOT NULL
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_keys_offloaded ON keys (data_size)
+WHERE
+    data_ref IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION queue_key_data_garbage () RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' OR OLD.data_ref IS DISTINCT FROM NEW.data_ref THEN
+        INSERT INTO blob_garbage (blob_key)
+        VALUES (OLD.data_ref)
+        ON CONFLICT (blob_key) DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER keys_data_blob_garbage
+AFTER UPDATE OR DELETE ON keys
+FOR EACH ROW
+WHEN (OLD.data_ref IS NOT NULL)
+EXECUTE FUNCTION queue_key_data_garbage ();
\ No newline at end of file
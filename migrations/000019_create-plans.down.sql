@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS vouchers;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS user_plan_expiry;
+
+ALTER TABLE users DROP COLUMN IF EXISTS plan_expires_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS plan_id;
+
+DROP TABLE IF EXISTS plans;
\ No newline at end of file
@@ -0,0 +1,38 @@
+-- Plans grant entitlements on top of the defaults from the configuration.
+-- A zero limit is unlimited; history_depth is the number of operations kept
+-- behind a snapshot.
+CREATE TABLE IF NOT EXISTS plans (
+    id TEXT NOT NULL PRIMARY KEY,
+    name TEXT NOT NULL,
+    max_entries BIGINT NOT NULL DEFAULT 0,
+    max_bytes BIGINT NOT NULL DEFAULT 0,
+    max_attachment_bytes BIGINT NOT NULL DEFAULT 0,
+    max_devices INT NOT NULL DEFAULT 0,
+    sharing_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    history_depth INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT plan_id_format CHECK (id ~ '^[a-z0-9-]{1,32}$'),
+    CONSTRAINT plan_limits_positive CHECK (
+        max_entries >= 0
+        AND max_bytes >= 0
+        AND max_attachment_bytes >= 0
+        AND max_devices >= 0
+        AND history_depth >= 0
+    )
+);
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS plan_id TEXT REFERENCES plans (id),
+ADD COLUMN IF NOT EXISTS plan_expires_at TIMESTAMPTZ,
+ADD CONSTRAINT user_plan_expiry CHECK ((plan_id IS NULL) = (plan_expires_at IS NULL));
+
+-- Vouchers are bearer codes: only a hash of the code is stored and the row is
+-- deleted when redeemed, so nothing ties a voucher to the account using it.
+CREATE TABLE IF NOT EXISTS vouchers (
+    code_hash BYTEA NOT NULL PRIMARY KEY,
+    plan_id TEXT NOT NULL REFERENCES plans (id) ON DELETE CASCADE,
+    duration_days INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT voucher_duration_positive CHECK (duration_days > 0)
+);
\ No newline at end of file
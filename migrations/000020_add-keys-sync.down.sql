@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS keys_tombstone ON keys;
+
+DROP TRIGGER IF EXISTS keys_change_seq ON keys;
+
+DROP FUNCTION IF EXISTS record_key_change ();
+
+DROP TABLE IF EXISTS key_tombstones;
+
+DROP INDEX IF EXISTS idx_keys_user_change_seq;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS change_seq;
+
+ALTER TABLE users DROP COLUMN IF EXISTS sync_floor;
+
+ALTER TABLE users DROP COLUMN IF EXISTS change_seq;
\ No newline at end of file
@@ -0,0 +1,80 @@
+-- Every change to a personal entry takes the next number of its owner's
+-- change sequence, so clients can ask for what changed after the last number
+-- they saw. Deletes leave a tombstone until the retention period is over;
+-- sync_floor is the highest sequence number whose tombstone was purged, and
+-- cursors below it can no longer be served.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS sync_floor BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE keys ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_keys_user_change_seq ON keys (user_id, change_seq)
+WHERE
+    vault_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS key_tombstones (
+    key_id UUID NOT NULL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    change_seq BIGINT NOT NULL,
+    deleted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_key_tombstones_user_change_seq ON key_tombstones (user_id, change_seq);
+
+CREATE INDEX IF NOT EXISTS idx_key_tombstones_deleted_at ON key_tombstones (deleted_at);
+
+WITH numbered AS (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at, id) AS seq
+    FROM keys
+    WHERE vault_id IS NULL
+)
+UPDATE keys k
+SET change_seq = n.seq
+FROM numbered n
+WHERE k.id = n.id;
+
+UPDATE users u
+SET change_seq = c.seq
+FROM (
+        SELECT user_id, MAX(change_seq) AS seq
+        FROM keys
+        GROUP BY user_id
+    ) c
+WHERE u.id = c.user_id;
+
+-- A deleted user takes their entries along; their row is gone by the time
+-- the entries are, and no tombstone is left.
+CREATE OR REPLACE FUNCTION record_key_change () RETURNS TRIGGER AS $$
+DECLARE
+    seq BIGINT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE users SET change_seq = change_seq + 1 WHERE id = OLD.user_id RETURNING change_seq INTO seq;
+        IF FOUND THEN
+            INSERT INTO key_tombstones (key_id, user_id, change_seq)
+            VALUES (OLD.id, OLD.user_id, seq)
+            ON CONFLICT (key_id) DO UPDATE
+            SET user_id = EXCLUDED.user_id, change_seq = EXCLUDED.change_seq, deleted_at = CURRENT_TIMESTAMP;
+        END IF;
+        RETURN NULL;
+    END IF;
+
+    UPDATE users SET change_seq = change_seq + 1 WHERE id = NEW.user_id RETURNING change_seq INTO NEW.change_seq;
+    DELETE FROM key_tombstones WHERE key_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Moving a payload between Postgres and the blob store is not a change.
+CREATE TRIGGER keys_change_seq
+BEFORE INSERT OR UPDATE OF encrypted_key, key_iv, data_iv, expires_at ON keys
+FOR EACH ROW
+WHEN (NEW.vault_id IS NULL)
+EXECUTE FUNCTION record_key_change ();
+
+CREATE TRIGGER keys_tombstone
+AFTER DELETE ON keys
+FOR EACH ROW
+WHEN (OLD.vault_id IS NULL)
+EXECUTE FUNCTION record_key_change ();
\ No newline at end of file
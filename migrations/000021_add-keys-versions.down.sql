@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS keys_change_seq ON keys;
+
+CREATE TRIGGER keys_change_seq
+BEFORE INSERT OR UPDATE OF encrypted_key, key_iv, data_iv, expires_at ON keys
+FOR EACH ROW
+WHEN (NEW.vault_id IS NULL)
+EXECUTE FUNCTION record_key_change ();
+
+DROP TABLE IF EXISTS key_conflicts;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS conflicts;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS version_vector;
\ No newline at end of file
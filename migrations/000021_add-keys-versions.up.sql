@@ -0,0 +1,52 @@
+-- Every entry carries a version vector: the number of writes each device
+-- made to it. A write based on a vector that does not cover the stored one
+-- is concurrent with it and is kept as a conflict sibling of the entry until
+-- a client writes a version that covers them both. conflicts counts the
+-- siblings of an entry.
+ALTER TABLE keys
+ADD COLUMN IF NOT EXISTS version_vector JSONB NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS conflicts INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS key_conflicts (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    key_id UUID NOT NULL REFERENCES keys (id) ON DELETE CASCADE,
+    device_id TEXT NOT NULL,
+    version_vector JSONB NOT NULL,
+    encrypted_key BYTEA NOT NULL,
+    key_iv BYTEA NOT NULL,
+    encrypted_data BYTEA,
+    data_iv BYTEA NOT NULL,
+    data_ref TEXT,
+    data_size BIGINT,
+    data_checksum BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT key_conflicts_data_location CHECK (
+        (
+            encrypted_data IS NOT NULL
+            AND data_ref IS NULL
+        )
+        OR (
+            encrypted_data IS NULL
+            AND data_ref IS NOT NULL
+            AND data_size IS NOT NULL
+            AND data_checksum IS NOT NULL
+        )
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_key_conflicts_key_id ON key_conflicts (key_id);
+
+CREATE TRIGGER key_conflicts_data_blob_garbage
+AFTER DELETE ON key_conflicts
+FOR EACH ROW
+WHEN (OLD.data_ref IS NOT NULL)
+EXECUTE FUNCTION queue_key_data_garbage ();
+
+-- A new sibling is a change for delta sync.
+DROP TRIGGER IF EXISTS keys_change_seq ON keys;
+
+CREATE TRIGGER keys_change_seq
+BEFORE INSERT OR UPDATE OF encrypted_key, key_iv, data_iv, expires_at, conflicts ON keys
+FOR EACH ROW
+WHEN (NEW.vault_id IS NULL)
+EXECUTE FUNCTION record_key_change ();
\ No newline at end of file
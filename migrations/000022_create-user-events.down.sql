@@ -0,0 +1,21 @@
+DROP TRIGGER IF EXISTS users_session_event ON users;
+
+DROP TRIGGER IF EXISTS key_shares_event ON key_shares;
+
+DROP TRIGGER IF EXISTS keys_delete_event ON keys;
+
+DROP TRIGGER IF EXISTS keys_event ON keys;
+
+DROP FUNCTION IF EXISTS record_session_event ();
+
+DROP FUNCTION IF EXISTS record_share_event ();
+
+DROP FUNCTION IF EXISTS record_key_event ();
+
+DROP FUNCTION IF EXISTS record_user_event (BIGINT, TEXT, TEXT);
+
+DROP TABLE IF EXISTS user_events;
+
+ALTER TABLE users DROP COLUMN IF EXISTS event_floor;
+
+ALTER TABLE users DROP COLUMN IF EXISTS event_seq;
\ No newline at end of file
@@ -0,0 +1,97 @@
+-- Events pushed to the connected clients of an account. seq numbers the
+-- events of each account; it is taken under the lock of the account row, so
+-- the events of an account commit in seq order and a client resumes from the
+-- last one it received. event_floor is raised past the events purged after
+-- the retention period.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS event_seq BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS event_floor BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS user_events (
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    subject TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_events_created_at ON user_events (created_at);
+
+-- Nothing is recorded for an account that is being deleted.
+CREATE OR REPLACE FUNCTION record_user_event (target BIGINT, event_type TEXT, event_subject TEXT)
+RETURNS VOID AS $$
+BEGIN
+    WITH bumped AS (
+        UPDATE users
+        SET event_seq = event_seq + 1
+        WHERE id = target
+        RETURNING id, event_seq
+    )
+    INSERT INTO user_events (user_id, seq, type, subject)
+    SELECT id, event_seq, event_type, event_subject
+    FROM bumped;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Personal entries only; a change is what delta sync reports as one.
+CREATE OR REPLACE FUNCTION record_key_event ()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM record_user_event (OLD.user_id, 'key.deleted', OLD.id::TEXT);
+        RETURN OLD;
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        PERFORM record_user_event (NEW.user_id, 'key.created', NEW.id::TEXT);
+    ELSIF NEW.change_seq IS DISTINCT FROM OLD.change_seq THEN
+        PERFORM record_user_event (NEW.user_id, 'key.updated', NEW.id::TEXT);
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER keys_event
+AFTER INSERT OR UPDATE ON keys
+FOR EACH ROW
+WHEN (NEW.vault_id IS NULL)
+EXECUTE FUNCTION record_key_event ();
+
+CREATE TRIGGER keys_delete_event
+AFTER DELETE ON keys
+FOR EACH ROW
+WHEN (OLD.vault_id IS NULL)
+EXECUTE FUNCTION record_key_event ();
+
+-- Shares are received when created and when a release re-wraps them.
+CREATE OR REPLACE FUNCTION record_share_event ()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM record_user_event (NEW.recipient_id, 'share.received', NEW.id::TEXT);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER key_shares_event
+AFTER INSERT OR UPDATE OF encrypted_key ON key_shares
+FOR EACH ROW
+EXECUTE FUNCTION record_share_event ();
+
+-- Changing the password ends the sessions opened with the old one.
+CREATE OR REPLACE FUNCTION record_session_event ()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM record_user_event (NEW.id, 'session.revoked', NULL);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_session_event
+AFTER UPDATE OF password ON users
+FOR EACH ROW
+WHEN (OLD.password IS DISTINCT FROM NEW.password)
+EXECUTE FUNCTION record_session_event ();
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS key_shares_delete_event ON key_shares;
+
+DROP TRIGGER IF EXISTS key_shares_status_event ON key_shares;
+
+DROP FUNCTION IF EXISTS record_share_change_event ();
\ No newline at end of file
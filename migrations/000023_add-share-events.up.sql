@@ -0,0 +1,26 @@
+-- Owners hear about the answers to their shares, and recipients about the
+-- shares they lose, including along with a deleted entry.
+CREATE OR REPLACE FUNCTION record_share_change_event ()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM record_user_event (OLD.recipient_id, 'share.revoked', OLD.id::TEXT);
+        RETURN OLD;
+    END IF;
+
+    PERFORM record_user_event (NEW.owner_id, 'share.updated', NEW.id::TEXT);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER key_shares_status_event
+AFTER UPDATE OF status ON key_shares
+FOR EACH ROW
+WHEN (OLD.status IS DISTINCT FROM NEW.status AND NEW.status <> 'pending')
+EXECUTE FUNCTION record_share_change_event ();
+
+CREATE TRIGGER key_shares_delete_event
+AFTER DELETE ON key_shares
+FOR EACH ROW
+EXECUTE FUNCTION record_share_change_event ();
\ No newline at end of file
@@ -0,0 +1,39 @@
+DROP TRIGGER IF EXISTS vault_members_removed_outbox ON vault_members;
+
+DROP TRIGGER IF EXISTS vault_members_key_outbox ON vault_members;
+
+DROP TRIGGER IF EXISTS vault_members_added_outbox ON vault_members;
+
+DROP TRIGGER IF EXISTS key_releases_scheduled_outbox ON key_releases;
+
+DROP TRIGGER IF EXISTS key_shares_deleted_outbox ON key_shares;
+
+DROP TRIGGER IF EXISTS key_shares_updated_outbox ON key_shares;
+
+DROP TRIGGER IF EXISTS key_shares_created_outbox ON key_shares;
+
+DROP TRIGGER IF EXISTS keys_deleted_outbox ON keys;
+
+DROP TRIGGER IF EXISTS keys_updated_outbox ON keys;
+
+DROP FUNCTION IF EXISTS record_vault_key_changed ();
+
+DROP FUNCTION IF EXISTS record_release_scheduled ();
+
+DROP FUNCTION IF EXISTS record_share_changed ();
+
+DROP FUNCTION IF EXISTS record_key_changed ();
+
+DROP TRIGGER IF EXISTS keys_added_outbox ON keys;
+
+DROP TRIGGER IF EXISTS users_password_outbox ON users;
+
+DROP TRIGGER IF EXISTS users_created_outbox ON users;
+
+DROP FUNCTION IF EXISTS record_key_added ();
+
+DROP FUNCTION IF EXISTS record_password_changed ();
+
+DROP FUNCTION IF EXISTS record_user_created ();
+
+DROP TABLE IF EXISTS outbox;
\ No newline at end of file
@@ -0,0 +1,202 @@
+-- Domain events for downstream systems, written by triggers in the
+-- transaction of the change they describe so that none is lost or sent for a
+-- change that rolled back. The dispatcher delivers them at least once:
+-- next_attempt_at is pushed forward while a delivery is in flight and by the
+-- backoff after a failure; dead_at is set once the attempts run out. Rows do
+-- not reference users so events outlive the account they are about.
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    delivered_at TIMESTAMP,
+    dead_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_next_attempt_at ON outbox (next_attempt_at)
+WHERE
+    delivered_at IS NULL
+    AND dead_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_delivered_at ON outbox (delivered_at)
+WHERE
+    delivered_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION record_user_created ()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO outbox (type, payload)
+    VALUES ('user.created', jsonb_build_object('user_id', NEW.id, 'user_address', NEW.user_address));
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_created_outbox
+AFTER INSERT ON users
+FOR EACH ROW
+EXECUTE FUNCTION record_user_created ();
+
+CREATE OR REPLACE FUNCTION record_password_changed ()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO outbox (type, payload)
+    VALUES ('user.password_changed', jsonb_build_object('user_id', NEW.id));
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_password_outbox
+AFTER UPDATE OF password ON users
+FOR EACH ROW
+WHEN (OLD.password IS DISTINCT FROM NEW.password)
+EXECUTE FUNCTION record_password_changed ();
+
+-- Personal and vault entries alike; vault_id is null for personal ones.
+CREATE OR REPLACE FUNCTION record_key_added ()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO outbox (type, payload)
+    VALUES ('key.added', jsonb_build_object('key_id', NEW.id, 'user_id', NEW.user_id, 'vault_id', NEW.vault_id));
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER keys_added_outbox
+AFTER INSERT ON keys
+FOR EACH ROW
+EXECUTE FUNCTION record_key_added ();
+
+-- Entry changes after creation. Moving a payload between Postgres and the
+-- blob store is not a change.
+CREATE OR REPLACE FUNCTION record_key_changed ()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO outbox (type, payload)
+        VALUES ('key.deleted', jsonb_build_object('key_id', OLD.id, 'user_id', OLD.user_id, 'vault_id', OLD.vault_id));
+
+        RETURN OLD;
+    END IF;
+
+    INSERT INTO outbox (type, payload)
+    VALUES ('key.updated', jsonb_build_object('key_id', NEW.id, 'user_id', NEW.user_id, 'vault_id', NEW.vault_id));
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER keys_updated_outbox
+AFTER UPDATE OF encrypted_key, key_iv, data_iv, expires_at ON keys
+FOR EACH ROW
+EXECUTE FUNCTION record_key_changed ();
+
+CREATE TRIGGER keys_deleted_outbox
+AFTER DELETE ON keys
+FOR EACH ROW
+EXECUTE FUNCTION record_key_changed ();
+
+-- Shares, including the ones due releases turn into. Answers of the
+-- recipient and re-wrapped keys are updates.
+CREATE OR REPLACE FUNCTION record_share_changed ()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed key_shares;
+    event_type TEXT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed := OLD;
+        event_type := 'share.revoked';
+    ELSIF TG_OP = 'INSERT' THEN
+        changed := NEW;
+        event_type := 'share.created';
+    ELSE
+        changed := NEW;
+        event_type := 'share.updated';
+    END IF;
+
+    INSERT INTO outbox (type, payload)
+    VALUES (event_type, jsonb_build_object('share_id', changed.id, 'key_id', changed.key_id, 'owner_id', changed.owner_id,
+        'recipient_id', changed.recipient_id, 'permission', changed.permission, 'status', changed.status));
+
+    RETURN changed;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER key_shares_created_outbox
+AFTER INSERT ON key_shares
+FOR EACH ROW
+EXECUTE FUNCTION record_share_changed ();
+
+CREATE TRIGGER key_shares_updated_outbox
+AFTER UPDATE OF encrypted_key, key_iv, permission, status ON key_shares
+FOR EACH ROW
+EXECUTE FUNCTION record_share_changed ();
+
+CREATE TRIGGER key_shares_deleted_outbox
+AFTER DELETE ON key_shares
+FOR EACH ROW
+EXECUTE FUNCTION record_share_changed ();
+
+CREATE OR REPLACE FUNCTION record_release_scheduled ()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO outbox (type, payload)
+    VALUES ('release.scheduled', jsonb_build_object('release_id', NEW.id, 'key_id', NEW.key_id, 'owner_id', NEW.owner_id,
+        'recipient_id', NEW.recipient_id, 'release_at', NEW.release_at));
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER key_releases_scheduled_outbox
+AFTER INSERT ON key_releases
+FOR EACH ROW
+EXECUTE FUNCTION record_release_scheduled ();
+
+-- Vault keys: a member gets a copy when invited, a new one on rotation and
+-- loses it when removed.
+CREATE OR REPLACE FUNCTION record_vault_key_changed ()
+RETURNS TRIGGER AS $$
+DECLARE
+    membership vault_members;
+    event_type TEXT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        membership := OLD;
+        event_type := 'vault.member_removed';
+    ELSIF TG_OP = 'INSERT' THEN
+        membership := NEW;
+        event_type := 'vault.member_added';
+    ELSE
+        membership := NEW;
+        event_type := 'vault.key_changed';
+    END IF;
+
+    INSERT INTO outbox (type, payload)
+    VALUES (event_type, jsonb_build_object('vault_id', membership.vault_id, 'user_id', membership.user_id,
+        'key_version', membership.key_version));
+
+    RETURN membership;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER vault_members_added_outbox
+AFTER INSERT ON vault_members
+FOR EACH ROW
+EXECUTE FUNCTION record_vault_key_changed ();
+
+CREATE TRIGGER vault_members_key_outbox
+AFTER UPDATE OF encrypted_vault_key, key_version ON vault_members
+FOR EACH ROW
+EXECUTE FUNCTION record_vault_key_changed ();
+
+CREATE TRIGGER vault_members_removed_outbox
+AFTER DELETE ON vault_members
+FOR EACH ROW
+EXECUTE FUNCTION record_vault_key_changed ();
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS keys_vault_delete_event ON keys;
+
+DROP TRIGGER IF EXISTS keys_vault_event ON keys;
+
+DROP FUNCTION IF EXISTS record_vault_key_event ();
\ No newline at end of file
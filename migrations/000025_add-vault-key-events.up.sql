@@ -0,0 +1,42 @@
+-- Vault entries are heard about by every accepted member of their vault.
+CREATE OR REPLACE FUNCTION record_vault_key_event ()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed keys;
+    event_type TEXT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed := OLD;
+        event_type := 'key.deleted';
+    ELSIF TG_OP = 'INSERT' THEN
+        changed := NEW;
+        event_type := 'key.created';
+    ELSIF NEW.change_seq IS DISTINCT FROM OLD.change_seq THEN
+        changed := NEW;
+        event_type := 'key.updated';
+    ELSE
+        RETURN NEW;
+    END IF;
+
+    PERFORM record_user_event (m.user_id, event_type, changed.id::TEXT)
+    FROM (
+        SELECT user_id FROM vault_members
+        WHERE vault_id = changed.vault_id AND status = 'accepted'
+        ORDER BY user_id
+    ) m;
+
+    RETURN changed;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER keys_vault_event
+AFTER INSERT OR UPDATE ON keys
+FOR EACH ROW
+WHEN (NEW.vault_id IS NOT NULL)
+EXECUTE FUNCTION record_vault_key_event ();
+
+CREATE TRIGGER keys_vault_delete_event
+AFTER DELETE ON keys
+FOR EACH ROW
+WHEN (OLD.vault_id IS NOT NULL)
+EXECUTE FUNCTION record_vault_key_event ();
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE emergency_contacts DROP COLUMN IF EXISTS inactivity_requested;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Requests opened by inactivity are withdrawn when the owner shows up again;
+-- the ones a contact asked for stay until the owner rejects them.
+ALTER TABLE emergency_contacts
+ADD COLUMN IF NOT EXISTS inactivity_requested BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
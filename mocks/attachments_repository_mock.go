@@ -0,0 +1,205 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/attachments/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/attachments/repository/repository.go -destination=./mocks/attachments_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	dto "github.com/ObscuraNote/api-general/internal/attachments/dto"
+	repository "github.com/ObscuraNote/api-general/internal/attachments/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAttachmentsRepository is a mock of AttachmentsRepository interface.
+type MockAttachmentsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAttachmentsRepositoryMockRecorder is the mock recorder for MockAttachmentsRepository.
+type MockAttachmentsRepositoryMockRecorder struct {
+	mock *MockAttachmentsRepository
+}
+
+// NewMockAttachmentsRepository creates a new mock instance.
+func NewMockAttachmentsRepository(ctrl *gomock.Controller) *MockAttachmentsRepository {
+	mock := &MockAttachmentsRepository{ctrl: ctrl}
+	mock.recorder = &MockAttachmentsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentsRepository) EXPECT() *MockAttachmentsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CollectGarbage mocks base method.
+func (m *MockAttachmentsRepository) CollectGarbage(uploadTTL time.Duration, limit int, remove func(string) error) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollectGarbage", uploadTTL, limit, remove)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CollectGarbage indicates an expected call of CollectGarbage.
+func (mr *MockAttachmentsRepositoryMockRecorder) CollectGarbage(uploadTTL, limit, remove any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectGarbage", reflect.TypeOf((*MockAttachmentsRepository)(nil).CollectGarbage), uploadTTL, limit, remove)
+}
+
+// CompleteAttachment mocks base method.
+func (m *MockAttachmentsRepository) CompleteAttachment(id string, chunkCount int) (*dto.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteAttachment", id, chunkCount)
+	ret0, _ := ret[0].(*dto.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteAttachment indicates an expected call of CompleteAttachment.
+func (mr *MockAttachmentsRepositoryMockRecorder) CompleteAttachment(id, chunkCount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteAttachment", reflect.TypeOf((*MockAttachmentsRepository)(nil).CompleteAttachment), id, chunkCount)
+}
+
+// CreateAttachment mocks base method.
+func (m *MockAttachmentsRepository) CreateAttachment(uploaderId int64, keyId string, input dto.AttachmentInput) (*dto.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAttachment", uploaderId, keyId, input)
+	ret0, _ := ret[0].(*dto.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAttachment indicates an expected call of CreateAttachment.
+func (mr *MockAttachmentsRepositoryMockRecorder) CreateAttachment(uploaderId, keyId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAttachment", reflect.TypeOf((*MockAttachmentsRepository)(nil).CreateAttachment), uploaderId, keyId, input)
+}
+
+// DeleteAttachment mocks base method.
+func (m *MockAttachmentsRepository) DeleteAttachment(keyId, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAttachment", keyId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAttachment indicates an expected call of DeleteAttachment.
+func (mr *MockAttachmentsRepositoryMockRecorder) DeleteAttachment(keyId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachment", reflect.TypeOf((*MockAttachmentsRepository)(nil).DeleteAttachment), keyId, id)
+}
+
+// GetAttachment mocks base method.
+func (m *MockAttachmentsRepository) GetAttachment(keyId, id string) (*dto.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachment", keyId, id)
+	ret0, _ := ret[0].(*dto.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachment indicates an expected call of GetAttachment.
+func (mr *MockAttachmentsRepositoryMockRecorder) GetAttachment(keyId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachment", reflect.TypeOf((*MockAttachmentsRepository)(nil).GetAttachment), keyId, id)
+}
+
+// GetAttachments mocks base method.
+func (m *MockAttachmentsRepository) GetAttachments(keyId string) ([]dto.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachments", keyId)
+	ret0, _ := ret[0].([]dto.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachments indicates an expected call of GetAttachments.
+func (mr *MockAttachmentsRepositoryMockRecorder) GetAttachments(keyId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachments", reflect.TypeOf((*MockAttachmentsRepository)(nil).GetAttachments), keyId)
+}
+
+// GetChunks mocks base method.
+func (m *MockAttachmentsRepository) GetChunks(id string) ([]dto.Chunk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChunks", id)
+	ret0, _ := ret[0].([]dto.Chunk)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChunks indicates an expected call of GetChunks.
+func (mr *MockAttachmentsRepositoryMockRecorder) GetChunks(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChunks", reflect.TypeOf((*MockAttachmentsRepository)(nil).GetChunks), id)
+}
+
+// PutChunk mocks base method.
+func (m *MockAttachmentsRepository) PutChunk(id string, index int, size int64, blobKey string, quota repository.QuotaFunc) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutChunk", id, index, size, blobKey, quota)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutChunk indicates an expected call of PutChunk.
+func (mr *MockAttachmentsRepositoryMockRecorder) PutChunk(id, index, size, blobKey, quota any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutChunk", reflect.TypeOf((*MockAttachmentsRepository)(nil).PutChunk), id, index, size, blobKey, quota)
+}
+
+// Mockscanner is a mock of scanner interface.
+type Mockscanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockscannerMockRecorder
+	isgomock struct{}
+}
+
+// MockscannerMockRecorder is the mock recorder for Mockscanner.
+type MockscannerMockRecorder struct {
+	mock *Mockscanner
+}
+
+// NewMockscanner creates a new mock instance.
+func NewMockscanner(ctrl *gomock.Controller) *Mockscanner {
+	mock := &Mockscanner{ctrl: ctrl}
+	mock.recorder = &MockscannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockscanner) EXPECT() *MockscannerMockRecorder {
+	return m.recorder
+}
+
+// Scan mocks base method.
+func (m *Mockscanner) Scan(dest ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range dest {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scan", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockscannerMockRecorder) Scan(dest ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*Mockscanner)(nil).Scan), dest...)
+}
@@ -0,0 +1,174 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/attachments/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/attachments/service/service.go -destination=./mocks/attachments_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	io "io"
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/attachments/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAttachmentsService is a mock of AttachmentsService interface.
+type MockAttachmentsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentsServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAttachmentsServiceMockRecorder is the mock recorder for MockAttachmentsService.
+type MockAttachmentsServiceMockRecorder struct {
+	mock *MockAttachmentsService
+}
+
+// NewMockAttachmentsService creates a new mock instance.
+func NewMockAttachmentsService(ctrl *gomock.Controller) *MockAttachmentsService {
+	mock := &MockAttachmentsService{ctrl: ctrl}
+	mock.recorder = &MockAttachmentsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentsService) EXPECT() *MockAttachmentsServiceMockRecorder {
+	return m.recorder
+}
+
+// CollectGarbage mocks base method.
+func (m *MockAttachmentsService) CollectGarbage() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollectGarbage")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CollectGarbage indicates an expected call of CollectGarbage.
+func (mr *MockAttachmentsServiceMockRecorder) CollectGarbage() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectGarbage", reflect.TypeOf((*MockAttachmentsService)(nil).CollectGarbage))
+}
+
+// CompleteAttachment mocks base method.
+func (m *MockAttachmentsService) CompleteAttachment(keyId, id string, input dto.CompleteInput) (*dto.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteAttachment", keyId, id, input)
+	ret0, _ := ret[0].(*dto.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteAttachment indicates an expected call of CompleteAttachment.
+func (mr *MockAttachmentsServiceMockRecorder) CompleteAttachment(keyId, id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteAttachment", reflect.TypeOf((*MockAttachmentsService)(nil).CompleteAttachment), keyId, id, input)
+}
+
+// CreateAttachment mocks base method.
+func (m *MockAttachmentsService) CreateAttachment(keyId string, input dto.AttachmentInput) (*dto.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAttachment", keyId, input)
+	ret0, _ := ret[0].(*dto.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAttachment indicates an expected call of CreateAttachment.
+func (mr *MockAttachmentsServiceMockRecorder) CreateAttachment(keyId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAttachment", reflect.TypeOf((*MockAttachmentsService)(nil).CreateAttachment), keyId, input)
+}
+
+// DeleteAttachment mocks base method.
+func (m *MockAttachmentsService) DeleteAttachment(keyId, id string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAttachment", keyId, id, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAttachment indicates an expected call of DeleteAttachment.
+func (mr *MockAttachmentsServiceMockRecorder) DeleteAttachment(keyId, id, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachment", reflect.TypeOf((*MockAttachmentsService)(nil).DeleteAttachment), keyId, id, auth)
+}
+
+// GetAttachment mocks base method.
+func (m *MockAttachmentsService) GetAttachment(keyId, id string, auth dto.AuthInput) (*dto.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachment", keyId, id, auth)
+	ret0, _ := ret[0].(*dto.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachment indicates an expected call of GetAttachment.
+func (mr *MockAttachmentsServiceMockRecorder) GetAttachment(keyId, id, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachment", reflect.TypeOf((*MockAttachmentsService)(nil).GetAttachment), keyId, id, auth)
+}
+
+// GetAttachments mocks base method.
+func (m *MockAttachmentsService) GetAttachments(keyId string, auth dto.AuthInput) ([]dto.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachments", keyId, auth)
+	ret0, _ := ret[0].([]dto.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachments indicates an expected call of GetAttachments.
+func (mr *MockAttachmentsServiceMockRecorder) GetAttachments(keyId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachments", reflect.TypeOf((*MockAttachmentsService)(nil).GetAttachments), keyId, auth)
+}
+
+// OpenContent mocks base method.
+func (m *MockAttachmentsService) OpenContent(keyId, id string, auth dto.AuthInput) (*dto.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenContent", keyId, id, auth)
+	ret0, _ := ret[0].(*dto.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenContent indicates an expected call of OpenContent.
+func (mr *MockAttachmentsServiceMockRecorder) OpenContent(keyId, id, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenContent", reflect.TypeOf((*MockAttachmentsService)(nil).OpenContent), keyId, id, auth)
+}
+
+// ReadContent mocks base method.
+func (m *MockAttachmentsService) ReadContent(attachment *dto.Attachment, offset, length int64) io.ReadCloser {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadContent", attachment, offset, length)
+	ret0, _ := ret[0].(io.ReadCloser)
+	return ret0
+}
+
+// ReadContent indicates an expected call of ReadContent.
+func (mr *MockAttachmentsServiceMockRecorder) ReadContent(attachment, offset, length any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadContent", reflect.TypeOf((*MockAttachmentsService)(nil).ReadContent), attachment, offset, length)
+}
+
+// UploadChunk mocks base method.
+func (m *MockAttachmentsService) UploadChunk(keyId, id string, index int, size int64, body io.Reader, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadChunk", keyId, id, index, size, body, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadChunk indicates an expected call of UploadChunk.
+func (mr *MockAttachmentsServiceMockRecorder) UploadChunk(keyId, id, index, size, body, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadChunk", reflect.TypeOf((*MockAttachmentsService)(nil).UploadChunk), keyId, id, index, size, body, auth)
+}
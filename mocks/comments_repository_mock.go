@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/comments/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/comments/repository/repository.go -destination=./mocks/comments_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/comments/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCommentsRepository is a mock of CommentsRepository interface.
+type MockCommentsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCommentsRepositoryMockRecorder is the mock recorder for MockCommentsRepository.
+type MockCommentsRepositoryMockRecorder struct {
+	mock *MockCommentsRepository
+}
+
+// NewMockCommentsRepository creates a new mock instance.
+func NewMockCommentsRepository(ctrl *gomock.Controller) *MockCommentsRepository {
+	mock := &MockCommentsRepository{ctrl: ctrl}
+	mock.recorder = &MockCommentsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentsRepository) EXPECT() *MockCommentsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddComment mocks base method.
+func (m *MockCommentsRepository) AddComment(authorId int64, keyId string, comment dto.CommentInput) (*dto.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddComment", authorId, keyId, comment)
+	ret0, _ := ret[0].(*dto.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddComment indicates an expected call of AddComment.
+func (mr *MockCommentsRepositoryMockRecorder) AddComment(authorId, keyId, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddComment", reflect.TypeOf((*MockCommentsRepository)(nil).AddComment), authorId, keyId, comment)
+}
+
+// DeleteAnyComment mocks base method.
+func (m *MockCommentsRepository) DeleteAnyComment(keyId, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAnyComment", keyId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAnyComment indicates an expected call of DeleteAnyComment.
+func (mr *MockCommentsRepositoryMockRecorder) DeleteAnyComment(keyId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAnyComment", reflect.TypeOf((*MockCommentsRepository)(nil).DeleteAnyComment), keyId, id)
+}
+
+// DeleteComment mocks base method.
+func (m *MockCommentsRepository) DeleteComment(authorId int64, keyId, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", authorId, keyId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockCommentsRepositoryMockRecorder) DeleteComment(authorId, keyId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockCommentsRepository)(nil).DeleteComment), authorId, keyId, id)
+}
+
+// GetComments mocks base method.
+func (m *MockCommentsRepository) GetComments(keyId string, afterSeq, limit int64) ([]dto.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComments", keyId, afterSeq, limit)
+	ret0, _ := ret[0].([]dto.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComments indicates an expected call of GetComments.
+func (mr *MockCommentsRepositoryMockRecorder) GetComments(keyId, afterSeq, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComments", reflect.TypeOf((*MockCommentsRepository)(nil).GetComments), keyId, afterSeq, limit)
+}
+
+// UpdateComment mocks base method.
+func (m *MockCommentsRepository) UpdateComment(authorId int64, keyId, id string, comment dto.CommentInput) (*dto.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComment", authorId, keyId, id, comment)
+	ret0, _ := ret[0].(*dto.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateComment indicates an expected call of UpdateComment.
+func (mr *MockCommentsRepositoryMockRecorder) UpdateComment(authorId, keyId, id, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockCommentsRepository)(nil).UpdateComment), authorId, keyId, id, comment)
+}
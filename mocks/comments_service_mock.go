@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/comments/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/comments/service/service.go -destination=./mocks/comments_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/comments/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCommentsService is a mock of CommentsService interface.
+type MockCommentsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentsServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockCommentsServiceMockRecorder is the mock recorder for MockCommentsService.
+type MockCommentsServiceMockRecorder struct {
+	mock *MockCommentsService
+}
+
+// NewMockCommentsService creates a new mock instance.
+func NewMockCommentsService(ctrl *gomock.Controller) *MockCommentsService {
+	mock := &MockCommentsService{ctrl: ctrl}
+	mock.recorder = &MockCommentsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentsService) EXPECT() *MockCommentsServiceMockRecorder {
+	return m.recorder
+}
+
+// AddComment mocks base method.
+func (m *MockCommentsService) AddComment(keyId string, comment dto.CommentInput) (*dto.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddComment", keyId, comment)
+	ret0, _ := ret[0].(*dto.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddComment indicates an expected call of AddComment.
+func (mr *MockCommentsServiceMockRecorder) AddComment(keyId, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddComment", reflect.TypeOf((*MockCommentsService)(nil).AddComment), keyId, comment)
+}
+
+// DeleteComment mocks base method.
+func (m *MockCommentsService) DeleteComment(keyId, commentId string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", keyId, commentId, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockCommentsServiceMockRecorder) DeleteComment(keyId, commentId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockCommentsService)(nil).DeleteComment), keyId, commentId, auth)
+}
+
+// GetComments mocks base method.
+func (m *MockCommentsService) GetComments(keyId string, cursor, limit int64, auth dto.AuthInput) (*dto.CommentPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComments", keyId, cursor, limit, auth)
+	ret0, _ := ret[0].(*dto.CommentPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComments indicates an expected call of GetComments.
+func (mr *MockCommentsServiceMockRecorder) GetComments(keyId, cursor, limit, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComments", reflect.TypeOf((*MockCommentsService)(nil).GetComments), keyId, cursor, limit, auth)
+}
+
+// UpdateComment mocks base method.
+func (m *MockCommentsService) UpdateComment(keyId, commentId string, comment dto.CommentInput) (*dto.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComment", keyId, commentId, comment)
+	ret0, _ := ret[0].(*dto.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateComment indicates an expected call of UpdateComment.
+func (mr *MockCommentsServiceMockRecorder) UpdateComment(keyId, commentId, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockCommentsService)(nil).UpdateComment), keyId, commentId, comment)
+}
@@ -0,0 +1,176 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/dropbox/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/dropbox/repository/repository.go -destination=./mocks/dropbox_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	dto "github.com/ObscuraNote/api-general/internal/dropbox/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDropboxRepository is a mock of DropboxRepository interface.
+type MockDropboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDropboxRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockDropboxRepositoryMockRecorder is the mock recorder for MockDropboxRepository.
+type MockDropboxRepositoryMockRecorder struct {
+	mock *MockDropboxRepository
+}
+
+// NewMockDropboxRepository creates a new mock instance.
+func NewMockDropboxRepository(ctrl *gomock.Controller) *MockDropboxRepository {
+	mock := &MockDropboxRepository{ctrl: ctrl}
+	mock.recorder = &MockDropboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDropboxRepository) EXPECT() *MockDropboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddSubmission mocks base method.
+func (m *MockDropboxRepository) AddSubmission(dropboxId string, digest []byte, expiresAt time.Time, sealedBlob []byte, maxSubmissions int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSubmission", dropboxId, digest, expiresAt, sealedBlob, maxSubmissions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddSubmission indicates an expected call of AddSubmission.
+func (mr *MockDropboxRepositoryMockRecorder) AddSubmission(dropboxId, digest, expiresAt, sealedBlob, maxSubmissions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSubmission", reflect.TypeOf((*MockDropboxRepository)(nil).AddSubmission), dropboxId, digest, expiresAt, sealedBlob, maxSubmissions)
+}
+
+// CreateDropbox mocks base method.
+func (m *MockDropboxRepository) CreateDropbox(userId int64, id string, encryptedLabel []byte) (*dto.Dropbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDropbox", userId, id, encryptedLabel)
+	ret0, _ := ret[0].(*dto.Dropbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDropbox indicates an expected call of CreateDropbox.
+func (mr *MockDropboxRepositoryMockRecorder) CreateDropbox(userId, id, encryptedLabel any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDropbox", reflect.TypeOf((*MockDropboxRepository)(nil).CreateDropbox), userId, id, encryptedLabel)
+}
+
+// DeleteDropbox mocks base method.
+func (m *MockDropboxRepository) DeleteDropbox(userId int64, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDropbox", userId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteDropbox indicates an expected call of DeleteDropbox.
+func (mr *MockDropboxRepositoryMockRecorder) DeleteDropbox(userId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDropbox", reflect.TypeOf((*MockDropboxRepository)(nil).DeleteDropbox), userId, id)
+}
+
+// DeleteExpiredChallenges mocks base method.
+func (m *MockDropboxRepository) DeleteExpiredChallenges() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredChallenges")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredChallenges indicates an expected call of DeleteExpiredChallenges.
+func (mr *MockDropboxRepositoryMockRecorder) DeleteExpiredChallenges() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredChallenges", reflect.TypeOf((*MockDropboxRepository)(nil).DeleteExpiredChallenges))
+}
+
+// DeleteSubmission mocks base method.
+func (m *MockDropboxRepository) DeleteSubmission(userId int64, dropboxId, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubmission", userId, dropboxId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSubmission indicates an expected call of DeleteSubmission.
+func (mr *MockDropboxRepositoryMockRecorder) DeleteSubmission(userId, dropboxId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubmission", reflect.TypeOf((*MockDropboxRepository)(nil).DeleteSubmission), userId, dropboxId, id)
+}
+
+// GetDropboxInfo mocks base method.
+func (m *MockDropboxRepository) GetDropboxInfo(id string) (*dto.DropboxInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropboxInfo", id)
+	ret0, _ := ret[0].(*dto.DropboxInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropboxInfo indicates an expected call of GetDropboxInfo.
+func (mr *MockDropboxRepositoryMockRecorder) GetDropboxInfo(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropboxInfo", reflect.TypeOf((*MockDropboxRepository)(nil).GetDropboxInfo), id)
+}
+
+// GetDropboxesByUser mocks base method.
+func (m *MockDropboxRepository) GetDropboxesByUser(userId int64) ([]dto.Dropbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropboxesByUser", userId)
+	ret0, _ := ret[0].([]dto.Dropbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropboxesByUser indicates an expected call of GetDropboxesByUser.
+func (mr *MockDropboxRepositoryMockRecorder) GetDropboxesByUser(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropboxesByUser", reflect.TypeOf((*MockDropboxRepository)(nil).GetDropboxesByUser), userId)
+}
+
+// GetSubmission mocks base method.
+func (m *MockDropboxRepository) GetSubmission(userId int64, dropboxId, id string) (*dto.Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubmission", userId, dropboxId, id)
+	ret0, _ := ret[0].(*dto.Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubmission indicates an expected call of GetSubmission.
+func (mr *MockDropboxRepositoryMockRecorder) GetSubmission(userId, dropboxId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmission", reflect.TypeOf((*MockDropboxRepository)(nil).GetSubmission), userId, dropboxId, id)
+}
+
+// GetSubmissions mocks base method.
+func (m *MockDropboxRepository) GetSubmissions(userId int64, dropboxId string) ([]dto.Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubmissions", userId, dropboxId)
+	ret0, _ := ret[0].([]dto.Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubmissions indicates an expected call of GetSubmissions.
+func (mr *MockDropboxRepositoryMockRecorder) GetSubmissions(userId, dropboxId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmissions", reflect.TypeOf((*MockDropboxRepository)(nil).GetSubmissions), userId, dropboxId)
+}
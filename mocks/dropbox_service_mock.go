@@ -0,0 +1,173 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/dropbox/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/dropbox/service/service.go -destination=./mocks/dropbox_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/dropbox/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDropboxService is a mock of DropboxService interface.
+type MockDropboxService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDropboxServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockDropboxServiceMockRecorder is the mock recorder for MockDropboxService.
+type MockDropboxServiceMockRecorder struct {
+	mock *MockDropboxService
+}
+
+// NewMockDropboxService creates a new mock instance.
+func NewMockDropboxService(ctrl *gomock.Controller) *MockDropboxService {
+	mock := &MockDropboxService{ctrl: ctrl}
+	mock.recorder = &MockDropboxServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDropboxService) EXPECT() *MockDropboxServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateDropbox mocks base method.
+func (m *MockDropboxService) CreateDropbox(input dto.DropboxInput) (*dto.Dropbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDropbox", input)
+	ret0, _ := ret[0].(*dto.Dropbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDropbox indicates an expected call of CreateDropbox.
+func (mr *MockDropboxServiceMockRecorder) CreateDropbox(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDropbox", reflect.TypeOf((*MockDropboxService)(nil).CreateDropbox), input)
+}
+
+// DeleteDropbox mocks base method.
+func (m *MockDropboxService) DeleteDropbox(id string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDropbox", id, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDropbox indicates an expected call of DeleteDropbox.
+func (mr *MockDropboxServiceMockRecorder) DeleteDropbox(id, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDropbox", reflect.TypeOf((*MockDropboxService)(nil).DeleteDropbox), id, auth)
+}
+
+// DeleteSubmission mocks base method.
+func (m *MockDropboxService) DeleteSubmission(id, submissionId string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubmission", id, submissionId, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubmission indicates an expected call of DeleteSubmission.
+func (mr *MockDropboxServiceMockRecorder) DeleteSubmission(id, submissionId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubmission", reflect.TypeOf((*MockDropboxService)(nil).DeleteSubmission), id, submissionId, auth)
+}
+
+// GetChallenge mocks base method.
+func (m *MockDropboxService) GetChallenge(id string) (*dto.Challenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChallenge", id)
+	ret0, _ := ret[0].(*dto.Challenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChallenge indicates an expected call of GetChallenge.
+func (mr *MockDropboxServiceMockRecorder) GetChallenge(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChallenge", reflect.TypeOf((*MockDropboxService)(nil).GetChallenge), id)
+}
+
+// GetDropboxInfo mocks base method.
+func (m *MockDropboxService) GetDropboxInfo(id string) (*dto.DropboxInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropboxInfo", id)
+	ret0, _ := ret[0].(*dto.DropboxInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropboxInfo indicates an expected call of GetDropboxInfo.
+func (mr *MockDropboxServiceMockRecorder) GetDropboxInfo(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropboxInfo", reflect.TypeOf((*MockDropboxService)(nil).GetDropboxInfo), id)
+}
+
+// GetDropboxes mocks base method.
+func (m *MockDropboxService) GetDropboxes(auth dto.AuthInput) ([]dto.Dropbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropboxes", auth)
+	ret0, _ := ret[0].([]dto.Dropbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropboxes indicates an expected call of GetDropboxes.
+func (mr *MockDropboxServiceMockRecorder) GetDropboxes(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropboxes", reflect.TypeOf((*MockDropboxService)(nil).GetDropboxes), auth)
+}
+
+// GetSubmission mocks base method.
+func (m *MockDropboxService) GetSubmission(id, submissionId string, auth dto.AuthInput) (*dto.Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubmission", id, submissionId, auth)
+	ret0, _ := ret[0].(*dto.Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubmission indicates an expected call of GetSubmission.
+func (mr *MockDropboxServiceMockRecorder) GetSubmission(id, submissionId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmission", reflect.TypeOf((*MockDropboxService)(nil).GetSubmission), id, submissionId, auth)
+}
+
+// GetSubmissions mocks base method.
+func (m *MockDropboxService) GetSubmissions(id string, auth dto.AuthInput) ([]dto.Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubmissions", id, auth)
+	ret0, _ := ret[0].([]dto.Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubmissions indicates an expected call of GetSubmissions.
+func (mr *MockDropboxServiceMockRecorder) GetSubmissions(id, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmissions", reflect.TypeOf((*MockDropboxService)(nil).GetSubmissions), id, auth)
+}
+
+// Submit mocks base method.
+func (m *MockDropboxService) Submit(id string, input dto.SubmissionInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Submit", id, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Submit indicates an expected call of Submit.
+func (mr *MockDropboxServiceMockRecorder) Submit(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Submit", reflect.TypeOf((*MockDropboxService)(nil).Submit), id, input)
+}
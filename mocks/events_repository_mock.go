@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/events/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/events/repository/repository.go -destination=./mocks/events_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/events/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEventsRepository is a mock of EventsRepository interface.
+type MockEventsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockEventsRepositoryMockRecorder is the mock recorder for MockEventsRepository.
+type MockEventsRepositoryMockRecorder struct {
+	mock *MockEventsRepository
+}
+
+// NewMockEventsRepository creates a new mock instance.
+func NewMockEventsRepository(ctrl *gomock.Controller) *MockEventsRepository {
+	mock := &MockEventsRepository{ctrl: ctrl}
+	mock.recorder = &MockEventsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventsRepository) EXPECT() *MockEventsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteOldEvents mocks base method.
+func (m *MockEventsRepository) DeleteOldEvents() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldEvents")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOldEvents indicates an expected call of DeleteOldEvents.
+func (mr *MockEventsRepositoryMockRecorder) DeleteOldEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldEvents", reflect.TypeOf((*MockEventsRepository)(nil).DeleteOldEvents))
+}
+
+// GetEventState mocks base method.
+func (m *MockEventsRepository) GetEventState(userId int64) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventState", userId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEventState indicates an expected call of GetEventState.
+func (mr *MockEventsRepositoryMockRecorder) GetEventState(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventState", reflect.TypeOf((*MockEventsRepository)(nil).GetEventState), userId)
+}
+
+// GetEvents mocks base method.
+func (m *MockEventsRepository) GetEvents(cursors map[int64]int64, limit int) ([]dto.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvents", cursors, limit)
+	ret0, _ := ret[0].([]dto.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvents indicates an expected call of GetEvents.
+func (mr *MockEventsRepositoryMockRecorder) GetEvents(cursors, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvents", reflect.TypeOf((*MockEventsRepository)(nil).GetEvents), cursors, limit)
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/events/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/events/service/service.go -destination=./mocks/events_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/events/dto"
+	service "github.com/ObscuraNote/api-general/internal/events/service"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEventsService is a mock of EventsService interface.
+type MockEventsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventsServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockEventsServiceMockRecorder is the mock recorder for MockEventsService.
+type MockEventsServiceMockRecorder struct {
+	mock *MockEventsService
+}
+
+// NewMockEventsService creates a new mock instance.
+func NewMockEventsService(ctrl *gomock.Controller) *MockEventsService {
+	mock := &MockEventsService{ctrl: ctrl}
+	mock.recorder = &MockEventsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventsService) EXPECT() *MockEventsServiceMockRecorder {
+	return m.recorder
+}
+
+// Start mocks base method.
+func (m *MockEventsService) Start(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Start", ctx)
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockEventsServiceMockRecorder) Start(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockEventsService)(nil).Start), ctx)
+}
+
+// Subscribe mocks base method.
+func (m *MockEventsService) Subscribe(auth dto.AuthInput, lastEventId int64) (*service.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", auth, lastEventId)
+	ret0, _ := ret[0].(*service.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventsServiceMockRecorder) Subscribe(auth, lastEventId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEventsService)(nil).Subscribe), auth, lastEventId)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockEventsService) Unsubscribe(sub *service.Subscription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Unsubscribe", sub)
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockEventsServiceMockRecorder) Unsubscribe(sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockEventsService)(nil).Unsubscribe), sub)
+}
@@ -11,8 +11,11 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
+	archive "github.com/ObscuraNote/api-general/internal/keys/archive"
 	dto "github.com/ObscuraNote/api-general/internal/keys/dto"
+	repository "github.com/ObscuraNote/api-general/internal/keys/repository"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,31 +44,242 @@ func (m *MockKeysRepository) EXPECT() *MockKeysRepositoryMockRecorder {
 }
 
 // AddKey mocks base method.
-func (m *MockKeysRepository) AddKey(userId int64, note dto.KeyImput) error {
+func (m *MockKeysRepository) AddKey(userId int64, note dto.KeyImput, limits repository.QuotaFunc) (*dto.KeyOutput, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddKey", userId, note)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "AddKey", userId, note, limits)
+	ret0, _ := ret[0].(*dto.KeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // AddKey indicates an expected call of AddKey.
-func (mr *MockKeysRepositoryMockRecorder) AddKey(userId, note any) *gomock.Call {
+func (mr *MockKeysRepositoryMockRecorder) AddKey(userId, note, limits any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddKey", reflect.TypeOf((*MockKeysRepository)(nil).AddKey), userId, note)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddKey", reflect.TypeOf((*MockKeysRepository)(nil).AddKey), userId, note, limits)
+}
+
+// CreateRelease mocks base method.
+func (m *MockKeysRepository) CreateRelease(ownerId, recipientId int64, keyId string, release dto.ReleaseInput) (*dto.ReleaseOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRelease", ownerId, recipientId, keyId, release)
+	ret0, _ := ret[0].(*dto.ReleaseOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRelease indicates an expected call of CreateRelease.
+func (mr *MockKeysRepositoryMockRecorder) CreateRelease(ownerId, recipientId, keyId, release any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRelease", reflect.TypeOf((*MockKeysRepository)(nil).CreateRelease), ownerId, recipientId, keyId, release)
+}
+
+// CreateShare mocks base method.
+func (m *MockKeysRepository) CreateShare(ownerId, recipientId int64, keyId string, share dto.ShareInput) (*dto.ShareOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShare", ownerId, recipientId, keyId, share)
+	ret0, _ := ret[0].(*dto.ShareOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShare indicates an expected call of CreateShare.
+func (mr *MockKeysRepositoryMockRecorder) CreateShare(ownerId, recipientId, keyId, share any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShare", reflect.TypeOf((*MockKeysRepository)(nil).CreateShare), ownerId, recipientId, keyId, share)
+}
+
+// DeleteExpiredKeys mocks base method.
+func (m *MockKeysRepository) DeleteExpiredKeys() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredKeys")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredKeys indicates an expected call of DeleteExpiredKeys.
+func (mr *MockKeysRepositoryMockRecorder) DeleteExpiredKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredKeys", reflect.TypeOf((*MockKeysRepository)(nil).DeleteExpiredKeys))
 }
 
 // DeleteKey mocks base method.
-func (m *MockKeysRepository) DeleteKey(id string) error {
+func (m *MockKeysRepository) DeleteKey(userId int64, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteKey", userId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteKey indicates an expected call of DeleteKey.
+func (mr *MockKeysRepositoryMockRecorder) DeleteKey(userId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKey", reflect.TypeOf((*MockKeysRepository)(nil).DeleteKey), userId, id)
+}
+
+// DeleteKeys mocks base method.
+func (m *MockKeysRepository) DeleteKeys(userId int64, refs []repository.KeyRef, atomic bool) ([]error, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteKey", id)
+	ret := m.ctrl.Call(m, "DeleteKeys", userId, refs, atomic)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteKeys indicates an expected call of DeleteKeys.
+func (mr *MockKeysRepositoryMockRecorder) DeleteKeys(userId, refs, atomic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKeys", reflect.TypeOf((*MockKeysRepository)(nil).DeleteKeys), userId, refs, atomic)
+}
+
+// DeleteOldTombstones mocks base method.
+func (m *MockKeysRepository) DeleteOldTombstones() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldTombstones")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOldTombstones indicates an expected call of DeleteOldTombstones.
+func (mr *MockKeysRepositoryMockRecorder) DeleteOldTombstones() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldTombstones", reflect.TypeOf((*MockKeysRepository)(nil).DeleteOldTombstones))
+}
+
+// DeleteRelease mocks base method.
+func (m *MockKeysRepository) DeleteRelease(ownerId int64, keyId, releaseId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRelease", ownerId, keyId, releaseId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRelease indicates an expected call of DeleteRelease.
+func (mr *MockKeysRepositoryMockRecorder) DeleteRelease(ownerId, keyId, releaseId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRelease", reflect.TypeOf((*MockKeysRepository)(nil).DeleteRelease), ownerId, keyId, releaseId)
+}
+
+// DeleteShare mocks base method.
+func (m *MockKeysRepository) DeleteShare(ownerId int64, keyId, shareId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShare", ownerId, keyId, shareId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteShare indicates an expected call of DeleteShare.
+func (mr *MockKeysRepositoryMockRecorder) DeleteShare(ownerId, keyId, shareId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShare", reflect.TypeOf((*MockKeysRepository)(nil).DeleteShare), ownerId, keyId, shareId)
+}
+
+// DeleteVaultKey mocks base method.
+func (m *MockKeysRepository) DeleteVaultKey(vaultId, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVaultKey", vaultId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteVaultKey indicates an expected call of DeleteVaultKey.
+func (mr *MockKeysRepositoryMockRecorder) DeleteVaultKey(vaultId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVaultKey", reflect.TypeOf((*MockKeysRepository)(nil).DeleteVaultKey), vaultId, id)
+}
+
+// ExportKeys mocks base method.
+func (m *MockKeysRepository) ExportKeys(userId int64, emit func(archive.Entry) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportKeys", userId, emit)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteKey indicates an expected call of DeleteKey.
-func (mr *MockKeysRepositoryMockRecorder) DeleteKey(id any) *gomock.Call {
+// ExportKeys indicates an expected call of ExportKeys.
+func (mr *MockKeysRepositoryMockRecorder) ExportKeys(userId, emit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportKeys", reflect.TypeOf((*MockKeysRepository)(nil).ExportKeys), userId, emit)
+}
+
+// GetChanges mocks base method.
+func (m *MockKeysRepository) GetChanges(userId, since int64, limit int) (*dto.SyncOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChanges", userId, since, limit)
+	ret0, _ := ret[0].(*dto.SyncOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChanges indicates an expected call of GetChanges.
+func (mr *MockKeysRepositoryMockRecorder) GetChanges(userId, since, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChanges", reflect.TypeOf((*MockKeysRepository)(nil).GetChanges), userId, since, limit)
+}
+
+// GetConflicts mocks base method.
+func (m *MockKeysRepository) GetConflicts(keyId string) ([]dto.ConflictOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConflicts", keyId)
+	ret0, _ := ret[0].([]dto.ConflictOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConflicts indicates an expected call of GetConflicts.
+func (mr *MockKeysRepositoryMockRecorder) GetConflicts(keyId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConflicts", reflect.TypeOf((*MockKeysRepository)(nil).GetConflicts), keyId)
+}
+
+// GetKeyAccess mocks base method.
+func (m *MockKeysRepository) GetKeyAccess(userId int64, id string) (*dto.KeyAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyAccess", userId, id)
+	ret0, _ := ret[0].(*dto.KeyAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyAccess indicates an expected call of GetKeyAccess.
+func (mr *MockKeysRepositoryMockRecorder) GetKeyAccess(userId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyAccess", reflect.TypeOf((*MockKeysRepository)(nil).GetKeyAccess), userId, id)
+}
+
+// GetKeyVault mocks base method.
+func (m *MockKeysRepository) GetKeyVault(id string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyVault", id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyVault indicates an expected call of GetKeyVault.
+func (mr *MockKeysRepositoryMockRecorder) GetKeyVault(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyVault", reflect.TypeOf((*MockKeysRepository)(nil).GetKeyVault), id)
+}
+
+// GetKeysByIds mocks base method.
+func (m *MockKeysRepository) GetKeysByIds(userId int64, ids []string) ([]dto.KeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeysByIds", userId, ids)
+	ret0, _ := ret[0].([]dto.KeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeysByIds indicates an expected call of GetKeysByIds.
+func (mr *MockKeysRepositoryMockRecorder) GetKeysByIds(userId, ids any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKey", reflect.TypeOf((*MockKeysRepository)(nil).DeleteKey), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeysByIds", reflect.TypeOf((*MockKeysRepository)(nil).GetKeysByIds), userId, ids)
 }
 
 // GetKeysByUser mocks base method.
@@ -82,3 +296,214 @@ func (mr *MockKeysRepositoryMockRecorder) GetKeysByUser(userId any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeysByUser", reflect.TypeOf((*MockKeysRepository)(nil).GetKeysByUser), userId)
 }
+
+// GetKeysByVault mocks base method.
+func (m *MockKeysRepository) GetKeysByVault(vaultId string) ([]dto.KeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeysByVault", vaultId)
+	ret0, _ := ret[0].([]dto.KeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeysByVault indicates an expected call of GetKeysByVault.
+func (mr *MockKeysRepositoryMockRecorder) GetKeysByVault(vaultId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeysByVault", reflect.TypeOf((*MockKeysRepository)(nil).GetKeysByVault), vaultId)
+}
+
+// GetReleasesByKey mocks base method.
+func (m *MockKeysRepository) GetReleasesByKey(ownerId int64, keyId string) ([]dto.ReleaseOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReleasesByKey", ownerId, keyId)
+	ret0, _ := ret[0].([]dto.ReleaseOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReleasesByKey indicates an expected call of GetReleasesByKey.
+func (mr *MockKeysRepositoryMockRecorder) GetReleasesByKey(ownerId, keyId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReleasesByKey", reflect.TypeOf((*MockKeysRepository)(nil).GetReleasesByKey), ownerId, keyId)
+}
+
+// GetSharedKey mocks base method.
+func (m *MockKeysRepository) GetSharedKey(recipientId int64, shareId string) (*dto.SharedKeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedKey", recipientId, shareId)
+	ret0, _ := ret[0].(*dto.SharedKeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharedKey indicates an expected call of GetSharedKey.
+func (mr *MockKeysRepositoryMockRecorder) GetSharedKey(recipientId, shareId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedKey", reflect.TypeOf((*MockKeysRepository)(nil).GetSharedKey), recipientId, shareId)
+}
+
+// GetSharedKeys mocks base method.
+func (m *MockKeysRepository) GetSharedKeys(recipientId int64) ([]dto.SharedKeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedKeys", recipientId)
+	ret0, _ := ret[0].([]dto.SharedKeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharedKeys indicates an expected call of GetSharedKeys.
+func (mr *MockKeysRepositoryMockRecorder) GetSharedKeys(recipientId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedKeys", reflect.TypeOf((*MockKeysRepository)(nil).GetSharedKeys), recipientId)
+}
+
+// GetSharesByKey mocks base method.
+func (m *MockKeysRepository) GetSharesByKey(ownerId int64, keyId string) ([]dto.ShareOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharesByKey", ownerId, keyId)
+	ret0, _ := ret[0].([]dto.ShareOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharesByKey indicates an expected call of GetSharesByKey.
+func (mr *MockKeysRepositoryMockRecorder) GetSharesByKey(ownerId, keyId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharesByKey", reflect.TypeOf((*MockKeysRepository)(nil).GetSharesByKey), ownerId, keyId)
+}
+
+// ImportKey mocks base method.
+func (m *MockKeysRepository) ImportKey(userId int64, userAddress string, entry archive.Entry, duplicateId, strategy string, limits repository.QuotaFunc) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportKey", userId, userAddress, entry, duplicateId, strategy, limits)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportKey indicates an expected call of ImportKey.
+func (mr *MockKeysRepositoryMockRecorder) ImportKey(userId, userAddress, entry, duplicateId, strategy, limits any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportKey", reflect.TypeOf((*MockKeysRepository)(nil).ImportKey), userId, userAddress, entry, duplicateId, strategy, limits)
+}
+
+// MigratePayloads mocks base method.
+func (m *MockKeysRepository) MigratePayloads() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigratePayloads")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MigratePayloads indicates an expected call of MigratePayloads.
+func (mr *MockKeysRepositoryMockRecorder) MigratePayloads() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratePayloads", reflect.TypeOf((*MockKeysRepository)(nil).MigratePayloads))
+}
+
+// ReleaseDueKeys mocks base method.
+func (m *MockKeysRepository) ReleaseDueKeys() ([]repository.ReleasedShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseDueKeys")
+	ret0, _ := ret[0].([]repository.ReleasedShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseDueKeys indicates an expected call of ReleaseDueKeys.
+func (mr *MockKeysRepositoryMockRecorder) ReleaseDueKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseDueKeys", reflect.TypeOf((*MockKeysRepository)(nil).ReleaseDueKeys))
+}
+
+// UpdateKey mocks base method.
+func (m *MockKeysRepository) UpdateKey(userId int64, id string, note dto.KeyImput, limits repository.QuotaFunc) (*dto.KeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateKey", userId, id, note, limits)
+	ret0, _ := ret[0].(*dto.KeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateKey indicates an expected call of UpdateKey.
+func (mr *MockKeysRepositoryMockRecorder) UpdateKey(userId, id, note, limits any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKey", reflect.TypeOf((*MockKeysRepository)(nil).UpdateKey), userId, id, note, limits)
+}
+
+// UpdateKeyData mocks base method.
+func (m *MockKeysRepository) UpdateKeyData(id, shareId string, recipientId int64, encryptedData, dataIV []byte, limits repository.QuotaFunc) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateKeyData", id, shareId, recipientId, encryptedData, dataIV, limits)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateKeyData indicates an expected call of UpdateKeyData.
+func (mr *MockKeysRepositoryMockRecorder) UpdateKeyData(id, shareId, recipientId, encryptedData, dataIV, limits any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKeyData", reflect.TypeOf((*MockKeysRepository)(nil).UpdateKeyData), id, shareId, recipientId, encryptedData, dataIV, limits)
+}
+
+// UpdateRelease mocks base method.
+func (m *MockKeysRepository) UpdateRelease(ownerId int64, keyId, releaseId string, releaseAt time.Time) (*dto.ReleaseOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRelease", ownerId, keyId, releaseId, releaseAt)
+	ret0, _ := ret[0].(*dto.ReleaseOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRelease indicates an expected call of UpdateRelease.
+func (mr *MockKeysRepositoryMockRecorder) UpdateRelease(ownerId, keyId, releaseId, releaseAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRelease", reflect.TypeOf((*MockKeysRepository)(nil).UpdateRelease), ownerId, keyId, releaseId, releaseAt)
+}
+
+// UpdateShareStatus mocks base method.
+func (m *MockKeysRepository) UpdateShareStatus(recipientId int64, shareId, status string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateShareStatus", recipientId, shareId, status)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateShareStatus indicates an expected call of UpdateShareStatus.
+func (mr *MockKeysRepositoryMockRecorder) UpdateShareStatus(recipientId, shareId, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateShareStatus", reflect.TypeOf((*MockKeysRepository)(nil).UpdateShareStatus), recipientId, shareId, status)
+}
+
+// UpdateVaultKey mocks base method.
+func (m *MockKeysRepository) UpdateVaultKey(vaultId, id string, note dto.KeyImput, limits repository.QuotaFunc) (*dto.KeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVaultKey", vaultId, id, note, limits)
+	ret0, _ := ret[0].(*dto.KeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVaultKey indicates an expected call of UpdateVaultKey.
+func (mr *MockKeysRepositoryMockRecorder) UpdateVaultKey(vaultId, id, note, limits any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVaultKey", reflect.TypeOf((*MockKeysRepository)(nil).UpdateVaultKey), vaultId, id, note, limits)
+}
+
+// WriteKeys mocks base method.
+func (m *MockKeysRepository) WriteKeys(userId int64, writes []repository.KeyWrite, atomic bool, limits repository.QuotaFunc) ([]*dto.KeyOutput, []error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteKeys", userId, writes, atomic, limits)
+	ret0, _ := ret[0].([]*dto.KeyOutput)
+	ret1, _ := ret[1].([]error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// WriteKeys indicates an expected call of WriteKeys.
+func (mr *MockKeysRepositoryMockRecorder) WriteKeys(userId, writes, atomic, limits any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteKeys", reflect.TypeOf((*MockKeysRepository)(nil).WriteKeys), userId, writes, atomic, limits)
+}
@@ -11,6 +11,7 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	dto "github.com/ObscuraNote/api-general/internal/keys/dto"
@@ -42,11 +43,12 @@ func (m *MockKeysService) EXPECT() *MockKeysServiceMockRecorder {
 }
 
 // AddKey mocks base method.
-func (m *MockKeysService) AddKey(note dto.KeyImput) error {
+func (m *MockKeysService) AddKey(note dto.KeyImput) (*dto.KeyOutput, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AddKey", note)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*dto.KeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // AddKey indicates an expected call of AddKey.
@@ -55,18 +57,164 @@ func (mr *MockKeysServiceMockRecorder) AddKey(note any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddKey", reflect.TypeOf((*MockKeysService)(nil).AddKey), note)
 }
 
+// AuthorizeKey mocks base method.
+func (m *MockKeysService) AuthorizeKey(keyId string, userId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeKey", keyId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorizeKey indicates an expected call of AuthorizeKey.
+func (mr *MockKeysServiceMockRecorder) AuthorizeKey(keyId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeKey", reflect.TypeOf((*MockKeysService)(nil).AuthorizeKey), keyId, userId)
+}
+
+// AuthorizeKeyWrite mocks base method.
+func (m *MockKeysService) AuthorizeKeyWrite(keyId string, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeKeyWrite", keyId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AuthorizeKeyWrite indicates an expected call of AuthorizeKeyWrite.
+func (mr *MockKeysServiceMockRecorder) AuthorizeKeyWrite(keyId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeKeyWrite", reflect.TypeOf((*MockKeysService)(nil).AuthorizeKeyWrite), keyId, userId)
+}
+
+// CancelRelease mocks base method.
+func (m *MockKeysService) CancelRelease(keyId, releaseId string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelRelease", keyId, releaseId, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelRelease indicates an expected call of CancelRelease.
+func (mr *MockKeysServiceMockRecorder) CancelRelease(keyId, releaseId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelRelease", reflect.TypeOf((*MockKeysService)(nil).CancelRelease), keyId, releaseId, auth)
+}
+
 // DeleteKey mocks base method.
-func (m *MockKeysService) DeleteKey(input dto.DeleteKeyInput) error {
+func (m *MockKeysService) DeleteKey(keyId string, auth dto.AuthInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteKey", input)
+	ret := m.ctrl.Call(m, "DeleteKey", keyId, auth)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteKey indicates an expected call of DeleteKey.
-func (mr *MockKeysServiceMockRecorder) DeleteKey(input any) *gomock.Call {
+func (mr *MockKeysServiceMockRecorder) DeleteKey(keyId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKey", reflect.TypeOf((*MockKeysService)(nil).DeleteKey), keyId, auth)
+}
+
+// DeleteKeys mocks base method.
+func (m *MockKeysService) DeleteKeys(auth dto.AuthInput, input dto.BatchIDsInput) (*dto.BatchOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteKeys", auth, input)
+	ret0, _ := ret[0].(*dto.BatchOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteKeys indicates an expected call of DeleteKeys.
+func (mr *MockKeysServiceMockRecorder) DeleteKeys(auth, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKeys", reflect.TypeOf((*MockKeysService)(nil).DeleteKeys), auth, input)
+}
+
+// ExportKeys mocks base method.
+func (m *MockKeysService) ExportKeys(auth dto.AuthInput, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportKeys", auth, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportKeys indicates an expected call of ExportKeys.
+func (mr *MockKeysServiceMockRecorder) ExportKeys(auth, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportKeys", reflect.TypeOf((*MockKeysService)(nil).ExportKeys), auth, w)
+}
+
+// ExportPublicKey mocks base method.
+func (m *MockKeysService) ExportPublicKey() dto.ExportPublicKey {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportPublicKey")
+	ret0, _ := ret[0].(dto.ExportPublicKey)
+	return ret0
+}
+
+// ExportPublicKey indicates an expected call of ExportPublicKey.
+func (mr *MockKeysServiceMockRecorder) ExportPublicKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportPublicKey", reflect.TypeOf((*MockKeysService)(nil).ExportPublicKey))
+}
+
+// GetConflicts mocks base method.
+func (m *MockKeysService) GetConflicts(keyId string, auth dto.AuthInput) ([]dto.ConflictOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConflicts", keyId, auth)
+	ret0, _ := ret[0].([]dto.ConflictOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConflicts indicates an expected call of GetConflicts.
+func (mr *MockKeysServiceMockRecorder) GetConflicts(keyId, auth any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKey", reflect.TypeOf((*MockKeysService)(nil).DeleteKey), input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConflicts", reflect.TypeOf((*MockKeysService)(nil).GetConflicts), keyId, auth)
+}
+
+// GetKeyReleases mocks base method.
+func (m *MockKeysService) GetKeyReleases(keyId string, auth dto.AuthInput) ([]dto.ReleaseOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyReleases", keyId, auth)
+	ret0, _ := ret[0].([]dto.ReleaseOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyReleases indicates an expected call of GetKeyReleases.
+func (mr *MockKeysServiceMockRecorder) GetKeyReleases(keyId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyReleases", reflect.TypeOf((*MockKeysService)(nil).GetKeyReleases), keyId, auth)
+}
+
+// GetKeyShares mocks base method.
+func (m *MockKeysService) GetKeyShares(keyId string, auth dto.AuthInput) ([]dto.ShareOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyShares", keyId, auth)
+	ret0, _ := ret[0].([]dto.ShareOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyShares indicates an expected call of GetKeyShares.
+func (mr *MockKeysServiceMockRecorder) GetKeyShares(keyId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyShares", reflect.TypeOf((*MockKeysService)(nil).GetKeyShares), keyId, auth)
+}
+
+// GetKeysByIds mocks base method.
+func (m *MockKeysService) GetKeysByIds(auth dto.AuthInput, input dto.BatchIDsInput) (*dto.BatchOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeysByIds", auth, input)
+	ret0, _ := ret[0].(*dto.BatchOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeysByIds indicates an expected call of GetKeysByIds.
+func (mr *MockKeysServiceMockRecorder) GetKeysByIds(auth, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeysByIds", reflect.TypeOf((*MockKeysService)(nil).GetKeysByIds), auth, input)
 }
 
 // GetKeysByUser mocks base method.
@@ -83,3 +231,196 @@ func (mr *MockKeysServiceMockRecorder) GetKeysByUser(ctx, auth any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeysByUser", reflect.TypeOf((*MockKeysService)(nil).GetKeysByUser), ctx, auth)
 }
+
+// GetSharedKeys mocks base method.
+func (m *MockKeysService) GetSharedKeys(auth dto.AuthInput) ([]dto.SharedKeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedKeys", auth)
+	ret0, _ := ret[0].([]dto.SharedKeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharedKeys indicates an expected call of GetSharedKeys.
+func (mr *MockKeysServiceMockRecorder) GetSharedKeys(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedKeys", reflect.TypeOf((*MockKeysService)(nil).GetSharedKeys), auth)
+}
+
+// GetVaultKeys mocks base method.
+func (m *MockKeysService) GetVaultKeys(vaultId string, auth dto.AuthInput) ([]dto.KeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVaultKeys", vaultId, auth)
+	ret0, _ := ret[0].([]dto.KeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaultKeys indicates an expected call of GetVaultKeys.
+func (mr *MockKeysServiceMockRecorder) GetVaultKeys(vaultId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaultKeys", reflect.TypeOf((*MockKeysService)(nil).GetVaultKeys), vaultId, auth)
+}
+
+// ImportKeys mocks base method.
+func (m *MockKeysService) ImportKeys(auth dto.AuthInput, strategy string, body io.Reader) (*dto.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportKeys", auth, strategy, body)
+	ret0, _ := ret[0].(*dto.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportKeys indicates an expected call of ImportKeys.
+func (mr *MockKeysServiceMockRecorder) ImportKeys(auth, strategy, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportKeys", reflect.TypeOf((*MockKeysService)(nil).ImportKeys), auth, strategy, body)
+}
+
+// ReleaseDueKeys mocks base method.
+func (m *MockKeysService) ReleaseDueKeys() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseDueKeys")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseDueKeys indicates an expected call of ReleaseDueKeys.
+func (mr *MockKeysServiceMockRecorder) ReleaseDueKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseDueKeys", reflect.TypeOf((*MockKeysService)(nil).ReleaseDueKeys))
+}
+
+// RescheduleRelease mocks base method.
+func (m *MockKeysService) RescheduleRelease(keyId, releaseId string, input dto.RescheduleInput) (*dto.ReleaseOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleRelease", keyId, releaseId, input)
+	ret0, _ := ret[0].(*dto.ReleaseOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RescheduleRelease indicates an expected call of RescheduleRelease.
+func (mr *MockKeysServiceMockRecorder) RescheduleRelease(keyId, releaseId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleRelease", reflect.TypeOf((*MockKeysService)(nil).RescheduleRelease), keyId, releaseId, input)
+}
+
+// RespondToShare mocks base method.
+func (m *MockKeysService) RespondToShare(shareId string, accept bool, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RespondToShare", shareId, accept, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RespondToShare indicates an expected call of RespondToShare.
+func (mr *MockKeysServiceMockRecorder) RespondToShare(shareId, accept, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RespondToShare", reflect.TypeOf((*MockKeysService)(nil).RespondToShare), shareId, accept, auth)
+}
+
+// RevokeShare mocks base method.
+func (m *MockKeysService) RevokeShare(keyId, shareId string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeShare", keyId, shareId, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeShare indicates an expected call of RevokeShare.
+func (mr *MockKeysServiceMockRecorder) RevokeShare(keyId, shareId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeShare", reflect.TypeOf((*MockKeysService)(nil).RevokeShare), keyId, shareId, auth)
+}
+
+// ScheduleRelease mocks base method.
+func (m *MockKeysService) ScheduleRelease(keyId string, release dto.ReleaseInput) (*dto.ReleaseOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleRelease", keyId, release)
+	ret0, _ := ret[0].(*dto.ReleaseOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleRelease indicates an expected call of ScheduleRelease.
+func (mr *MockKeysServiceMockRecorder) ScheduleRelease(keyId, release any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleRelease", reflect.TypeOf((*MockKeysService)(nil).ScheduleRelease), keyId, release)
+}
+
+// ShareKey mocks base method.
+func (m *MockKeysService) ShareKey(keyId string, share dto.ShareInput) (*dto.ShareOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShareKey", keyId, share)
+	ret0, _ := ret[0].(*dto.ShareOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShareKey indicates an expected call of ShareKey.
+func (mr *MockKeysServiceMockRecorder) ShareKey(keyId, share any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShareKey", reflect.TypeOf((*MockKeysService)(nil).ShareKey), keyId, share)
+}
+
+// Sync mocks base method.
+func (m *MockKeysService) Sync(auth dto.AuthInput, since, limit int64) (*dto.SyncOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", auth, since, limit)
+	ret0, _ := ret[0].(*dto.SyncOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockKeysServiceMockRecorder) Sync(auth, since, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockKeysService)(nil).Sync), auth, since, limit)
+}
+
+// UpdateKey mocks base method.
+func (m *MockKeysService) UpdateKey(keyId string, note dto.KeyImput) (*dto.KeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateKey", keyId, note)
+	ret0, _ := ret[0].(*dto.KeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateKey indicates an expected call of UpdateKey.
+func (mr *MockKeysServiceMockRecorder) UpdateKey(keyId, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKey", reflect.TypeOf((*MockKeysService)(nil).UpdateKey), keyId, note)
+}
+
+// UpdateSharedKey mocks base method.
+func (m *MockKeysService) UpdateSharedKey(shareId string, note dto.KeyImput) (*dto.SharedKeyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSharedKey", shareId, note)
+	ret0, _ := ret[0].(*dto.SharedKeyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSharedKey indicates an expected call of UpdateSharedKey.
+func (mr *MockKeysServiceMockRecorder) UpdateSharedKey(shareId, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSharedKey", reflect.TypeOf((*MockKeysService)(nil).UpdateSharedKey), shareId, note)
+}
+
+// WriteKeys mocks base method.
+func (m *MockKeysService) WriteKeys(input dto.BatchWriteInput) (*dto.BatchOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteKeys", input)
+	ret0, _ := ret[0].(*dto.BatchOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteKeys indicates an expected call of WriteKeys.
+func (mr *MockKeysServiceMockRecorder) WriteKeys(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteKeys", reflect.TypeOf((*MockKeysService)(nil).WriteKeys), input)
+}
@@ -0,0 +1,160 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/messages/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/messages/repository/repository.go -destination=./mocks/messages_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/messages/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMessagesRepository is a mock of MessagesRepository interface.
+type MockMessagesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessagesRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMessagesRepositoryMockRecorder is the mock recorder for MockMessagesRepository.
+type MockMessagesRepositoryMockRecorder struct {
+	mock *MockMessagesRepository
+}
+
+// NewMockMessagesRepository creates a new mock instance.
+func NewMockMessagesRepository(ctrl *gomock.Controller) *MockMessagesRepository {
+	mock := &MockMessagesRepository{ctrl: ctrl}
+	mock.recorder = &MockMessagesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessagesRepository) EXPECT() *MockMessagesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddMessage mocks base method.
+func (m *MockMessagesRepository) AddMessage(senderId, recipientId int64, encryptedBody []byte, quota int64) (*dto.SentMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMessage", senderId, recipientId, encryptedBody, quota)
+	ret0, _ := ret[0].(*dto.SentMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMessage indicates an expected call of AddMessage.
+func (mr *MockMessagesRepositoryMockRecorder) AddMessage(senderId, recipientId, encryptedBody, quota any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMessage", reflect.TypeOf((*MockMessagesRepository)(nil).AddMessage), senderId, recipientId, encryptedBody, quota)
+}
+
+// BlockSender mocks base method.
+func (m *MockMessagesRepository) BlockSender(userId, blockedId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSender", userId, blockedId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSender indicates an expected call of BlockSender.
+func (mr *MockMessagesRepositoryMockRecorder) BlockSender(userId, blockedId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSender", reflect.TypeOf((*MockMessagesRepository)(nil).BlockSender), userId, blockedId)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockMessagesRepository) DeleteMessage(recipientId int64, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", recipientId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockMessagesRepositoryMockRecorder) DeleteMessage(recipientId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockMessagesRepository)(nil).DeleteMessage), recipientId, id)
+}
+
+// GetBlockedSenders mocks base method.
+func (m *MockMessagesRepository) GetBlockedSenders(userId int64) ([]dto.BlockedSender, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockedSenders", userId)
+	ret0, _ := ret[0].([]dto.BlockedSender)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockedSenders indicates an expected call of GetBlockedSenders.
+func (mr *MockMessagesRepositoryMockRecorder) GetBlockedSenders(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockedSenders", reflect.TypeOf((*MockMessagesRepository)(nil).GetBlockedSenders), userId)
+}
+
+// GetMessages mocks base method.
+func (m *MockMessagesRepository) GetMessages(recipientId, beforeSeq int64, unreadOnly bool, limit int64) ([]dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessages", recipientId, beforeSeq, unreadOnly, limit)
+	ret0, _ := ret[0].([]dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessages indicates an expected call of GetMessages.
+func (mr *MockMessagesRepositoryMockRecorder) GetMessages(recipientId, beforeSeq, unreadOnly, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockMessagesRepository)(nil).GetMessages), recipientId, beforeSeq, unreadOnly, limit)
+}
+
+// GetSummary mocks base method.
+func (m *MockMessagesRepository) GetSummary(recipientId int64) (*dto.InboxSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSummary", recipientId)
+	ret0, _ := ret[0].(*dto.InboxSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSummary indicates an expected call of GetSummary.
+func (mr *MockMessagesRepositoryMockRecorder) GetSummary(recipientId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummary", reflect.TypeOf((*MockMessagesRepository)(nil).GetSummary), recipientId)
+}
+
+// MarkRead mocks base method.
+func (m *MockMessagesRepository) MarkRead(recipientId int64, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", recipientId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockMessagesRepositoryMockRecorder) MarkRead(recipientId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockMessagesRepository)(nil).MarkRead), recipientId, id)
+}
+
+// UnblockSender mocks base method.
+func (m *MockMessagesRepository) UnblockSender(userId, blockedId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnblockSender", userId, blockedId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnblockSender indicates an expected call of UnblockSender.
+func (mr *MockMessagesRepositoryMockRecorder) UnblockSender(userId, blockedId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnblockSender", reflect.TypeOf((*MockMessagesRepository)(nil).UnblockSender), userId, blockedId)
+}
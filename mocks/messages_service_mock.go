@@ -0,0 +1,157 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/messages/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/messages/service/service.go -destination=./mocks/messages_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/messages/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMessagesService is a mock of MessagesService interface.
+type MockMessagesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessagesServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockMessagesServiceMockRecorder is the mock recorder for MockMessagesService.
+type MockMessagesServiceMockRecorder struct {
+	mock *MockMessagesService
+}
+
+// NewMockMessagesService creates a new mock instance.
+func NewMockMessagesService(ctrl *gomock.Controller) *MockMessagesService {
+	mock := &MockMessagesService{ctrl: ctrl}
+	mock.recorder = &MockMessagesServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessagesService) EXPECT() *MockMessagesServiceMockRecorder {
+	return m.recorder
+}
+
+// BlockSender mocks base method.
+func (m *MockMessagesService) BlockSender(senderAddress string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSender", senderAddress, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSender indicates an expected call of BlockSender.
+func (mr *MockMessagesServiceMockRecorder) BlockSender(senderAddress, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSender", reflect.TypeOf((*MockMessagesService)(nil).BlockSender), senderAddress, auth)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockMessagesService) DeleteMessage(id string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", id, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockMessagesServiceMockRecorder) DeleteMessage(id, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockMessagesService)(nil).DeleteMessage), id, auth)
+}
+
+// GetBlockedSenders mocks base method.
+func (m *MockMessagesService) GetBlockedSenders(auth dto.AuthInput) ([]dto.BlockedSender, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockedSenders", auth)
+	ret0, _ := ret[0].([]dto.BlockedSender)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockedSenders indicates an expected call of GetBlockedSenders.
+func (mr *MockMessagesServiceMockRecorder) GetBlockedSenders(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockedSenders", reflect.TypeOf((*MockMessagesService)(nil).GetBlockedSenders), auth)
+}
+
+// GetMessages mocks base method.
+func (m *MockMessagesService) GetMessages(cursor, limit int64, unreadOnly bool, auth dto.AuthInput) (*dto.MessagePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessages", cursor, limit, unreadOnly, auth)
+	ret0, _ := ret[0].(*dto.MessagePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessages indicates an expected call of GetMessages.
+func (mr *MockMessagesServiceMockRecorder) GetMessages(cursor, limit, unreadOnly, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockMessagesService)(nil).GetMessages), cursor, limit, unreadOnly, auth)
+}
+
+// GetSummary mocks base method.
+func (m *MockMessagesService) GetSummary(auth dto.AuthInput) (*dto.InboxSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSummary", auth)
+	ret0, _ := ret[0].(*dto.InboxSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSummary indicates an expected call of GetSummary.
+func (mr *MockMessagesServiceMockRecorder) GetSummary(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummary", reflect.TypeOf((*MockMessagesService)(nil).GetSummary), auth)
+}
+
+// MarkRead mocks base method.
+func (m *MockMessagesService) MarkRead(id string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", id, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockMessagesServiceMockRecorder) MarkRead(id, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockMessagesService)(nil).MarkRead), id, auth)
+}
+
+// SendMessage mocks base method.
+func (m *MockMessagesService) SendMessage(input dto.MessageInput) (*dto.SentMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", input)
+	ret0, _ := ret[0].(*dto.SentMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockMessagesServiceMockRecorder) SendMessage(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockMessagesService)(nil).SendMessage), input)
+}
+
+// UnblockSender mocks base method.
+func (m *MockMessagesService) UnblockSender(senderAddress string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnblockSender", senderAddress, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnblockSender indicates an expected call of UnblockSender.
+func (mr *MockMessagesServiceMockRecorder) UnblockSender(senderAddress, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnblockSender", reflect.TypeOf((*MockMessagesService)(nil).UnblockSender), senderAddress, auth)
+}
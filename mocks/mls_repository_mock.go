@@ -0,0 +1,175 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/mls/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/mls/repository/repository.go -destination=./mocks/mls_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/mls/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMLSRepository is a mock of MLSRepository interface.
+type MockMLSRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMLSRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMLSRepositoryMockRecorder is the mock recorder for MockMLSRepository.
+type MockMLSRepositoryMockRecorder struct {
+	mock *MockMLSRepository
+}
+
+// NewMockMLSRepository creates a new mock instance.
+func NewMockMLSRepository(ctrl *gomock.Controller) *MockMLSRepository {
+	mock := &MockMLSRepository{ctrl: ctrl}
+	mock.recorder = &MockMLSRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMLSRepository) EXPECT() *MockMLSRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddCommit mocks base method.
+func (m *MockMLSRepository) AddCommit(vaultId string, senderId, epoch int64, commit []byte, welcomes []dto.Welcome) (*dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCommit", vaultId, senderId, epoch, commit, welcomes)
+	ret0, _ := ret[0].(*dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCommit indicates an expected call of AddCommit.
+func (mr *MockMLSRepositoryMockRecorder) AddCommit(vaultId, senderId, epoch, commit, welcomes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommit", reflect.TypeOf((*MockMLSRepository)(nil).AddCommit), vaultId, senderId, epoch, commit, welcomes)
+}
+
+// AddKeyPackages mocks base method.
+func (m *MockMLSRepository) AddKeyPackages(userId int64, deviceId string, keyPackages [][]byte, lastResort []byte, limit, maxDevices int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddKeyPackages", userId, deviceId, keyPackages, lastResort, limit, maxDevices)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddKeyPackages indicates an expected call of AddKeyPackages.
+func (mr *MockMLSRepositoryMockRecorder) AddKeyPackages(userId, deviceId, keyPackages, lastResort, limit, maxDevices any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddKeyPackages", reflect.TypeOf((*MockMLSRepository)(nil).AddKeyPackages), userId, deviceId, keyPackages, lastResort, limit, maxDevices)
+}
+
+// AddProposal mocks base method.
+func (m *MockMLSRepository) AddProposal(vaultId string, senderId, epoch int64, proposal []byte) (*dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProposal", vaultId, senderId, epoch, proposal)
+	ret0, _ := ret[0].(*dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProposal indicates an expected call of AddProposal.
+func (mr *MockMLSRepositoryMockRecorder) AddProposal(vaultId, senderId, epoch, proposal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProposal", reflect.TypeOf((*MockMLSRepository)(nil).AddProposal), vaultId, senderId, epoch, proposal)
+}
+
+// ClaimKeyPackages mocks base method.
+func (m *MockMLSRepository) ClaimKeyPackages(userId int64) ([]dto.KeyPackage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimKeyPackages", userId)
+	ret0, _ := ret[0].([]dto.KeyPackage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimKeyPackages indicates an expected call of ClaimKeyPackages.
+func (mr *MockMLSRepositoryMockRecorder) ClaimKeyPackages(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimKeyPackages", reflect.TypeOf((*MockMLSRepository)(nil).ClaimKeyPackages), userId)
+}
+
+// CountKeyPackages mocks base method.
+func (m *MockMLSRepository) CountKeyPackages(userId int64) ([]dto.KeyPackageCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountKeyPackages", userId)
+	ret0, _ := ret[0].([]dto.KeyPackageCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountKeyPackages indicates an expected call of CountKeyPackages.
+func (mr *MockMLSRepositoryMockRecorder) CountKeyPackages(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountKeyPackages", reflect.TypeOf((*MockMLSRepository)(nil).CountKeyPackages), userId)
+}
+
+// CreateGroup mocks base method.
+func (m *MockMLSRepository) CreateGroup(vaultId string) (*dto.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", vaultId)
+	ret0, _ := ret[0].(*dto.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockMLSRepositoryMockRecorder) CreateGroup(vaultId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockMLSRepository)(nil).CreateGroup), vaultId)
+}
+
+// GetGroup mocks base method.
+func (m *MockMLSRepository) GetGroup(vaultId string) (*dto.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroup", vaultId)
+	ret0, _ := ret[0].(*dto.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroup indicates an expected call of GetGroup.
+func (mr *MockMLSRepositoryMockRecorder) GetGroup(vaultId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroup", reflect.TypeOf((*MockMLSRepository)(nil).GetGroup), vaultId)
+}
+
+// GetMessages mocks base method.
+func (m *MockMLSRepository) GetMessages(vaultId string, userId, afterSeq, limit int64) ([]dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessages", vaultId, userId, afterSeq, limit)
+	ret0, _ := ret[0].([]dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessages indicates an expected call of GetMessages.
+func (mr *MockMLSRepositoryMockRecorder) GetMessages(vaultId, userId, afterSeq, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockMLSRepository)(nil).GetMessages), vaultId, userId, afterSeq, limit)
+}
+
+// GetWelcomes mocks base method.
+func (m *MockMLSRepository) GetWelcomes(userId int64) ([]dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWelcomes", userId)
+	ret0, _ := ret[0].([]dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWelcomes indicates an expected call of GetWelcomes.
+func (mr *MockMLSRepositoryMockRecorder) GetWelcomes(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWelcomes", reflect.TypeOf((*MockMLSRepository)(nil).GetWelcomes), userId)
+}
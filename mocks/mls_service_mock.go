@@ -0,0 +1,175 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/mls/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/mls/service/service.go -destination=./mocks/mls_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/mls/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMLSService is a mock of MLSService interface.
+type MockMLSService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMLSServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockMLSServiceMockRecorder is the mock recorder for MockMLSService.
+type MockMLSServiceMockRecorder struct {
+	mock *MockMLSService
+}
+
+// NewMockMLSService creates a new mock instance.
+func NewMockMLSService(ctrl *gomock.Controller) *MockMLSService {
+	mock := &MockMLSService{ctrl: ctrl}
+	mock.recorder = &MockMLSServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMLSService) EXPECT() *MockMLSServiceMockRecorder {
+	return m.recorder
+}
+
+// ClaimKeyPackages mocks base method.
+func (m *MockMLSService) ClaimKeyPackages(userAddress string, auth dto.AuthInput) ([]dto.KeyPackage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimKeyPackages", userAddress, auth)
+	ret0, _ := ret[0].([]dto.KeyPackage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimKeyPackages indicates an expected call of ClaimKeyPackages.
+func (mr *MockMLSServiceMockRecorder) ClaimKeyPackages(userAddress, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimKeyPackages", reflect.TypeOf((*MockMLSService)(nil).ClaimKeyPackages), userAddress, auth)
+}
+
+// CreateGroup mocks base method.
+func (m *MockMLSService) CreateGroup(vaultId string, auth dto.AuthInput) (*dto.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", vaultId, auth)
+	ret0, _ := ret[0].(*dto.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockMLSServiceMockRecorder) CreateGroup(vaultId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockMLSService)(nil).CreateGroup), vaultId, auth)
+}
+
+// GetGroup mocks base method.
+func (m *MockMLSService) GetGroup(vaultId string, auth dto.AuthInput) (*dto.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroup", vaultId, auth)
+	ret0, _ := ret[0].(*dto.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroup indicates an expected call of GetGroup.
+func (mr *MockMLSServiceMockRecorder) GetGroup(vaultId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroup", reflect.TypeOf((*MockMLSService)(nil).GetGroup), vaultId, auth)
+}
+
+// GetKeyPackageCounts mocks base method.
+func (m *MockMLSService) GetKeyPackageCounts(auth dto.AuthInput) ([]dto.KeyPackageCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyPackageCounts", auth)
+	ret0, _ := ret[0].([]dto.KeyPackageCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyPackageCounts indicates an expected call of GetKeyPackageCounts.
+func (mr *MockMLSServiceMockRecorder) GetKeyPackageCounts(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyPackageCounts", reflect.TypeOf((*MockMLSService)(nil).GetKeyPackageCounts), auth)
+}
+
+// GetMessages mocks base method.
+func (m *MockMLSService) GetMessages(vaultId string, afterSeq, limit int64, auth dto.AuthInput) ([]dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessages", vaultId, afterSeq, limit, auth)
+	ret0, _ := ret[0].([]dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessages indicates an expected call of GetMessages.
+func (mr *MockMLSServiceMockRecorder) GetMessages(vaultId, afterSeq, limit, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockMLSService)(nil).GetMessages), vaultId, afterSeq, limit, auth)
+}
+
+// GetWelcomes mocks base method.
+func (m *MockMLSService) GetWelcomes(auth dto.AuthInput) ([]dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWelcomes", auth)
+	ret0, _ := ret[0].([]dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWelcomes indicates an expected call of GetWelcomes.
+func (mr *MockMLSServiceMockRecorder) GetWelcomes(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWelcomes", reflect.TypeOf((*MockMLSService)(nil).GetWelcomes), auth)
+}
+
+// SendCommit mocks base method.
+func (m *MockMLSService) SendCommit(vaultId string, input dto.CommitInput) (*dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCommit", vaultId, input)
+	ret0, _ := ret[0].(*dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendCommit indicates an expected call of SendCommit.
+func (mr *MockMLSServiceMockRecorder) SendCommit(vaultId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCommit", reflect.TypeOf((*MockMLSService)(nil).SendCommit), vaultId, input)
+}
+
+// SendProposal mocks base method.
+func (m *MockMLSService) SendProposal(vaultId string, input dto.ProposalInput) (*dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendProposal", vaultId, input)
+	ret0, _ := ret[0].(*dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendProposal indicates an expected call of SendProposal.
+func (mr *MockMLSServiceMockRecorder) SendProposal(vaultId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendProposal", reflect.TypeOf((*MockMLSService)(nil).SendProposal), vaultId, input)
+}
+
+// UploadKeyPackages mocks base method.
+func (m *MockMLSService) UploadKeyPackages(input dto.KeyPackagesInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadKeyPackages", input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadKeyPackages indicates an expected call of UploadKeyPackages.
+func (mr *MockMLSServiceMockRecorder) UploadKeyPackages(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadKeyPackages", reflect.TypeOf((*MockMLSService)(nil).UploadKeyPackages), input)
+}
@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/oplog/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/oplog/repository/repository.go -destination=./mocks/oplog_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/oplog/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOplogRepository is a mock of OplogRepository interface.
+type MockOplogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOplogRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOplogRepositoryMockRecorder is the mock recorder for MockOplogRepository.
+type MockOplogRepositoryMockRecorder struct {
+	mock *MockOplogRepository
+}
+
+// NewMockOplogRepository creates a new mock instance.
+func NewMockOplogRepository(ctrl *gomock.Controller) *MockOplogRepository {
+	mock := &MockOplogRepository{ctrl: ctrl}
+	mock.recorder = &MockOplogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOplogRepository) EXPECT() *MockOplogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// PullOperations mocks base method.
+func (m *MockOplogRepository) PullOperations(keyId string, afterSeq, limit int64) (*dto.OperationsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PullOperations", keyId, afterSeq, limit)
+	ret0, _ := ret[0].(*dto.OperationsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PullOperations indicates an expected call of PullOperations.
+func (mr *MockOplogRepositoryMockRecorder) PullOperations(keyId, afterSeq, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PullOperations", reflect.TypeOf((*MockOplogRepository)(nil).PullOperations), keyId, afterSeq, limit)
+}
+
+// PushOperations mocks base method.
+func (m *MockOplogRepository) PushOperations(keyId string, authorId int64, operations []dto.OperationInput) (*dto.PushResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushOperations", keyId, authorId, operations)
+	ret0, _ := ret[0].(*dto.PushResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PushOperations indicates an expected call of PushOperations.
+func (mr *MockOplogRepositoryMockRecorder) PushOperations(keyId, authorId, operations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushOperations", reflect.TypeOf((*MockOplogRepository)(nil).PushOperations), keyId, authorId, operations)
+}
+
+// SaveSnapshot mocks base method.
+func (m *MockOplogRepository) SaveSnapshot(keyId string, snapshot dto.SnapshotInput, keep int) (*dto.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSnapshot", keyId, snapshot, keep)
+	ret0, _ := ret[0].(*dto.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveSnapshot indicates an expected call of SaveSnapshot.
+func (mr *MockOplogRepositoryMockRecorder) SaveSnapshot(keyId, snapshot, keep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSnapshot", reflect.TypeOf((*MockOplogRepository)(nil).SaveSnapshot), keyId, snapshot, keep)
+}
@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/oplog/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/oplog/service/service.go -destination=./mocks/oplog_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/oplog/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOplogService is a mock of OplogService interface.
+type MockOplogService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOplogServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockOplogServiceMockRecorder is the mock recorder for MockOplogService.
+type MockOplogServiceMockRecorder struct {
+	mock *MockOplogService
+}
+
+// NewMockOplogService creates a new mock instance.
+func NewMockOplogService(ctrl *gomock.Controller) *MockOplogService {
+	mock := &MockOplogService{ctrl: ctrl}
+	mock.recorder = &MockOplogServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOplogService) EXPECT() *MockOplogServiceMockRecorder {
+	return m.recorder
+}
+
+// PullOperations mocks base method.
+func (m *MockOplogService) PullOperations(keyId string, afterSeq, limit int64, auth dto.AuthInput) (*dto.OperationsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PullOperations", keyId, afterSeq, limit, auth)
+	ret0, _ := ret[0].(*dto.OperationsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PullOperations indicates an expected call of PullOperations.
+func (mr *MockOplogServiceMockRecorder) PullOperations(keyId, afterSeq, limit, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PullOperations", reflect.TypeOf((*MockOplogService)(nil).PullOperations), keyId, afterSeq, limit, auth)
+}
+
+// PushOperations mocks base method.
+func (m *MockOplogService) PushOperations(keyId string, input dto.PushInput) (*dto.PushResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PushOperations", keyId, input)
+	ret0, _ := ret[0].(*dto.PushResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PushOperations indicates an expected call of PushOperations.
+func (mr *MockOplogServiceMockRecorder) PushOperations(keyId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushOperations", reflect.TypeOf((*MockOplogService)(nil).PushOperations), keyId, input)
+}
+
+// SaveSnapshot mocks base method.
+func (m *MockOplogService) SaveSnapshot(keyId string, input dto.SnapshotInput) (*dto.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSnapshot", keyId, input)
+	ret0, _ := ret[0].(*dto.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveSnapshot indicates an expected call of SaveSnapshot.
+func (mr *MockOplogServiceMockRecorder) SaveSnapshot(keyId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSnapshot", reflect.TypeOf((*MockOplogService)(nil).SaveSnapshot), keyId, input)
+}
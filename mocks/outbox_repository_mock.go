@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/outbox/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/outbox/repository/repository.go -destination=./mocks/outbox_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	dto "github.com/ObscuraNote/api-general/internal/outbox/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimMessages mocks base method.
+func (m *MockOutboxRepository) ClaimMessages(limit int) ([]dto.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimMessages", limit)
+	ret0, _ := ret[0].([]dto.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimMessages indicates an expected call of ClaimMessages.
+func (mr *MockOutboxRepositoryMockRecorder) ClaimMessages(limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimMessages", reflect.TypeOf((*MockOutboxRepository)(nil).ClaimMessages), limit)
+}
+
+// DeleteOldDelivered mocks base method.
+func (m *MockOutboxRepository) DeleteOldDelivered() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldDelivered")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOldDelivered indicates an expected call of DeleteOldDelivered.
+func (mr *MockOutboxRepositoryMockRecorder) DeleteOldDelivered() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldDelivered", reflect.TypeOf((*MockOutboxRepository)(nil).DeleteOldDelivered))
+}
+
+// MarkDelivered mocks base method.
+func (m *MockOutboxRepository) MarkDelivered(id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDelivered", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDelivered indicates an expected call of MarkDelivered.
+func (mr *MockOutboxRepositoryMockRecorder) MarkDelivered(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDelivered", reflect.TypeOf((*MockOutboxRepository)(nil).MarkDelivered), id)
+}
+
+// MarkFailed mocks base method.
+func (m *MockOutboxRepository) MarkFailed(id int64, reason string, backoff time.Duration, dead bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", id, reason, backoff, dead)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockOutboxRepositoryMockRecorder) MarkFailed(id, reason, backoff, dead any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockOutboxRepository)(nil).MarkFailed), id, reason, backoff, dead)
+}
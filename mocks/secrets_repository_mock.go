@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/secrets/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/secrets/repository/repository.go -destination=./mocks/secrets_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	dto "github.com/ObscuraNote/api-general/internal/secrets/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSecretsRepository is a mock of SecretsRepository interface.
+type MockSecretsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSecretsRepositoryMockRecorder is the mock recorder for MockSecretsRepository.
+type MockSecretsRepositoryMockRecorder struct {
+	mock *MockSecretsRepository
+}
+
+// NewMockSecretsRepository creates a new mock instance.
+func NewMockSecretsRepository(ctrl *gomock.Controller) *MockSecretsRepository {
+	mock := &MockSecretsRepository{ctrl: ctrl}
+	mock.recorder = &MockSecretsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretsRepository) EXPECT() *MockSecretsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeSecret mocks base method.
+func (m *MockSecretsRepository) ConsumeSecret(id string) (*dto.SecretOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeSecret", id)
+	ret0, _ := ret[0].(*dto.SecretOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeSecret indicates an expected call of ConsumeSecret.
+func (mr *MockSecretsRepositoryMockRecorder) ConsumeSecret(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeSecret", reflect.TypeOf((*MockSecretsRepository)(nil).ConsumeSecret), id)
+}
+
+// CreateSecret mocks base method.
+func (m *MockSecretsRepository) CreateSecret(id string, ciphertext []byte, maxViews int, expiresAt time.Time) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecret", id, ciphertext, maxViews, expiresAt)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecret indicates an expected call of CreateSecret.
+func (mr *MockSecretsRepositoryMockRecorder) CreateSecret(id, ciphertext, maxViews, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecret", reflect.TypeOf((*MockSecretsRepository)(nil).CreateSecret), id, ciphertext, maxViews, expiresAt)
+}
+
+// DeleteExpiredSecrets mocks base method.
+func (m *MockSecretsRepository) DeleteExpiredSecrets() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSecrets")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredSecrets indicates an expected call of DeleteExpiredSecrets.
+func (mr *MockSecretsRepositoryMockRecorder) DeleteExpiredSecrets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSecrets", reflect.TypeOf((*MockSecretsRepository)(nil).DeleteExpiredSecrets))
+}
@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/secrets/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/secrets/service/service.go -destination=./mocks/secrets_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/secrets/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSecretsService is a mock of SecretsService interface.
+type MockSecretsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretsServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockSecretsServiceMockRecorder is the mock recorder for MockSecretsService.
+type MockSecretsServiceMockRecorder struct {
+	mock *MockSecretsService
+}
+
+// NewMockSecretsService creates a new mock instance.
+func NewMockSecretsService(ctrl *gomock.Controller) *MockSecretsService {
+	mock := &MockSecretsService{ctrl: ctrl}
+	mock.recorder = &MockSecretsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretsService) EXPECT() *MockSecretsServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateSecret mocks base method.
+func (m *MockSecretsService) CreateSecret(input dto.SecretInput) (*dto.SecretCreated, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecret", input)
+	ret0, _ := ret[0].(*dto.SecretCreated)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecret indicates an expected call of CreateSecret.
+func (mr *MockSecretsServiceMockRecorder) CreateSecret(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecret", reflect.TypeOf((*MockSecretsService)(nil).CreateSecret), input)
+}
+
+// GetSecret mocks base method.
+func (m *MockSecretsService) GetSecret(id string) (*dto.SecretOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecret", id)
+	ret0, _ := ret[0].(*dto.SecretOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecret indicates an expected call of GetSecret.
+func (mr *MockSecretsServiceMockRecorder) GetSecret(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockSecretsService)(nil).GetSecret), id)
+}
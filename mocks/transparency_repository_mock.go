@@ -0,0 +1,190 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/transparency/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/transparency/repository/repository.go -destination=./mocks/transparency_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/transparency/dto"
+	merkle "github.com/ObscuraNote/api-general/internal/transparency/merkle"
+	sqlx "github.com/jmoiron/sqlx"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTransparencyRepository is a mock of TransparencyRepository interface.
+type MockTransparencyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransparencyRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTransparencyRepositoryMockRecorder is the mock recorder for MockTransparencyRepository.
+type MockTransparencyRepositoryMockRecorder struct {
+	mock *MockTransparencyRepository
+}
+
+// NewMockTransparencyRepository creates a new mock instance.
+func NewMockTransparencyRepository(ctrl *gomock.Controller) *MockTransparencyRepository {
+	mock := &MockTransparencyRepository{ctrl: ctrl}
+	mock.recorder = &MockTransparencyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransparencyRepository) EXPECT() *MockTransparencyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindLeafIndex mocks base method.
+func (m *MockTransparencyRepository) FindLeafIndex(userAddress string, version int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLeafIndex", userAddress, version)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLeafIndex indicates an expected call of FindLeafIndex.
+func (mr *MockTransparencyRepositoryMockRecorder) FindLeafIndex(userAddress, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLeafIndex", reflect.TypeOf((*MockTransparencyRepository)(nil).FindLeafIndex), userAddress, version)
+}
+
+// GetLatestTreeHead mocks base method.
+func (m *MockTransparencyRepository) GetLatestTreeHead() (*dto.TreeHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestTreeHead")
+	ret0, _ := ret[0].(*dto.TreeHead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestTreeHead indicates an expected call of GetLatestTreeHead.
+func (mr *MockTransparencyRepositoryMockRecorder) GetLatestTreeHead() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestTreeHead", reflect.TypeOf((*MockTransparencyRepository)(nil).GetLatestTreeHead))
+}
+
+// GetLatestTreeHeadTx mocks base method.
+func (m *MockTransparencyRepository) GetLatestTreeHeadTx(ctx context.Context, tx *sqlx.Tx) (*dto.TreeHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestTreeHeadTx", ctx, tx)
+	ret0, _ := ret[0].(*dto.TreeHead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestTreeHeadTx indicates an expected call of GetLatestTreeHeadTx.
+func (mr *MockTransparencyRepositoryMockRecorder) GetLatestTreeHeadTx(ctx, tx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestTreeHeadTx", reflect.TypeOf((*MockTransparencyRepository)(nil).GetLatestTreeHeadTx), ctx, tx)
+}
+
+// GetLeaves mocks base method.
+func (m *MockTransparencyRepository) GetLeaves(start, end int64) ([]dto.LogLeaf, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLeaves", start, end)
+	ret0, _ := ret[0].([]dto.LogLeaf)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLeaves indicates an expected call of GetLeaves.
+func (mr *MockTransparencyRepositoryMockRecorder) GetLeaves(start, end any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeaves", reflect.TypeOf((*MockTransparencyRepository)(nil).GetLeaves), start, end)
+}
+
+// GetNodes mocks base method.
+func (m *MockTransparencyRepository) GetNodes(nodes []merkle.Node) (map[merkle.Node][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNodes", nodes)
+	ret0, _ := ret[0].(map[merkle.Node][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNodes indicates an expected call of GetNodes.
+func (mr *MockTransparencyRepositoryMockRecorder) GetNodes(nodes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodes", reflect.TypeOf((*MockTransparencyRepository)(nil).GetNodes), nodes)
+}
+
+// GetTreeHead mocks base method.
+func (m *MockTransparencyRepository) GetTreeHead(treeSize int64) (*dto.TreeHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTreeHead", treeSize)
+	ret0, _ := ret[0].(*dto.TreeHead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeHead indicates an expected call of GetTreeHead.
+func (mr *MockTransparencyRepositoryMockRecorder) GetTreeHead(treeSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeHead", reflect.TypeOf((*MockTransparencyRepository)(nil).GetTreeHead), treeSize)
+}
+
+// InsertLeafTx mocks base method.
+func (m *MockTransparencyRepository) InsertLeafTx(ctx context.Context, tx *sqlx.Tx, leaf dto.LogLeaf, entry dto.LogEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLeafTx", ctx, tx, leaf, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertLeafTx indicates an expected call of InsertLeafTx.
+func (mr *MockTransparencyRepositoryMockRecorder) InsertLeafTx(ctx, tx, leaf, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLeafTx", reflect.TypeOf((*MockTransparencyRepository)(nil).InsertLeafTx), ctx, tx, leaf, entry)
+}
+
+// InsertNodesTx mocks base method.
+func (m *MockTransparencyRepository) InsertNodesTx(ctx context.Context, tx *sqlx.Tx, leafIndex int64, hashes [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertNodesTx", ctx, tx, leafIndex, hashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertNodesTx indicates an expected call of InsertNodesTx.
+func (mr *MockTransparencyRepositoryMockRecorder) InsertNodesTx(ctx, tx, leafIndex, hashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNodesTx", reflect.TypeOf((*MockTransparencyRepository)(nil).InsertNodesTx), ctx, tx, leafIndex, hashes)
+}
+
+// InsertTreeHeadTx mocks base method.
+func (m *MockTransparencyRepository) InsertTreeHeadTx(ctx context.Context, tx *sqlx.Tx, head dto.TreeHead) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTreeHeadTx", ctx, tx, head)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertTreeHeadTx indicates an expected call of InsertTreeHeadTx.
+func (mr *MockTransparencyRepositoryMockRecorder) InsertTreeHeadTx(ctx, tx, head any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTreeHeadTx", reflect.TypeOf((*MockTransparencyRepository)(nil).InsertTreeHeadTx), ctx, tx, head)
+}
+
+// LockLogTx mocks base method.
+func (m *MockTransparencyRepository) LockLogTx(ctx context.Context, tx *sqlx.Tx) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLogTx", ctx, tx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLogTx indicates an expected call of LockLogTx.
+func (mr *MockTransparencyRepositoryMockRecorder) LockLogTx(ctx, tx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogTx", reflect.TypeOf((*MockTransparencyRepository)(nil).LockLogTx), ctx, tx)
+}
@@ -0,0 +1,184 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/transparency/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/transparency/service/service.go -destination=./mocks/transparency_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/transparency/dto"
+	sqlx "github.com/jmoiron/sqlx"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAppender is a mock of Appender interface.
+type MockAppender struct {
+	ctrl     *gomock.Controller
+	recorder *MockAppenderMockRecorder
+	isgomock struct{}
+}
+
+// MockAppenderMockRecorder is the mock recorder for MockAppender.
+type MockAppenderMockRecorder struct {
+	mock *MockAppender
+}
+
+// NewMockAppender creates a new mock instance.
+func NewMockAppender(ctrl *gomock.Controller) *MockAppender {
+	mock := &MockAppender{ctrl: ctrl}
+	mock.recorder = &MockAppenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAppender) EXPECT() *MockAppenderMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockAppender) Append(ctx context.Context, tx *sqlx.Tx, entry dto.LogEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, tx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockAppenderMockRecorder) Append(ctx, tx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockAppender)(nil).Append), ctx, tx, entry)
+}
+
+// MockTransparencyService is a mock of TransparencyService interface.
+type MockTransparencyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransparencyServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockTransparencyServiceMockRecorder is the mock recorder for MockTransparencyService.
+type MockTransparencyServiceMockRecorder struct {
+	mock *MockTransparencyService
+}
+
+// NewMockTransparencyService creates a new mock instance.
+func NewMockTransparencyService(ctrl *gomock.Controller) *MockTransparencyService {
+	mock := &MockTransparencyService{ctrl: ctrl}
+	mock.recorder = &MockTransparencyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransparencyService) EXPECT() *MockTransparencyServiceMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockTransparencyService) Append(ctx context.Context, tx *sqlx.Tx, entry dto.LogEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, tx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockTransparencyServiceMockRecorder) Append(ctx, tx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockTransparencyService)(nil).Append), ctx, tx, entry)
+}
+
+// GetConsistencyProof mocks base method.
+func (m *MockTransparencyService) GetConsistencyProof(first, second int64) (*dto.ConsistencyProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConsistencyProof", first, second)
+	ret0, _ := ret[0].(*dto.ConsistencyProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConsistencyProof indicates an expected call of GetConsistencyProof.
+func (mr *MockTransparencyServiceMockRecorder) GetConsistencyProof(first, second any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsistencyProof", reflect.TypeOf((*MockTransparencyService)(nil).GetConsistencyProof), first, second)
+}
+
+// GetEntries mocks base method.
+func (m *MockTransparencyService) GetEntries(start, end int64) ([]dto.LogLeaf, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntries", start, end)
+	ret0, _ := ret[0].([]dto.LogLeaf)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntries indicates an expected call of GetEntries.
+func (mr *MockTransparencyServiceMockRecorder) GetEntries(start, end any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntries", reflect.TypeOf((*MockTransparencyService)(nil).GetEntries), start, end)
+}
+
+// GetInclusionProof mocks base method.
+func (m *MockTransparencyService) GetInclusionProof(leafIndex, treeSize int64) (*dto.InclusionProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInclusionProof", leafIndex, treeSize)
+	ret0, _ := ret[0].(*dto.InclusionProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInclusionProof indicates an expected call of GetInclusionProof.
+func (mr *MockTransparencyServiceMockRecorder) GetInclusionProof(leafIndex, treeSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInclusionProof", reflect.TypeOf((*MockTransparencyService)(nil).GetInclusionProof), leafIndex, treeSize)
+}
+
+// GetInclusionProofByKey mocks base method.
+func (m *MockTransparencyService) GetInclusionProofByKey(userAddress string, version int, treeSize int64) (*dto.InclusionProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInclusionProofByKey", userAddress, version, treeSize)
+	ret0, _ := ret[0].(*dto.InclusionProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInclusionProofByKey indicates an expected call of GetInclusionProofByKey.
+func (mr *MockTransparencyServiceMockRecorder) GetInclusionProofByKey(userAddress, version, treeSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInclusionProofByKey", reflect.TypeOf((*MockTransparencyService)(nil).GetInclusionProofByKey), userAddress, version, treeSize)
+}
+
+// GetTreeHead mocks base method.
+func (m *MockTransparencyService) GetTreeHead(treeSize int64) (*dto.TreeHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTreeHead", treeSize)
+	ret0, _ := ret[0].(*dto.TreeHead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeHead indicates an expected call of GetTreeHead.
+func (mr *MockTransparencyServiceMockRecorder) GetTreeHead(treeSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeHead", reflect.TypeOf((*MockTransparencyService)(nil).GetTreeHead), treeSize)
+}
+
+// PublicKey mocks base method.
+func (m *MockTransparencyService) PublicKey() dto.LogPublicKey {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicKey")
+	ret0, _ := ret[0].(dto.LogPublicKey)
+	return ret0
+}
+
+// PublicKey indicates an expected call of PublicKey.
+func (mr *MockTransparencyServiceMockRecorder) PublicKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKey", reflect.TypeOf((*MockTransparencyService)(nil).PublicKey))
+}
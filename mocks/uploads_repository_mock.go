@@ -0,0 +1,132 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/uploads/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/uploads/repository/repository.go -destination=./mocks/uploads_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	dto "github.com/ObscuraNote/api-general/internal/uploads/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUploadsRepository is a mock of UploadsRepository interface.
+type MockUploadsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUploadsRepositoryMockRecorder is the mock recorder for MockUploadsRepository.
+type MockUploadsRepositoryMockRecorder struct {
+	mock *MockUploadsRepository
+}
+
+// NewMockUploadsRepository creates a new mock instance.
+func NewMockUploadsRepository(ctrl *gomock.Controller) *MockUploadsRepository {
+	mock := &MockUploadsRepository{ctrl: ctrl}
+	mock.recorder = &MockUploadsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadsRepository) EXPECT() *MockUploadsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddSegment mocks base method.
+func (m *MockUploadsRepository) AddSegment(id string, offset, size int64, blobKey string, expiresAt time.Time) (*dto.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSegment", id, offset, size, blobKey, expiresAt)
+	ret0, _ := ret[0].(*dto.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSegment indicates an expected call of AddSegment.
+func (mr *MockUploadsRepositoryMockRecorder) AddSegment(id, offset, size, blobKey, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSegment", reflect.TypeOf((*MockUploadsRepository)(nil).AddSegment), id, offset, size, blobKey, expiresAt)
+}
+
+// CreateUpload mocks base method.
+func (m *MockUploadsRepository) CreateUpload(userId, length int64, metadata *string, expiresAt time.Time) (*dto.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpload", userId, length, metadata, expiresAt)
+	ret0, _ := ret[0].(*dto.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpload indicates an expected call of CreateUpload.
+func (mr *MockUploadsRepositoryMockRecorder) CreateUpload(userId, length, metadata, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpload", reflect.TypeOf((*MockUploadsRepository)(nil).CreateUpload), userId, length, metadata, expiresAt)
+}
+
+// DeleteExpiredUploads mocks base method.
+func (m *MockUploadsRepository) DeleteExpiredUploads() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredUploads")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredUploads indicates an expected call of DeleteExpiredUploads.
+func (mr *MockUploadsRepositoryMockRecorder) DeleteExpiredUploads() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredUploads", reflect.TypeOf((*MockUploadsRepository)(nil).DeleteExpiredUploads))
+}
+
+// DeleteUpload mocks base method.
+func (m *MockUploadsRepository) DeleteUpload(userId int64, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUpload", userId, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUpload indicates an expected call of DeleteUpload.
+func (mr *MockUploadsRepositoryMockRecorder) DeleteUpload(userId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUpload", reflect.TypeOf((*MockUploadsRepository)(nil).DeleteUpload), userId, id)
+}
+
+// GetSegments mocks base method.
+func (m *MockUploadsRepository) GetSegments(id string) ([]dto.Segment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegments", id)
+	ret0, _ := ret[0].([]dto.Segment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegments indicates an expected call of GetSegments.
+func (mr *MockUploadsRepositoryMockRecorder) GetSegments(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegments", reflect.TypeOf((*MockUploadsRepository)(nil).GetSegments), id)
+}
+
+// GetUpload mocks base method.
+func (m *MockUploadsRepository) GetUpload(userId int64, id string) (*dto.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUpload", userId, id)
+	ret0, _ := ret[0].(*dto.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUpload indicates an expected call of GetUpload.
+func (mr *MockUploadsRepositoryMockRecorder) GetUpload(userId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpload", reflect.TypeOf((*MockUploadsRepository)(nil).GetUpload), userId, id)
+}
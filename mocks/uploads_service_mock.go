@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/uploads/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/uploads/service/service.go -destination=./mocks/uploads_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	io "io"
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/uploads/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUploadsService is a mock of UploadsService interface.
+type MockUploadsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadsServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockUploadsServiceMockRecorder is the mock recorder for MockUploadsService.
+type MockUploadsServiceMockRecorder struct {
+	mock *MockUploadsService
+}
+
+// NewMockUploadsService creates a new mock instance.
+func NewMockUploadsService(ctrl *gomock.Controller) *MockUploadsService {
+	mock := &MockUploadsService{ctrl: ctrl}
+	mock.recorder = &MockUploadsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadsService) EXPECT() *MockUploadsServiceMockRecorder {
+	return m.recorder
+}
+
+// AppendUpload mocks base method.
+func (m *MockUploadsService) AppendUpload(id string, offset int64, body io.Reader, auth dto.AuthInput) (*dto.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendUpload", id, offset, body, auth)
+	ret0, _ := ret[0].(*dto.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppendUpload indicates an expected call of AppendUpload.
+func (mr *MockUploadsServiceMockRecorder) AppendUpload(id, offset, body, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendUpload", reflect.TypeOf((*MockUploadsService)(nil).AppendUpload), id, offset, body, auth)
+}
+
+// CreateUpload mocks base method.
+func (m *MockUploadsService) CreateUpload(length int64, metadata string, auth dto.AuthInput) (*dto.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUpload", length, metadata, auth)
+	ret0, _ := ret[0].(*dto.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUpload indicates an expected call of CreateUpload.
+func (mr *MockUploadsServiceMockRecorder) CreateUpload(length, metadata, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUpload", reflect.TypeOf((*MockUploadsService)(nil).CreateUpload), length, metadata, auth)
+}
+
+// DeleteUpload mocks base method.
+func (m *MockUploadsService) DeleteUpload(id string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUpload", id, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUpload indicates an expected call of DeleteUpload.
+func (mr *MockUploadsServiceMockRecorder) DeleteUpload(id, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUpload", reflect.TypeOf((*MockUploadsService)(nil).DeleteUpload), id, auth)
+}
+
+// GetUpload mocks base method.
+func (m *MockUploadsService) GetUpload(id string, auth dto.AuthInput) (*dto.Upload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUpload", id, auth)
+	ret0, _ := ret[0].(*dto.Upload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUpload indicates an expected call of GetUpload.
+func (mr *MockUploadsServiceMockRecorder) GetUpload(id, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpload", reflect.TypeOf((*MockUploadsService)(nil).GetUpload), id, auth)
+}
+
+// ReadUpload mocks base method.
+func (m *MockUploadsService) ReadUpload(id string, userId int64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadUpload", id, userId)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadUpload indicates an expected call of ReadUpload.
+func (mr *MockUploadsServiceMockRecorder) ReadUpload(id, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadUpload", reflect.TypeOf((*MockUploadsService)(nil).ReadUpload), id, userId)
+}
+
+// ReleaseUpload mocks base method.
+func (m *MockUploadsService) ReleaseUpload(id string, userId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseUpload", id, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseUpload indicates an expected call of ReleaseUpload.
+func (mr *MockUploadsServiceMockRecorder) ReleaseUpload(id, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseUpload", reflect.TypeOf((*MockUploadsService)(nil).ReleaseUpload), id, userId)
+}
@@ -12,6 +12,8 @@ package mocks
 import (
 	reflect "reflect"
 
+	dto "github.com/ObscuraNote/api-general/internal/users/dto"
+	repository "github.com/ObscuraNote/api-general/internal/users/repository"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,6 +41,35 @@ func (m *MockUsersRepository) EXPECT() *MockUsersRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddPublicKeys mocks base method.
+func (m *MockUsersRepository) AddPublicKeys(userId int64, keys dto.PublicKeysInput, hook repository.TxHook) (*dto.PublicKeys, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPublicKeys", userId, keys, hook)
+	ret0, _ := ret[0].(*dto.PublicKeys)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPublicKeys indicates an expected call of AddPublicKeys.
+func (mr *MockUsersRepositoryMockRecorder) AddPublicKeys(userId, keys, hook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPublicKeys", reflect.TypeOf((*MockUsersRepository)(nil).AddPublicKeys), userId, keys, hook)
+}
+
+// AddVouchers mocks base method.
+func (m *MockUsersRepository) AddVouchers(planId string, durationDays int, codeHashes [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVouchers", planId, durationDays, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddVouchers indicates an expected call of AddVouchers.
+func (mr *MockUsersRepositoryMockRecorder) AddVouchers(planId, durationDays, codeHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVouchers", reflect.TypeOf((*MockUsersRepository)(nil).AddVouchers), planId, durationDays, codeHashes)
+}
+
 // CheckUserExists mocks base method.
 func (m *MockUsersRepository) CheckUserExists(userAddress, password string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -68,19 +99,124 @@ func (mr *MockUsersRepositoryMockRecorder) CreateUser(userAddress, password any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUsersRepository)(nil).CreateUser), userAddress, password)
 }
 
+// DeleteEmergencyContact mocks base method.
+func (m *MockUsersRepository) DeleteEmergencyContact(ownerId, contactId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEmergencyContact", ownerId, contactId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteEmergencyContact indicates an expected call of DeleteEmergencyContact.
+func (mr *MockUsersRepositoryMockRecorder) DeleteEmergencyContact(ownerId, contactId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmergencyContact", reflect.TypeOf((*MockUsersRepository)(nil).DeleteEmergencyContact), ownerId, contactId)
+}
+
 // DeleteUser mocks base method.
-func (m *MockUsersRepository) DeleteUser(userId int64) (bool, error) {
+func (m *MockUsersRepository) DeleteUser(userId int64, hook repository.TxHook) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", userId)
+	ret := m.ctrl.Call(m, "DeleteUser", userId, hook)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockUsersRepositoryMockRecorder) DeleteUser(userId any) *gomock.Call {
+func (mr *MockUsersRepositoryMockRecorder) DeleteUser(userId, hook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUsersRepository)(nil).DeleteUser), userId, hook)
+}
+
+// GetEmergencyContacts mocks base method.
+func (m *MockUsersRepository) GetEmergencyContacts(ownerId int64) ([]dto.EmergencyContact, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyContacts", ownerId)
+	ret0, _ := ret[0].([]dto.EmergencyContact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyContacts indicates an expected call of GetEmergencyContacts.
+func (mr *MockUsersRepositoryMockRecorder) GetEmergencyContacts(ownerId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyContacts", reflect.TypeOf((*MockUsersRepository)(nil).GetEmergencyContacts), ownerId)
+}
+
+// GetEmergencyGrants mocks base method.
+func (m *MockUsersRepository) GetEmergencyGrants(contactId int64) ([]dto.EmergencyGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyGrants", contactId)
+	ret0, _ := ret[0].([]dto.EmergencyGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyGrants indicates an expected call of GetEmergencyGrants.
+func (mr *MockUsersRepositoryMockRecorder) GetEmergencyGrants(contactId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyGrants", reflect.TypeOf((*MockUsersRepository)(nil).GetEmergencyGrants), contactId)
+}
+
+// GetLatestPublicKeys mocks base method.
+func (m *MockUsersRepository) GetLatestPublicKeys(userId int64) (*dto.PublicKeys, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestPublicKeys", userId)
+	ret0, _ := ret[0].(*dto.PublicKeys)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestPublicKeys indicates an expected call of GetLatestPublicKeys.
+func (mr *MockUsersRepositoryMockRecorder) GetLatestPublicKeys(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestPublicKeys", reflect.TypeOf((*MockUsersRepository)(nil).GetLatestPublicKeys), userId)
+}
+
+// GetPlan mocks base method.
+func (m *MockUsersRepository) GetPlan(userId int64) (*dto.Entitlements, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlan", userId)
+	ret0, _ := ret[0].(*dto.Entitlements)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlan indicates an expected call of GetPlan.
+func (mr *MockUsersRepositoryMockRecorder) GetPlan(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlan", reflect.TypeOf((*MockUsersRepository)(nil).GetPlan), userId)
+}
+
+// GetPublicKeys mocks base method.
+func (m *MockUsersRepository) GetPublicKeys(userAddress string) ([]dto.PublicKeys, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicKeys", userAddress)
+	ret0, _ := ret[0].([]dto.PublicKeys)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicKeys indicates an expected call of GetPublicKeys.
+func (mr *MockUsersRepositoryMockRecorder) GetPublicKeys(userAddress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicKeys", reflect.TypeOf((*MockUsersRepository)(nil).GetPublicKeys), userAddress)
+}
+
+// GetUsage mocks base method.
+func (m *MockUsersRepository) GetUsage(userId int64) (*dto.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", userId)
+	ret0, _ := ret[0].(*dto.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockUsersRepositoryMockRecorder) GetUsage(userId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUsersRepository)(nil).DeleteUser), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockUsersRepository)(nil).GetUsage), userId)
 }
 
 // GetUserId mocks base method.
@@ -98,6 +234,80 @@ func (mr *MockUsersRepositoryMockRecorder) GetUserId(userAddress, password any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserId", reflect.TypeOf((*MockUsersRepository)(nil).GetUserId), userAddress, password)
 }
 
+// GetUserIdByAddress mocks base method.
+func (m *MockUsersRepository) GetUserIdByAddress(userAddress string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdByAddress", userAddress)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdByAddress indicates an expected call of GetUserIdByAddress.
+func (mr *MockUsersRepositoryMockRecorder) GetUserIdByAddress(userAddress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdByAddress", reflect.TypeOf((*MockUsersRepository)(nil).GetUserIdByAddress), userAddress)
+}
+
+// ProcessEmergencyAccess mocks base method.
+func (m *MockUsersRepository) ProcessEmergencyAccess() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessEmergencyAccess")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessEmergencyAccess indicates an expected call of ProcessEmergencyAccess.
+func (mr *MockUsersRepositoryMockRecorder) ProcessEmergencyAccess() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessEmergencyAccess", reflect.TypeOf((*MockUsersRepository)(nil).ProcessEmergencyAccess))
+}
+
+// RedeemVoucher mocks base method.
+func (m *MockUsersRepository) RedeemVoucher(userId int64, codeHash []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemVoucher", userId, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RedeemVoucher indicates an expected call of RedeemVoucher.
+func (mr *MockUsersRepositoryMockRecorder) RedeemVoucher(userId, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemVoucher", reflect.TypeOf((*MockUsersRepository)(nil).RedeemVoucher), userId, codeHash)
+}
+
+// RejectEmergencyRequest mocks base method.
+func (m *MockUsersRepository) RejectEmergencyRequest(ownerId, contactId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectEmergencyRequest", ownerId, contactId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectEmergencyRequest indicates an expected call of RejectEmergencyRequest.
+func (mr *MockUsersRepositoryMockRecorder) RejectEmergencyRequest(ownerId, contactId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectEmergencyRequest", reflect.TypeOf((*MockUsersRepository)(nil).RejectEmergencyRequest), ownerId, contactId)
+}
+
+// RequestEmergencyAccess mocks base method.
+func (m *MockUsersRepository) RequestEmergencyAccess(ownerId, contactId int64) (*dto.EmergencyGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmergencyAccess", ownerId, contactId)
+	ret0, _ := ret[0].(*dto.EmergencyGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestEmergencyAccess indicates an expected call of RequestEmergencyAccess.
+func (mr *MockUsersRepositoryMockRecorder) RequestEmergencyAccess(ownerId, contactId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmergencyAccess", reflect.TypeOf((*MockUsersRepository)(nil).RequestEmergencyAccess), ownerId, contactId)
+}
+
 // UpdatePassword mocks base method.
 func (m *MockUsersRepository) UpdatePassword(userId int64, password string) error {
 	m.ctrl.T.Helper()
@@ -111,3 +321,32 @@ func (mr *MockUsersRepositoryMockRecorder) UpdatePassword(userId, password any)
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUsersRepository)(nil).UpdatePassword), userId, password)
 }
+
+// UpsertEmergencyContact mocks base method.
+func (m *MockUsersRepository) UpsertEmergencyContact(ownerId, contactId int64, contact dto.EmergencyContactInput) (*dto.EmergencyContact, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEmergencyContact", ownerId, contactId, contact)
+	ret0, _ := ret[0].(*dto.EmergencyContact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertEmergencyContact indicates an expected call of UpsertEmergencyContact.
+func (mr *MockUsersRepositoryMockRecorder) UpsertEmergencyContact(ownerId, contactId, contact any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEmergencyContact", reflect.TypeOf((*MockUsersRepository)(nil).UpsertEmergencyContact), ownerId, contactId, contact)
+}
+
+// UpsertPlan mocks base method.
+func (m *MockUsersRepository) UpsertPlan(plan dto.Plan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertPlan", plan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertPlan indicates an expected call of UpsertPlan.
+func (mr *MockUsersRepositoryMockRecorder) UpsertPlan(plan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertPlan", reflect.TypeOf((*MockUsersRepository)(nil).UpsertPlan), plan)
+}
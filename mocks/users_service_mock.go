@@ -12,6 +12,7 @@ package mocks
 import (
 	reflect "reflect"
 
+	dto "github.com/ObscuraNote/api-general/internal/users/dto"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,6 +40,21 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// AddEmergencyContact mocks base method.
+func (m *MockUserService) AddEmergencyContact(contact dto.EmergencyContactInput) (*dto.EmergencyContact, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEmergencyContact", contact)
+	ret0, _ := ret[0].(*dto.EmergencyContact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddEmergencyContact indicates an expected call of AddEmergencyContact.
+func (mr *MockUserServiceMockRecorder) AddEmergencyContact(contact any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEmergencyContact", reflect.TypeOf((*MockUserService)(nil).AddEmergencyContact), contact)
+}
+
 // CheckUserExists mocks base method.
 func (m *MockUserService) CheckUserExists(userAddress, password string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -83,6 +99,96 @@ func (mr *MockUserServiceMockRecorder) DeleteUser(userAddress, password any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserService)(nil).DeleteUser), userAddress, password)
 }
 
+// GetEmergencyContacts mocks base method.
+func (m *MockUserService) GetEmergencyContacts(auth dto.UserInput) ([]dto.EmergencyContact, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyContacts", auth)
+	ret0, _ := ret[0].([]dto.EmergencyContact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyContacts indicates an expected call of GetEmergencyContacts.
+func (mr *MockUserServiceMockRecorder) GetEmergencyContacts(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyContacts", reflect.TypeOf((*MockUserService)(nil).GetEmergencyContacts), auth)
+}
+
+// GetEmergencyGrants mocks base method.
+func (m *MockUserService) GetEmergencyGrants(auth dto.UserInput) ([]dto.EmergencyGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmergencyGrants", auth)
+	ret0, _ := ret[0].([]dto.EmergencyGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmergencyGrants indicates an expected call of GetEmergencyGrants.
+func (mr *MockUserServiceMockRecorder) GetEmergencyGrants(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmergencyGrants", reflect.TypeOf((*MockUserService)(nil).GetEmergencyGrants), auth)
+}
+
+// GetEntitlements mocks base method.
+func (m *MockUserService) GetEntitlements(userId int64) (*dto.Entitlements, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntitlements", userId)
+	ret0, _ := ret[0].(*dto.Entitlements)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntitlements indicates an expected call of GetEntitlements.
+func (mr *MockUserServiceMockRecorder) GetEntitlements(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntitlements", reflect.TypeOf((*MockUserService)(nil).GetEntitlements), userId)
+}
+
+// GetPlan mocks base method.
+func (m *MockUserService) GetPlan(auth dto.UserInput) (*dto.Entitlements, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlan", auth)
+	ret0, _ := ret[0].(*dto.Entitlements)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlan indicates an expected call of GetPlan.
+func (mr *MockUserServiceMockRecorder) GetPlan(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlan", reflect.TypeOf((*MockUserService)(nil).GetPlan), auth)
+}
+
+// GetPublicKeys mocks base method.
+func (m *MockUserService) GetPublicKeys(userAddress string) (*dto.PublicKeyDirectory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicKeys", userAddress)
+	ret0, _ := ret[0].(*dto.PublicKeyDirectory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicKeys indicates an expected call of GetPublicKeys.
+func (mr *MockUserServiceMockRecorder) GetPublicKeys(userAddress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicKeys", reflect.TypeOf((*MockUserService)(nil).GetPublicKeys), userAddress)
+}
+
+// GetUsage mocks base method.
+func (m *MockUserService) GetUsage(auth dto.UserInput) (*dto.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", auth)
+	ret0, _ := ret[0].(*dto.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockUserServiceMockRecorder) GetUsage(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockUserService)(nil).GetUsage), auth)
+}
+
 // GetUserId mocks base method.
 func (m *MockUserService) GetUserId(userAddress, password string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -98,6 +204,94 @@ func (mr *MockUserServiceMockRecorder) GetUserId(userAddress, password any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserId", reflect.TypeOf((*MockUserService)(nil).GetUserId), userAddress, password)
 }
 
+// GetUserIdByAddress mocks base method.
+func (m *MockUserService) GetUserIdByAddress(userAddress string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdByAddress", userAddress)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdByAddress indicates an expected call of GetUserIdByAddress.
+func (mr *MockUserServiceMockRecorder) GetUserIdByAddress(userAddress any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdByAddress", reflect.TypeOf((*MockUserService)(nil).GetUserIdByAddress), userAddress)
+}
+
+// PublishPublicKeys mocks base method.
+func (m *MockUserService) PublishPublicKeys(keys dto.PublicKeysInput) (*dto.PublicKeys, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPublicKeys", keys)
+	ret0, _ := ret[0].(*dto.PublicKeys)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishPublicKeys indicates an expected call of PublishPublicKeys.
+func (mr *MockUserServiceMockRecorder) PublishPublicKeys(keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPublicKeys", reflect.TypeOf((*MockUserService)(nil).PublishPublicKeys), keys)
+}
+
+// RedeemVoucher mocks base method.
+func (m *MockUserService) RedeemVoucher(input dto.VoucherInput) (*dto.Entitlements, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemVoucher", input)
+	ret0, _ := ret[0].(*dto.Entitlements)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemVoucher indicates an expected call of RedeemVoucher.
+func (mr *MockUserServiceMockRecorder) RedeemVoucher(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemVoucher", reflect.TypeOf((*MockUserService)(nil).RedeemVoucher), input)
+}
+
+// RejectEmergencyRequest mocks base method.
+func (m *MockUserService) RejectEmergencyRequest(contactAddress string, auth dto.UserInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectEmergencyRequest", contactAddress, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectEmergencyRequest indicates an expected call of RejectEmergencyRequest.
+func (mr *MockUserServiceMockRecorder) RejectEmergencyRequest(contactAddress, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectEmergencyRequest", reflect.TypeOf((*MockUserService)(nil).RejectEmergencyRequest), contactAddress, auth)
+}
+
+// RemoveEmergencyContact mocks base method.
+func (m *MockUserService) RemoveEmergencyContact(contactAddress string, auth dto.UserInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveEmergencyContact", contactAddress, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveEmergencyContact indicates an expected call of RemoveEmergencyContact.
+func (mr *MockUserServiceMockRecorder) RemoveEmergencyContact(contactAddress, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveEmergencyContact", reflect.TypeOf((*MockUserService)(nil).RemoveEmergencyContact), contactAddress, auth)
+}
+
+// RequestEmergencyAccess mocks base method.
+func (m *MockUserService) RequestEmergencyAccess(ownerAddress string, auth dto.UserInput) (*dto.EmergencyGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmergencyAccess", ownerAddress, auth)
+	ret0, _ := ret[0].(*dto.EmergencyGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestEmergencyAccess indicates an expected call of RequestEmergencyAccess.
+func (mr *MockUserServiceMockRecorder) RequestEmergencyAccess(ownerAddress, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmergencyAccess", reflect.TypeOf((*MockUserService)(nil).RequestEmergencyAccess), ownerAddress, auth)
+}
+
 // UpdatePassword mocks base method.
 func (m *MockUserService) UpdatePassword(userAddress, password, newPassword string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,221 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/vaults/repository/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/vaults/repository/repository.go -destination=./mocks/vaults_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/vaults/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockVaultsRepository is a mock of VaultsRepository interface.
+type MockVaultsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockVaultsRepositoryMockRecorder is the mock recorder for MockVaultsRepository.
+type MockVaultsRepositoryMockRecorder struct {
+	mock *MockVaultsRepository
+}
+
+// NewMockVaultsRepository creates a new mock instance.
+func NewMockVaultsRepository(ctrl *gomock.Controller) *MockVaultsRepository {
+	mock := &MockVaultsRepository{ctrl: ctrl}
+	mock.recorder = &MockVaultsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultsRepository) EXPECT() *MockVaultsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AcceptMember mocks base method.
+func (m *MockVaultsRepository) AcceptMember(vaultId string, userId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptMember", vaultId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptMember indicates an expected call of AcceptMember.
+func (mr *MockVaultsRepositoryMockRecorder) AcceptMember(vaultId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptMember", reflect.TypeOf((*MockVaultsRepository)(nil).AcceptMember), vaultId, userId)
+}
+
+// AddMember mocks base method.
+func (m *MockVaultsRepository) AddMember(vaultId string, userId int64, member dto.MemberInput) (*dto.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", vaultId, userId, member)
+	ret0, _ := ret[0].(*dto.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockVaultsRepositoryMockRecorder) AddMember(vaultId, userId, member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockVaultsRepository)(nil).AddMember), vaultId, userId, member)
+}
+
+// CreateVault mocks base method.
+func (m *MockVaultsRepository) CreateVault(userId int64, vault dto.VaultInput) (*dto.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVault", userId, vault)
+	ret0, _ := ret[0].(*dto.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVault indicates an expected call of CreateVault.
+func (mr *MockVaultsRepositoryMockRecorder) CreateVault(userId, vault any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVault", reflect.TypeOf((*MockVaultsRepository)(nil).CreateVault), userId, vault)
+}
+
+// DeleteVault mocks base method.
+func (m *MockVaultsRepository) DeleteVault(vaultId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVault", vaultId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteVault indicates an expected call of DeleteVault.
+func (mr *MockVaultsRepositoryMockRecorder) DeleteVault(vaultId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockVaultsRepository)(nil).DeleteVault), vaultId)
+}
+
+// GetMemberIds mocks base method.
+func (m *MockVaultsRepository) GetMemberIds(vaultId string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberIds", vaultId)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberIds indicates an expected call of GetMemberIds.
+func (mr *MockVaultsRepositoryMockRecorder) GetMemberIds(vaultId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberIds", reflect.TypeOf((*MockVaultsRepository)(nil).GetMemberIds), vaultId)
+}
+
+// GetMembers mocks base method.
+func (m *MockVaultsRepository) GetMembers(vaultId string) ([]dto.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", vaultId)
+	ret0, _ := ret[0].([]dto.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockVaultsRepositoryMockRecorder) GetMembers(vaultId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockVaultsRepository)(nil).GetMembers), vaultId)
+}
+
+// GetMembership mocks base method.
+func (m *MockVaultsRepository) GetMembership(vaultId string, userId int64) (*dto.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembership", vaultId, userId)
+	ret0, _ := ret[0].(*dto.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembership indicates an expected call of GetMembership.
+func (mr *MockVaultsRepositoryMockRecorder) GetMembership(vaultId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembership", reflect.TypeOf((*MockVaultsRepository)(nil).GetMembership), vaultId, userId)
+}
+
+// GetRotationStatus mocks base method.
+func (m *MockVaultsRepository) GetRotationStatus(vaultId string) (*dto.RotationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRotationStatus", vaultId)
+	ret0, _ := ret[0].(*dto.RotationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRotationStatus indicates an expected call of GetRotationStatus.
+func (mr *MockVaultsRepositoryMockRecorder) GetRotationStatus(vaultId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRotationStatus", reflect.TypeOf((*MockVaultsRepository)(nil).GetRotationStatus), vaultId)
+}
+
+// GetVaultsByUser mocks base method.
+func (m *MockVaultsRepository) GetVaultsByUser(userId int64) ([]dto.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVaultsByUser", userId)
+	ret0, _ := ret[0].([]dto.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaultsByUser indicates an expected call of GetVaultsByUser.
+func (mr *MockVaultsRepositoryMockRecorder) GetVaultsByUser(userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaultsByUser", reflect.TypeOf((*MockVaultsRepository)(nil).GetVaultsByUser), userId)
+}
+
+// RemoveMember mocks base method.
+func (m *MockVaultsRepository) RemoveMember(vaultId string, userId int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", vaultId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockVaultsRepositoryMockRecorder) RemoveMember(vaultId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockVaultsRepository)(nil).RemoveMember), vaultId, userId)
+}
+
+// RotateVaultKey mocks base method.
+func (m *MockVaultsRepository) RotateVaultKey(vaultId string, keyVersion int, memberKeys []dto.MemberKey) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateVaultKey", vaultId, keyVersion, memberKeys)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateVaultKey indicates an expected call of RotateVaultKey.
+func (mr *MockVaultsRepositoryMockRecorder) RotateVaultKey(vaultId, keyVersion, memberKeys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateVaultKey", reflect.TypeOf((*MockVaultsRepository)(nil).RotateVaultKey), vaultId, keyVersion, memberKeys)
+}
+
+// UpdateMemberRole mocks base method.
+func (m *MockVaultsRepository) UpdateMemberRole(vaultId string, userId int64, role string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberRole", vaultId, userId, role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMemberRole indicates an expected call of UpdateMemberRole.
+func (mr *MockVaultsRepositoryMockRecorder) UpdateMemberRole(vaultId, userId, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRole", reflect.TypeOf((*MockVaultsRepository)(nil).UpdateMemberRole), vaultId, userId, role)
+}
@@ -0,0 +1,216 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/vaults/service/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/vaults/service/service.go -destination=./mocks/vaults_service_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	dto "github.com/ObscuraNote/api-general/internal/vaults/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockVaultsService is a mock of VaultsService interface.
+type MockVaultsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultsServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockVaultsServiceMockRecorder is the mock recorder for MockVaultsService.
+type MockVaultsServiceMockRecorder struct {
+	mock *MockVaultsService
+}
+
+// NewMockVaultsService creates a new mock instance.
+func NewMockVaultsService(ctrl *gomock.Controller) *MockVaultsService {
+	mock := &MockVaultsService{ctrl: ctrl}
+	mock.recorder = &MockVaultsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultsService) EXPECT() *MockVaultsServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptInvitation mocks base method.
+func (m *MockVaultsService) AcceptInvitation(vaultId string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", vaultId, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockVaultsServiceMockRecorder) AcceptInvitation(vaultId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockVaultsService)(nil).AcceptInvitation), vaultId, auth)
+}
+
+// ChangeMemberRole mocks base method.
+func (m *MockVaultsService) ChangeMemberRole(vaultId, memberAddress string, input dto.RoleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeMemberRole", vaultId, memberAddress, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeMemberRole indicates an expected call of ChangeMemberRole.
+func (mr *MockVaultsServiceMockRecorder) ChangeMemberRole(vaultId, memberAddress, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeMemberRole", reflect.TypeOf((*MockVaultsService)(nil).ChangeMemberRole), vaultId, memberAddress, input)
+}
+
+// CreateVault mocks base method.
+func (m *MockVaultsService) CreateVault(vault dto.VaultInput) (*dto.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVault", vault)
+	ret0, _ := ret[0].(*dto.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVault indicates an expected call of CreateVault.
+func (mr *MockVaultsServiceMockRecorder) CreateVault(vault any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVault", reflect.TypeOf((*MockVaultsService)(nil).CreateVault), vault)
+}
+
+// DeleteVault mocks base method.
+func (m *MockVaultsService) DeleteVault(vaultId string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVault", vaultId, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVault indicates an expected call of DeleteVault.
+func (mr *MockVaultsServiceMockRecorder) DeleteVault(vaultId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockVaultsService)(nil).DeleteVault), vaultId, auth)
+}
+
+// GetMemberIds mocks base method.
+func (m *MockVaultsService) GetMemberIds(vaultId string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberIds", vaultId)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberIds indicates an expected call of GetMemberIds.
+func (mr *MockVaultsServiceMockRecorder) GetMemberIds(vaultId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberIds", reflect.TypeOf((*MockVaultsService)(nil).GetMemberIds), vaultId)
+}
+
+// GetMembers mocks base method.
+func (m *MockVaultsService) GetMembers(vaultId string, auth dto.AuthInput) ([]dto.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", vaultId, auth)
+	ret0, _ := ret[0].([]dto.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockVaultsServiceMockRecorder) GetMembers(vaultId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockVaultsService)(nil).GetMembers), vaultId, auth)
+}
+
+// GetRotationStatus mocks base method.
+func (m *MockVaultsService) GetRotationStatus(vaultId string, auth dto.AuthInput) (*dto.RotationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRotationStatus", vaultId, auth)
+	ret0, _ := ret[0].(*dto.RotationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRotationStatus indicates an expected call of GetRotationStatus.
+func (mr *MockVaultsServiceMockRecorder) GetRotationStatus(vaultId, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRotationStatus", reflect.TypeOf((*MockVaultsService)(nil).GetRotationStatus), vaultId, auth)
+}
+
+// GetVaults mocks base method.
+func (m *MockVaultsService) GetVaults(auth dto.AuthInput) ([]dto.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVaults", auth)
+	ret0, _ := ret[0].([]dto.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaults indicates an expected call of GetVaults.
+func (mr *MockVaultsServiceMockRecorder) GetVaults(auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaults", reflect.TypeOf((*MockVaultsService)(nil).GetVaults), auth)
+}
+
+// InviteMember mocks base method.
+func (m *MockVaultsService) InviteMember(vaultId string, member dto.MemberInput) (*dto.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteMember", vaultId, member)
+	ret0, _ := ret[0].(*dto.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteMember indicates an expected call of InviteMember.
+func (mr *MockVaultsServiceMockRecorder) InviteMember(vaultId, member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteMember", reflect.TypeOf((*MockVaultsService)(nil).InviteMember), vaultId, member)
+}
+
+// RemoveMember mocks base method.
+func (m *MockVaultsService) RemoveMember(vaultId, memberAddress string, auth dto.AuthInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", vaultId, memberAddress, auth)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockVaultsServiceMockRecorder) RemoveMember(vaultId, memberAddress, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockVaultsService)(nil).RemoveMember), vaultId, memberAddress, auth)
+}
+
+// RequireRole mocks base method.
+func (m *MockVaultsService) RequireRole(vaultId string, userId int64, minRole string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequireRole", vaultId, userId, minRole)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequireRole indicates an expected call of RequireRole.
+func (mr *MockVaultsServiceMockRecorder) RequireRole(vaultId, userId, minRole any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequireRole", reflect.TypeOf((*MockVaultsService)(nil).RequireRole), vaultId, userId, minRole)
+}
+
+// RotateKey mocks base method.
+func (m *MockVaultsService) RotateKey(vaultId string, rotation dto.RotationInput) (*dto.RotationStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateKey", vaultId, rotation)
+	ret0, _ := ret[0].(*dto.RotationStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateKey indicates an expected call of RotateKey.
+func (mr *MockVaultsServiceMockRecorder) RotateKey(vaultId, rotation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateKey", reflect.TypeOf((*MockVaultsService)(nil).RotateKey), vaultId, rotation)
+}